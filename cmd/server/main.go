@@ -2,19 +2,44 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
 	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/api"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/auth"
 	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/config"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/consensus"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/coopetition"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/delivery"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/enrichment"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/escalation"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/incident"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/leader"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/loop"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/notes"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/notify"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/oncall"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/rules"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/runbook"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/scheduler"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/silence"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blob"
 	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blockchain"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/mail"
 	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/search"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/search/replay"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/timeseries"
+	_ "github.com/lib/pq"
 )
 
 func main() {
@@ -29,24 +54,525 @@ func main() {
 
 	// Initialize components
 	detector := anomaly.NewDetector()
-	searchClient := search.NewBingSearchClient(cfg.Bing.APIKey, cfg.Bing.Endpoint)
+
+	// Only accept anomalies of registered types with valid metadata
+	types, err := anomaly.NewRegistry(anomaly.DefaultTypes...)
+	if err != nil {
+		log.Fatalf("Invalid built-in anomaly types: %v", err)
+	}
+	if cfg.Types.File != "" {
+		defs, err := anomaly.LoadTypesFile(cfg.Types.File)
+		if err != nil {
+			log.Fatalf("Failed to load anomaly types: %v", err)
+		}
+		if err := types.Register(defs...); err != nil {
+			log.Fatalf("Invalid anomaly type: %v", err)
+		}
+	}
+	detector.SetRegistry(types)
+
+	// Flag anomalies expected during silences and maintenance windows
+	var silences *silence.Store
+	if cfg.Silences.Enabled {
+		silences = silence.NewStore()
+		if cfg.Silences.WindowsFile != "" {
+			windows, err := silence.LoadWindowsFile(cfg.Silences.WindowsFile)
+			if err != nil {
+				log.Fatalf("Failed to load maintenance windows: %v", err)
+			}
+			if err := silences.AddWindows(windows...); err != nil {
+				log.Fatalf("Invalid maintenance window: %v", err)
+			}
+		}
+		detector.SetSuppressor(silences)
+	}
+
+	bingOpts := []search.ClientOption{
+		search.WithTimeout(time.Duration(cfg.Bing.Timeout) * time.Second),
+	}
+	bingKey := cfg.Bing.APIKey
+	if cfg.Bing.CassetteMode != "" {
+		transport, err := replay.NewTransport(replay.Mode(cfg.Bing.CassetteMode), cfg.Bing.CassettePath, nil)
+		if err != nil {
+			log.Fatalf("Failed to open search cassette: %v", err)
+		}
+		bingOpts = append(bingOpts, search.WithTransport(transport))
+		if bingKey == "" && cfg.Bing.CassetteMode == string(replay.ModeReplay) {
+			// Replay never reaches Bing, so any key bypasses the mock
+			bingKey = "replay"
+		}
+		log.Printf("📼 Search %s mode using cassette %s", cfg.Bing.CassetteMode, cfg.Bing.CassettePath)
+	}
+	bingClient := search.NewBingSearchClient(bingKey, cfg.Bing.Endpoint, bingOpts...)
+	searchClient := search.NewGuardedProvider(bingClient, search.MockSearchClient{},
+		search.NewResponseCache(time.Duration(cfg.Bing.CacheTTL)*time.Second, cfg.Bing.CacheSize),
+		search.NewTokenBucket(cfg.Bing.RateLimit, cfg.Bing.RateBurst),
+		search.NewCircuitBreaker(cfg.Bing.BreakerThreshold, time.Duration(cfg.Bing.BreakerCooldown)*time.Second),
+	)
 	blockchainClient := blockchain.NewManusClient(
 		cfg.Manus.NodeURL,
 		cfg.Manus.NetworkID,
 		cfg.Manus.EnablePlanetary,
 	)
 
+	// Keep the local index in step with anomalies and ledger writes
+	localSearch := search.NewLocalSearchClient(search.NewIndex(nil))
+	detector.Subscribe(func(event anomaly.Event) {
+		localSearch.IndexAnomaly(event.Anomaly)
+	})
+	blockchainClient.Subscribe(localSearch.IndexLedgerEntry)
+
+	handlerOpts := []api.Option{
+		api.WithSearchProvider(localSearch),
+		api.WithSearchGuard(searchClient),
+	}
+	if silences != nil {
+		handlerOpts = append(handlerOpts, api.WithSilences(silences))
+	}
+
+	// Enrich new anomalies with search context in the background
+	var enricher *enrichment.Enricher
+	if cfg.Enrichment.Enabled {
+		enrichCfg := enrichment.DefaultConfig()
+		enrichCfg.Workers = cfg.Enrichment.Workers
+		enrichCfg.QueueSize = cfg.Enrichment.QueueSize
+		for _, t := range cfg.Enrichment.DisabledTypes {
+			enrichCfg.DisabledTypes = append(enrichCfg.DisabledTypes, models.AnomalyType(t))
+		}
+
+		enricher, err = enrichment.NewEnricher(detector, searchClient, localSearch, enrichCfg)
+		if err != nil {
+			log.Fatalf("Failed to create enricher: %v", err)
+		}
+		enricher.Start()
+		detector.Subscribe(enricher.HandleEvent)
+		handlerOpts = append(handlerOpts, api.WithEnricher(enricher))
+	}
+
+	// Triage anomalies through the Superintelligence Loop, after enrichment
+	// when it is enabled
+	var triage *loop.Loop
+	if cfg.Loop.Enabled {
+		loopCfg := loop.DefaultConfig()
+		loopCfg.Workers = cfg.Loop.Workers
+		loopCfg.MinConfidence = cfg.Loop.MinConfidence
+		loopCfg.WaitForEnrichment = enricher != nil
+		if len(cfg.Loop.SafeTypes) > 0 {
+			loopCfg.SafeTypes = nil
+			for _, t := range cfg.Loop.SafeTypes {
+				loopCfg.SafeTypes = append(loopCfg.SafeTypes, models.AnomalyType(t))
+			}
+		}
+		if len(cfg.Loop.SafeSeverities) > 0 {
+			loopCfg.SafeSeverities = nil
+			for _, s := range cfg.Loop.SafeSeverities {
+				loopCfg.SafeSeverities = append(loopCfg.SafeSeverities, models.AnomalySeverity(s))
+			}
+		}
+
+		triage = loop.New(detector, blockchainClient, loopCfg,
+			loop.PlaybookSource{Playbook: loop.DefaultPlaybook},
+			loop.HistorySource{Local: localSearch},
+		)
+		triage.Start()
+		detector.Subscribe(triage.HandleEvent)
+		handlerOpts = append(handlerOpts, api.WithLoop(triage))
+	}
+
+	// Agents detect anomalies and compete to resolve them through the loop
+	var arena *coopetition.Arena
+	if cfg.Agents.Enabled {
+		// Copilot verifies commits as they are anchored; the Emissary reads
+		// route latency from ingested metrics
+		copilot := coopetition.NewCopilotAgent(blockchainClient, loop.HistorySource{Local: localSearch})
+		blockchainClient.Subscribe(copilot.WatchLedger)
+		arena = coopetition.NewArena(detector,
+			coopetition.NewManusAgent(blockchainClient, time.Duration(cfg.Agents.MaxBlockAge)*time.Second),
+			copilot,
+			coopetition.NewEmissaryAgent(cfg.Agents.LatencyThresholdMs),
+		)
+		detector.Subscribe(arena.HandleEvent)
+		if triage != nil {
+			triage.AddSource(arena)
+		}
+		handlerOpts = append(handlerOpts, api.WithArena(arena))
+	}
+
+	// Require N-of-M approvals before resolving high-impact anomalies
+	var board *consensus.Board
+	if cfg.Consensus.Enabled {
+		policy := consensus.DefaultPolicy()
+		policy.Required = cfg.Consensus.Required
+		policy.Voters = cfg.Consensus.Voters
+		policy.VoteTTL = time.Duration(cfg.Consensus.VoteTTL) * time.Second
+		if len(cfg.Consensus.Severities) > 0 {
+			policy.Severities = nil
+			for _, s := range cfg.Consensus.Severities {
+				policy.Severities = append(policy.Severities, models.AnomalySeverity(s))
+			}
+		}
+
+		var voters []consensus.Voter
+		if arena != nil && cfg.Consensus.AgentsVote {
+			for _, agent := range arena.Agents() {
+				voters = append(voters, coopetition.AgentVoter{Agent: agent, MinConfidence: cfg.Loop.MinConfidence})
+			}
+		}
+
+		var err error
+		board, err = consensus.NewBoard(detector, blockchainClient, policy, voters...)
+		if err != nil {
+			log.Fatalf("Invalid consensus policy: %v", err)
+		}
+		if triage != nil {
+			triage.SetGate(board)
+		}
+		handlerOpts = append(handlerOpts, api.WithConsensus(board))
+
+	}
+
+	// Remediation runbooks, resolving through consensus where required
+	if cfg.Runbooks.Enabled {
+		runner := runbook.NewRunner(detector, blockchainClient,
+			&http.Client{Timeout: time.Duration(cfg.Runbooks.WebhookTimeout) * time.Second})
+		runbooks := runbook.DefaultRunbooks
+		if cfg.Runbooks.File != "" {
+			runbooks, err = runbook.LoadFile(cfg.Runbooks.File)
+			if err != nil {
+				log.Fatalf("Failed to load runbooks: %v", err)
+			}
+		}
+		if err := runner.Add(runbooks...); err != nil {
+			log.Fatalf("Invalid runbook: %v", err)
+		}
+		if board != nil {
+			runner.SetGate(board)
+		}
+		handlerOpts = append(handlerOpts, api.WithRunbooks(runner))
+	}
+
+	// Raise anomalies from declarative rules over metrics and events
+	if cfg.Rules.Enabled {
+		ruleEngine := rules.NewEngine(detector)
+		if cfg.Rules.File != "" {
+			loaded, err := rules.LoadFile(cfg.Rules.File)
+			if err != nil {
+				log.Fatalf("Failed to load rules: %v", err)
+			}
+			if err := ruleEngine.Add(loaded...); err != nil {
+				log.Fatalf("Invalid rule: %v", err)
+			}
+		}
+		detector.Subscribe(ruleEngine.HandleEvent)
+		handlerOpts = append(handlerOpts, api.WithRules(ruleEngine))
+	}
+
+	// Group correlated anomalies into incidents
+	if cfg.Incidents.Enabled {
+		incidentCfg := incident.DefaultConfig(time.Duration(cfg.Incidents.Window) * time.Second)
+		if cfg.Incidents.RulesFile != "" {
+			incidentCfg.Rules, err = incident.LoadRulesFile(cfg.Incidents.RulesFile)
+			if err != nil {
+				log.Fatalf("Failed to load correlation rules: %v", err)
+			}
+		}
+		correlator, err := incident.NewCorrelator(detector, incidentCfg)
+		if err != nil {
+			log.Fatalf("Invalid correlation rule: %v", err)
+		}
+		detector.Subscribe(correlator.HandleEvent)
+		handlerOpts = append(handlerOpts, api.WithIncidents(correlator))
+	}
+
+	// Assign new anomalies to whoever is on call for them
+	if cfg.OnCall.Enabled {
+		var rosterCfg oncall.Config
+		if cfg.OnCall.File != "" {
+			rosterCfg, err = oncall.LoadFile(cfg.OnCall.File)
+			if err != nil {
+				log.Fatalf("Failed to load on-call rotations: %v", err)
+			}
+		}
+		roster, err := oncall.NewRoster(rosterCfg)
+		if err != nil {
+			log.Fatalf("Invalid on-call rotation: %v", err)
+		}
+		assigner := oncall.NewAssigner(detector, roster)
+		detector.Subscribe(assigner.HandleEvent)
+		handlerOpts = append(handlerOpts, api.WithOnCall(assigner))
+	}
+
+	// Keep comments and attachments with the anomalies they investigate
+	if cfg.Notes.Enabled {
+		blobs, err := blob.NewLocal(cfg.Notes.AttachmentDir)
+		if err != nil {
+			log.Fatalf("Failed to open attachment store: %v", err)
+		}
+		notesStore := notes.NewStore(detector, blobs, int64(cfg.Notes.MaxAttachmentSize))
+		if board != nil {
+			board.SetNotes(notesStore)
+		}
+		handlerOpts = append(handlerOpts, api.WithNotes(notesStore))
+	}
+
+	// Run detection sources and housekeeping on their own schedules
+	jobs := scheduler.New()
+	addJob := func(name, spec string, run func(ctx context.Context) (int, error)) {
+		if spec == "" {
+			return
+		}
+		schedule, err := scheduler.Parse(spec)
+		if err != nil {
+			log.Fatalf("Invalid schedule for %s: %v", name, err)
+		}
+		err = jobs.Add(scheduler.Job{
+			Name:     name,
+			Schedule: schedule,
+			Jitter:   time.Duration(cfg.Scheduler.Jitter) * time.Second,
+			Timeout:  time.Duration(cfg.Scheduler.Timeout) * time.Second,
+			Run:      run,
+		})
+		if err != nil {
+			log.Fatalf("Failed to schedule %s: %v", name, err)
+		}
+	}
+	if cfg.Scheduler.Enabled {
+		if arena != nil {
+			schedules := map[string]struct{ job, spec string }{
+				coopetition.AgentManus:    {"agent:manus", cfg.Scheduler.Manus},
+				coopetition.AgentCopilot:  {"agent:copilot", cfg.Scheduler.Copilot},
+				coopetition.AgentEmissary: {"agent:emissary", cfg.Scheduler.Emissary},
+			}
+			for _, agent := range arena.Agents() {
+				agent := agent
+				s := schedules[agent.Name()]
+				addJob(s.job, s.spec, arena.DetectJob(agent))
+			}
+		}
+		addJob("simulation", cfg.Scheduler.Simulation, func(ctx context.Context) (int, error) {
+			return len(detector.DetectAnomalies()), nil
+		})
+	}
+	if board != nil && cfg.Consensus.SweepPeriod > 0 {
+		addJob("consensus-expiry", fmt.Sprintf("@every %ds", cfg.Consensus.SweepPeriod), func(ctx context.Context) (int, error) {
+			n := board.Expire()
+			if n > 0 {
+				log.Printf("🗳️  Expired %d stale approval votes", n)
+			}
+			return n, nil
+		})
+	}
+
+	// Email goes through an SMTP relay when one is configured
+	var mailer mail.Sender
+	if cfg.SMTP.Host != "" {
+		smtp, err := mail.NewSMTP(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From)
+		if err != nil {
+			log.Fatalf("Invalid SMTP configuration: %v", err)
+		}
+		mailer = smtp
+	}
+
+	// Route anomaly events and escalations to chat, email and paging
+	// channels
+	var channels *notify.Router
+	if cfg.Notify.ChannelsFile != "" {
+		configs, err := notify.LoadFile(cfg.Notify.ChannelsFile)
+		if err != nil {
+			log.Fatalf("Failed to load notification channels: %v", err)
+		}
+		channels, err = notify.NewRouter(configs, mailer, &http.Client{
+			Timeout: time.Duration(cfg.Notify.WebhookTimeout) * time.Second,
+		})
+		if err != nil {
+			log.Fatalf("Invalid notification channel: %v", err)
+		}
+		detector.Subscribe(channels.HandleEvent)
+		addJob("notify-digest", cfg.Notify.DigestSchedule, channels.Flush)
+		handlerOpts = append(handlerOpts, api.WithNotifications(channels))
+	}
+
+	// Track SLAs and escalate anomalies that miss them
+	if cfg.Escalation.Enabled {
+		notifiers := notify.Multi{notify.LogNotifier{}}
+		if cfg.Notify.WebhookURL != "" {
+			notifiers = append(notifiers, notify.NewWebhookNotifier(cfg.Notify.WebhookURL, &http.Client{
+				Timeout: time.Duration(cfg.Notify.WebhookTimeout) * time.Second,
+			}))
+		}
+		if channels != nil {
+			notifiers = append(notifiers, channels)
+		}
+		policies := escalation.DefaultPolicies
+		if cfg.Escalation.PolicyFile != "" {
+			policies, err = escalation.LoadFile(cfg.Escalation.PolicyFile)
+			if err != nil {
+				log.Fatalf("Failed to load escalation policies: %v", err)
+			}
+		}
+		tracker, err := escalation.NewTracker(detector, notifiers, policies...)
+		if err != nil {
+			log.Fatalf("Invalid escalation policy: %v", err)
+		}
+		detector.Subscribe(tracker.HandleEvent)
+		addJob("escalation", cfg.Escalation.CheckSchedule, tracker.Check)
+		handlerOpts = append(handlerOpts, api.WithEscalation(tracker))
+	}
+
+	// Deliver subscribed reports by email and webhook
+	if cfg.Reports.Enabled {
+		reports := delivery.NewService(detector, mailer, nil)
+		if cfg.Reports.SubscriptionsFile != "" {
+			subs, err := delivery.LoadFile(cfg.Reports.SubscriptionsFile)
+			if err != nil {
+				log.Fatalf("Failed to load report subscriptions: %v", err)
+			}
+			if err := reports.Add(subs...); err != nil {
+				log.Fatalf("Invalid report subscription: %v", err)
+			}
+		}
+		addJob("report-delivery", cfg.Reports.CheckSchedule, reports.Run)
+		handlerOpts = append(handlerOpts, api.WithDelivery(reports))
+	}
+
+	// Ingest metrics and score them with the time-series engine
+	var metricStore *timeseries.Store
+	if cfg.Metrics.Enabled {
+		metricStore = timeseries.NewStore(timeseries.StoreConfig{
+			Capacity:   cfg.Metrics.BufferSize,
+			MaxSeries:  cfg.Metrics.MaxSeries,
+			Resolution: time.Duration(cfg.Metrics.RollupResolution) * time.Second,
+			Rollups:    cfg.Metrics.RollupSize,
+		})
+		engine, err := timeseries.NewEngine(timeseries.DefaultMetrics...)
+		if err != nil {
+			log.Fatalf("Invalid metric configuration: %v", err)
+		}
+		engine.SetMaxSeries(cfg.Metrics.MaxSeries)
+		if path := cfg.Metrics.PersistFile; path != "" {
+			if err := metricStore.Load(path); err != nil {
+				log.Fatalf("Failed to load metrics: %v", err)
+			}
+			schedule, err := scheduler.Parse(cfg.Metrics.PersistSchedule)
+			if err != nil {
+				log.Fatalf("Invalid schedule for metrics-persist: %v", err)
+			}
+			// Each replica persists the metrics it received
+			err = jobs.Add(scheduler.Job{
+				Name:         "metrics-persist",
+				Schedule:     schedule,
+				Timeout:      time.Duration(cfg.Scheduler.Timeout) * time.Second,
+				EveryReplica: true,
+				Run: func(ctx context.Context) (int, error) {
+					return 0, metricStore.Save(path)
+				},
+			})
+			if err != nil {
+				log.Fatalf("Failed to schedule metrics-persist: %v", err)
+			}
+		}
+		handlerOpts = append(handlerOpts, api.WithMetrics(metricStore, engine))
+	}
+	handlerOpts = append(handlerOpts, api.WithScheduler(jobs))
+
+	// Elect one replica to run scheduled work when several are deployed
+	var elector *leader.Elector
+	if cfg.Leader.Enabled {
+		var store leader.Store
+		switch cfg.Leader.Store {
+		case "memory":
+			store = leader.NewMemoryStore()
+		case "sql":
+			db, err := sql.Open("postgres", cfg.Database.DSN())
+			if err != nil {
+				log.Fatalf("Failed to open database: %v", err)
+			}
+			defer db.Close()
+			sqlStore := leader.NewSQLStore(db)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			err = sqlStore.Migrate(ctx)
+			cancel()
+			if err != nil {
+				log.Fatalf("Failed to prepare leader election: %v", err)
+			}
+			store = sqlStore
+		default:
+			log.Fatalf("Unknown leader store %q", cfg.Leader.Store)
+		}
+
+		identity := cfg.Leader.Identity
+		if identity == "" {
+			identity, _ = os.Hostname()
+		}
+		elector = leader.NewElector(store, cfg.Leader.Lease, identity,
+			time.Duration(cfg.Leader.LeaseTTL)*time.Second)
+		elector.Subscribe(func(leading bool) {
+			if leading {
+				log.Printf("👑 %s is now the leader replica", identity)
+			} else {
+				log.Printf("💤 %s is now a standby replica", identity)
+			}
+		})
+		elector.Start()
+		jobs.SetLeader(elector)
+		handlerOpts = append(handlerOpts, api.WithElector(elector))
+	}
+
+	// Authenticate API requests with API keys and signed JWTs
+	var authn *auth.Authenticator
+	if cfg.Auth.Enabled {
+		keys, err := auth.NewKeyStore(cfg.Auth.KeysFile)
+		if err != nil {
+			log.Fatalf("Failed to load API keys: %v", err)
+		}
+		var verifier *auth.Verifier
+		if cfg.Auth.JWTIssuer != "" {
+			jwtConfig := auth.JWTConfig{
+				Issuer:      cfg.Auth.JWTIssuer,
+				Audience:    cfg.Auth.JWTAudience,
+				HMACSecret:  []byte(cfg.Auth.JWTHMACSecret),
+				Ed25519Keys: make(map[string]ed25519.PublicKey),
+			}
+			for _, entry := range cfg.Auth.JWTEd25519Keys {
+				kid, encoded, ok := strings.Cut(entry, "=")
+				if !ok {
+					log.Fatalf("Invalid Ed25519 key %q: expected kid=base64", entry)
+				}
+				key, err := auth.ParseEd25519Key(encoded)
+				if err != nil {
+					log.Fatalf("Invalid Ed25519 key %s: %v", kid, err)
+				}
+				jwtConfig.Ed25519Keys[kid] = key
+			}
+			verifier, err = auth.NewVerifier(jwtConfig)
+			if err != nil {
+				log.Fatalf("Invalid JWT configuration: %v", err)
+			}
+		}
+		if cfg.Auth.BootstrapKey == "" && verifier == nil && len(keys.Keys()) == 0 {
+			log.Fatalf("Authentication is enabled but no credentials are configured: set AUTH_BOOTSTRAP_KEY or AUTH_JWT_ISSUER, or AUTH_ENABLED=false")
+		}
+		authn = auth.NewAuthenticator(keys, verifier, cfg.Auth.BootstrapKey)
+		handlerOpts = append(handlerOpts, api.WithAuth(keys))
+	} else {
+		log.Println("⚠️  API authentication is disabled; every endpoint is open")
+	}
+
 	// Create API handler
-	handler := api.NewHandler(detector, searchClient, blockchainClient)
+	handler := api.NewHandler(detector, searchClient, blockchainClient, handlerOpts...)
 
 	// Setup routes
 	router := api.SetupRoutes(handler)
+	var root http.Handler = router
+	if authn != nil {
+		root = authn.Middleware(router)
+	}
 
 	// Create server
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	server := &http.Server{
 		Addr:         addr,
-		Handler:      router,
+		Handler:      root,
 		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
 	}
@@ -62,10 +588,20 @@ func main() {
 		}
 	}()
 
-	// Perform initial anomaly detection
-	log.Println("🔍 Running initial anomaly detection...")
-	anomalies := detector.DetectAnomalies()
-	log.Printf("✅ Detected %d anomalies", len(anomalies))
+	// Perform initial anomaly detection on the leader only
+	if elector == nil || elector.IsLeader() {
+		log.Println("🔍 Running initial anomaly detection...")
+		anomalies := detector.DetectAnomalies()
+		log.Printf("✅ Detected %d anomalies", len(anomalies))
+		if arena != nil {
+			found, err := arena.Detect(context.Background())
+			if err != nil {
+				log.Printf("⚠️  Agent detection failed: %v", err)
+			}
+			log.Printf("🤝 Agents detected %d anomalies", len(found))
+		}
+	}
+	jobs.Start()
 
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
@@ -81,6 +617,22 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	jobs.Stop()
+	if elector != nil {
+		elector.Stop()
+	}
+	if metricStore != nil && cfg.Metrics.PersistFile != "" {
+		if err := metricStore.Save(cfg.Metrics.PersistFile); err != nil {
+			log.Printf("⚠️  Failed to save metrics: %v", err)
+		}
+	}
+
+	if enricher != nil {
+		enricher.Stop()
+	}
+	if triage != nil {
+		triage.Stop()
+	}
 
 	log.Println("✅ Server exited gracefully")
 }
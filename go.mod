@@ -2,4 +2,7 @@ module github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-I
 
 go 1.22.0
 
-require github.com/google/uuid v1.6.0
+require (
+	github.com/google/uuid v1.6.0
+	github.com/lib/pq v1.10.9
+)
@@ -1,6 +1,7 @@
 package anomaly
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -9,10 +10,41 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrResolved is returned when changing an anomaly that is resolved
+	ErrResolved = errors.New("anomaly is already resolved")
+	// ErrAcknowledged is returned when acknowledging an anomaly twice
+	ErrAcknowledged = errors.New("anomaly is already acknowledged")
+)
+
+// EventType identifies the kind of change reported to subscribers
+type EventType string
+
+const (
+	EventDetected EventType = "detected"
+	EventResolved EventType = "resolved"
+	EventUpdated  EventType = "updated"
+)
+
+// Event describes a change to an anomaly held by the detector
+type Event struct {
+	Type    EventType
+	Anomaly models.Anomaly
+}
+
+// Suppressor decides whether a new anomaly is expected, e.g. during a
+// maintenance window, and names what suppressed it
+type Suppressor interface {
+	Suppress(a *models.Anomaly) (string, bool)
+}
+
 // Detector handles anomaly detection and management
 type Detector struct {
-	anomalies map[string]*models.Anomaly
-	mu        sync.RWMutex
+	anomalies   map[string]*models.Anomaly
+	subscribers []func(Event)
+	registry    *Registry
+	suppressor  Suppressor
+	mu          sync.RWMutex
 }
 
 // NewDetector creates a new anomaly detector
@@ -22,11 +54,59 @@ func NewDetector() *Detector {
 	}
 }
 
-// DetectAnomalies simulates anomaly detection across the system
-func (d *Detector) DetectAnomalies() []*models.Anomaly {
+// Subscribe registers fn to be called after every anomaly change.
+// Subscribers run synchronously outside the detector lock, so they may
+// call back into the detector but should hand slow work to a goroutine.
+func (d *Detector) Subscribe(fn func(Event)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.subscribers = append(d.subscribers, fn)
+}
+
+// SetRegistry makes Record reject anomalies whose type is not in r or
+// whose metadata does not match the type's schema
+func (d *Detector) SetRegistry(r *Registry) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
+	d.registry = r
+}
+
+// SetSuppressor flags new anomalies s matches as suppressed. Suppressed
+// anomalies are recorded and published like any other, but subscribers
+// should not notify about them and reports do not count them as pending.
+func (d *Detector) SetSuppressor(s Suppressor) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.suppressor = s
+}
+
+// Registry returns the type registry, or nil if every type is accepted
+func (d *Detector) Registry() *Registry {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.registry
+}
+
+// publish delivers events to all subscribers; callers must not hold d.mu
+func (d *Detector) publish(events ...Event) {
+	d.mu.RLock()
+	subscribers := make([]func(Event), len(d.subscribers))
+	copy(subscribers, d.subscribers)
+	d.mu.RUnlock()
+
+	for _, event := range events {
+		for _, fn := range subscribers {
+			fn(event)
+		}
+	}
+}
+
+// DetectAnomalies simulates anomaly detection across the system
+func (d *Detector) DetectAnomalies() []*models.Anomaly {
 	// Simulate detection of various anomalies
 	anomalies := []*models.Anomaly{
 		{
@@ -88,12 +168,82 @@ func (d *Detector) DetectAnomalies() []*models.Anomaly {
 		},
 	}
 
-	// Store anomalies
+	return d.Record(anomalies...)
+}
+
+// Record stores anomalies reported by a detection source and notifies
+// subscribers. Missing IDs, detection times and statuses are filled in,
+// and missing severities from the type registry. Anomalies the registry
+// rejects are dropped and counted against their type; only recorded
+// anomalies are returned. Anomalies the suppressor matches are flagged.
+// Missing fingerprints are computed from the type's fingerprint keys.
+func (d *Detector) Record(anomalies ...*models.Anomaly) []*models.Anomaly {
+	d.mu.Lock()
+
+	events := make([]Event, 0, len(anomalies))
+	detected := make([]*models.Anomaly, 0, len(anomalies))
 	for _, anomaly := range anomalies {
+		if d.registry != nil {
+			err := d.registry.Validate(anomaly)
+			d.registry.observe(anomaly.Type, err)
+			if err != nil {
+				continue
+			}
+			if anomaly.Severity == "" {
+				def, _ := d.registry.Get(anomaly.Type)
+				anomaly.Severity = def.DefaultSeverity
+			}
+		}
+		if anomaly.Fingerprint == "" {
+			var keys []string
+			if d.registry != nil {
+				def, _ := d.registry.Get(anomaly.Type)
+				keys = def.Fingerprint
+			}
+			anomaly.Fingerprint = Fingerprint(anomaly, keys)
+		}
+		if anomaly.ID == "" {
+			anomaly.ID = uuid.New().String()
+		}
+		if anomaly.DetectedAt.IsZero() {
+			anomaly.DetectedAt = time.Now()
+		}
+		if anomaly.Status == "" {
+			anomaly.Status = models.StatusDetected
+		}
+		if d.suppressor != nil {
+			anomaly.SilencedBy, anomaly.Suppressed = d.suppressor.Suppress(anomaly)
+		}
+		anomaly.Timeline = append(anomaly.Timeline, models.TimelineEntry{
+			At:     anomaly.DetectedAt,
+			Actor:  anomaly.Source,
+			Action: "detected",
+		})
+		if anomaly.Suppressed {
+			anomaly.Timeline = append(anomaly.Timeline, models.TimelineEntry{
+				At:     time.Now(),
+				Action: "suppressed",
+				Detail: anomaly.SilencedBy,
+			})
+		}
 		d.anomalies[anomaly.ID] = anomaly
+		events = append(events, Event{Type: EventDetected, Anomaly: *anomaly})
+		detected = append(detected, clone(anomaly))
 	}
+	d.mu.Unlock()
 
-	return anomalies
+	d.publish(events...)
+
+	return detected
+}
+
+// Validate checks an anomaly against the type registry without recording it
+func (d *Detector) Validate(a *models.Anomaly) error {
+	registry := d.Registry()
+	if registry == nil {
+		return nil
+	}
+	return registry.Validate(a)
 }
 
 // GetAnomaly retrieves a specific anomaly by ID
@@ -106,7 +256,7 @@ func (d *Detector) GetAnomaly(id string) (*models.Anomaly, error) {
 		return nil, fmt.Errorf("anomaly not found: %s", id)
 	}
 
-	return anomaly, nil
+	return clone(anomaly), nil
 }
 
 // GetAllAnomalies returns all detected anomalies
@@ -116,26 +266,138 @@ func (d *Detector) GetAllAnomalies() []*models.Anomaly {
 
 	anomalies := make([]*models.Anomaly, 0, len(d.anomalies))
 	for _, anomaly := range d.anomalies {
-		anomalies = append(anomalies, anomaly)
+		anomalies = append(anomalies, clone(anomaly))
 	}
 
 	return anomalies
 }
 
-// ResolveAnomaly marks an anomaly as resolved
-func (d *Detector) ResolveAnomaly(id, resolution string) error {
+// Update applies fn to the stored anomaly under the detector lock and
+// notifies subscribers. fn must replace, not mutate, shared maps and
+// pointers such as Metadata and Context; it may append to Timeline.
+func (d *Detector) Update(id string, fn func(*models.Anomaly)) error {
+	d.mu.Lock()
+
+	anomaly, exists := d.anomalies[id]
+	if !exists {
+		d.mu.Unlock()
+		return fmt.Errorf("anomaly not found: %s", id)
+	}
+
+	fn(anomaly)
+	event := Event{Type: EventUpdated, Anomaly: *anomaly}
+	d.mu.Unlock()
+
+	d.publish(event)
+
+	return nil
+}
+
+// Acknowledge records that someone has taken ownership of an unresolved
+// anomaly
+func (d *Detector) Acknowledge(id, by string) error {
 	d.mu.Lock()
-	defer d.mu.Unlock()
 
 	anomaly, exists := d.anomalies[id]
 	if !exists {
+		d.mu.Unlock()
 		return fmt.Errorf("anomaly not found: %s", id)
 	}
+	if anomaly.Status == models.StatusResolved {
+		d.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrResolved, id)
+	}
+	if anomaly.AcknowledgedAt != nil {
+		d.mu.Unlock()
+		return fmt.Errorf("%w by %s", ErrAcknowledged, anomaly.AcknowledgedBy)
+	}
+
+	now := time.Now()
+	anomaly.AcknowledgedAt = &now
+	anomaly.AcknowledgedBy = by
+	anomaly.Timeline = append(anomaly.Timeline, models.TimelineEntry{At: now, Actor: by, Action: "acknowledged"})
+	event := Event{Type: EventUpdated, Anomaly: *anomaly}
+	d.mu.Unlock()
+
+	d.publish(event)
+
+	return nil
+}
+
+// Assign makes assignee the owner of an unresolved anomaly, or clears the
+// owner when assignee is nil, recording the change in its timeline
+func (d *Detector) Assign(id string, assignee *models.Assignment) error {
+	d.mu.Lock()
+
+	anomaly, exists := d.anomalies[id]
+	if !exists {
+		d.mu.Unlock()
+		return fmt.Errorf("anomaly not found: %s", id)
+	}
+	if anomaly.Status == models.StatusResolved {
+		d.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrResolved, id)
+	}
+
+	entry := models.TimelineEntry{At: time.Now()}
+	switch {
+	case assignee == nil:
+		if anomaly.Assignee == nil {
+			d.mu.Unlock()
+			return nil
+		}
+		entry.Action = "unassigned"
+		entry.Detail = "from " + anomaly.Assignee.Name
+	case anomaly.Assignee == nil:
+		entry.Actor = assignee.AssignedBy
+		entry.Action = "assigned"
+		entry.Detail = "to " + assignee.Name
+		entry.At = assignee.AssignedAt
+	default:
+		entry.Actor = assignee.AssignedBy
+		entry.Action = "reassigned"
+		entry.Detail = "from " + anomaly.Assignee.Name + " to " + assignee.Name
+		entry.At = assignee.AssignedAt
+	}
+	if assignee != nil && assignee.Reason != "" {
+		entry.Detail += ": " + assignee.Reason
+	}
+
+	anomaly.Assignee = assignee
+	anomaly.Timeline = append(anomaly.Timeline, entry)
+	event := Event{Type: EventUpdated, Anomaly: *anomaly}
+	d.mu.Unlock()
+
+	d.publish(event)
+
+	return nil
+}
+
+// ResolveAnomaly marks an unresolved anomaly as resolved by by, which may
+// be empty for automated resolutions
+func (d *Detector) ResolveAnomaly(id, resolution, by string) error {
+	d.mu.Lock()
+
+	anomaly, exists := d.anomalies[id]
+	if !exists {
+		d.mu.Unlock()
+		return fmt.Errorf("anomaly not found: %s", id)
+	}
+	if anomaly.Status == models.StatusResolved {
+		d.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrResolved, id)
+	}
 
 	now := time.Now()
 	anomaly.Status = models.StatusResolved
 	anomaly.ResolvedAt = &now
+	anomaly.ResolvedBy = by
 	anomaly.Resolution = resolution
+	anomaly.Timeline = append(anomaly.Timeline, models.TimelineEntry{At: now, Actor: by, Action: "resolved", Detail: resolution})
+	event := Event{Type: EventResolved, Anomaly: *anomaly}
+	d.mu.Unlock()
+
+	d.publish(event)
 
 	return nil
 }
@@ -155,10 +417,13 @@ func (d *Detector) GenerateReport() *models.AnomalyReport {
 	}
 
 	for _, anomaly := range d.anomalies {
-		// Count by status
-		if anomaly.Status == models.StatusResolved {
+		// Count by status; suppressed anomalies are expected, not pending
+		switch {
+		case anomaly.Status == models.StatusResolved:
 			report.ResolvedAnomalies++
-		} else {
+		case anomaly.Suppressed:
+			report.SuppressedAnomalies++
+		default:
 			report.PendingAnomalies++
 		}
 
@@ -168,10 +433,24 @@ func (d *Detector) GenerateReport() *models.AnomalyReport {
 		// Count by type
 		report.ByType[anomaly.Type]++
 	}
+	report.SLA = slaReport(d.anomalies, report.GeneratedAt)
 
 	return report
 }
 
+// clone returns a copy of a that callers can read without holding the lock
+func clone(a *models.Anomaly) *models.Anomaly {
+	c := *a
+	c.Timeline = append([]models.TimelineEntry(nil), a.Timeline...)
+	if a.Metadata != nil {
+		c.Metadata = make(map[string]interface{}, len(a.Metadata))
+		for k, v := range a.Metadata {
+			c.Metadata[k] = v
+		}
+	}
+	return &c
+}
+
 // Helper function to create time pointer
 func timePtr(t time.Time) *time.Time {
 	return &t
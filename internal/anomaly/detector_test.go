@@ -1,6 +1,7 @@
 package anomaly
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
@@ -79,7 +80,7 @@ func TestResolveAnomaly(t *testing.T) {
 	firstAnomaly := anomalies[0]
 	resolution := "Test resolution"
 
-	err := detector.ResolveAnomaly(firstAnomaly.ID, resolution)
+	err := detector.ResolveAnomaly(firstAnomaly.ID, resolution, "alice")
 	if err != nil {
 		t.Fatalf("Failed to resolve anomaly: %v", err)
 	}
@@ -97,6 +98,19 @@ func TestResolveAnomaly(t *testing.T) {
 	if resolved.ResolvedAt == nil {
 		t.Error("ResolvedAt should not be nil")
 	}
+
+	if resolved.ResolvedBy != "alice" {
+		t.Errorf("Expected resolved by alice, got %q", resolved.ResolvedBy)
+	}
+
+	var events int
+	detector.Subscribe(func(Event) { events++ })
+	if err := detector.ResolveAnomaly(firstAnomaly.ID, "Late resolution", "bob"); !errors.Is(err, ErrResolved) {
+		t.Fatalf("Expected ErrResolved resolving twice, got %v", err)
+	}
+	if again, _ := detector.GetAnomaly(firstAnomaly.ID); again.Resolution != resolution || again.ResolvedBy != "alice" || events != 0 {
+		t.Errorf("Expected a second resolution to change nothing, got %q by %q with %d events", again.Resolution, again.ResolvedBy, events)
+	}
 }
 
 func TestGenerateReport(t *testing.T) {
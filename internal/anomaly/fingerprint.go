@@ -0,0 +1,51 @@
+package anomaly
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// Fingerprint identifies the problem behind a, so that recurrences of it
+// can be counted. It hashes the type with the values of keys in a's
+// metadata, or with the source when keys is empty. Array values are
+// compared as sets.
+func Fingerprint(a *models.Anomaly, keys []string) string {
+	parts := []string{string(a.Type)}
+	if len(keys) == 0 {
+		parts = append(parts, "source="+a.Source)
+	}
+	for _, key := range keys {
+		parts = append(parts, key+"="+canonical(a.Metadata[key]))
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// canonical renders v so equal values, including arrays in any order,
+// render the same
+func canonical(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		items := make([]string, rv.Len())
+		for i := range items {
+			items[i] = canonical(rv.Index(i).Interface())
+		}
+		sort.Strings(items)
+		return "[" + strings.Join(items, ",") + "]"
+	}
+	if data, err := json.Marshal(v); err == nil {
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,74 @@
+package anomaly
+
+import (
+	"math"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// SLABreaches reports whether a missed its acknowledge or resolve deadline
+// as of now. Resolving an anomaly also acknowledges it.
+func SLABreaches(a *models.Anomaly, now time.Time) (ack, resolve bool) {
+	if a.SLA == nil {
+		return false, false
+	}
+
+	acked := a.AcknowledgedAt
+	if acked == nil {
+		acked = a.ResolvedAt
+	}
+	if acked != nil {
+		ack = acked.After(a.SLA.AckDeadline)
+	} else {
+		ack = now.After(a.SLA.AckDeadline)
+	}
+
+	if a.ResolvedAt != nil {
+		resolve = a.ResolvedAt.After(a.SLA.ResolveDeadline)
+	} else {
+		resolve = a.Status != models.StatusResolved && now.After(a.SLA.ResolveDeadline)
+	}
+
+	return ack || a.SLA.AckBreached, resolve || a.SLA.ResolveBreached
+}
+
+// slaReport summarises SLA compliance of anomalies; callers hold d.mu
+func slaReport(anomalies map[string]*models.Anomaly, now time.Time) *models.SLAReport {
+	report := &models.SLAReport{BySeverity: make(map[models.AnomalySeverity]float64)}
+	tracked := make(map[models.AnomalySeverity]int)
+	met := make(map[models.AnomalySeverity]int)
+
+	for _, a := range anomalies {
+		if a.SLA == nil {
+			continue
+		}
+		report.Tracked++
+		tracked[a.SLA.Policy]++
+
+		ack, resolve := SLABreaches(a, now)
+		if ack {
+			report.AckBreached++
+		}
+		if resolve {
+			report.ResolveBreached++
+		}
+		if !ack && !resolve {
+			report.Met++
+			met[a.SLA.Policy]++
+		}
+	}
+	if report.Tracked == 0 {
+		return nil
+	}
+
+	report.Compliance = ratio(report.Met, report.Tracked)
+	for severity, n := range tracked {
+		report.BySeverity[severity] = ratio(met[severity], n)
+	}
+	return report
+}
+
+func ratio(n, total int) float64 {
+	return math.Round(float64(n)/float64(total)*1000) / 1000
+}
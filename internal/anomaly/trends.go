@@ -0,0 +1,285 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// maxTrendBuckets bounds the buckets one trend report may hold
+const maxTrendBuckets = 400
+
+// TrendQuery selects the time range and bucket size of a trend report.
+// Zero values default to the 30 days up to now in daily buckets with the
+// top 10 fingerprints.
+type TrendQuery struct {
+	From   time.Time
+	To     time.Time
+	Bucket models.TrendBucketSize
+	Top    int
+}
+
+func (q *TrendQuery) normalize(now time.Time) (time.Duration, error) {
+	if q.To.IsZero() {
+		q.To = now
+	}
+	if q.From.IsZero() {
+		q.From = q.To.AddDate(0, 0, -30)
+	}
+	if q.Bucket == "" {
+		q.Bucket = models.BucketDay
+	}
+	if q.Top <= 0 {
+		q.Top = 10
+	}
+	if !q.To.After(q.From) {
+		return 0, fmt.Errorf("to must be after from")
+	}
+
+	var width time.Duration
+	switch q.Bucket {
+	case models.BucketDay:
+		width = 24 * time.Hour
+	case models.BucketWeek:
+		width = 7 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("invalid bucket %q", q.Bucket)
+	}
+	if q.To.Sub(q.From) > maxTrendBuckets*width {
+		return 0, fmt.Errorf("range spans more than %d %s buckets", maxTrendBuckets, q.Bucket)
+	}
+	return width, nil
+}
+
+// Trends reports how anomalies developed over q's range: new and resolved
+// counts per bucket, time to acknowledge (MTTA) of anomalies acknowledged
+// in the range and time to resolve (MTTR) of those resolved in it, the
+// most recurring fingerprints and how often resolved problems came back.
+// The range is compared with the period of the same length before it.
+// Suppressed anomalies are left out.
+func (d *Detector) Trends(q TrendQuery) (*models.TrendReport, error) {
+	now := time.Now()
+	width, err := q.normalize(now)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	anomalies := make([]*models.Anomaly, 0, len(d.anomalies))
+	// A new anomaly regresses when its fingerprint was resolved before
+	firstResolved := make(map[string]time.Time)
+	for _, a := range d.anomalies {
+		if a.Suppressed {
+			continue
+		}
+		anomalies = append(anomalies, a)
+		if a.ResolvedAt != nil && a.Fingerprint != "" {
+			if first, ok := firstResolved[a.Fingerprint]; !ok || a.ResolvedAt.Before(first) {
+				firstResolved[a.Fingerprint] = *a.ResolvedAt
+			}
+		}
+	}
+	regressed := func(a *models.Anomaly) bool {
+		first, ok := firstResolved[a.Fingerprint]
+		return ok && first.Before(a.DetectedAt)
+	}
+
+	length := q.To.Sub(q.From)
+	report := &models.TrendReport{
+		Bucket:      q.Bucket,
+		Current:     summarize(anomalies, q.From, q.To, regressed),
+		Previous:    summarize(anomalies, q.From.Add(-length), q.From, regressed),
+		BySeverity:  make(map[models.AnomalySeverity]models.BreakdownStats),
+		ByType:      make(map[models.AnomalyType]models.BreakdownStats),
+		GeneratedAt: now,
+	}
+	report.Change = compare(report.Current, report.Previous)
+
+	for start := q.From; start.Before(q.To); start = start.Add(width) {
+		end := start.Add(width)
+		if end.After(q.To) {
+			end = q.To
+		}
+		s := summarize(anomalies, start, end, regressed)
+		report.Buckets = append(report.Buckets, models.TrendBucket{
+			Start:    start,
+			End:      end,
+			New:      s.New,
+			Resolved: s.Resolved,
+			MTTA:     s.MTTA.Mean,
+			MTTR:     s.MTTR.Mean,
+		})
+	}
+
+	bySeverity := make(map[models.AnomalySeverity][]*models.Anomaly)
+	byType := make(map[models.AnomalyType][]*models.Anomaly)
+	for _, a := range anomalies {
+		bySeverity[a.Severity] = append(bySeverity[a.Severity], a)
+		byType[a.Type] = append(byType[a.Type], a)
+	}
+	for severity, group := range bySeverity {
+		if b, ok := breakdown(group, q.From, q.To); ok {
+			report.BySeverity[severity] = b
+		}
+	}
+	for typ, group := range byType {
+		if b, ok := breakdown(group, q.From, q.To); ok {
+			report.ByType[typ] = b
+		}
+	}
+
+	report.TopFingerprints = topFingerprints(anomalies, q.From, q.To, q.Top)
+	return report, nil
+}
+
+func within(t time.Time, from, to time.Time) bool {
+	return !t.Before(from) && t.Before(to)
+}
+
+// acknowledgedAt returns when a was acknowledged; resolving an anomaly
+// also acknowledges it
+func acknowledgedAt(a *models.Anomaly) *time.Time {
+	if a.AcknowledgedAt != nil {
+		return a.AcknowledgedAt
+	}
+	return a.ResolvedAt
+}
+
+// durations returns the times to acknowledge of anomalies acknowledged in
+// [from, to) and the times to resolve of those resolved in it
+func durations(anomalies []*models.Anomaly, from, to time.Time) (tta, ttr []time.Duration) {
+	for _, a := range anomalies {
+		if acked := acknowledgedAt(a); acked != nil && within(*acked, from, to) {
+			tta = append(tta, acked.Sub(a.DetectedAt))
+		}
+		if a.ResolvedAt != nil && within(*a.ResolvedAt, from, to) {
+			ttr = append(ttr, a.ResolvedAt.Sub(a.DetectedAt))
+		}
+	}
+	return tta, ttr
+}
+
+func summarize(anomalies []*models.Anomaly, from, to time.Time, regressed func(*models.Anomaly) bool) models.PeriodSummary {
+	s := models.PeriodSummary{From: from, To: to}
+	for _, a := range anomalies {
+		if within(a.DetectedAt, from, to) {
+			s.New++
+			if regressed(a) {
+				s.Regressions++
+			}
+		}
+		if a.ResolvedAt != nil && within(*a.ResolvedAt, from, to) {
+			s.Resolved++
+		}
+	}
+	if s.New > 0 {
+		s.RegressionRate = ratio(s.Regressions, s.New)
+	}
+
+	tta, ttr := durations(anomalies, from, to)
+	s.MTTA = durationStats(tta)
+	s.MTTR = durationStats(ttr)
+	return s
+}
+
+// breakdown summarises a group of anomalies, reporting false when none
+// of them was detected, acknowledged or resolved in [from, to)
+func breakdown(group []*models.Anomaly, from, to time.Time) (models.BreakdownStats, bool) {
+	var b models.BreakdownStats
+	for _, a := range group {
+		if within(a.DetectedAt, from, to) {
+			b.New++
+		}
+	}
+	tta, ttr := durations(group, from, to)
+	b.MTTA = durationStats(tta)
+	b.MTTR = durationStats(ttr)
+	return b, b.New > 0 || b.MTTA.Count > 0 || b.MTTR.Count > 0
+}
+
+// durationStats returns the mean and nearest-rank percentiles of ds in
+// seconds
+func durationStats(ds []time.Duration) models.DurationStats {
+	if len(ds) == 0 {
+		return models.DurationStats{}
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+
+	var total time.Duration
+	for _, d := range ds {
+		total += d
+	}
+	percentile := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(ds)))) - 1
+		return seconds(ds[max(i, 0)])
+	}
+	return models.DurationStats{
+		Count: len(ds),
+		Mean:  seconds(total / time.Duration(len(ds))),
+		P50:   percentile(0.5),
+		P90:   percentile(0.9),
+		P99:   percentile(0.99),
+	}
+}
+
+func seconds(d time.Duration) float64 {
+	return math.Round(d.Seconds()*10) / 10
+}
+
+func compare(current, previous models.PeriodSummary) models.PeriodChange {
+	c := models.PeriodChange{
+		New:            current.New - previous.New,
+		Resolved:       current.Resolved - previous.Resolved,
+		MTTA:           math.Round((current.MTTA.Mean-previous.MTTA.Mean)*10) / 10,
+		MTTR:           math.Round((current.MTTR.Mean-previous.MTTR.Mean)*10) / 10,
+		RegressionRate: math.Round((current.RegressionRate-previous.RegressionRate)*1000) / 1000,
+	}
+	if previous.New > 0 {
+		percent := math.Round(float64(c.New)/float64(previous.New)*1000) / 10
+		c.NewPercent = &percent
+	}
+	return c
+}
+
+// topFingerprints returns the fingerprints detected more than once in
+// [from, to), most frequent first
+func topFingerprints(anomalies []*models.Anomaly, from, to time.Time, n int) []models.FingerprintCount {
+	counts := make(map[string]*models.FingerprintCount)
+	for _, a := range anomalies {
+		if a.Fingerprint == "" || !within(a.DetectedAt, from, to) {
+			continue
+		}
+		c, ok := counts[a.Fingerprint]
+		if !ok {
+			c = &models.FingerprintCount{Fingerprint: a.Fingerprint, Type: a.Type}
+			counts[a.Fingerprint] = c
+		}
+		c.Count++
+		if a.DetectedAt.After(c.LastSeen) {
+			c.LastSeen = a.DetectedAt
+			c.LastID = a.ID
+		}
+	}
+
+	top := make([]models.FingerprintCount, 0, len(counts))
+	for _, c := range counts {
+		if c.Count > 1 {
+			top = append(top, *c)
+		}
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].LastSeen.After(top[j].LastSeen)
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
@@ -0,0 +1,104 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+func TestFingerprintIgnoresArrayOrder(t *testing.T) {
+	keys := []string{"nodes_affected"}
+	a := &models.Anomaly{Type: models.AnomalyTypeLedgerDivergence, Metadata: map[string]interface{}{"nodes_affected": []string{"mars", "moon"}}}
+	b := &models.Anomaly{Type: models.AnomalyTypeLedgerDivergence, Metadata: map[string]interface{}{"nodes_affected": []interface{}{"moon", "mars"}}}
+	c := &models.Anomaly{Type: models.AnomalyTypeLedgerDivergence, Metadata: map[string]interface{}{"nodes_affected": []string{"earth"}}}
+
+	if Fingerprint(a, keys) != Fingerprint(b, keys) {
+		t.Error("Expected the same nodes in any order to share a fingerprint")
+	}
+	if Fingerprint(a, keys) == Fingerprint(c, keys) {
+		t.Error("Expected different nodes to differ")
+	}
+}
+
+func TestTrends(t *testing.T) {
+	registry, _ := NewRegistry(DefaultTypes...)
+	detector := NewDetector()
+	detector.SetRegistry(registry)
+
+	to := time.Now().Truncate(24 * time.Hour)
+	from := to.AddDate(0, 0, -2)
+	at := func(days, minutes int) time.Time {
+		return from.Add(time.Duration(days)*24*time.Hour + time.Duration(minutes)*time.Minute)
+	}
+	route := func(detected time.Time, route string) *models.Anomaly {
+		return &models.Anomaly{
+			Type:       models.AnomalyTypeNodeDesynchronization,
+			Severity:   models.SeverityLow,
+			DetectedAt: detected,
+			Metadata:   map[string]interface{}{"affected_route": route},
+		}
+	}
+
+	// Previous period: one anomaly on Earth-Mars, resolved after an hour
+	old := detector.Record(route(at(-1, 0), "Earth-Mars"))[0]
+	detector.Update(old.ID, func(a *models.Anomaly) {
+		a.Status = models.StatusResolved
+		a.ResolvedAt = timePtr(at(-1, 60))
+	})
+
+	// Current period: Earth-Mars comes back twice, plus one other route
+	recurring := detector.Record(route(at(0, 0), "Earth-Mars"), route(at(1, 0), "Earth-Mars"), route(at(1, 30), "Earth-Moon"))
+	ackedAt := at(0, 10)
+	detector.Update(recurring[0].ID, func(a *models.Anomaly) {
+		a.AcknowledgedAt = &ackedAt
+		a.Status = models.StatusResolved
+		a.ResolvedAt = timePtr(at(0, 30))
+	})
+
+	report, err := detector.Trends(TrendQuery{From: from, To: to})
+	if err != nil {
+		t.Fatalf("Trends failed: %v", err)
+	}
+
+	cur := report.Current
+	if cur.New != 3 || cur.Resolved != 1 || cur.Regressions != 2 || cur.RegressionRate != 0.667 {
+		t.Errorf("Unexpected current period: %+v", cur)
+	}
+	if cur.MTTA.Count != 1 || cur.MTTA.Mean != 600 || cur.MTTR.Mean != 1800 {
+		t.Errorf("Expected 10m MTTA and 30m MTTR, got %+v and %+v", cur.MTTA, cur.MTTR)
+	}
+	if report.Previous.New != 1 || report.Previous.MTTR.Mean != 3600 || report.Change.MTTR != -1800 {
+		t.Errorf("Unexpected comparison: previous %+v, change %+v", report.Previous, report.Change)
+	}
+	if report.Change.NewPercent == nil || *report.Change.NewPercent != 200 {
+		t.Errorf("Expected new anomalies up 200%%, got %+v", report.Change)
+	}
+
+	if len(report.Buckets) != 2 || report.Buckets[0].New != 1 || report.Buckets[1].New != 2 || report.Buckets[0].Resolved != 1 {
+		t.Errorf("Unexpected buckets: %+v", report.Buckets)
+	}
+	if len(report.TopFingerprints) != 1 || report.TopFingerprints[0].Count != 2 || report.TopFingerprints[0].LastID != recurring[1].ID {
+		t.Errorf("Expected Earth-Mars to recur, got %+v", report.TopFingerprints)
+	}
+	if low := report.BySeverity[models.SeverityLow]; low.New != 3 || low.MTTR.Count != 1 {
+		t.Errorf("Unexpected severity breakdown: %+v", low)
+	}
+}
+
+func TestTrendQueryValidation(t *testing.T) {
+	detector := NewDetector()
+	now := time.Now()
+	for _, q := range []TrendQuery{
+		{From: now, To: now.Add(-time.Hour)},
+		{Bucket: "month"},
+		{From: now.AddDate(-2, 0, 0), To: now},
+	} {
+		if _, err := detector.Trends(q); err == nil {
+			t.Errorf("Expected query %+v to be rejected", q)
+		}
+	}
+	if r, err := detector.Trends(TrendQuery{Bucket: models.BucketWeek}); err != nil || len(r.Buckets) != 5 {
+		t.Errorf("Expected 30 days in 5 weekly buckets, got %v", err)
+	}
+}
@@ -0,0 +1,305 @@
+package anomaly
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// ErrUnknownType is returned for an anomaly type that was never registered
+var ErrUnknownType = errors.New("unknown anomaly type")
+
+// FieldType is the JSON kind a metadata field must have
+type FieldType string
+
+const (
+	FieldAny    FieldType = "any"
+	FieldString FieldType = "string"
+	FieldNumber FieldType = "number"
+	FieldBool   FieldType = "bool"
+	FieldArray  FieldType = "array"
+	FieldObject FieldType = "object"
+	FieldTime   FieldType = "time"
+)
+
+// Field describes one metadata key of an anomaly type
+type Field struct {
+	Type        FieldType `json:"type"`
+	Required    bool      `json:"required,omitempty"`
+	Description string    `json:"description,omitempty"`
+}
+
+// TypeDefinition describes an anomaly type. Metadata keys not listed in
+// the schema are allowed; listed keys must have the declared type.
+// Fingerprint lists the metadata keys identifying the underlying problem,
+// so recurrences of it share a fingerprint.
+type TypeDefinition struct {
+	Name            models.AnomalyType     `json:"name"`
+	Description     string                 `json:"description"`
+	DefaultSeverity models.AnomalySeverity `json:"default_severity"`
+	Metadata        map[string]Field       `json:"metadata,omitempty"`
+	Fingerprint     []string               `json:"fingerprint,omitempty"`
+	Runbook         string                 `json:"runbook,omitempty"`
+}
+
+// TypeStatus is a registered type with counts of anomalies recorded and
+// rejected by the detector
+type TypeStatus struct {
+	TypeDefinition
+	Recorded  int    `json:"recorded"`
+	Rejected  int    `json:"rejected"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// DefaultTypes holds the built-in anomaly types
+var DefaultTypes = []TypeDefinition{
+	{
+		Name:            models.AnomalyTypeLedgerDivergence,
+		Description:     "Ledger hashes or sync status diverge across planetary nodes",
+		DefaultSeverity: models.SeverityCritical,
+		Metadata: map[string]Field{
+			"nodes_affected": {Type: FieldArray, Description: "Node IDs whose ledger diverges"},
+			"hash_mismatch":  {Type: FieldBool},
+			"sync_status":    {Type: FieldString},
+			"current_block":  {Type: FieldNumber},
+		},
+		Fingerprint: []string{"nodes_affected"},
+		Runbook:     "resync-divergent-nodes",
+	},
+	{
+		Name:            models.AnomalyTypeDAOVoteFailure,
+		Description:     "DAO votes fail to propagate across the interplanetary network",
+		DefaultSeverity: models.SeverityHigh,
+		Metadata: map[string]Field{
+			"proposal_id":  {Type: FieldString},
+			"failed_nodes": {Type: FieldNumber},
+			"total_nodes":  {Type: FieldNumber},
+		},
+		Fingerprint: []string{"proposal_id"},
+	},
+	{
+		Name:            models.AnomalyTypeCommitAnomaly,
+		Description:     "A commit is not recorded on the Manus ledger or looks anomalous",
+		DefaultSeverity: models.SeverityMedium,
+		Metadata: map[string]Field{
+			"commit_hash": {Type: FieldString, Required: true, Description: "Hash of the offending commit"},
+			"repository":  {Type: FieldString},
+		},
+		Fingerprint: []string{"repository"},
+	},
+	{
+		Name:            models.AnomalyTypeNodeDesynchronization,
+		Description:     "Planetary nodes fall behind or exceed latency thresholds",
+		DefaultSeverity: models.SeverityLow,
+		Metadata: map[string]Field{
+			"latency_ms":      {Type: FieldNumber},
+			"threshold_ms":    {Type: FieldNumber},
+			"affected_route":  {Type: FieldString},
+			"last_block_time": {Type: FieldTime},
+		},
+		Fingerprint: []string{"affected_route"},
+	},
+	{
+		Name:            models.AnomalyTypeUnknown,
+		Description:     "Anomalies that do not fit another type",
+		DefaultSeverity: models.SeverityLow,
+	},
+}
+
+// Registry holds the anomaly types the detector accepts
+type Registry struct {
+	types map[models.AnomalyType]*TypeStatus
+	mu    sync.RWMutex
+}
+
+// NewRegistry creates a registry holding defs
+func NewRegistry(defs ...TypeDefinition) (*Registry, error) {
+	r := &Registry{types: make(map[models.AnomalyType]*TypeStatus)}
+	if err := r.Register(defs...); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Register validates and adds types, replacing any with the same name.
+// Nothing is registered if any definition is invalid.
+func (r *Registry) Register(defs ...TypeDefinition) error {
+	for _, def := range defs {
+		if err := def.validate(); err != nil {
+			return fmt.Errorf("anomaly type %q: %w", def.Name, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, def := range defs {
+		if existing, ok := r.types[def.Name]; ok {
+			existing.TypeDefinition = def
+			continue
+		}
+		r.types[def.Name] = &TypeStatus{TypeDefinition: def}
+	}
+	return nil
+}
+
+// Get returns a registered type
+func (r *Registry) Get(name models.AnomalyType) (TypeDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	status, ok := r.types[name]
+	if !ok {
+		return TypeDefinition{}, false
+	}
+	return status.TypeDefinition, true
+}
+
+// Types returns every registered type sorted by name
+func (r *Registry) Types() []TypeStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	statuses := make([]TypeStatus, 0, len(r.types))
+	for _, status := range r.types {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// Validate checks that a's type is registered and its metadata matches
+// the type's schema
+func (r *Registry) Validate(a *models.Anomaly) error {
+	def, ok := r.Get(a.Type)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownType, a.Type)
+	}
+
+	var problems []string
+	for _, key := range sortedKeys(def.Metadata) {
+		field := def.Metadata[key]
+		value, present := a.Metadata[key]
+		if !present || value == nil {
+			if field.Required {
+				problems = append(problems, fmt.Sprintf("metadata %s is required", key))
+			}
+			continue
+		}
+		if !field.Type.matches(value) {
+			problems = append(problems, fmt.Sprintf("metadata %s must be %s", key, field.Type))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid %s anomaly: %s", a.Type, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// observe counts a recorded or rejected anomaly of a registered type
+func (r *Registry) observe(name models.AnomalyType, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status, ok := r.types[name]
+	if !ok {
+		return
+	}
+	if err != nil {
+		status.Rejected++
+		status.LastError = err.Error()
+		return
+	}
+	status.Recorded++
+}
+
+func (def TypeDefinition) validate() error {
+	if def.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch def.DefaultSeverity {
+	case models.SeverityCritical, models.SeverityHigh, models.SeverityMedium, models.SeverityLow:
+	default:
+		return fmt.Errorf("invalid default severity %q", def.DefaultSeverity)
+	}
+	for key, field := range def.Metadata {
+		switch field.Type {
+		case FieldAny, FieldString, FieldNumber, FieldBool, FieldArray, FieldObject, FieldTime:
+		default:
+			return fmt.Errorf("metadata %s has invalid type %q", key, field.Type)
+		}
+	}
+	for _, key := range def.Fingerprint {
+		if _, ok := def.Metadata[key]; !ok {
+			return fmt.Errorf("fingerprint key %s is not in the metadata schema", key)
+		}
+	}
+	return nil
+}
+
+// matches reports whether value, as set by a Go source or decoded from
+// JSON, has type t
+func (t FieldType) matches(value interface{}) bool {
+	switch t {
+	case FieldAny:
+		return true
+	case FieldTime:
+		switch v := value.(type) {
+		case time.Time, *time.Time:
+			return true
+		case string:
+			_, err := time.Parse(time.RFC3339Nano, v)
+			return err == nil
+		}
+		return false
+	}
+
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.String:
+		return t == FieldString
+	case reflect.Bool:
+		return t == FieldBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return t == FieldNumber
+	case reflect.Slice, reflect.Array:
+		return t == FieldArray
+	case reflect.Map, reflect.Struct:
+		return t == FieldObject
+	}
+	return false
+}
+
+func sortedKeys(fields map[string]Field) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LoadTypesFile reads anomaly type definitions from a JSON array
+func LoadTypesFile(path string) ([]TypeDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anomaly types: %w", err)
+	}
+
+	var defs []TypeDefinition
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("invalid anomaly types file %s: %w", path, err)
+	}
+	return defs, nil
+}
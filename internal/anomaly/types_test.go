@@ -0,0 +1,150 @@
+package anomaly
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+func TestDefaultTypesAcceptBuiltInAnomalies(t *testing.T) {
+	registry, err := NewRegistry(DefaultTypes...)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+
+	detector := NewDetector()
+	detector.SetRegistry(registry)
+	if n := len(detector.DetectAnomalies()); n != 4 {
+		t.Errorf("Expected all 4 simulated anomalies to be recorded, got %d", n)
+	}
+}
+
+func TestRegistryValidatesMetadata(t *testing.T) {
+	registry, err := NewRegistry(TypeDefinition{
+		Name:            "solar_flare",
+		Description:     "Solar activity disrupts relay links",
+		DefaultSeverity: models.SeverityHigh,
+		Metadata: map[string]Field{
+			"kp_index": {Type: FieldNumber, Required: true},
+			"relays":   {Type: FieldArray},
+			"peak_at":  {Type: FieldTime},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		anomaly  models.Anomaly
+		problems []string
+	}{
+		{
+			name: "valid",
+			anomaly: models.Anomaly{Type: "solar_flare", Metadata: map[string]interface{}{
+				"kp_index": 7,
+				"relays":   []string{"lunar-relay-1"},
+				"peak_at":  "2026-03-04T10:00:00Z",
+				"extra":    true,
+			}},
+		},
+		{
+			name: "decoded from JSON",
+			anomaly: models.Anomaly{Type: "solar_flare", Metadata: map[string]interface{}{
+				"kp_index": 7.0,
+				"relays":   []interface{}{"lunar-relay-1"},
+				"peak_at":  time.Now(),
+			}},
+		},
+		{
+			name:     "missing required",
+			anomaly:  models.Anomaly{Type: "solar_flare"},
+			problems: []string{"metadata kp_index is required"},
+		},
+		{
+			name: "wrong types",
+			anomaly: models.Anomaly{Type: "solar_flare", Metadata: map[string]interface{}{
+				"kp_index": "severe",
+				"relays":   "lunar-relay-1",
+				"peak_at":  "yesterday",
+			}},
+			problems: []string{"kp_index must be number", "peak_at must be time", "relays must be array"},
+		},
+	}
+
+	for _, tt := range tests {
+		err := registry.Validate(&tt.anomaly)
+		if len(tt.problems) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: expected validation to fail", tt.name)
+			continue
+		}
+		for _, problem := range tt.problems {
+			if !strings.Contains(err.Error(), problem) {
+				t.Errorf("%s: expected %q in %q", tt.name, problem, err)
+			}
+		}
+	}
+
+	if err := registry.Validate(&models.Anomaly{Type: "gamma_burst"}); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("Expected ErrUnknownType, got %v", err)
+	}
+}
+
+func TestRegisterRejectsInvalidDefinitions(t *testing.T) {
+	registry, _ := NewRegistry()
+	invalid := []TypeDefinition{
+		{Description: "no name", DefaultSeverity: models.SeverityLow},
+		{Name: "no_severity"},
+		{Name: "bad_field", DefaultSeverity: models.SeverityLow, Metadata: map[string]Field{"x": {Type: "integer"}}},
+	}
+	for _, def := range invalid {
+		if err := registry.Register(TypeDefinition{Name: "ok", DefaultSeverity: models.SeverityLow}, def); err == nil {
+			t.Errorf("Expected %+v to be rejected", def)
+		}
+	}
+	if len(registry.Types()) != 0 {
+		t.Error("Expected nothing registered when any definition is invalid")
+	}
+}
+
+func TestRecordRejectsInvalidAnomalies(t *testing.T) {
+	registry, _ := NewRegistry(DefaultTypes...)
+	detector := NewDetector()
+	detector.SetRegistry(registry)
+
+	var events int
+	detector.Subscribe(func(Event) { events++ })
+
+	recorded := detector.Record(
+		&models.Anomaly{Type: models.AnomalyTypeCommitAnomaly, Metadata: map[string]interface{}{"commit_hash": "a3f5b2c1"}},
+		&models.Anomaly{Type: models.AnomalyTypeCommitAnomaly},
+		&models.Anomaly{Type: "gamma_burst"},
+	)
+	if len(recorded) != 1 || events != 1 {
+		t.Fatalf("Expected only the valid anomaly to be recorded, got %d recorded and %d events", len(recorded), events)
+	}
+	if recorded[0].Severity != models.SeverityMedium {
+		t.Errorf("Expected default severity medium, got %q", recorded[0].Severity)
+	}
+	if len(detector.GetAllAnomalies()) != 1 {
+		t.Error("Expected rejected anomalies not to be stored")
+	}
+
+	for _, status := range registry.Types() {
+		if status.Name != models.AnomalyTypeCommitAnomaly {
+			continue
+		}
+		if status.Recorded != 1 || status.Rejected != 1 || !strings.Contains(status.LastError, "commit_hash is required") {
+			t.Errorf("Unexpected commit_anomaly status: %+v", status)
+		}
+	}
+}
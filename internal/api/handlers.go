@@ -1,29 +1,224 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/auth"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/consensus"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/coopetition"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/delivery"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/enrichment"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/escalation"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/export"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/incident"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/leader"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/loop"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/notes"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/notify"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/oncall"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/rules"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/runbook"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/scheduler"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/silence"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blob"
 	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blockchain"
 	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/search"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/timeseries"
 )
 
 // Handler holds dependencies for API handlers
 type Handler struct {
 	detector   *anomaly.Detector
-	search     *search.BingSearchClient
+	search     search.Provider
+	providers  map[string]search.Provider
+	guard      *search.GuardedProvider
 	blockchain *blockchain.ManusClient
+	enricher   *enrichment.Enricher
+	loop       *loop.Loop
+	arena      *coopetition.Arena
+	consensus  *consensus.Board
+	runbooks   *runbook.Runner
+	scheduler  *scheduler.Scheduler
+	elector    *leader.Elector
+	metrics    *timeseries.Store
+	engine     *timeseries.Engine
+	rules      *rules.Engine
+	incidents  *incident.Correlator
+	silences   *silence.Store
+	escalation *escalation.Tracker
+	assigner   *oncall.Assigner
+	notes      *notes.Store
+	delivery   *delivery.Service
+	channels   *notify.Router
+	keys       *auth.KeyStore
+}
+
+// Option configures optional Handler dependencies
+type Option func(*Handler)
+
+// WithSearchProvider makes an additional provider selectable through the
+// "provider" query parameter of the search endpoint
+func WithSearchProvider(provider search.Provider) Option {
+	return func(h *Handler) {
+		h.providers[provider.Name()] = provider
+	}
+}
+
+// WithSearchGuard exposes cache, rate limiter and circuit breaker counters
+func WithSearchGuard(guard *search.GuardedProvider) Option {
+	return func(h *Handler) {
+		h.guard = guard
+	}
+}
+
+// WithEnricher exposes context enrichment status
+func WithEnricher(enricher *enrichment.Enricher) Option {
+	return func(h *Handler) {
+		h.enricher = enricher
+	}
+}
+
+// WithLoop exposes Superintelligence Loop traces and proposal review
+func WithLoop(l *loop.Loop) Option {
+	return func(h *Handler) {
+		h.loop = l
+	}
+}
+
+// WithArena exposes the Coopetition leaderboard
+func WithArena(arena *coopetition.Arena) Option {
+	return func(h *Handler) {
+		h.arena = arena
+	}
+}
+
+// WithConsensus requires approval votes before resolving anomalies the
+// board's policy covers
+func WithConsensus(board *consensus.Board) Option {
+	return func(h *Handler) {
+		h.consensus = board
+	}
+}
+
+// WithRunbooks enables remediation runbook endpoints
+func WithRunbooks(runner *runbook.Runner) Option {
+	return func(h *Handler) {
+		h.runbooks = runner
+	}
+}
+
+// WithScheduler enables scheduled job endpoints
+func WithScheduler(s *scheduler.Scheduler) Option {
+	return func(h *Handler) {
+		h.scheduler = s
+	}
+}
+
+// WithElector reports leader election state on the health endpoint
+func WithElector(elector *leader.Elector) Option {
+	return func(h *Handler) {
+		h.elector = elector
+	}
+}
+
+// WithMetrics enables metric ingestion and queries. Samples of metrics
+// the engine is configured for are scored and anomalies recorded.
+func WithMetrics(store *timeseries.Store, engine *timeseries.Engine) Option {
+	return func(h *Handler) {
+		h.metrics = store
+		h.engine = engine
+	}
+}
+
+// WithRules enables rule management endpoints and evaluates metric rules
+// on ingested samples
+func WithRules(engine *rules.Engine) Option {
+	return func(h *Handler) {
+		h.rules = engine
+	}
+}
+
+// WithIncidents enables incident endpoints
+func WithIncidents(c *incident.Correlator) Option {
+	return func(h *Handler) {
+		h.incidents = c
+	}
+}
+
+// WithSilences enables silence and maintenance window endpoints
+func WithSilences(store *silence.Store) Option {
+	return func(h *Handler) {
+		h.silences = store
+	}
+}
+
+// WithEscalation exposes escalation policies
+func WithEscalation(tracker *escalation.Tracker) Option {
+	return func(h *Handler) {
+		h.escalation = tracker
+	}
+}
+
+// WithOnCall enables assignment and on-call rotation endpoints
+func WithOnCall(assigner *oncall.Assigner) Option {
+	return func(h *Handler) {
+		h.assigner = assigner
+	}
+}
+
+// WithNotes enables comment and attachment endpoints and anchoring notes
+// with resolutions
+func WithNotes(store *notes.Store) Option {
+	return func(h *Handler) {
+		h.notes = store
+	}
+}
+
+// WithDelivery enables report subscription and delivery endpoints
+func WithDelivery(service *delivery.Service) Option {
+	return func(h *Handler) {
+		h.delivery = service
+	}
+}
+
+// WithNotifications enables the notification channel status endpoint
+func WithNotifications(router *notify.Router) Option {
+	return func(h *Handler) {
+		h.channels = router
+	}
+}
+
+// WithAuth enables API key management and the caller identity endpoint
+func WithAuth(keys *auth.KeyStore) Option {
+	return func(h *Handler) {
+		h.keys = keys
+	}
 }
 
 // NewHandler creates a new API handler
-func NewHandler(detector *anomaly.Detector, searchClient *search.BingSearchClient, blockchainClient *blockchain.ManusClient) *Handler {
-	return &Handler{
+func NewHandler(detector *anomaly.Detector, searchClient search.Provider, blockchainClient *blockchain.ManusClient, opts ...Option) *Handler {
+	h := &Handler{
 		detector:   detector,
 		search:     searchClient,
+		providers:  map[string]search.Provider{searchClient.Name(): searchClient},
 		blockchain: blockchainClient,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // HealthCheck handles health check requests
@@ -33,15 +228,68 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 		"timestamp": time.Now().UTC(),
 		"service":   "Manus Copilot Integration",
 	}
+	// Standby replicas are healthy too; leadership is informational
+	if h.elector != nil {
+		response["leader"] = h.elector.Status()
+	}
 	respondJSON(w, http.StatusOK, response)
 }
 
-// GetAnomalies handles requests to get all anomalies
+// GetAnomalies handles requests to get all anomalies. The assignee
+// parameter keeps those owned by a user, directly or through a team;
+// "me" names the caller.
 func (h *Handler) GetAnomalies(w http.ResponseWriter, r *http.Request) {
 	anomalies := h.detector.GetAllAnomalies()
+
+	assignee := r.URL.Query().Get("assignee")
+	if assignee == "me" {
+		assignee = actor(r)
+		if assignee == "" {
+			respondError(w, http.StatusBadRequest, "an authenticated caller or X-Actor header is required for assignee=me")
+			return
+		}
+	}
+	if assignee != "" {
+		owned := make([]*models.Anomaly, 0, len(anomalies))
+		for _, a := range anomalies {
+			if h.owns(assignee, a) {
+				owned = append(owned, a)
+			}
+		}
+		anomalies = owned
+	}
+
 	respondJSON(w, http.StatusOK, anomalies)
 }
 
+// owns reports whether user is a's assignee or, with on-call enabled, a
+// member of its assigned team
+func (h *Handler) owns(user string, a *models.Anomaly) bool {
+	if h.assigner != nil {
+		return h.assigner.Roster().Owns(user, a)
+	}
+	return a.Assignee != nil && a.Assignee.Name == user
+}
+
+// actor returns who is making the request: the authenticated principal
+// or, with authentication disabled, the X-Actor header
+func actor(r *http.Request) string {
+	if p, ok := auth.FromContext(r.Context()); ok {
+		return p.Subject
+	}
+	return strings.TrimSpace(r.Header.Get("X-Actor"))
+}
+
+// actorOr returns the authenticated principal, which takes the place of
+// any name given in the request body, or with authentication disabled
+// claimed, falling back to the X-Actor header
+func actorOr(r *http.Request, claimed string) string {
+	if _, ok := auth.FromContext(r.Context()); ok || claimed == "" {
+		return actor(r)
+	}
+	return claimed
+}
+
 // DetectAnomalies handles requests to trigger anomaly detection
 func (h *Handler) DetectAnomalies(w http.ResponseWriter, r *http.Request) {
 	anomalies := h.detector.DetectAnomalies()
@@ -68,11 +316,37 @@ func (h *Handler) GetAnomaly(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, anomaly)
 }
 
-// ResolveAnomaly handles requests to resolve an anomaly
+// GetEnrichment handles requests for the enrichment status of an anomaly,
+// or for pipeline counters when no ID is given
+func (h *Handler) GetEnrichment(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		respondJSON(w, http.StatusOK, h.enricher.Stats())
+		return
+	}
+
+	anomaly, err := h.detector.GetAnomaly(id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if anomaly.Context == nil {
+		respondError(w, http.StatusNotFound, "no enrichment recorded for anomaly: "+id)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, anomaly.Context)
+}
+
+// ResolveAnomaly handles requests to resolve an anomaly. Anomalies covered
+// by the consensus policy are put up for approval instead.
 func (h *Handler) ResolveAnomaly(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		ID         string `json:"id"`
-		Resolution string `json:"resolution"`
+		ID          string `json:"id"`
+		Resolution  string `json:"resolution"`
+		RequestedBy string `json:"requested_by"`
+		AnchorNotes bool   `json:"anchor_notes"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -84,37 +358,283 @@ func (h *Handler) ResolveAnomaly(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "id and resolution are required")
 		return
 	}
+	req.RequestedBy = actorOr(r, req.RequestedBy)
+
+	if req.AnchorNotes && h.notes == nil {
+		respondError(w, http.StatusBadRequest, "notes are not enabled")
+		return
+	}
+
+	if h.consensus != nil {
+		anomaly, err := h.detector.GetAnomaly(req.ID)
+		if err != nil {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if h.consensus.Requires(anomaly) {
+			open := h.consensus.Open
+			if req.AnchorNotes {
+				open = h.consensus.OpenAnchoringNotes
+			}
+			approval, err := open(r.Context(), req.ID, req.Resolution, req.RequestedBy)
+			if err != nil {
+				respondConsensusError(w, err)
+				return
+			}
+			respondApproval(w, req.ID, approval)
+			return
+		}
+	}
+
+	if err := h.detector.ResolveAnomaly(req.ID, req.Resolution, req.RequestedBy); err != nil {
+		respondConsensusError(w, err)
+		return
+	}
+
+	// Log to blockchain, with a digest of the investigation notes if asked
+	entry := blockchain.LedgerEntry{AnomalyID: req.ID, Description: req.Resolution, Actor: req.RequestedBy}
+	response := map[string]interface{}{
+		"status":     "resolved",
+		"anomaly_id": req.ID,
+	}
+	if req.AnchorNotes {
+		digest, comments, attachments := h.notes.Digest(req.ID)
+		entry.Payload = map[string]interface{}{
+			"notes_digest": digest,
+			"comments":     comments,
+			"attachments":  attachments,
+		}
+		response["notes_digest"] = digest
+	}
+	txHash, _ := h.blockchain.LogEntry(entry)
+	response["blockchain_tx"] = txHash
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// AcknowledgeAnomaly handles requests to take ownership of an anomaly,
+// which stops escalation for a missed acknowledge deadline
+func (h *Handler) AcknowledgeAnomaly(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID             string `json:"id"`
+		AcknowledgedBy string `json:"acknowledged_by"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	req.AcknowledgedBy = actorOr(r, req.AcknowledgedBy)
+	if req.ID == "" || req.AcknowledgedBy == "" {
+		respondError(w, http.StatusBadRequest, "id and acknowledged_by are required")
+		return
+	}
 
-	if err := h.detector.ResolveAnomaly(req.ID, req.Resolution); err != nil {
+	if err := h.detector.Acknowledge(req.ID, req.AcknowledgedBy); err != nil {
+		if errors.Is(err, anomaly.ErrResolved) || errors.Is(err, anomaly.ErrAcknowledged) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
 		respondError(w, http.StatusNotFound, err.Error())
 		return
 	}
 
-	// Log to blockchain
-	txHash, _ := h.blockchain.LogAnomaly(req.ID, req.Resolution)
+	a, _ := h.detector.GetAnomaly(req.ID)
+	respondJSON(w, http.StatusOK, a)
+}
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"status":           "resolved",
-		"anomaly_id":       req.ID,
-		"blockchain_tx":    txHash,
-	})
+// GetEscalationPolicies handles requests to list escalation policies
+func (h *Handler) GetEscalationPolicies(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.escalation.Policies())
+}
+
+// VoteOnResolution handles approval votes on a pending resolution
+func (h *Handler) VoteOnResolution(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID      string `json:"id"`
+		Voter   string `json:"voter"`
+		Approve bool   `json:"approve"`
+		Comment string `json:"comment"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	req.Voter = actorOr(r, req.Voter)
+	if req.ID == "" || req.Voter == "" {
+		respondError(w, http.StatusBadRequest, "id and voter are required")
+		return
+	}
+
+	approval, err := h.consensus.Vote(r.Context(), req.ID, req.Voter, req.Approve, req.Comment)
+	if err != nil {
+		respondConsensusError(w, err)
+		return
+	}
+
+	respondApproval(w, req.ID, approval)
+}
+
+// GetApprovals handles requests for the approval state of an anomaly, or
+// for all anomalies awaiting approval when no ID is given
+func (h *Handler) GetApprovals(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		respondJSON(w, http.StatusOK, h.consensus.Pending())
+		return
+	}
+
+	anomaly, err := h.detector.GetAnomaly(id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if anomaly.Approval == nil {
+		respondError(w, http.StatusNotFound, "no approval requested for anomaly: "+id)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, anomaly.Approval)
 }
 
-// GetReport handles requests to get anomaly report
+// GetReport handles requests to get anomaly report, as JSON or, chosen by
+// the format parameter or Accept header, as a Markdown, HTML, CSV or PDF
+// document
 func (h *Handler) GetReport(w http.ResponseWriter, r *http.Request) {
+	format := export.Negotiate(r.Header.Get("Accept"))
+	if value := r.URL.Query().Get("format"); value != "" {
+		f, err := export.ParseFormat(value)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		format = f
+	}
+	w.Header().Set("Vary", "Accept")
+
 	report := h.detector.GenerateReport()
+	if format == export.FormatJSON {
+		respondJSON(w, http.StatusOK, report)
+		return
+	}
+
+	anomalies := h.detector.GetAllAnomalies()
+	sort.Slice(anomalies, func(i, j int) bool {
+		return anomalies[i].DetectedAt.After(anomalies[j].DetectedAt)
+	})
+	trends, _ := h.detector.Trends(anomaly.TrendQuery{})
+	doc := export.Document{
+		Title:     "Anomaly Report",
+		Report:    report,
+		Trends:    trends,
+		Anomalies: anomalies,
+	}
+
+	// Render fully before writing so a failure can still be reported
+	var buf bytes.Buffer
+	if err := export.Render(&buf, format, doc); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	filename := "anomaly-report-" + report.GeneratedAt.UTC().Format("2006-01-02") + "." + format.Extension()
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": filename}))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// GetTrends handles requests for trend reports over a time range
+func (h *Handler) GetTrends(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := anomaly.TrendQuery{Bucket: models.TrendBucketSize(params.Get("bucket"))}
+
+	for name, bound := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		if value := params.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				respondError(w, http.StatusBadRequest, "invalid "+name+" time, expected RFC 3339")
+				return
+			}
+			*bound = t
+		}
+	}
+	if value := params.Get("top"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "query parameter 'top' must be an integer")
+			return
+		}
+		query.Top = n
+	}
+
+	report, err := h.detector.Trends(query)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	respondJSON(w, http.StatusOK, report)
 }
 
 // Search handles search requests
 func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query().Get("q")
+	params := r.URL.Query()
+	query := params.Get("q")
 	if query == "" {
 		respondError(w, http.StatusBadRequest, "query parameter 'q' is required")
 		return
 	}
 
-	results, err := h.search.Search(query, 10)
+	provider := h.search
+	if name := params.Get("provider"); name != "" {
+		p, ok := h.providers[name]
+		if !ok {
+			respondError(w, http.StatusBadRequest, "unknown search provider: "+name)
+			return
+		}
+		provider = p
+	}
+
+	req := search.SearchRequest{
+		Query:      query,
+		Market:     params.Get("mkt"),
+		Language:   params.Get("setLang"),
+		Freshness:  search.Freshness(params.Get("freshness")),
+		SafeSearch: search.SafeSearch(params.Get("safeSearch")),
+	}
+	for _, name := range []string{"count", "offset"} {
+		value := params.Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("query parameter '%s' must be an integer", name))
+			return
+		}
+		if name == "count" {
+			req.Count = n
+		} else {
+			req.Offset = n
+		}
+	}
+	for _, value := range params["site"] {
+		for _, site := range strings.Split(value, ",") {
+			if site = strings.TrimSpace(site); site != "" {
+				req.Sites = append(req.Sites, site)
+			}
+		}
+	}
+
+	if err := req.Validate(); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	results, err := provider.Search(r.Context(), req)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -123,6 +643,133 @@ func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, results)
 }
 
+// GetLoopTraces handles requests for Superintelligence Loop traces, either
+// a single trace by ID or the recent traces, optionally for one anomaly
+func (h *Handler) GetLoopTraces(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	if id := params.Get("id"); id != "" {
+		trace, ok := h.loop.Trace(id)
+		if !ok {
+			respondError(w, http.StatusNotFound, "trace not found: "+id)
+			return
+		}
+		respondJSON(w, http.StatusOK, trace)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, h.loop.Traces(params.Get("anomaly_id")))
+}
+
+// GetLoopStats handles requests for the Superintelligence Loop queue counters
+func (h *Handler) GetLoopStats(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.loop.Stats())
+}
+
+// ReviewProposal handles approval or rejection of a proposed resolution
+func (h *Handler) ReviewProposal(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AnomalyID string `json:"anomaly_id"`
+		Reviewer  string `json:"reviewer"`
+		Approve   bool   `json:"approve"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	req.Reviewer = actorOr(r, req.Reviewer)
+	if req.AnomalyID == "" || req.Reviewer == "" {
+		respondError(w, http.StatusBadRequest, "anomaly_id and reviewer are required")
+		return
+	}
+
+	trace, err := h.loop.Review(r.Context(), req.AnomalyID, req.Reviewer, req.Approve)
+	if errors.Is(err, loop.ErrNoPendingProposal) || errors.Is(err, anomaly.ErrResolved) {
+		respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, trace)
+}
+
+// GetLeaderboard handles requests for Coopetition agent standings
+func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.arena.Leaderboard())
+}
+
+// GetRunbooks handles requests to list runbooks, optionally for one type
+func (h *Handler) GetRunbooks(w http.ResponseWriter, r *http.Request) {
+	anomalyType := models.AnomalyType(r.URL.Query().Get("type"))
+	respondJSON(w, http.StatusOK, h.runbooks.Runbooks(anomalyType))
+}
+
+// RunRunbook handles requests to run a runbook against an anomaly
+func (h *Handler) RunRunbook(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AnomalyID   string `json:"anomaly_id"`
+		Runbook     string `json:"runbook"`
+		DryRun      bool   `json:"dry_run"`
+		RequestedBy string `json:"requested_by"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.AnomalyID == "" {
+		respondError(w, http.StatusBadRequest, "anomaly_id is required")
+		return
+	}
+
+	execution, err := h.runbooks.Start(r.Context(), req.AnomalyID, req.Runbook, req.DryRun, actorOr(r, req.RequestedBy))
+	if err != nil {
+		respondRunbookError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, execution)
+}
+
+// ApproveRunbookStep handles approval or rejection of a paused destructive
+// runbook step
+func (h *Handler) ApproveRunbookStep(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AnomalyID string `json:"anomaly_id"`
+		Approver  string `json:"approver"`
+		Approve   bool   `json:"approve"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	req.Approver = actorOr(r, req.Approver)
+	if req.AnomalyID == "" || req.Approver == "" {
+		respondError(w, http.StatusBadRequest, "anomaly_id and approver are required")
+		return
+	}
+
+	execution, err := h.runbooks.Approve(r.Context(), req.AnomalyID, req.Approver, req.Approve)
+	if err != nil {
+		respondRunbookError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, execution)
+}
+
+// GetSearchStats handles requests for search cache and breaker counters
+func (h *Handler) GetSearchStats(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.guard.Stats())
+}
+
 // GetBlockchainStatus handles requests to get blockchain status
 func (h *Handler) GetBlockchainStatus(w http.ResponseWriter, r *http.Request) {
 	status, err := h.blockchain.GetStatus()
@@ -162,3 +809,846 @@ func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 func respondError(w http.ResponseWriter, status int, message string) {
 	respondJSON(w, status, map[string]string{"error": message})
 }
+
+// GetSchedulerJobs handles requests to list scheduled jobs
+func (h *Handler) GetSchedulerJobs(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.scheduler.Jobs())
+}
+
+// GetSchedulerRuns handles requests for run history, optionally for one job
+func (h *Handler) GetSchedulerRuns(w http.ResponseWriter, r *http.Request) {
+	runs, err := h.scheduler.History(r.URL.Query().Get("job"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, runs)
+}
+
+// RunSchedulerJob handles requests to run a scheduled job now
+func (h *Handler) RunSchedulerJob(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Job string `json:"job"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.Job == "" {
+		respondError(w, http.StatusBadRequest, "job is required")
+		return
+	}
+
+	if err := h.scheduler.Trigger(req.Job); err != nil {
+		switch {
+		case errors.Is(err, scheduler.ErrUnknownJob):
+			respondError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, scheduler.ErrNotLeader), errors.Is(err, scheduler.ErrStopped):
+			respondError(w, http.StatusServiceUnavailable, err.Error())
+		default:
+			respondError(w, http.StatusConflict, err.Error())
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, map[string]string{
+		"status": "started",
+		"job":    req.Job,
+	})
+}
+
+// AnomalyTypes handles listing (GET) and registering or replacing (POST)
+// anomaly types
+func (h *Handler) AnomalyTypes(w http.ResponseWriter, r *http.Request) {
+	registry := h.detector.Registry()
+	if r.Method != http.MethodPost {
+		respondJSON(w, http.StatusOK, registry.Types())
+		return
+	}
+
+	var def anomaly.TypeDefinition
+	if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := registry.Register(def); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusCreated, def)
+}
+
+// Rules handles listing (GET), adding or replacing (POST) and removing
+// (DELETE ?name=) rules
+func (h *Handler) Rules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var rule rules.Rule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if err := h.rules.Add(rule); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondJSON(w, http.StatusCreated, rule)
+	case http.MethodDelete:
+		name := r.URL.Query().Get("name")
+		if err := h.rules.Remove(name); err != nil {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]string{
+			"status": "removed",
+			"name":   name,
+		})
+	default:
+		respondJSON(w, http.StatusOK, h.rules.Rules())
+	}
+}
+
+// TestRule handles requests to validate a rule and run its fixtures
+// without loading it
+func (h *Handler) TestRule(w http.ResponseWriter, r *http.Request) {
+	var rule rules.Rule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	results, err := rules.Test(rule)
+	if err != nil {
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"valid": false,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	passed := true
+	for _, result := range results {
+		passed = passed && result.Passed
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"valid":   true,
+		"passed":  passed,
+		"results": results,
+	})
+}
+
+// maxMetricsBody caps the size of a metrics batch
+const maxMetricsBody = 8 << 20
+
+// Metrics handles metric ingestion (POST) and lists stored series (GET)
+func (h *Handler) Metrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondJSON(w, http.StatusOK, h.metrics.Series(r.URL.Query().Get("metric")))
+		return
+	}
+
+	// Prometheus text exposition unless the batch is JSON
+	var samples []timeseries.Sample
+	body := http.MaxBytesReader(w, r.Body, maxMetricsBody)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var req struct {
+			Samples []timeseries.Sample `json:"samples"`
+		}
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		samples = req.Samples
+	} else {
+		var err error
+		samples, err = timeseries.ParseText(body, time.Now().UTC())
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid exposition format: "+err.Error())
+			return
+		}
+	}
+
+	if len(samples) == 0 {
+		respondError(w, http.StatusBadRequest, "no samples")
+		return
+	}
+	for _, sample := range samples {
+		if sample.Metric == "" {
+			respondError(w, http.StatusBadRequest, "metric is required for every sample")
+			return
+		}
+	}
+
+	errs := []string{}
+	accepted, err := h.metrics.Append(samples...)
+	if err != nil {
+		errs = append(errs, strings.Split(err.Error(), "\n")...)
+	}
+
+	var scored []timeseries.Sample
+	for _, sample := range samples {
+		if h.engine.Has(sample.Metric) {
+			scored = append(scored, sample)
+		}
+	}
+	found, err := h.engine.Ingest(scored...)
+	if err != nil {
+		errs = append(errs, strings.Split(err.Error(), "\n")...)
+	}
+	anomalies := h.detector.Record(found...)
+	if h.rules != nil {
+		anomalies = append(anomalies, h.rules.EvaluateMetrics(samples...)...)
+	}
+	if h.arena != nil {
+		h.arena.ObserveSamples(samples...)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"accepted":  accepted,
+		"scored":    len(scored),
+		"anomalies": anomalies,
+		"errors":    errs,
+	})
+}
+
+// QueryMetrics handles requests for one series over a time range, either
+// given explicitly or around an anomaly detected from a metric
+func (h *Handler) QueryMetrics(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	metric := params.Get("metric")
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(params.Get("labels"), ",") {
+		if k, v, ok := strings.Cut(pair, "="); ok {
+			labels[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-time.Hour)
+	if id := params.Get("anomaly_id"); id != "" {
+		a, err := h.detector.GetAnomaly(id)
+		if err != nil {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		metric, _ = a.Metadata["metric"].(string)
+		if metric == "" {
+			respondError(w, http.StatusBadRequest, "anomaly was not detected from a metric")
+			return
+		}
+		labels, _ = a.Metadata["labels"].(map[string]string)
+
+		around := time.Hour
+		if value := params.Get("around"); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				respondError(w, http.StatusBadRequest, "invalid around duration")
+				return
+			}
+			around = d
+		}
+		from, to = a.DetectedAt.Add(-around), a.DetectedAt.Add(around)
+	}
+
+	if metric == "" {
+		respondError(w, http.StatusBadRequest, "metric or anomaly_id is required")
+		return
+	}
+	for name, bound := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := params.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				respondError(w, http.StatusBadRequest, "invalid "+name+" time, expected RFC 3339")
+				return
+			}
+			*bound = t
+		}
+	}
+
+	result, ok := h.metrics.Query(metric, labels, from, to)
+	if !ok {
+		respondError(w, http.StatusNotFound, "series not found: "+metric)
+		return
+	}
+	respondJSON(w, http.StatusOK, result)
+}
+
+func respondApproval(w http.ResponseWriter, id string, approval *models.Approval) {
+	status := http.StatusOK
+	result := "resolved"
+	switch approval.Status {
+	case models.ApprovalPending:
+		status = http.StatusAccepted
+		result = "pending_approval"
+	case models.ApprovalRejected:
+		result = "rejected"
+	}
+
+	respondJSON(w, status, map[string]interface{}{
+		"status":        result,
+		"anomaly_id":    id,
+		"approval":      approval,
+		"blockchain_tx": approval.TxHash,
+	})
+}
+
+func respondRunbookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, runbook.ErrInProgress), errors.Is(err, runbook.ErrNotAwaitingApproval), errors.Is(err, runbook.ErrAlreadyResolved):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, runbook.ErrSelfApproval):
+		respondError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, runbook.ErrNoRunbook):
+		respondError(w, http.StatusNotFound, err.Error())
+	default:
+		respondConsensusError(w, err)
+	}
+}
+
+func respondConsensusError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, consensus.ErrNotEligible):
+		respondError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, consensus.ErrNoBallot), errors.Is(err, consensus.ErrBallotPending), errors.Is(err, consensus.ErrAlreadyResolved),
+		errors.Is(err, anomaly.ErrResolved):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		respondError(w, http.StatusNotFound, err.Error())
+	}
+}
+
+// GetIncidents handles requests to list incidents, optionally by status
+func (h *Handler) GetIncidents(w http.ResponseWriter, r *http.Request) {
+	status := models.IncidentStatus(r.URL.Query().Get("status"))
+	respondJSON(w, http.StatusOK, h.incidents.Incidents(status))
+}
+
+// GetIncident handles requests for one incident with its member anomalies
+func (h *Handler) GetIncident(w http.ResponseWriter, r *http.Request) {
+	inc, err := h.incidents.Get(r.URL.Query().Get("id"))
+	if err != nil {
+		respondIncidentError(w, err)
+		return
+	}
+
+	members := make([]*models.Anomaly, 0, len(inc.Anomalies))
+	for _, id := range inc.Anomalies {
+		if a, err := h.detector.GetAnomaly(id); err == nil {
+			members = append(members, a)
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"incident": inc,
+		"members":  members,
+	})
+}
+
+// ResolveIncident handles requests to resolve every member of an incident.
+// Members the consensus policy covers get a ballot instead, and the
+// incident resolves once they are approved.
+func (h *Handler) ResolveIncident(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID          string `json:"id"`
+		Resolution  string `json:"resolution"`
+		RequestedBy string `json:"requested_by"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.ID == "" || req.Resolution == "" {
+		respondError(w, http.StatusBadRequest, "id and resolution are required")
+		return
+	}
+
+	req.RequestedBy = actorOr(r, req.RequestedBy)
+
+	results, err := h.incidents.Resolve(req.ID, req.Resolution, func(id, resolution string) (string, error) {
+		if h.consensus != nil {
+			a, err := h.detector.GetAnomaly(id)
+			if err != nil {
+				return "", err
+			}
+			if h.consensus.Requires(a) {
+				approval, err := h.consensus.Open(r.Context(), id, resolution, req.RequestedBy)
+				if err != nil {
+					return "", err
+				}
+				if approval.Status == models.ApprovalPending {
+					return "pending_approval", nil
+				}
+				return string(approval.Status), nil
+			}
+		}
+
+		if err := h.detector.ResolveAnomaly(id, resolution, req.RequestedBy); err != nil {
+			return "", err
+		}
+		h.blockchain.LogEntry(blockchain.LedgerEntry{AnomalyID: id, Description: resolution, Actor: req.RequestedBy})
+		return string(models.StatusResolved), nil
+	})
+	if err != nil {
+		respondIncidentError(w, err)
+		return
+	}
+
+	inc, _ := h.incidents.Get(req.ID)
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"incident": inc,
+		"members":  results,
+	})
+}
+
+// MarkRootCause handles requests to confirm an incident's root cause
+func (h *Handler) MarkRootCause(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID        string `json:"id"`
+		AnomalyID string `json:"anomaly_id"`
+		MarkedBy  string `json:"marked_by"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.ID == "" || req.AnomalyID == "" {
+		respondError(w, http.StatusBadRequest, "id and anomaly_id are required")
+		return
+	}
+
+	inc, err := h.incidents.MarkRootCause(req.ID, req.AnomalyID, actorOr(r, req.MarkedBy))
+	if err != nil {
+		respondIncidentError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, inc)
+}
+
+func respondIncidentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, incident.ErrResolved):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, incident.ErrNotMember):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusNotFound, err.Error())
+	}
+}
+
+// Silences handles listing (GET ?state=), creating (POST) and expiring
+// (DELETE ?id=) silences
+func (h *Handler) Silences(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var req silence.Silence
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		req.CreatedBy = actorOr(r, req.CreatedBy)
+		created, err := h.silences.Add(req)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondJSON(w, http.StatusCreated, created)
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		if err := h.silences.Expire(id); err != nil {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]string{
+			"status": "expired",
+			"id":     id,
+		})
+	default:
+		state := silence.State(r.URL.Query().Get("state"))
+		respondJSON(w, http.StatusOK, h.silences.Silences(state))
+	}
+}
+
+// MaintenanceWindows handles listing (GET), adding or replacing (POST) and
+// removing (DELETE ?name=) maintenance windows
+func (h *Handler) MaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var window silence.Window
+		if err := json.NewDecoder(r.Body).Decode(&window); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		window.CreatedBy = actorOr(r, window.CreatedBy)
+		if err := h.silences.AddWindows(window); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondJSON(w, http.StatusCreated, window)
+	case http.MethodDelete:
+		name := r.URL.Query().Get("name")
+		if err := h.silences.RemoveWindow(name); err != nil {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]string{
+			"status": "removed",
+			"name":   name,
+		})
+	default:
+		respondJSON(w, http.StatusOK, h.silences.Windows())
+	}
+}
+
+// AssignAnomaly handles requests to assign an anomaly to a user or team.
+// An empty assignee clears the owner.
+func (h *Handler) AssignAnomaly(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID         string `json:"id"`
+		Assignee   string `json:"assignee"`
+		AssignedBy string `json:"assigned_by"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	req.AssignedBy = actorOr(r, req.AssignedBy)
+	if req.ID == "" || req.AssignedBy == "" {
+		respondError(w, http.StatusBadRequest, "id and assigned_by are required")
+		return
+	}
+
+	if err := h.assigner.Assign(req.ID, req.Assignee, req.AssignedBy); err != nil {
+		if errors.Is(err, anomaly.ErrResolved) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	a, _ := h.detector.GetAnomaly(req.ID)
+	respondJSON(w, http.StatusOK, a)
+}
+
+// GetOnCall handles requests for teams and who is on call for each
+// rotation
+func (h *Handler) GetOnCall(w http.ResponseWriter, r *http.Request) {
+	roster := h.assigner.Roster()
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"teams":     roster.Teams(),
+		"rotations": roster.Rotations(),
+	})
+}
+
+// AddOnCallOverride handles requests to put someone on call for a
+// rotation during a time range
+func (h *Handler) AddOnCallOverride(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		Rotation string `json:"rotation"`
+		oncall.Override
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.CreatedBy = actorOr(r, req.CreatedBy)
+
+	if err := h.assigner.Roster().AddOverride(req.Rotation, req.Override); err != nil {
+		if errors.Is(err, oncall.ErrUnknownRotation) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, req.Override)
+}
+
+// Comments handles listing (GET ?id=), adding (POST) and editing (PUT) an
+// anomaly's comments
+func (h *Handler) Comments(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost, http.MethodPut:
+		var req struct {
+			ID        string `json:"id"`
+			CommentID string `json:"comment_id"`
+			Author    string `json:"author"`
+			Body      string `json:"body"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if _, err := h.detector.GetAnomaly(req.ID); err != nil {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		req.Author = actorOr(r, req.Author)
+
+		if r.Method == http.MethodPut {
+			c, err := h.notes.EditComment(req.ID, req.CommentID, req.Author, req.Body)
+			if err != nil {
+				respondNotesError(w, err)
+				return
+			}
+			respondJSON(w, http.StatusOK, c)
+			return
+		}
+		c, err := h.notes.AddComment(req.ID, req.Author, req.Body)
+		if err != nil {
+			respondNotesError(w, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, c)
+	default:
+		id := r.URL.Query().Get("id")
+		if _, err := h.detector.GetAnomaly(id); err != nil {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, h.notes.Comments(id))
+	}
+}
+
+// Attachments handles uploading (POST multipart form with id, uploaded_by
+// and file), listing (GET ?id=) and downloading (GET ?id=&attachment_id=)
+// an anomaly's attachments
+func (h *Handler) Attachments(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		h.uploadAttachment(w, r)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if _, err := h.detector.GetAnomaly(id); err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	attachmentID := r.URL.Query().Get("attachment_id")
+	if attachmentID == "" {
+		respondJSON(w, http.StatusOK, h.notes.Attachments(id))
+		return
+	}
+
+	a, content, err := h.notes.Open(r.Context(), id, attachmentID)
+	if err != nil {
+		respondNotesError(w, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(a.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Name}))
+	w.Header().Set("Digest", a.Digest)
+	io.Copy(w, content)
+}
+
+func (h *Handler) uploadAttachment(w http.ResponseWriter, r *http.Request) {
+	// Leave room for the other form fields; the store enforces the file limit
+	r.Body = http.MaxBytesReader(w, r.Body, h.notes.MaxSize()+1<<20)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "expected a multipart form")
+		return
+	}
+
+	fields := make(map[string]string)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			if err == io.EOF {
+				respondError(w, http.StatusBadRequest, "file is required")
+			} else {
+				respondError(w, http.StatusBadRequest, "invalid multipart form")
+			}
+			return
+		}
+		if part.FormName() != "file" {
+			// Fields must come before the file so it can be streamed
+			value, _ := io.ReadAll(io.LimitReader(part, 1024))
+			fields[part.FormName()] = string(value)
+			continue
+		}
+
+		id := fields["id"]
+		if _, err := h.detector.GetAnomaly(id); err != nil {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		a, err := h.notes.Attach(r.Context(), id, part.FileName(), part.Header.Get("Content-Type"), actorOr(r, fields["uploaded_by"]), part)
+		if err != nil {
+			respondNotesError(w, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, a)
+		return
+	}
+}
+
+func respondNotesError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, notes.ErrNotFound), errors.Is(err, blob.ErrNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, notes.ErrNotAuthor):
+		respondError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, notes.ErrTooLarge), errors.As(err, &tooLarge):
+		respondError(w, http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		respondError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// ReportSubscriptions handles listing (GET), adding or replacing (POST)
+// and removing (DELETE ?name=) scheduled report subscriptions
+func (h *Handler) ReportSubscriptions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var sub delivery.Subscription
+		if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		sub.CreatedBy = actorOr(r, sub.CreatedBy)
+		if err := h.delivery.Add(sub); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondJSON(w, http.StatusCreated, sub)
+	case http.MethodDelete:
+		name := r.URL.Query().Get("name")
+		if err := h.delivery.Remove(name); err != nil {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]string{
+			"status": "removed",
+			"name":   name,
+		})
+	default:
+		respondJSON(w, http.StatusOK, h.delivery.Subscriptions())
+	}
+}
+
+// GetReportDeliveries handles requests for delivery history, optionally
+// for one subscription
+func (h *Handler) GetReportDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := h.delivery.History(r.URL.Query().Get("subscription"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, deliveries)
+}
+
+// SendReport handles requests to deliver a subscription's report now
+func (h *Handler) SendReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Name == "" {
+		respondError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	deliveries, err := h.delivery.SendNow(r.Context(), req.Name)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	status := http.StatusOK
+	for _, d := range deliveries {
+		if d.Status != "delivered" {
+			status = http.StatusBadGateway
+		}
+	}
+	respondJSON(w, status, deliveries)
+}
+
+// GetNotificationChannels handles requests to list notification channels
+// with their delivery counters
+func (h *Handler) GetNotificationChannels(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.channels.Channels())
+}
+
+// WhoAmI handles requests for the caller's authenticated identity
+func (h *Handler) WhoAmI(w http.ResponseWriter, r *http.Request) {
+	p, ok := auth.FromContext(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "not authenticated")
+		return
+	}
+	respondJSON(w, http.StatusOK, p)
+}
+
+// APIKeys handles listing (GET), creating (POST) and revoking (DELETE
+// ?id=) API keys. Only admins may manage keys. The secret is returned
+// once, when the key is created.
+func (h *Handler) APIKeys(w http.ResponseWriter, r *http.Request) {
+	if p, ok := auth.FromContext(r.Context()); !ok || !p.Admin {
+		respondError(w, http.StatusForbidden, "admin access required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var req struct {
+			Name  string `json:"name"`
+			Admin bool   `json:"admin"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		key, token, err := h.keys.Create(req.Name, req.Admin, actor(r))
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondJSON(w, http.StatusCreated, map[string]interface{}{
+			"key":   key,
+			"token": token,
+		})
+	case http.MethodDelete:
+		key, err := h.keys.Revoke(r.URL.Query().Get("id"), actor(r))
+		if err != nil {
+			if errors.Is(err, auth.ErrUnknownKey) {
+				respondError(w, http.StatusNotFound, err.Error())
+				return
+			}
+			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, key)
+	default:
+		respondJSON(w, http.StatusOK, h.keys.Keys())
+	}
+}
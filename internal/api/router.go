@@ -11,15 +11,110 @@ func SetupRoutes(handler *Handler) *http.ServeMux {
 	// Health check
 	mux.HandleFunc("/health", handler.HealthCheck)
 
+	// Authentication endpoints
+	if handler.keys != nil {
+		mux.HandleFunc("/api/v1/auth/whoami", handler.WhoAmI)
+		mux.HandleFunc("/api/v1/auth/keys", handler.APIKeys)
+	}
+
 	// Anomaly endpoints
 	mux.HandleFunc("/api/v1/anomalies", handler.GetAnomalies)
 	mux.HandleFunc("/api/v1/anomalies/detect", handler.DetectAnomalies)
 	mux.HandleFunc("/api/v1/anomalies/get", handler.GetAnomaly)
 	mux.HandleFunc("/api/v1/anomalies/resolve", handler.ResolveAnomaly)
 	mux.HandleFunc("/api/v1/anomalies/report", handler.GetReport)
+	mux.HandleFunc("/api/v1/anomalies/trends", handler.GetTrends)
+	mux.HandleFunc("/api/v1/anomalies/acknowledge", handler.AcknowledgeAnomaly)
+	if handler.assigner != nil {
+		mux.HandleFunc("/api/v1/anomalies/assign", handler.AssignAnomaly)
+		mux.HandleFunc("/api/v1/oncall", handler.GetOnCall)
+		mux.HandleFunc("/api/v1/oncall/overrides", handler.AddOnCallOverride)
+	}
+	if handler.notes != nil {
+		mux.HandleFunc("/api/v1/anomalies/comments", handler.Comments)
+		mux.HandleFunc("/api/v1/anomalies/attachments", handler.Attachments)
+	}
+	if handler.escalation != nil {
+		mux.HandleFunc("/api/v1/escalation/policies", handler.GetEscalationPolicies)
+	}
+	if handler.channels != nil {
+		mux.HandleFunc("/api/v1/notifications/channels", handler.GetNotificationChannels)
+	}
+	if handler.detector.Registry() != nil {
+		mux.HandleFunc("/api/v1/anomaly-types", handler.AnomalyTypes)
+	}
+	if handler.enricher != nil {
+		mux.HandleFunc("/api/v1/anomalies/enrichment", handler.GetEnrichment)
+	}
+	if handler.consensus != nil {
+		mux.HandleFunc("/api/v1/anomalies/vote", handler.VoteOnResolution)
+		mux.HandleFunc("/api/v1/anomalies/approvals", handler.GetApprovals)
+	}
+
+	// Incident endpoints
+	if handler.incidents != nil {
+		mux.HandleFunc("/api/v1/incidents", handler.GetIncidents)
+		mux.HandleFunc("/api/v1/incidents/get", handler.GetIncident)
+		mux.HandleFunc("/api/v1/incidents/resolve", handler.ResolveIncident)
+		mux.HandleFunc("/api/v1/incidents/root-cause", handler.MarkRootCause)
+	}
+
+	// Silence endpoints
+	if handler.silences != nil {
+		mux.HandleFunc("/api/v1/silences", handler.Silences)
+		mux.HandleFunc("/api/v1/maintenance-windows", handler.MaintenanceWindows)
+	}
+
+	// Report delivery endpoints
+	if handler.delivery != nil {
+		mux.HandleFunc("/api/v1/reports/subscriptions", handler.ReportSubscriptions)
+		mux.HandleFunc("/api/v1/reports/deliveries", handler.GetReportDeliveries)
+		mux.HandleFunc("/api/v1/reports/send", handler.SendReport)
+	}
 
 	// Search endpoint
 	mux.HandleFunc("/api/v1/search", handler.Search)
+	if handler.guard != nil {
+		mux.HandleFunc("/api/v1/search/stats", handler.GetSearchStats)
+	}
+
+	// Superintelligence Loop endpoints
+	if handler.loop != nil {
+		mux.HandleFunc("/api/v1/loop/traces", handler.GetLoopTraces)
+		mux.HandleFunc("/api/v1/loop/stats", handler.GetLoopStats)
+		mux.HandleFunc("/api/v1/loop/review", handler.ReviewProposal)
+	}
+
+	// Runbook endpoints
+	if handler.runbooks != nil {
+		mux.HandleFunc("/api/v1/runbooks", handler.GetRunbooks)
+		mux.HandleFunc("/api/v1/runbooks/run", handler.RunRunbook)
+		mux.HandleFunc("/api/v1/runbooks/approve", handler.ApproveRunbookStep)
+	}
+
+	// Coopetition endpoint
+	if handler.arena != nil {
+		mux.HandleFunc("/api/v1/coopetition/leaderboard", handler.GetLeaderboard)
+	}
+
+	// Metrics endpoints
+	if handler.metrics != nil {
+		mux.HandleFunc("/api/v1/metrics", handler.Metrics)
+		mux.HandleFunc("/api/v1/metrics/query", handler.QueryMetrics)
+	}
+
+	// Rule endpoints
+	if handler.rules != nil {
+		mux.HandleFunc("/api/v1/rules", handler.Rules)
+		mux.HandleFunc("/api/v1/rules/test", handler.TestRule)
+	}
+
+	// Scheduler endpoints
+	if handler.scheduler != nil {
+		mux.HandleFunc("/api/v1/scheduler/jobs", handler.GetSchedulerJobs)
+		mux.HandleFunc("/api/v1/scheduler/runs", handler.GetSchedulerRuns)
+		mux.HandleFunc("/api/v1/scheduler/run", handler.RunSchedulerJob)
+	}
 
 	// Blockchain endpoint
 	mux.HandleFunc("/api/v1/blockchain/status", handler.GetBlockchainStatus)
@@ -0,0 +1,130 @@
+// Package auth authenticates API requests with API keys or signed JWT
+// bearer tokens and carries the authenticated principal in the request
+// context, so handlers can record who acted.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrMissingCredentials is returned for a request without an API key
+	// or bearer token
+	ErrMissingCredentials = errors.New("missing credentials")
+	// ErrInvalidCredentials is returned for an unknown, revoked or
+	// malformed API key or token
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Method is how a principal authenticated
+type Method string
+
+const (
+	MethodAPIKey Method = "api_key"
+	MethodJWT    Method = "jwt"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject string `json:"subject"`
+	Method  Method `json:"method"`
+	KeyID   string `json:"key_id,omitempty"`
+	Admin   bool   `json:"admin"`
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying p
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal a request authenticated as
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Principal)
+	return p, ok
+}
+
+// Authenticator checks the credentials of API requests. A request sends
+// an API key in the X-API-Key header or as a bearer token; any other
+// bearer token is verified as a JWT.
+type Authenticator struct {
+	keys      *KeyStore
+	verifier  *Verifier
+	bootstrap []byte
+}
+
+// NewAuthenticator creates an authenticator accepting keys from keys and,
+// when verifier is not nil, JWTs. bootstrapKey, when set, is an admin key
+// given in configuration for creating the first stored keys; only its
+// hash is kept.
+func NewAuthenticator(keys *KeyStore, verifier *Verifier, bootstrapKey string) *Authenticator {
+	a := &Authenticator{keys: keys, verifier: verifier}
+	if bootstrapKey != "" {
+		sum := sha256.Sum256([]byte(bootstrapKey))
+		a.bootstrap = sum[:]
+	}
+	return a
+}
+
+// Authenticate returns the principal r's credentials identify
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token := strings.TrimSpace(r.Header.Get("X-API-Key"))
+	if token == "" {
+		scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return nil, ErrMissingCredentials
+		}
+		token = strings.TrimSpace(value)
+	}
+	if token == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	if a.bootstrap != nil {
+		sum := sha256.Sum256([]byte(token))
+		if subtle.ConstantTimeCompare(sum[:], a.bootstrap) == 1 {
+			return &Principal{Subject: "bootstrap", Method: MethodAPIKey, Admin: true}, nil
+		}
+	}
+	if strings.HasPrefix(token, keyPrefix) {
+		return a.keys.Verify(token)
+	}
+	if a.verifier == nil {
+		return nil, ErrInvalidCredentials
+	}
+	return a.verifier.Verify(token)
+}
+
+// Middleware rejects requests under /api/ that do not authenticate with
+// 401 and passes the principal on to next in the request context. Other
+// paths, such as the health check, stay open.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		p, err := a.Authenticate(r)
+		if err != nil {
+			challenge := `Bearer realm="api"`
+			if !errors.Is(err, ErrMissingCredentials) {
+				challenge += `, error="invalid_token"`
+			}
+			w.Header().Set("WWW-Authenticate", challenge)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("unauthorized: %v", err)})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), p)))
+	})
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sign(t *testing.T, alg, kid string, claims map[string]interface{}, key interface{}) string {
+	t.Helper()
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	segment := func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := segment(header) + "." + segment(claims)
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(k, []byte(signed))
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func claims(overrides map[string]interface{}) map[string]interface{} {
+	c := map[string]interface{}{
+		"iss": "https://auth.example.com",
+		"aud": "anomaly-api",
+		"sub": "alice",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(c, k)
+			continue
+		}
+		c[k] = v
+	}
+	return c
+}
+
+func TestKeyStoreHashesAndRevokes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	store, err := NewKeyStore(path)
+	if err != nil {
+		t.Fatalf("NewKeyStore failed: %v", err)
+	}
+
+	key, token, err := store.Create("ci-bot", false, "alice")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), token) || !strings.Contains(string(data), hashKey(token)) {
+		t.Error("Expected only the key's hash to be stored")
+	}
+
+	// A new store reads the hashed key back
+	reloaded, err := NewKeyStore(path)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	p, err := reloaded.Verify(token)
+	if err != nil || p.Subject != "ci-bot" || p.KeyID != key.ID || p.Admin {
+		t.Fatalf("Expected the key to authenticate as ci-bot, got %+v, %v", p, err)
+	}
+	if _, err := reloaded.Verify(token + "x"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected a wrong secret to fail, got %v", err)
+	}
+
+	if _, err := reloaded.Revoke(key.ID, "alice"); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if _, err := reloaded.Verify(token); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected a revoked key to fail, got %v", err)
+	}
+	if _, err := reloaded.Revoke("missing", "alice"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected an unknown key error, got %v", err)
+	}
+}
+
+func TestVerifierHMAC(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	v, err := NewVerifier(JWTConfig{Issuer: "https://auth.example.com", Audience: "anomaly-api", HMACSecret: secret})
+	if err != nil {
+		t.Fatalf("NewVerifier failed: %v", err)
+	}
+
+	p, err := v.Verify(sign(t, "HS256", "", claims(map[string]interface{}{"aud": []string{"other", "anomaly-api"}, "roles": []string{"admin"}}), secret))
+	if err != nil || p.Subject != "alice" || !p.Admin || p.Method != MethodJWT {
+		t.Fatalf("Expected a valid admin token, got %+v, %v", p, err)
+	}
+
+	for name, token := range map[string]string{
+		"wrong issuer":   sign(t, "HS256", "", claims(map[string]interface{}{"iss": "https://evil.example.com"}), secret),
+		"wrong audience": sign(t, "HS256", "", claims(map[string]interface{}{"aud": "other"}), secret),
+		"expired":        sign(t, "HS256", "", claims(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}), secret),
+		"no expiry":      sign(t, "HS256", "", claims(map[string]interface{}{"exp": nil}), secret),
+		"not yet valid":  sign(t, "HS256", "", claims(map[string]interface{}{"nbf": time.Now().Add(time.Hour).Unix()}), secret),
+		"no subject":     sign(t, "HS256", "", claims(map[string]interface{}{"sub": nil}), secret),
+		"wrong secret":   sign(t, "HS256", "", claims(nil), []byte("another secret that is 32 bytes!")),
+		"alg none":       sign(t, "none", "", claims(nil), nil),
+	} {
+		if _, err := v.Verify(token); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Expected %s to be rejected, got %v", name, err)
+		}
+	}
+}
+
+func TestVerifierEd25519(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	v, err := NewVerifier(JWTConfig{
+		Issuer:      "https://auth.example.com",
+		Audience:    "anomaly-api",
+		Ed25519Keys: map[string]ed25519.PublicKey{"2026-10": public},
+	})
+	if err != nil {
+		t.Fatalf("NewVerifier failed: %v", err)
+	}
+
+	if p, err := v.Verify(sign(t, "EdDSA", "2026-10", claims(map[string]interface{}{"scope": "read write"}), private)); err != nil || p.Admin {
+		t.Errorf("Expected a valid non-admin token, got %+v, %v", p, err)
+	}
+	if _, err := v.Verify(sign(t, "EdDSA", "", claims(nil), private)); err != nil {
+		t.Errorf("Expected a token without kid to match any key, got %v", err)
+	}
+	if _, err := v.Verify(sign(t, "EdDSA", "retired", claims(nil), private)); err == nil {
+		t.Error("Expected an unknown key ID to be rejected")
+	}
+	// The public key must not be usable as an HMAC secret
+	if _, err := v.Verify(sign(t, "HS256", "", claims(nil), []byte(public))); err == nil {
+		t.Error("Expected HS256 to be rejected without an HMAC secret")
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(public)
+	if parsed, err := ParseEd25519Key(encoded); err != nil || !parsed.Equal(public) {
+		t.Errorf("ParseEd25519Key failed: %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	store, _ := NewKeyStore("")
+	_, token, _ := store.Create("ci-bot", false, "")
+	authn := NewAuthenticator(store, nil, "bootstrap-secret")
+
+	var seen *Principal
+	handler := authn.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = FromContext(r.Context())
+	}))
+	serve := func(path string, header http.Header) *httptest.ResponseRecorder {
+		seen = nil
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header = header
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("/health", http.Header{}); rec.Code != http.StatusOK {
+		t.Errorf("Expected the health check to stay open, got %d", rec.Code)
+	}
+	rec := serve("/api/v1/anomalies", http.Header{})
+	if rec.Code != http.StatusUnauthorized || !strings.HasPrefix(rec.Header().Get("WWW-Authenticate"), "Bearer") {
+		t.Errorf("Expected 401 with a challenge, got %d", rec.Code)
+	}
+	if rec := serve("/api/v1/anomalies", http.Header{"Authorization": {"Bearer not-a-token"}}); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected an unverifiable token to be rejected, got %d", rec.Code)
+	}
+
+	if rec := serve("/api/v1/anomalies", http.Header{"Authorization": {"Bearer " + token}}); rec.Code != http.StatusOK || seen == nil || seen.Subject != "ci-bot" {
+		t.Errorf("Expected the key to authenticate, got %d %+v", rec.Code, seen)
+	}
+	if serve("/api/v1/anomalies", http.Header{"X-Api-Key": {"bootstrap-secret"}}); seen == nil || !seen.Admin {
+		t.Errorf("Expected the bootstrap key to authenticate as admin, got %+v", seen)
+	}
+}
@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultLeeway tolerates clock skew between the issuer and this server
+const defaultLeeway = time.Minute
+
+// JWTConfig configures bearer token verification. Tokens signed with
+// HS256 are checked against HMACSecret and tokens signed with EdDSA
+// against Ed25519Keys, by key ID when the token names one.
+type JWTConfig struct {
+	Issuer      string
+	Audience    string
+	HMACSecret  []byte
+	Ed25519Keys map[string]ed25519.PublicKey
+	// AdminRole is the role in the roles or scope claim that grants
+	// admin access, "admin" by default
+	AdminRole string
+	Leeway    time.Duration
+}
+
+// Verifier checks signed JWTs
+type Verifier struct {
+	config JWTConfig
+	now    func() time.Time
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	Roles     []string `json:"roles"`
+	Scope     string   `json:"scope"`
+}
+
+// audience accepts the aud claim as a string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// NewVerifier creates a verifier for config. An issuer, an audience and
+// at least one key are required.
+func NewVerifier(config JWTConfig) (*Verifier, error) {
+	if config.Issuer == "" || config.Audience == "" {
+		return nil, fmt.Errorf("JWT issuer and audience are required")
+	}
+	if len(config.HMACSecret) == 0 && len(config.Ed25519Keys) == 0 {
+		return nil, fmt.Errorf("an HMAC secret or Ed25519 public key is required")
+	}
+	if len(config.HMACSecret) > 0 && len(config.HMACSecret) < 32 {
+		return nil, fmt.Errorf("the HMAC secret must be at least 32 bytes")
+	}
+	if config.AdminRole == "" {
+		config.AdminRole = "admin"
+	}
+	if config.Leeway == 0 {
+		config.Leeway = defaultLeeway
+	}
+	return &Verifier{config: config, now: time.Now}, nil
+}
+
+// Verify checks token's signature and claims and returns its subject as
+// the principal
+func (v *Verifier) Verify(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidCredentials)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidCredentials)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidCredentials)
+	}
+	if !v.verifySignature(header, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidCredentials)
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidCredentials)
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	admin := false
+	for _, role := range append(claims.Roles, strings.Fields(claims.Scope)...) {
+		if role == v.config.AdminRole {
+			admin = true
+		}
+	}
+	return &Principal{Subject: claims.Subject, Method: MethodJWT, Admin: admin}, nil
+}
+
+// verifySignature only accepts the algorithms a key is configured for,
+// so a token cannot choose how it is checked
+func (v *Verifier) verifySignature(header jwtHeader, signed, signature []byte) bool {
+	switch header.Alg {
+	case "HS256":
+		if len(v.config.HMACSecret) == 0 {
+			return false
+		}
+		mac := hmac.New(sha256.New, v.config.HMACSecret)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), signature)
+	case "EdDSA":
+		if header.Kid != "" {
+			key, ok := v.config.Ed25519Keys[header.Kid]
+			return ok && ed25519.Verify(key, signed, signature)
+		}
+		for _, key := range v.config.Ed25519Keys {
+			if ed25519.Verify(key, signed, signature) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (v *Verifier) checkClaims(c jwtClaims) error {
+	now := v.now()
+	if c.ExpiresAt == nil {
+		return fmt.Errorf("token has no expiry")
+	}
+	if now.After(time.Unix(*c.ExpiresAt, 0).Add(v.config.Leeway)) {
+		return fmt.Errorf("token expired")
+	}
+	if c.NotBefore != nil && now.Add(v.config.Leeway).Before(time.Unix(*c.NotBefore, 0)) {
+		return fmt.Errorf("token not valid yet")
+	}
+	if c.Issuer != v.config.Issuer {
+		return fmt.Errorf("unexpected issuer %q", c.Issuer)
+	}
+	found := false
+	for _, aud := range c.Audience {
+		if aud == v.config.Audience {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("token is not for audience %q", v.config.Audience)
+	}
+	if c.Subject == "" {
+		return fmt.Errorf("token has no subject")
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// ParseEd25519Key decodes a base64 public key, either the raw 32 bytes or
+// a DER encoded SubjectPublicKeyInfo as in a PEM "PUBLIC KEY" block
+func ParseEd25519Key(s string) (ed25519.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Ed25519 public key: %w", err)
+	}
+	if len(der) == ed25519.PublicKeySize {
+		return ed25519.PublicKey(der), nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Ed25519 public key: %w", err)
+	}
+	key, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not Ed25519")
+	}
+	return key, nil
+}
@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keyPrefix starts every API key so it can be told apart from a JWT
+const keyPrefix = "ak_"
+
+// ErrUnknownKey is returned for a key ID that was never created
+var ErrUnknownKey = errors.New("unknown API key")
+
+// Key describes an API key. The secret itself is only shown when the key
+// is created; the store keeps its SHA-256 hash.
+type Key struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Admin      bool       `json:"admin"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy  string     `json:"revoked_by,omitempty"`
+}
+
+// storedKey is a key as saved at rest
+type storedKey struct {
+	Key
+	Hash string `json:"hash"`
+}
+
+// KeyStore holds hashed API keys, saved to a JSON file when it has a path
+type KeyStore struct {
+	path string
+	keys map[string]*storedKey
+	now  func() time.Time
+	mu   sync.RWMutex
+}
+
+// NewKeyStore creates a store saved to path, loading the keys already
+// there. An empty path keeps keys in memory only.
+func NewKeyStore(path string) (*KeyStore, error) {
+	s := &KeyStore{path: path, keys: make(map[string]*storedKey), now: time.Now}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %w", err)
+	}
+	var stored []*storedKey
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("invalid API keys file %s: %w", path, err)
+	}
+	for _, k := range stored {
+		s.keys[k.ID] = k
+	}
+	return s, nil
+}
+
+// Create adds a key for name and returns it with the secret token, which
+// is not stored and cannot be retrieved again
+func (s *KeyStore) Create(name string, admin bool, createdBy string) (Key, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return Key{}, "", fmt.Errorf("key name is required")
+	}
+	id := make([]byte, 8)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return Key{}, "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return Key{}, "", err
+	}
+	k := &storedKey{Key: Key{
+		ID:        hex.EncodeToString(id),
+		Name:      name,
+		Admin:     admin,
+		CreatedBy: createdBy,
+		CreatedAt: s.now(),
+	}}
+	token := keyPrefix + k.ID + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	k.Hash = hashKey(token)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[k.ID] = k
+	if err := s.save(); err != nil {
+		delete(s.keys, k.ID)
+		return Key{}, "", err
+	}
+	return k.Key, token, nil
+}
+
+// Revoke stops a key from authenticating
+func (s *KeyStore) Revoke(id, by string) (Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.keys[id]
+	if !ok {
+		return Key{}, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	if k.RevokedAt == nil {
+		now := s.now()
+		k.RevokedAt = &now
+		k.RevokedBy = by
+		if err := s.save(); err != nil {
+			k.RevokedAt, k.RevokedBy = nil, ""
+			return Key{}, err
+		}
+	}
+	return k.Key, nil
+}
+
+// Keys returns every key, revoked ones included, oldest first
+func (s *KeyStore) Keys() []Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]Key, 0, len(s.keys))
+	for _, k := range s.keys {
+		keys = append(keys, k.Key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys
+}
+
+// Verify returns the principal for an API key token
+func (s *KeyStore) Verify(token string) (*Principal, error) {
+	id, _, ok := strings.Cut(strings.TrimPrefix(token, keyPrefix), "_")
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, exists := s.keys[id]
+	if !exists || subtle.ConstantTimeCompare([]byte(hashKey(token)), []byte(k.Hash)) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	if k.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: key revoked", ErrInvalidCredentials)
+	}
+	// Last use is kept in memory and saved with the next change
+	now := s.now()
+	k.LastUsedAt = &now
+	return &Principal{Subject: k.Name, Method: MethodAPIKey, KeyID: k.ID, Admin: k.Admin}, nil
+}
+
+// save writes the keys to the store's file; callers hold s.mu
+func (s *KeyStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	stored := make([]*storedKey, 0, len(s.keys))
+	for _, k := range s.keys {
+		stored = append(stored, k)
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].CreatedAt.Before(stored[j].CreatedAt) })
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".keys-*")
+	if err != nil {
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	return nil
+}
+
+func hashKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
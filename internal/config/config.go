@@ -4,14 +4,34 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config holds all application configuration
 type Config struct {
-	Server   ServerConfig
-	Bing     BingConfig
-	Database DatabaseConfig
-	Manus    ManusConfig
+	Server     ServerConfig
+	Bing       BingConfig
+	Database   DatabaseConfig
+	Manus      ManusConfig
+	Enrichment EnrichmentConfig
+	Loop       LoopConfig
+	Agents     AgentsConfig
+	Consensus  ConsensusConfig
+	Runbooks   RunbooksConfig
+	Scheduler  SchedulerConfig
+	Leader     LeaderConfig
+	Metrics    MetricsConfig
+	Rules      RulesConfig
+	Types      TypesConfig
+	Incidents  IncidentsConfig
+	Silences   SilencesConfig
+	Escalation EscalationConfig
+	Notify     NotifyConfig
+	OnCall     OnCallConfig
+	Notes      NotesConfig
+	Reports    ReportsConfig
+	SMTP       SMTPConfig
+	Auth       AuthConfig
 }
 
 // ServerConfig holds server-related configuration
@@ -25,8 +45,17 @@ type ServerConfig struct {
 
 // BingConfig holds Bing Search API configuration
 type BingConfig struct {
-	APIKey   string
-	Endpoint string
+	APIKey           string
+	Endpoint         string
+	Timeout          int
+	RateLimit        float64
+	RateBurst        int
+	CacheTTL         int
+	CacheSize        int
+	BreakerThreshold int
+	BreakerCooldown  int
+	CassetteMode     string
+	CassettePath     string
 }
 
 // DatabaseConfig holds database configuration
@@ -39,6 +68,12 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+// DSN returns the PostgreSQL connection string
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		d.Host, d.Port, d.User, d.Password, d.DBName, d.SSLMode)
+}
+
 // ManusConfig holds Manus Blockchain configuration
 type ManusConfig struct {
 	NodeURL         string
@@ -46,6 +81,165 @@ type ManusConfig struct {
 	EnablePlanetary bool
 }
 
+// EnrichmentConfig holds anomaly context enrichment configuration
+type EnrichmentConfig struct {
+	Enabled       bool
+	Workers       int
+	QueueSize     int
+	DisabledTypes []string
+}
+
+// LoopConfig holds Superintelligence Loop configuration
+type LoopConfig struct {
+	Enabled        bool
+	Workers        int
+	SafeTypes      []string
+	SafeSeverities []string
+	MinConfidence  float64
+}
+
+// AgentsConfig holds Coopetition agent configuration
+type AgentsConfig struct {
+	Enabled            bool
+	MaxBlockAge        int
+	LatencyThresholdMs float64
+}
+
+// ConsensusConfig holds the approval policy for high-impact resolutions
+type ConsensusConfig struct {
+	Enabled     bool
+	Severities  []string
+	Required    int
+	Voters      []string
+	VoteTTL     int
+	AgentsVote  bool
+	SweepPeriod int
+}
+
+// RunbooksConfig holds remediation runbook configuration
+type RunbooksConfig struct {
+	Enabled        bool
+	File           string
+	WebhookTimeout int
+}
+
+// SchedulerConfig holds background detection schedules. Schedules are
+// "@every <duration>" or five-field cron expressions; an empty schedule
+// disables the job.
+type SchedulerConfig struct {
+	Enabled    bool
+	Jitter     int
+	Timeout    int
+	Manus      string
+	Copilot    string
+	Emissary   string
+	Simulation string
+}
+
+// LeaderConfig holds leader election configuration. Store is "sql" to
+// share leases through the database or "memory" for a single replica.
+type LeaderConfig struct {
+	Enabled  bool
+	Store    string
+	Lease    string
+	Identity string
+	LeaseTTL int
+}
+
+// MetricsConfig holds metric ingestion configuration. Rollups are
+// RollupResolution-second averages kept after raw points are evicted and
+// saved to PersistFile, when set, on PersistSchedule.
+type MetricsConfig struct {
+	Enabled          bool
+	BufferSize       int
+	MaxSeries        int
+	RollupResolution int
+	RollupSize       int
+	PersistFile      string
+	PersistSchedule  string
+}
+
+// RulesConfig holds declarative rule configuration
+type RulesConfig struct {
+	Enabled bool
+	File    string
+}
+
+// TypesConfig holds anomaly type registry configuration
+type TypesConfig struct {
+	File string
+}
+
+// SilencesConfig holds silence and maintenance window configuration
+type SilencesConfig struct {
+	Enabled     bool
+	WindowsFile string
+}
+
+// EscalationConfig holds SLA tracking and escalation configuration
+type EscalationConfig struct {
+	Enabled       bool
+	PolicyFile    string
+	CheckSchedule string
+}
+
+// NotifyConfig holds notification delivery configuration
+type NotifyConfig struct {
+	WebhookURL     string
+	WebhookTimeout int
+	ChannelsFile   string
+	DigestSchedule string
+}
+
+// OnCallConfig holds assignment and on-call rotation configuration
+type OnCallConfig struct {
+	Enabled bool
+	File    string
+}
+
+// NotesConfig holds comment and attachment configuration
+type NotesConfig struct {
+	Enabled           bool
+	AttachmentDir     string
+	MaxAttachmentSize int
+}
+
+// ReportsConfig holds scheduled report delivery configuration
+type ReportsConfig struct {
+	Enabled           bool
+	SubscriptionsFile string
+	CheckSchedule     string
+}
+
+// SMTPConfig holds the relay email is sent through. Email is disabled
+// when Host is empty.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// AuthConfig holds API authentication configuration. JWTs are accepted
+// when JWTIssuer is set; JWTEd25519Keys entries are "kid=base64 key".
+type AuthConfig struct {
+	Enabled        bool
+	KeysFile       string
+	BootstrapKey   string
+	JWTIssuer      string
+	JWTAudience    string
+	JWTHMACSecret  string
+	JWTEd25519Keys []string
+}
+
+// IncidentsConfig holds anomaly correlation configuration
+type IncidentsConfig struct {
+	Enabled   bool
+	Window    int
+	RulesFile string
+}
+
 // Load reads configuration from environment variables
 func Load() (*Config, error) {
 	config := &Config{
@@ -57,8 +251,17 @@ func Load() (*Config, error) {
 			WriteTimeout: getEnvAsInt("WRITE_TIMEOUT", 15),
 		},
 		Bing: BingConfig{
-			APIKey:   getEnv("BING_API_KEY", ""),
-			Endpoint: getEnv("BING_ENDPOINT", "https://api.bing.microsoft.com/v7.0/search"),
+			APIKey:           getEnv("BING_API_KEY", ""),
+			Endpoint:         getEnv("BING_ENDPOINT", "https://api.bing.microsoft.com/v7.0/search"),
+			Timeout:          getEnvAsInt("BING_TIMEOUT", 10),
+			RateLimit:        getEnvAsFloat("BING_RATE_LIMIT", 3),
+			RateBurst:        getEnvAsInt("BING_RATE_BURST", 3),
+			CacheTTL:         getEnvAsInt("SEARCH_CACHE_TTL", 300),
+			CacheSize:        getEnvAsInt("SEARCH_CACHE_SIZE", 512),
+			BreakerThreshold: getEnvAsInt("SEARCH_BREAKER_THRESHOLD", 5),
+			BreakerCooldown:  getEnvAsInt("SEARCH_BREAKER_COOLDOWN", 30),
+			CassetteMode:     getEnv("BING_CASSETTE_MODE", ""),
+			CassettePath:     getEnv("BING_CASSETTE", "testdata/cassettes/bing.json"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -73,12 +276,129 @@ func Load() (*Config, error) {
 			NetworkID:       getEnv("MANUS_NETWORK_ID", "1"),
 			EnablePlanetary: getEnvAsBool("MANUS_ENABLE_PLANETARY", false),
 		},
+		Enrichment: EnrichmentConfig{
+			Enabled:       getEnvAsBool("ENRICHMENT_ENABLED", true),
+			Workers:       getEnvAsInt("ENRICHMENT_WORKERS", 4),
+			QueueSize:     getEnvAsInt("ENRICHMENT_QUEUE_SIZE", 100),
+			DisabledTypes: getEnvAsList("ENRICHMENT_DISABLED_TYPES"),
+		},
+		Loop: LoopConfig{
+			Enabled:        getEnvAsBool("LOOP_ENABLED", true),
+			Workers:        getEnvAsInt("LOOP_WORKERS", 2),
+			SafeTypes:      getEnvAsList("LOOP_SAFE_TYPES"),
+			SafeSeverities: getEnvAsList("LOOP_SAFE_SEVERITIES"),
+			MinConfidence:  getEnvAsFloat("LOOP_MIN_CONFIDENCE", 0.6),
+		},
+		Agents: AgentsConfig{
+			Enabled:            getEnvAsBool("AGENTS_ENABLED", true),
+			MaxBlockAge:        getEnvAsInt("AGENTS_MAX_BLOCK_AGE", 120),
+			LatencyThresholdMs: getEnvAsFloat("AGENTS_LATENCY_THRESHOLD_MS", 300),
+		},
+		Consensus: ConsensusConfig{
+			Enabled:     getEnvAsBool("CONSENSUS_ENABLED", true),
+			Severities:  getEnvAsList("CONSENSUS_SEVERITIES"),
+			Required:    getEnvAsInt("CONSENSUS_REQUIRED", 2),
+			Voters:      getEnvAsList("CONSENSUS_VOTERS"),
+			VoteTTL:     getEnvAsInt("CONSENSUS_VOTE_TTL", 3600),
+			AgentsVote:  getEnvAsBool("CONSENSUS_AGENTS_VOTE", true),
+			SweepPeriod: getEnvAsInt("CONSENSUS_SWEEP_INTERVAL", 60),
+		},
+		Runbooks: RunbooksConfig{
+			Enabled:        getEnvAsBool("RUNBOOKS_ENABLED", true),
+			File:           getEnv("RUNBOOKS_FILE", ""),
+			WebhookTimeout: getEnvAsInt("RUNBOOK_WEBHOOK_TIMEOUT", 10),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:    getEnvAsBool("SCHEDULER_ENABLED", true),
+			Jitter:     getEnvAsInt("SCHEDULE_JITTER", 10),
+			Timeout:    getEnvAsInt("SCHEDULE_TIMEOUT", 30),
+			Manus:      getEnv("SCHEDULE_MANUS", "@every 1m"),
+			Copilot:    getEnv("SCHEDULE_COPILOT", "@every 5m"),
+			Emissary:   getEnv("SCHEDULE_EMISSARY", "@every 30s"),
+			Simulation: getEnv("SCHEDULE_SIMULATION", ""),
+		},
+		Leader: LeaderConfig{
+			Enabled:  getEnvAsBool("LEADER_ELECTION_ENABLED", false),
+			Store:    getEnv("LEADER_STORE", "sql"),
+			Lease:    getEnv("LEADER_LEASE", "manus-copilot"),
+			Identity: getEnv("LEADER_IDENTITY", ""),
+			LeaseTTL: getEnvAsInt("LEADER_LEASE_TTL", 15),
+		},
+		Metrics: MetricsConfig{
+			Enabled:          getEnvAsBool("METRICS_ENABLED", true),
+			BufferSize:       getEnvAsInt("METRICS_BUFFER_SIZE", 1440),
+			MaxSeries:        getEnvAsInt("METRICS_MAX_SERIES", 10000),
+			RollupResolution: getEnvAsInt("METRICS_ROLLUP_RESOLUTION", 60),
+			RollupSize:       getEnvAsInt("METRICS_ROLLUP_SIZE", 10080),
+			PersistFile:      getEnv("METRICS_PERSIST_FILE", ""),
+			PersistSchedule:  getEnv("METRICS_PERSIST_SCHEDULE", "@every 5m"),
+		},
+		Rules: RulesConfig{
+			Enabled: getEnvAsBool("RULES_ENABLED", true),
+			File:    getEnv("RULES_FILE", ""),
+		},
+		Types: TypesConfig{
+			File: getEnv("ANOMALY_TYPES_FILE", ""),
+		},
+		Incidents: IncidentsConfig{
+			Enabled:   getEnvAsBool("INCIDENTS_ENABLED", true),
+			Window:    getEnvAsInt("INCIDENT_WINDOW", 600),
+			RulesFile: getEnv("INCIDENT_RULES_FILE", ""),
+		},
+		Silences: SilencesConfig{
+			Enabled:     getEnvAsBool("SILENCES_ENABLED", true),
+			WindowsFile: getEnv("MAINTENANCE_WINDOWS_FILE", ""),
+		},
+		Escalation: EscalationConfig{
+			Enabled:       getEnvAsBool("ESCALATION_ENABLED", true),
+			PolicyFile:    getEnv("ESCALATION_POLICY_FILE", ""),
+			CheckSchedule: getEnv("ESCALATION_CHECK_SCHEDULE", "@every 30s"),
+		},
+		Notify: NotifyConfig{
+			WebhookURL:     getEnv("NOTIFY_WEBHOOK_URL", ""),
+			WebhookTimeout: getEnvAsInt("NOTIFY_WEBHOOK_TIMEOUT", 10),
+			ChannelsFile:   getEnv("NOTIFY_CHANNELS_FILE", ""),
+			DigestSchedule: getEnv("NOTIFY_DIGEST_SCHEDULE", "@every 30s"),
+		},
+		OnCall: OnCallConfig{
+			Enabled: getEnvAsBool("ONCALL_ENABLED", true),
+			File:    getEnv("ONCALL_FILE", ""),
+		},
+		Notes: NotesConfig{
+			Enabled:           getEnvAsBool("NOTES_ENABLED", true),
+			AttachmentDir:     getEnv("ATTACHMENT_DIR", "data/attachments"),
+			MaxAttachmentSize: getEnvAsInt("MAX_ATTACHMENT_SIZE", 10<<20),
+		},
+		Reports: ReportsConfig{
+			Enabled:           getEnvAsBool("REPORTS_ENABLED", true),
+			SubscriptionsFile: getEnv("REPORT_SUBSCRIPTIONS_FILE", ""),
+			CheckSchedule:     getEnv("REPORT_CHECK_SCHEDULE", "@every 1m"),
+		},
+		SMTP: SMTPConfig{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getEnvAsInt("SMTP_PORT", 587),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", "anomalies@localhost"),
+		},
+		Auth: AuthConfig{
+			Enabled:        getEnvAsBool("AUTH_ENABLED", true),
+			KeysFile:       getEnv("AUTH_KEYS_FILE", "data/api-keys.json"),
+			BootstrapKey:   getEnv("AUTH_BOOTSTRAP_KEY", ""),
+			JWTIssuer:      getEnv("AUTH_JWT_ISSUER", ""),
+			JWTAudience:    getEnv("AUTH_JWT_AUDIENCE", ""),
+			JWTHMACSecret:  getEnv("AUTH_JWT_HMAC_SECRET", ""),
+			JWTEd25519Keys: getEnvAsList("AUTH_JWT_ED25519_KEYS"),
+		},
 	}
 
 	// Validate required fields
 	if config.Server.Environment == "production" && config.Bing.APIKey == "" {
 		return nil, fmt.Errorf("BING_API_KEY is required in production environment")
 	}
+	if config.Leader.Enabled && config.Leader.LeaseTTL <= 0 {
+		return nil, fmt.Errorf("LEADER_LEASE_TTL must be a positive number of seconds")
+	}
 
 	return config, nil
 }
@@ -99,6 +419,14 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	valueStr := os.Getenv(key)
 	if value, err := strconv.ParseBool(valueStr); err == nil {
@@ -106,3 +434,13 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
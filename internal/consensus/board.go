@@ -0,0 +1,355 @@
+// Package consensus gates resolutions of high-impact anomalies behind
+// N-of-M approvals from human users and agents, recording the final tally
+// on the ledger alongside the resolution.
+package consensus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blockchain"
+)
+
+var (
+	// ErrNoBallot is returned when voting on an anomaly without a pending approval
+	ErrNoBallot = errors.New("no pending approval")
+	// ErrBallotPending is returned when a different resolution is already up for approval
+	ErrBallotPending = errors.New("another resolution is pending approval")
+	// ErrNotEligible is returned when the voter is not in the policy's voter list
+	ErrNotEligible = errors.New("voter is not eligible")
+	// ErrAlreadyResolved is returned when requesting approval for a resolved anomaly
+	ErrAlreadyResolved = errors.New("anomaly is already resolved")
+)
+
+// Policy requires Required approvals for anomalies of the listed
+// severities. With Voters set, only those names may vote (N-of-M);
+// otherwise anyone may. Votes older than VoteTTL no longer count.
+type Policy struct {
+	Severities []models.AnomalySeverity
+	Required   int
+	Voters     []string
+	VoteTTL    time.Duration
+}
+
+// DefaultPolicy requires two approvals for critical and high anomalies
+func DefaultPolicy() Policy {
+	return Policy{
+		Severities: []models.AnomalySeverity{models.SeverityCritical, models.SeverityHigh},
+		Required:   2,
+		VoteTTL:    time.Hour,
+	}
+}
+
+// Notes digests an anomaly's investigation notes; the notes store
+// implements it
+type Notes interface {
+	Digest(anomalyID string) (digest string, comments, attachments int)
+}
+
+// Voter casts an automated vote when a ballot opens. ok is false when the
+// voter abstains.
+type Voter interface {
+	Name() string
+	Vote(ctx context.Context, a *models.Anomaly, resolution string) (approve, ok bool)
+}
+
+// Board runs approval ballots on anomalies held by the detector
+type Board struct {
+	detector   *anomaly.Detector
+	ledger     *blockchain.ManusClient
+	policy     Policy
+	severities map[models.AnomalySeverity]bool
+	eligible   map[string]bool
+	voters     []Voter
+	notes      Notes
+	now        func() time.Time
+	mu         sync.Mutex
+}
+
+// NewBoard creates a board enforcing policy. voters vote automatically on
+// every ballot they are eligible for. A fixed voter list must be able to
+// reach the required number of approvals.
+func NewBoard(detector *anomaly.Detector, ledger *blockchain.ManusClient, policy Policy, voters ...Voter) (*Board, error) {
+	if policy.Required <= 0 {
+		policy.Required = 1
+	}
+	if len(policy.Voters) > 0 && policy.Required > len(policy.Voters) {
+		return nil, fmt.Errorf("%d approvals required but only %d voters are eligible", policy.Required, len(policy.Voters))
+	}
+
+	b := &Board{
+		detector:   detector,
+		ledger:     ledger,
+		policy:     policy,
+		severities: make(map[models.AnomalySeverity]bool),
+		eligible:   make(map[string]bool),
+		voters:     voters,
+		now:        time.Now,
+	}
+	for _, s := range policy.Severities {
+		b.severities[s] = true
+	}
+	for _, v := range policy.Voters {
+		b.eligible[v] = true
+	}
+	return b, nil
+}
+
+// SetNotes lets ballots anchor a digest of the anomaly's notes with their
+// tally
+func (b *Board) SetNotes(n Notes) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.notes = n
+}
+
+// Requires reports whether resolving a needs approval
+func (b *Board) Requires(a *models.Anomaly) bool {
+	return b.severities[a.Severity]
+}
+
+// Open puts resolution up for approval. The requester's approval and any
+// automated votes are counted straight away, so the ballot may close
+// immediately. Requesting the resolution already pending counts as a vote.
+func (b *Board) Open(ctx context.Context, id, resolution, requestedBy string) (*models.Approval, error) {
+	return b.open(ctx, id, resolution, requestedBy, false)
+}
+
+// OpenAnchoringNotes is Open, additionally recording a digest of the
+// anomaly's notes in the ledger entry written when the ballot closes
+func (b *Board) OpenAnchoringNotes(ctx context.Context, id, resolution, requestedBy string) (*models.Approval, error) {
+	return b.open(ctx, id, resolution, requestedBy, true)
+}
+
+func (b *Board) open(ctx context.Context, id, resolution, requestedBy string, anchorNotes bool) (*models.Approval, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if anchorNotes && b.notes == nil {
+		return nil, fmt.Errorf("notes are not enabled")
+	}
+
+	a, err := b.detector.GetAnomaly(id)
+	if err != nil {
+		return nil, err
+	}
+	if a.Status == models.StatusResolved {
+		return nil, fmt.Errorf("%w: %s", ErrAlreadyResolved, id)
+	}
+
+	if a.Approval != nil && a.Approval.Status == models.ApprovalPending {
+		if a.Approval.Resolution != resolution {
+			return nil, fmt.Errorf("%w: %q", ErrBallotPending, a.Approval.Resolution)
+		}
+		approval := copyApproval(a.Approval)
+		approval.AnchorNotes = approval.AnchorNotes || anchorNotes
+		if requestedBy != "" && b.canVote(requestedBy) {
+			cast(approval, models.Vote{Voter: requestedBy, Kind: models.VoterHuman, Approve: true, CastAt: b.now()})
+		}
+		return b.apply(a, approval, requestedBy)
+	}
+
+	approval := &models.Approval{
+		Resolution:  resolution,
+		RequestedBy: requestedBy,
+		Required:    b.policy.Required,
+		Eligible:    b.policy.Voters,
+		Status:      models.ApprovalPending,
+		OpenedAt:    b.now(),
+		AnchorNotes: anchorNotes,
+	}
+	if requestedBy != "" && b.canVote(requestedBy) {
+		cast(approval, models.Vote{Voter: requestedBy, Kind: models.VoterHuman, Approve: true, CastAt: b.now()})
+	}
+	for _, v := range b.voters {
+		if !b.canVote(v.Name()) {
+			continue
+		}
+		if approve, ok := v.Vote(ctx, a, resolution); ok {
+			cast(approval, models.Vote{Voter: v.Name(), Kind: models.VoterAgent, Approve: approve, CastAt: b.now()})
+		}
+	}
+	return b.apply(a, approval, requestedBy)
+}
+
+// Vote records a human vote on the pending approval for an anomaly. A
+// voter voting again replaces their earlier vote.
+func (b *Board) Vote(ctx context.Context, id, voter string, approve bool, comment string) (*models.Approval, error) {
+	if !b.canVote(voter) {
+		return nil, fmt.Errorf("%w: %s", ErrNotEligible, voter)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	a, err := b.detector.GetAnomaly(id)
+	if err != nil {
+		return nil, err
+	}
+	if a.Approval == nil || a.Approval.Status != models.ApprovalPending {
+		return nil, fmt.Errorf("%w for anomaly %s", ErrNoBallot, id)
+	}
+	if a.Status == models.StatusResolved {
+		return nil, fmt.Errorf("%w: %s", ErrAlreadyResolved, id)
+	}
+
+	approval := copyApproval(a.Approval)
+	cast(approval, models.Vote{Voter: voter, Kind: models.VoterHuman, Approve: approve, Comment: comment, CastAt: b.now()})
+	return b.apply(a, approval, voter)
+}
+
+// Expire drops stale votes from every pending approval and returns how
+// many were dropped
+func (b *Board) Expire() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var dropped int
+	for _, a := range b.Pending() {
+		approval := copyApproval(a.Approval)
+		n := b.prune(approval)
+		if n == 0 {
+			continue
+		}
+		dropped += n
+		b.detector.Update(a.ID, func(stored *models.Anomaly) {
+			stored.Approval = approval
+		})
+	}
+	return dropped
+}
+
+// Pending returns anomalies with an open approval
+func (b *Board) Pending() []*models.Anomaly {
+	var pending []*models.Anomaly
+	for _, a := range b.detector.GetAllAnomalies() {
+		if a.Approval != nil && a.Approval.Status == models.ApprovalPending {
+			pending = append(pending, a)
+		}
+	}
+	return pending
+}
+
+func (b *Board) canVote(voter string) bool {
+	return len(b.eligible) == 0 || b.eligible[voter]
+}
+
+// apply tallies approval, stores it and, once it closes, writes the tally
+// to the ledger as actor, whose request or vote closed the ballot, and
+// applies an approved resolution. Callers hold b.mu.
+func (b *Board) apply(a *models.Anomaly, approval *models.Approval, actor string) (*models.Approval, error) {
+	b.prune(approval)
+	approvals, rejections := count(approval)
+
+	switch {
+	case approvals >= approval.Required:
+		approval.Status = models.ApprovalApproved
+	case rejections > b.maxRejections():
+		approval.Status = models.ApprovalRejected
+	}
+
+	if approval.Status != models.ApprovalPending {
+		now := b.now()
+		approval.ClosedAt = &now
+		payload := map[string]interface{}{
+			"decision":   string(approval.Status),
+			"resolution": approval.Resolution,
+			"required":   approval.Required,
+			"approvals":  approvals,
+			"rejections": rejections,
+			"votes":      approval.Votes,
+		}
+		if approval.AnchorNotes && b.notes != nil {
+			digest, comments, attachments := b.notes.Digest(a.ID)
+			payload["notes_digest"] = digest
+			payload["comments"] = comments
+			payload["attachments"] = attachments
+			approval.NotesDigest = digest
+		}
+		txHash, err := b.ledger.LogEntry(blockchain.LedgerEntry{
+			AnomalyID:   a.ID,
+			Description: fmt.Sprintf("Consensus %s: %s", approval.Status, approval.Resolution),
+			Actor:       actor,
+			Payload:     payload,
+		})
+		if err != nil {
+			return nil, err
+		}
+		approval.TxHash = txHash
+	}
+
+	if err := b.detector.Update(a.ID, func(stored *models.Anomaly) {
+		stored.Approval = approval
+	}); err != nil {
+		return nil, err
+	}
+	if approval.Status == models.ApprovalApproved {
+		if err := b.detector.ResolveAnomaly(a.ID, approval.Resolution, approval.RequestedBy); err != nil {
+			return nil, err
+		}
+	}
+	return copyApproval(approval), nil
+}
+
+// maxRejections is how many rejections a ballot survives. With a fixed
+// voter list it fails once approval is out of reach; with open voting it
+// fails when rejections reach the required approvals.
+func (b *Board) maxRejections() int {
+	if len(b.eligible) > 0 {
+		return len(b.eligible) - b.policy.Required
+	}
+	return b.policy.Required - 1
+}
+
+// prune drops votes older than the policy TTL and returns how many
+func (b *Board) prune(approval *models.Approval) int {
+	if b.policy.VoteTTL <= 0 {
+		return 0
+	}
+
+	cutoff := b.now().Add(-b.policy.VoteTTL)
+	votes := approval.Votes[:0]
+	for _, v := range approval.Votes {
+		if v.CastAt.After(cutoff) {
+			votes = append(votes, v)
+		}
+	}
+	dropped := len(approval.Votes) - len(votes)
+	approval.Votes = votes
+	approval.Expired += dropped
+	return dropped
+}
+
+func cast(approval *models.Approval, vote models.Vote) {
+	for i, v := range approval.Votes {
+		if v.Voter == vote.Voter {
+			approval.Votes[i] = vote
+			return
+		}
+	}
+	approval.Votes = append(approval.Votes, vote)
+}
+
+func count(approval *models.Approval) (approvals, rejections int) {
+	for _, v := range approval.Votes {
+		if v.Approve {
+			approvals++
+		} else {
+			rejections++
+		}
+	}
+	return approvals, rejections
+}
+
+func copyApproval(a *models.Approval) *models.Approval {
+	c := *a
+	c.Votes = append([]models.Vote(nil), a.Votes...)
+	c.Eligible = append([]string(nil), a.Eligible...)
+	return &c
+}
@@ -0,0 +1,223 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blockchain"
+)
+
+const resolution = "Resynchronize divergent nodes from the canonical Earth peer"
+
+type stubVoter struct {
+	name    string
+	approve bool
+}
+
+func (s stubVoter) Name() string { return s.name }
+
+func (s stubVoter) Vote(ctx context.Context, a *models.Anomaly, resolution string) (bool, bool) {
+	return s.approve, true
+}
+
+func newTestBoard(t *testing.T, policy Policy, voters ...Voter) (*Board, *anomaly.Detector, *blockchain.ManusClient, string) {
+	t.Helper()
+	detector := anomaly.NewDetector()
+	ledger := blockchain.NewManusClient("http://localhost:9545", "1", false)
+
+	var id string
+	for _, a := range detector.DetectAnomalies() {
+		if a.Severity == models.SeverityCritical {
+			id = a.ID
+		}
+	}
+	board, err := NewBoard(detector, ledger, policy, voters...)
+	if err != nil {
+		t.Fatalf("NewBoard failed: %v", err)
+	}
+	return board, detector, ledger, id
+}
+
+func TestNewBoardRejectsUnreachableQuorum(t *testing.T) {
+	detector := anomaly.NewDetector()
+	ledger := blockchain.NewManusClient("http://localhost:9545", "1", false)
+	if _, err := NewBoard(detector, ledger, Policy{Required: 3, Voters: []string{"alice", "bob"}}); err == nil {
+		t.Error("Expected 3 required approvals from 2 voters to be rejected")
+	}
+}
+
+func TestApprovalResolvesOnceQuorumReached(t *testing.T) {
+	board, detector, ledger, id := newTestBoard(t, DefaultPolicy())
+	ctx := context.Background()
+
+	approval, err := board.Open(ctx, id, resolution, "alice")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if approval.Status != models.ApprovalPending || len(approval.Votes) != 1 {
+		t.Fatalf("Expected pending approval with the requester's vote, got %+v", approval)
+	}
+	if a, _ := detector.GetAnomaly(id); a.Status == models.StatusResolved {
+		t.Fatal("Expected anomaly to stay unresolved until quorum")
+	}
+
+	approval, err = board.Vote(ctx, id, "bob", true, "checked peer hashes")
+	if err != nil {
+		t.Fatalf("Vote failed: %v", err)
+	}
+	if approval.Status != models.ApprovalApproved || approval.TxHash == "" {
+		t.Fatalf("Expected approved ballot with a ledger tally, got %+v", approval)
+	}
+
+	a, _ := detector.GetAnomaly(id)
+	if a.Status != models.StatusResolved || a.Resolution != resolution {
+		t.Errorf("Expected anomaly resolved with %q, got %s %q", resolution, a.Status, a.Resolution)
+	}
+
+	entries := ledger.Entries()
+	if len(entries) != 1 || entries[0].Payload["approvals"] != 2 || entries[0].TxHash != approval.TxHash || entries[0].Actor != "bob" {
+		t.Errorf("Expected tally on the ledger, got %+v", entries)
+	}
+
+	if _, err := board.Vote(ctx, id, "carol", true, ""); !errors.Is(err, ErrNoBallot) {
+		t.Errorf("Expected ErrNoBallot after closing, got %v", err)
+	}
+}
+
+type stubNotes struct{}
+
+func (stubNotes) Digest(anomalyID string) (string, int, int) {
+	return "sha256:" + anomalyID, 2, 1
+}
+
+func TestApprovalAnchorsNotesWhenClosing(t *testing.T) {
+	board, _, ledger, id := newTestBoard(t, DefaultPolicy())
+	ctx := context.Background()
+
+	if _, err := board.OpenAnchoringNotes(ctx, id, resolution, "alice"); err == nil {
+		t.Error("Expected anchoring to fail without notes")
+	}
+	board.SetNotes(stubNotes{})
+
+	approval, err := board.OpenAnchoringNotes(ctx, id, resolution, "alice")
+	if err != nil {
+		t.Fatalf("OpenAnchoringNotes failed: %v", err)
+	}
+	if !approval.AnchorNotes || approval.NotesDigest != "" {
+		t.Fatalf("Expected the anchor request to wait for the ballot to close, got %+v", approval)
+	}
+
+	approval, err = board.Vote(ctx, id, "bob", true, "")
+	if err != nil {
+		t.Fatalf("Vote failed: %v", err)
+	}
+	entries := ledger.Entries()
+	if approval.NotesDigest != "sha256:"+id || len(entries) != 1 || entries[0].Payload["notes_digest"] != approval.NotesDigest || entries[0].Payload["comments"] != 2 {
+		t.Errorf("Expected the tally to carry the notes digest, got %+v and %+v", approval, entries)
+	}
+}
+
+func TestFixedVotersRejectWhenQuorumUnreachable(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.Voters = []string{"alice", "bob", "carol"}
+	board, detector, _, id := newTestBoard(t, policy)
+	ctx := context.Background()
+
+	if _, err := board.Open(ctx, id, resolution, "mallory"); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err := board.Vote(ctx, id, "mallory", true, ""); !errors.Is(err, ErrNotEligible) {
+		t.Errorf("Expected ErrNotEligible, got %v", err)
+	}
+
+	board.Vote(ctx, id, "alice", false, "")
+	approval, err := board.Vote(ctx, id, "bob", false, "")
+	if err != nil {
+		t.Fatalf("Vote failed: %v", err)
+	}
+	if approval.Status != models.ApprovalRejected {
+		t.Fatalf("Expected rejection once 2 of 3 cannot be reached, got %s", approval.Status)
+	}
+	if a, _ := detector.GetAnomaly(id); a.Status == models.StatusResolved {
+		t.Error("Expected rejected resolution not to be applied")
+	}
+}
+
+func TestStaleVotesExpire(t *testing.T) {
+	board, detector, _, id := newTestBoard(t, DefaultPolicy())
+	now := time.Now()
+	board.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	board.Open(ctx, id, resolution, "alice")
+	now = now.Add(2 * time.Hour)
+
+	if n := board.Expire(); n != 1 {
+		t.Fatalf("Expected 1 stale vote dropped, got %d", n)
+	}
+
+	approval, _ := board.Vote(ctx, id, "bob", true, "")
+	if approval.Status != models.ApprovalPending || approval.Expired != 1 {
+		t.Errorf("Expected ballot still pending after expiry, got %+v", approval)
+	}
+	if a, _ := detector.GetAnomaly(id); a.Status == models.StatusResolved {
+		t.Error("Expected expired vote not to count towards quorum")
+	}
+}
+
+func TestAgentVotesCountAtOpen(t *testing.T) {
+	board, detector, _, id := newTestBoard(t, DefaultPolicy(), stubVoter{name: "Manus Blockchain Monitor", approve: true})
+
+	approval, err := board.Open(context.Background(), id, resolution, "alice")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if approval.Status != models.ApprovalApproved {
+		t.Fatalf("Expected requester and agent to reach quorum, got %+v", approval)
+	}
+	if approval.Votes[1].Kind != models.VoterAgent {
+		t.Errorf("Expected agent vote, got %+v", approval.Votes[1])
+	}
+	if a, _ := detector.GetAnomaly(id); a.Status != models.StatusResolved {
+		t.Error("Expected anomaly resolved")
+	}
+}
+
+func TestConflictingRequestWhilePending(t *testing.T) {
+	board, _, _, id := newTestBoard(t, DefaultPolicy())
+	ctx := context.Background()
+
+	board.Open(ctx, id, resolution, "alice")
+	if _, err := board.Open(ctx, id, "Re-anchor the latest checkpoint", "bob"); !errors.Is(err, ErrBallotPending) {
+		t.Errorf("Expected ErrBallotPending, got %v", err)
+	}
+
+	approval, err := board.Open(ctx, id, resolution, "bob")
+	if err != nil || approval.Status != models.ApprovalApproved {
+		t.Errorf("Expected repeated request to count as a vote, got %+v, %v", approval, err)
+	}
+}
+
+func TestLateVoteOnResolvedAnomalyIsRejected(t *testing.T) {
+	board, detector, ledger, id := newTestBoard(t, DefaultPolicy())
+	ctx := context.Background()
+
+	board.Open(ctx, id, resolution, "alice")
+	if err := detector.ResolveAnomaly(id, "Resolved outside the ballot", "carol"); err != nil {
+		t.Fatalf("ResolveAnomaly failed: %v", err)
+	}
+
+	if _, err := board.Vote(ctx, id, "bob", true, ""); !errors.Is(err, ErrAlreadyResolved) {
+		t.Errorf("Expected ErrAlreadyResolved, got %v", err)
+	}
+	if entries := ledger.Entries(); len(entries) != 0 {
+		t.Errorf("Expected no tally for a late ballot, got %+v", entries)
+	}
+	if a, _ := detector.GetAnomaly(id); a.Resolution != "Resolved outside the ballot" {
+		t.Errorf("Expected the first resolution to stand, got %q", a.Resolution)
+	}
+}
@@ -0,0 +1,81 @@
+// Package coopetition implements the Coopetition framework: agents
+// collaborate by detecting anomalies for a shared detector and compete by
+// proposing resolutions, earning or losing standing by outcome.
+package coopetition
+
+import (
+	"context"
+	"strings"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// Agent names, matching the anomaly Source strings they report under
+const (
+	AgentManus    = "Manus Blockchain Monitor"
+	AgentCopilot  = "GitHub Copilot Integration"
+	AgentEmissary = "xAI Emissary"
+)
+
+// Proposal is an agent's suggested resolution for an anomaly
+type Proposal struct {
+	Agent      string  `json:"agent"`
+	Resolution string  `json:"resolution"`
+	Confidence float64 `json:"confidence"`
+	Rationale  string  `json:"rationale,omitempty"`
+}
+
+// Agent both detects anomalies and competes to resolve them. Propose
+// returns nil when the agent has nothing to offer for a.
+type Agent interface {
+	Name() string
+	Detect(ctx context.Context) ([]*models.Anomaly, error)
+	Propose(ctx context.Context, a *models.Anomaly) (*Proposal, error)
+}
+
+// sameResolution compares resolutions the way the loop merges candidates
+func sameResolution(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+// metadataFloat reads a numeric metadata value, which may be an int when
+// set in process or a float64 when decoded from JSON
+func metadataFloat(a *models.Anomaly, key string) (float64, bool) {
+	switch v := a.Metadata[key].(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// AgentVoter lets an agent vote on resolutions up for consensus: it
+// approves a resolution it would propose itself, rejects one that
+// conflicts with a confident proposal of its own, and abstains otherwise
+type AgentVoter struct {
+	Agent         Agent
+	MinConfidence float64
+}
+
+// Name identifies the voting agent
+func (v AgentVoter) Name() string {
+	return v.Agent.Name()
+}
+
+// Vote implements consensus.Voter
+func (v AgentVoter) Vote(ctx context.Context, a *models.Anomaly, resolution string) (approve, ok bool) {
+	p, err := v.Agent.Propose(ctx, a)
+	if err != nil || p == nil {
+		return false, false
+	}
+	if sameResolution(p.Resolution, resolution) {
+		return true, true
+	}
+	if p.Confidence >= v.MinConfidence {
+		return false, true
+	}
+	return false, false
+}
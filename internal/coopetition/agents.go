@@ -0,0 +1,337 @@
+package coopetition
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/loop"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blockchain"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/timeseries"
+)
+
+// LatencyMetric is the ingested metric the Emissary reads route latency
+// from, using the sample's route label
+const LatencyMetric = "node_latency_ms"
+
+// ManusAgent watches the Manus ledger and specialises in ledger faults
+type ManusAgent struct {
+	ledger      *blockchain.ManusClient
+	maxBlockAge time.Duration
+	raised      map[string]bool
+	mu          sync.Mutex
+}
+
+// NewManusAgent creates an agent that reports a stalled chain once the
+// last block is older than maxBlockAge
+func NewManusAgent(ledger *blockchain.ManusClient, maxBlockAge time.Duration) *ManusAgent {
+	return &ManusAgent{
+		ledger:      ledger,
+		maxBlockAge: maxBlockAge,
+		raised:      make(map[string]bool),
+	}
+}
+
+// Name identifies the agent
+func (m *ManusAgent) Name() string {
+	return AgentManus
+}
+
+// Detect checks ledger sync state and block age. A condition is reported
+// once and again only after it has cleared.
+func (m *ManusAgent) Detect(ctx context.Context) ([]*models.Anomaly, error) {
+	status, err := m.ledger.GetStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var anomalies []*models.Anomaly
+	if status.SyncStatus != "synchronized" {
+		if !m.raised["sync"] {
+			anomalies = append(anomalies, &models.Anomaly{
+				Type:        models.AnomalyTypeLedgerDivergence,
+				Description: fmt.Sprintf("Ledger reports sync status %q across planetary nodes", status.SyncStatus),
+				Severity:    models.SeverityCritical,
+				Metadata: map[string]interface{}{
+					"sync_status":   status.SyncStatus,
+					"current_block": status.CurrentBlock,
+					"nodes":         status.PlanetaryNodes,
+				},
+			})
+		}
+		m.raised["sync"] = true
+	} else {
+		m.raised["sync"] = false
+	}
+
+	if age := time.Since(status.LastBlockTime); m.maxBlockAge > 0 && age > m.maxBlockAge {
+		if !m.raised["stall"] {
+			anomalies = append(anomalies, &models.Anomaly{
+				Type:        models.AnomalyTypeNodeDesynchronization,
+				Description: fmt.Sprintf("No new block for %s on network %s", age.Round(time.Second), status.NetworkID),
+				Severity:    models.SeverityMedium,
+				Metadata: map[string]interface{}{
+					"current_block":   status.CurrentBlock,
+					"last_block_time": status.LastBlockTime,
+				},
+			})
+		}
+		m.raised["stall"] = true
+	} else {
+		m.raised["stall"] = false
+	}
+
+	return anomalies, nil
+}
+
+// Propose offers ledger-level remedies
+func (m *ManusAgent) Propose(ctx context.Context, a *models.Anomaly) (*Proposal, error) {
+	switch a.Type {
+	case models.AnomalyTypeLedgerDivergence:
+		if mismatch, _ := a.Metadata["hash_mismatch"].(bool); mismatch {
+			return &Proposal{
+				Resolution: "Resynchronize divergent nodes from the canonical Earth peer",
+				Confidence: 0.8,
+				Rationale:  "hash mismatch reported; Earth peer holds the canonical chain",
+			}, nil
+		}
+		return &Proposal{
+			Resolution: "Re-anchor the latest checkpoint on all planetary nodes",
+			Confidence: 0.6,
+			Rationale:  "divergence without a hash mismatch points at a stale checkpoint",
+		}, nil
+	case models.AnomalyTypeNodeDesynchronization:
+		return &Proposal{
+			Resolution: "Resynchronize node clock and replay missed blocks",
+			Confidence: 0.55,
+			Rationale:  "replaying missed blocks restores ledger height",
+		}, nil
+	case models.AnomalyTypeDAOVoteFailure:
+		return &Proposal{
+			Resolution: "Re-broadcast the DAO vote from the nearest healthy relay",
+			Confidence: 0.55,
+			Rationale:  "vote transactions are still pending in the ledger mempool",
+		}, nil
+	}
+	return nil, nil
+}
+
+// CopilotAgent verifies commits against the ledger and draws on the
+// resolutions of similar past anomalies
+type CopilotAgent struct {
+	ledger  *blockchain.ManusClient
+	history loop.HistorySource
+	pending []string
+	mu      sync.Mutex
+}
+
+// NewCopilotAgent creates a commit-verifying agent
+func NewCopilotAgent(ledger *blockchain.ManusClient, history loop.HistorySource) *CopilotAgent {
+	return &CopilotAgent{ledger: ledger, history: history}
+}
+
+// Name identifies the agent
+func (c *CopilotAgent) Name() string {
+	return AgentCopilot
+}
+
+// Watch queues commit hashes for verification on the next Detect
+func (c *CopilotAgent) Watch(hashes ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending = append(c.pending, hashes...)
+}
+
+// WatchLedger queues the commit anchored by a ledger entry; it is meant to
+// be passed to ManusClient.Subscribe
+func (c *CopilotAgent) WatchLedger(e blockchain.LedgerEntry) {
+	if hash, _ := e.Payload["commit_hash"].(string); hash != "" {
+		c.Watch(hash)
+	}
+}
+
+// Detect reports watched commits that fail ledger verification
+func (c *CopilotAgent) Detect(ctx context.Context) ([]*models.Anomaly, error) {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	var anomalies []*models.Anomaly
+	for _, hash := range pending {
+		verified, err := c.ledger.VerifyCommit(hash)
+		if err == nil && verified {
+			continue
+		}
+		anomalies = append(anomalies, &models.Anomaly{
+			Type:        models.AnomalyTypeCommitAnomaly,
+			Description: fmt.Sprintf("Commit %s is not recorded on the Manus ledger", hash),
+			Severity:    models.SeverityMedium,
+			Metadata: map[string]interface{}{
+				"commit_hash": hash,
+			},
+		})
+	}
+	return anomalies, nil
+}
+
+// Propose verifies commit anomalies and otherwise reuses the closest past
+// resolution
+func (c *CopilotAgent) Propose(ctx context.Context, a *models.Anomaly) (*Proposal, error) {
+	if a.Type == models.AnomalyTypeCommitAnomaly {
+		hash, _ := a.Metadata["commit_hash"].(string)
+		if verified, err := c.ledger.VerifyCommit(hash); err == nil && verified {
+			return &Proposal{
+				Resolution: "Commit verified and logged immutably on blockchain",
+				Confidence: 0.85,
+				Rationale:  fmt.Sprintf("commit %s verified against the ledger", hash),
+			}, nil
+		}
+		return &Proposal{
+			Resolution: "Revert the unverified commit and require a signed re-submission",
+			Confidence: 0.7,
+			Rationale:  fmt.Sprintf("commit %s could not be verified", hash),
+		}, nil
+	}
+
+	candidates, err := c.history.Candidates(ctx, a)
+	if err != nil || len(candidates) == 0 {
+		return nil, err
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	best := candidates[0]
+	return &Proposal{
+		Resolution: best.Resolution,
+		Confidence: 0.9 * best.Score,
+		Rationale:  best.Evidence[0],
+	}, nil
+}
+
+// EmissaryAgent observes interplanetary route latency
+type EmissaryAgent struct {
+	thresholdMs float64
+	latency     map[string]float64
+	raised      map[string]bool
+	mu          sync.Mutex
+}
+
+// NewEmissaryAgent creates an agent that reports routes slower than
+// thresholdMs
+func NewEmissaryAgent(thresholdMs float64) *EmissaryAgent {
+	return &EmissaryAgent{
+		thresholdMs: thresholdMs,
+		latency:     make(map[string]float64),
+		raised:      make(map[string]bool),
+	}
+}
+
+// Name identifies the agent
+func (e *EmissaryAgent) Name() string {
+	return AgentEmissary
+}
+
+// ObserveLatency records the latest latency measured on a route
+func (e *EmissaryAgent) ObserveLatency(route string, ms float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.latency[route] = ms
+}
+
+// ObserveSamples records route latency from ingested samples
+func (e *EmissaryAgent) ObserveSamples(samples ...timeseries.Sample) {
+	for _, s := range samples {
+		route := s.Labels["route"]
+		if s.Metric != LatencyMetric || route == "" || math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+			continue
+		}
+		e.ObserveLatency(route, s.Value)
+	}
+}
+
+// Detect reports routes over the latency threshold. A route is reported
+// once and again only after it has recovered.
+func (e *EmissaryAgent) Detect(ctx context.Context) ([]*models.Anomaly, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	routes := make([]string, 0, len(e.latency))
+	for route := range e.latency {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	var anomalies []*models.Anomaly
+	for _, route := range routes {
+		ms := e.latency[route]
+		if ms <= e.thresholdMs {
+			e.raised[route] = false
+			continue
+		}
+		if e.raised[route] {
+			continue
+		}
+		e.raised[route] = true
+
+		severity := models.SeverityLow
+		if ms > 2*e.thresholdMs {
+			severity = models.SeverityMedium
+		}
+		anomalies = append(anomalies, &models.Anomaly{
+			Type:        models.AnomalyTypeNodeDesynchronization,
+			Description: fmt.Sprintf("Interplanetary node synchronization delay detected on %s", route),
+			Severity:    severity,
+			Metadata: map[string]interface{}{
+				"latency_ms":     ms,
+				"threshold_ms":   e.thresholdMs,
+				"affected_route": route,
+			},
+		})
+	}
+	return anomalies, nil
+}
+
+// Propose judges whether observed latency is tolerable or needs a reroute
+func (e *EmissaryAgent) Propose(ctx context.Context, a *models.Anomaly) (*Proposal, error) {
+	switch a.Type {
+	case models.AnomalyTypeNodeDesynchronization:
+		latency, ok := metadataFloat(a, "latency_ms")
+		if !ok {
+			return nil, nil
+		}
+		threshold, ok := metadataFloat(a, "threshold_ms")
+		if !ok {
+			threshold = e.thresholdMs
+		}
+		if latency <= 2*threshold {
+			return &Proposal{
+				Resolution: "Latency within interplanetary tolerance; monitor route",
+				Confidence: 0.7,
+				Rationale:  fmt.Sprintf("%.0fms is within twice the %.0fms threshold", latency, threshold),
+			}, nil
+		}
+		return &Proposal{
+			Resolution: "Reroute traffic through the Moon relay",
+			Confidence: 0.65,
+			Rationale:  fmt.Sprintf("%.0fms exceeds twice the %.0fms threshold", latency, threshold),
+		}, nil
+	case models.AnomalyTypeDAOVoteFailure:
+		return &Proposal{
+			Resolution: "Re-broadcast the DAO vote from the nearest healthy relay",
+			Confidence: 0.5,
+			Rationale:  "relay routes are reachable from the Emissary",
+		}, nil
+	}
+	return nil, nil
+}
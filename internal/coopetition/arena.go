@@ -0,0 +1,270 @@
+package coopetition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/loop"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/timeseries"
+)
+
+// Points awarded when a proposal is settled
+const (
+	CreditAccepted  = 1.0
+	PenaltyLost     = -0.25
+	PenaltyRejected = -1.0
+)
+
+// Standing is an agent's position on the leaderboard. Precision is the
+// share of its settled proposals that were applied; recall is the share of
+// all resolved anomalies whose applied resolution it proposed.
+type Standing struct {
+	Agent                string        `json:"agent"`
+	Points               float64       `json:"points"`
+	Detected             int           `json:"detected"`
+	Confirmed            int           `json:"confirmed"`
+	Proposals            int           `json:"proposals"`
+	Accepted             int           `json:"accepted"`
+	Rejected             int           `json:"rejected"`
+	Lost                 int           `json:"lost"`
+	Precision            float64       `json:"precision"`
+	Recall               float64       `json:"recall"`
+	MeanTimeToResolution time.Duration `json:"mean_time_to_resolution_ns"`
+}
+
+type record struct {
+	detected, confirmed int
+	proposals           int
+	accepted, rejected  int
+	lost                int
+	points              float64
+	timeToResolution    time.Duration
+}
+
+// reputation is the smoothed share of settled proposals that were applied
+func (r *record) reputation() float64 {
+	return float64(r.accepted+1) / float64(r.accepted+r.rejected+r.lost+2)
+}
+
+// Arena runs agents against each other. It is a loop.CandidateSource, so
+// the loop picks and applies the winning proposal, and it settles
+// proposals by watching detector events.
+type Arena struct {
+	detector  *anomaly.Detector
+	agents    []Agent
+	records   map[string]*record
+	proposals map[string][]Proposal
+	resolved  int
+	mu        sync.Mutex
+}
+
+// NewArena creates an arena for agents
+func NewArena(detector *anomaly.Detector, agents ...Agent) *Arena {
+	a := &Arena{
+		detector:  detector,
+		agents:    agents,
+		records:   make(map[string]*record),
+		proposals: make(map[string][]Proposal),
+	}
+	for _, agent := range agents {
+		a.records[agent.Name()] = &record{}
+	}
+	return a
+}
+
+// Agents returns the competing agents
+func (a *Arena) Agents() []Agent {
+	return a.agents
+}
+
+// Detect runs every agent's detection and records what they find under
+// the agent's name
+func (a *Arena) Detect(ctx context.Context) ([]*models.Anomaly, error) {
+	var detected []*models.Anomaly
+	var errs []error
+	for _, agent := range a.agents {
+		found, err := a.DetectAgent(ctx, agent)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", agent.Name(), err))
+		}
+		detected = append(detected, found...)
+	}
+	return detected, errors.Join(errs...)
+}
+
+// DetectAgent runs one agent's detection and records what it finds
+func (a *Arena) DetectAgent(ctx context.Context, agent Agent) ([]*models.Anomaly, error) {
+	found, err := agent.Detect(ctx)
+	if len(found) == 0 {
+		return nil, err
+	}
+	for _, f := range found {
+		f.Source = agent.Name()
+	}
+
+	a.mu.Lock()
+	if r, ok := a.records[agent.Name()]; ok {
+		r.detected += len(found)
+	}
+	a.mu.Unlock()
+
+	return a.detector.Record(found...), err
+}
+
+// DetectJob returns a scheduler run function for one agent's detection
+func (a *Arena) DetectJob(agent Agent) func(ctx context.Context) (int, error) {
+	return func(ctx context.Context) (int, error) {
+		found, err := a.DetectAgent(ctx, agent)
+		return len(found), err
+	}
+}
+
+// SampleObserver is implemented by agents that learn from ingested metrics
+type SampleObserver interface {
+	ObserveSamples(samples ...timeseries.Sample)
+}
+
+// ObserveSamples passes ingested samples to every agent that observes them
+func (a *Arena) ObserveSamples(samples ...timeseries.Sample) {
+	for _, agent := range a.agents {
+		if o, ok := agent.(SampleObserver); ok {
+			o.ObserveSamples(samples...)
+		}
+	}
+}
+
+// Name identifies the arena as a candidate source
+func (a *Arena) Name() string {
+	return "coopetition"
+}
+
+// Candidates collects one proposal per agent, weighting each agent's
+// confidence by its track record
+func (a *Arena) Candidates(ctx context.Context, an *models.Anomaly) ([]loop.Candidate, error) {
+	var proposals []Proposal
+	for _, agent := range a.agents {
+		p, err := agent.Propose(ctx, an)
+		if err != nil || p == nil || p.Resolution == "" {
+			continue
+		}
+		p.Agent = agent.Name()
+		p.Confidence = clamp(p.Confidence)
+		proposals = append(proposals, *p)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	candidates := make([]loop.Candidate, 0, len(proposals))
+	for _, p := range proposals {
+		r := a.records[p.Agent]
+		r.proposals++
+		candidates = append(candidates, loop.Candidate{
+			Resolution: p.Resolution,
+			Score:      clamp(p.Confidence * (0.5 + r.reputation())),
+			Sources:    []string{p.Agent},
+			Evidence:   []string{fmt.Sprintf("%s (reputation %.2f): %s", p.Agent, r.reputation(), p.Rationale)},
+		})
+	}
+	a.proposals[an.ID] = proposals
+	return candidates, nil
+}
+
+// HandleEvent settles proposals as anomalies are resolved or proposals
+// rejected; it is meant to be passed to Detector.Subscribe
+func (a *Arena) HandleEvent(event anomaly.Event) {
+	an := event.Anomaly
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch event.Type {
+	case anomaly.EventResolved:
+		a.resolved++
+		if r, ok := a.records[an.Source]; ok {
+			r.confirmed++
+		}
+		for _, p := range a.proposals[an.ID] {
+			r := a.records[p.Agent]
+			if !sameResolution(p.Resolution, an.Resolution) {
+				r.lost++
+				r.points += PenaltyLost
+				continue
+			}
+			r.accepted++
+			r.points += CreditAccepted
+			if an.ResolvedAt != nil {
+				r.timeToResolution += an.ResolvedAt.Sub(an.DetectedAt)
+			}
+		}
+		delete(a.proposals, an.ID)
+
+	case anomaly.EventUpdated:
+		if an.Proposal == nil || an.Proposal.Status != models.ProposalRejected {
+			return
+		}
+		var remaining []Proposal
+		for _, p := range a.proposals[an.ID] {
+			if !sameResolution(p.Resolution, an.Proposal.Resolution) {
+				remaining = append(remaining, p)
+				continue
+			}
+			r := a.records[p.Agent]
+			r.rejected++
+			r.points += PenaltyRejected
+		}
+		a.proposals[an.ID] = remaining
+	}
+}
+
+// Leaderboard returns agent standings, highest points first
+func (a *Arena) Leaderboard() []Standing {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	standings := make([]Standing, 0, len(a.agents))
+	for _, agent := range a.agents {
+		r := a.records[agent.Name()]
+		s := Standing{
+			Agent:     agent.Name(),
+			Points:    r.points,
+			Detected:  r.detected,
+			Confirmed: r.confirmed,
+			Proposals: r.proposals,
+			Accepted:  r.accepted,
+			Rejected:  r.rejected,
+			Lost:      r.lost,
+		}
+		if settled := r.accepted + r.rejected + r.lost; settled > 0 {
+			s.Precision = float64(r.accepted) / float64(settled)
+		}
+		if a.resolved > 0 {
+			s.Recall = float64(r.accepted) / float64(a.resolved)
+		}
+		if r.accepted > 0 {
+			s.MeanTimeToResolution = r.timeToResolution / time.Duration(r.accepted)
+		}
+		standings = append(standings, s)
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].Points > standings[j].Points
+	})
+	return standings
+}
+
+func clamp(score float64) float64 {
+	switch {
+	case score < 0:
+		return 0
+	case score > 1:
+		return 1
+	}
+	return score
+}
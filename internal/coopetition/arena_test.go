@@ -0,0 +1,196 @@
+package coopetition
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/loop"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/scheduler"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blockchain"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/timeseries"
+)
+
+func newTestArena(t *testing.T) (*Arena, *loop.Loop, *anomaly.Detector, map[models.AnomalyType]string) {
+	t.Helper()
+	detector := anomaly.NewDetector()
+	ledger := blockchain.NewManusClient("http://localhost:9545", "1", false)
+
+	arena := NewArena(detector,
+		NewManusAgent(ledger, time.Minute),
+		NewCopilotAgent(ledger, loop.HistorySource{}),
+		NewEmissaryAgent(300),
+	)
+	detector.Subscribe(arena.HandleEvent)
+	l := loop.New(detector, ledger, loop.DefaultConfig(), arena)
+
+	ids := make(map[models.AnomalyType]string)
+	for _, a := range detector.DetectAnomalies() {
+		ids[a.Type] = a.ID
+	}
+	return arena, l, detector, ids
+}
+
+func standing(t *testing.T, arena *Arena, agent string) Standing {
+	t.Helper()
+	for _, s := range arena.Leaderboard() {
+		if s.Agent == agent {
+			return s
+		}
+	}
+	t.Fatalf("Agent %s missing from leaderboard", agent)
+	return Standing{}
+}
+
+func TestWinningProposalIsAppliedAndCredited(t *testing.T) {
+	arena, l, detector, ids := newTestArena(t)
+	id := ids[models.AnomalyTypeNodeDesynchronization]
+
+	trace, err := l.Process(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if trace.Decision != loop.DecisionAutoResolved {
+		t.Fatalf("Expected auto-resolution, got %s", trace.Decision)
+	}
+
+	a, _ := detector.GetAnomaly(id)
+	if a.Resolution != "Latency within interplanetary tolerance; monitor route" {
+		t.Errorf("Expected Emissary proposal to win, got %q", a.Resolution)
+	}
+
+	emissary := standing(t, arena, AgentEmissary)
+	if emissary.Accepted != 1 || emissary.Points != CreditAccepted || emissary.Precision != 1 || emissary.Recall != 1 {
+		t.Errorf("Unexpected winner standing: %+v", emissary)
+	}
+	if emissary.MeanTimeToResolution < 29*time.Minute {
+		t.Errorf("Expected time to resolution from detection, got %s", emissary.MeanTimeToResolution)
+	}
+
+	manus := standing(t, arena, AgentManus)
+	if manus.Lost != 1 || manus.Points != PenaltyLost {
+		t.Errorf("Expected losing proposal to be penalised, got %+v", manus)
+	}
+
+	if board := arena.Leaderboard(); board[0].Agent != AgentEmissary {
+		t.Errorf("Expected Emissary to lead, got %s", board[0].Agent)
+	}
+}
+
+func TestRejectedProposalPenalisesAgreeingAgents(t *testing.T) {
+	arena, l, _, ids := newTestArena(t)
+	id := ids[models.AnomalyTypeDAOVoteFailure]
+
+	trace, _ := l.Process(context.Background(), id)
+	if trace.Decision != loop.DecisionProposed {
+		t.Fatalf("Expected a proposal for a high severity anomaly, got %s", trace.Decision)
+	}
+	if len(trace.Candidates) != 1 || len(trace.Candidates[0].Sources) != 2 {
+		t.Fatalf("Expected Manus and Emissary to agree on one candidate, got %+v", trace.Candidates)
+	}
+
+	if _, err := l.Review(context.Background(), id, "alice", false); err != nil {
+		t.Fatalf("Review failed: %v", err)
+	}
+	for _, agent := range []string{AgentManus, AgentEmissary} {
+		s := standing(t, arena, agent)
+		if s.Rejected != 1 || s.Points != PenaltyRejected {
+			t.Errorf("Expected %s to be penalised, got %+v", agent, s)
+		}
+	}
+}
+
+func TestReputationWeightsProposals(t *testing.T) {
+	arena, _, _, _ := newTestArena(t)
+	arena.records[AgentManus].accepted = 8
+
+	a := &models.Anomaly{ID: "x", Type: models.AnomalyTypeLedgerDivergence}
+	candidates, _ := arena.Candidates(context.Background(), a)
+	if len(candidates) != 1 || candidates[0].Score <= 0.8 {
+		t.Errorf("Expected a proven agent's confidence to be boosted, got %+v", candidates)
+	}
+}
+
+func TestEmissaryDetectsRouteOnce(t *testing.T) {
+	detector := anomaly.NewDetector()
+	emissary := NewEmissaryAgent(300)
+	arena := NewArena(detector, emissary)
+
+	emissary.ObserveLatency("Earth-Mars", 900)
+	found, err := arena.Detect(context.Background())
+	if err != nil || len(found) != 1 {
+		t.Fatalf("Expected one anomaly, got %d (%v)", len(found), err)
+	}
+	if found[0].Source != AgentEmissary || found[0].Severity != models.SeverityMedium {
+		t.Errorf("Unexpected anomaly: %+v", found[0])
+	}
+
+	if found, _ := arena.Detect(context.Background()); len(found) != 0 {
+		t.Error("Expected an ongoing condition not to be reported again")
+	}
+
+	emissary.ObserveLatency("Earth-Mars", 100)
+	arena.Detect(context.Background())
+	emissary.ObserveLatency("Earth-Mars", 400)
+	if found, _ := arena.Detect(context.Background()); len(found) != 1 {
+		t.Error("Expected a recurring condition to be reported after recovery")
+	}
+
+	if s := standing(t, arena, AgentEmissary); s.Detected != 2 {
+		t.Errorf("Expected 2 detections, got %d", s.Detected)
+	}
+}
+
+func TestScheduledDetectionClaimsIngestedLatency(t *testing.T) {
+	detector := anomaly.NewDetector()
+	emissary := NewEmissaryAgent(300)
+	arena := NewArena(detector, emissary)
+
+	arena.ObserveSamples(
+		timeseries.Sample{Metric: LatencyMetric, Labels: map[string]string{"route": "Earth-Mars"}, Value: 450},
+		timeseries.Sample{Metric: "block_interval_s", Labels: map[string]string{"route": "Earth-Moon"}, Value: 900},
+	)
+
+	jobs := scheduler.New()
+	if err := jobs.Add(scheduler.Job{Name: "agent:emissary", Schedule: scheduler.Every(time.Hour), Run: arena.DetectJob(emissary)}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := jobs.Trigger("agent:emissary"); err != nil {
+		t.Fatalf("Trigger failed: %v", err)
+	}
+	jobs.Stop()
+
+	if runs, _ := jobs.History("agent:emissary"); len(runs) != 1 || runs[0].Findings != 1 {
+		t.Fatalf("Expected one run with one finding, got %+v", runs)
+	}
+	var claimed []*models.Anomaly
+	for _, a := range detector.GetAllAnomalies() {
+		if a.Source == AgentEmissary {
+			claimed = append(claimed, a)
+		}
+	}
+	if len(claimed) != 1 || claimed[0].Metadata["affected_route"] != "Earth-Mars" {
+		t.Errorf("Expected the Emissary to claim the Earth-Mars route, got %+v", claimed)
+	}
+	if s := standing(t, arena, AgentEmissary); s.Detected != 1 {
+		t.Errorf("Expected 1 detection, got %d", s.Detected)
+	}
+}
+
+func TestCopilotWatchesAnchoredCommits(t *testing.T) {
+	ledger := blockchain.NewManusClient("http://localhost:9545", "1", false)
+	copilot := NewCopilotAgent(ledger, loop.HistorySource{})
+	ledger.Subscribe(copilot.WatchLedger)
+
+	ledger.LogAnomaly("1234", "unrelated entry")
+	ledger.AnchorCommit("", "a3f5b2c1", "alice")
+
+	copilot.mu.Lock()
+	pending := copilot.pending
+	copilot.mu.Unlock()
+	if len(pending) != 1 || pending[0] != "a3f5b2c1" {
+		t.Errorf("Expected the anchored commit to be queued, got %v", pending)
+	}
+}
@@ -0,0 +1,368 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/export"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/mail"
+	"github.com/google/uuid"
+)
+
+// historyLimit is how many deliveries are kept per subscription
+const historyLimit = 50
+
+var (
+	// ErrUnknownSubscription is returned for a subscription name that was
+	// never added
+	ErrUnknownSubscription = errors.New("unknown subscription")
+	// ErrEmailDisabled is returned when adding an email subscription
+	// without an SMTP relay configured
+	ErrEmailDisabled = errors.New("email delivery is not configured")
+)
+
+// Delivery records one attempt to send a report to one destination
+type Delivery struct {
+	ID           string        `json:"id"`
+	Subscription string        `json:"subscription"`
+	Trigger      string        `json:"trigger"`
+	Channel      string        `json:"channel"`
+	Target       string        `json:"target"`
+	Format       export.Format `json:"format"`
+	From         time.Time     `json:"from"`
+	To           time.Time     `json:"to"`
+	At           time.Time     `json:"delivered_at"`
+	Bytes        int           `json:"bytes"`
+	Status       string        `json:"status"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// SubscriptionStatus summarises a subscription for the API
+type SubscriptionStatus struct {
+	Subscription
+	NextRun      time.Time `json:"next_run"`
+	LastDelivery *Delivery `json:"last_delivery,omitempty"`
+}
+
+type entry struct {
+	schedule *schedule
+	nextRun  time.Time
+	history  []Delivery
+}
+
+// Service delivers subscribed reports on their schedule or on demand
+type Service struct {
+	detector *anomaly.Detector
+	mailer   mail.Sender
+	client   *http.Client
+	entries  map[string]*entry
+	now      func() time.Time
+	mu       sync.RWMutex
+}
+
+// NewService creates a service rendering reports from detector. Email is
+// sent through mailer, which may be nil to allow webhooks only; webhooks
+// use client or a client with a 30 second timeout.
+func NewService(detector *anomaly.Detector, mailer mail.Sender, client *http.Client) *Service {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Service{
+		detector: detector,
+		mailer:   mailer,
+		client:   client,
+		entries:  make(map[string]*entry),
+		now:      time.Now,
+	}
+}
+
+// Add validates and adds subscriptions, replacing any with the same name.
+// Nothing is added if one is invalid.
+func (s *Service) Add(subs ...Subscription) error {
+	compiled := make([]*schedule, 0, len(subs))
+	for _, sub := range subs {
+		sc, err := compile(sub)
+		if err != nil {
+			return err
+		}
+		if len(sc.Email) > 0 && s.mailer == nil {
+			return fmt.Errorf("subscription %s: %w", sc.Name, ErrEmailDisabled)
+		}
+		compiled = append(compiled, sc)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for _, sc := range compiled {
+		e := &entry{schedule: sc, nextRun: sc.next(now)}
+		if old, ok := s.entries[sc.Name]; ok {
+			e.history = old.history
+		}
+		s.entries[sc.Name] = e
+	}
+	return nil
+}
+
+// Remove deletes a subscription and its history
+func (s *Service) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[name]; !ok {
+		return ErrUnknownSubscription
+	}
+	delete(s.entries, name)
+	return nil
+}
+
+// Subscriptions returns every subscription sorted by name
+func (s *Service) Subscriptions() []SubscriptionStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]SubscriptionStatus, 0, len(s.entries))
+	for _, e := range s.entries {
+		status := SubscriptionStatus{Subscription: e.schedule.Subscription, NextRun: e.nextRun}
+		if len(e.history) > 0 {
+			last := e.history[0]
+			status.LastDelivery = &last
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// History returns the deliveries of a subscription, or of all when name
+// is empty, newest first
+func (s *Service) History(name string) ([]Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var deliveries []Delivery
+	for n, e := range s.entries {
+		if name == "" || n == name {
+			deliveries = append(deliveries, e.history...)
+		}
+	}
+	if name != "" && s.entries[name] == nil {
+		return nil, ErrUnknownSubscription
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool { return deliveries[i].At.After(deliveries[j].At) })
+	return deliveries, nil
+}
+
+// SendNow delivers a subscription immediately, covering the day or week
+// up to now. Its schedule is unchanged.
+func (s *Service) SendNow(ctx context.Context, name string) ([]Delivery, error) {
+	s.mu.RLock()
+	e, ok := s.entries[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownSubscription
+	}
+
+	from, to := e.schedule.period(s.now())
+	return s.deliver(ctx, e.schedule, "manual", from, to), nil
+}
+
+// Run delivers every subscription that is due and returns how many
+// deliveries succeeded. A subscription that missed several runs, e.g.
+// while the server was down, is delivered once for the latest period.
+func (s *Service) Run(ctx context.Context) (int, error) {
+	now := s.now()
+	type due struct {
+		schedule *schedule
+		at       time.Time
+	}
+	var pending []due
+
+	s.mu.Lock()
+	for _, e := range s.entries {
+		if e.nextRun.After(now) {
+			continue
+		}
+		at := e.nextRun
+		for next := e.schedule.next(at); !next.After(now); next = e.schedule.next(next) {
+			at = next
+		}
+		pending = append(pending, due{schedule: e.schedule, at: at})
+		e.nextRun = e.schedule.next(now)
+	}
+	s.mu.Unlock()
+
+	delivered, failed := 0, 0
+	for _, d := range pending {
+		from, to := d.schedule.period(d.at)
+		for _, result := range s.deliver(ctx, d.schedule, "schedule", from, to) {
+			if result.Status == "delivered" {
+				delivered++
+			} else {
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return delivered, fmt.Errorf("%d report deliveries failed", failed)
+	}
+	return delivered, nil
+}
+
+// deliver renders the report for [from, to) and sends it to each of the
+// subscription's destinations, recording the outcome
+func (s *Service) deliver(ctx context.Context, sc *schedule, trigger string, from, to time.Time) []Delivery {
+	var destinations []Delivery
+	if len(sc.Email) > 0 {
+		destinations = append(destinations, Delivery{Channel: "email", Target: strings.Join(sc.Email, ", ")})
+	}
+	if sc.Webhook != "" {
+		destinations = append(destinations, Delivery{Channel: "webhook", Target: sc.Webhook})
+	}
+
+	doc, body, renderErr := s.render(sc, from, to)
+	results := make([]Delivery, 0, len(destinations))
+	for _, d := range destinations {
+		d.ID = uuid.New().String()
+		d.Subscription = sc.Name
+		d.Trigger = trigger
+		d.Format = sc.Format
+		d.From, d.To = from, to
+		d.Bytes = len(body)
+
+		err := renderErr
+		if err == nil && d.Channel == "email" {
+			err = s.sendEmail(ctx, sc, doc, body, to)
+		} else if err == nil {
+			err = s.postWebhook(ctx, sc, body, from, to)
+		}
+		d.At = s.now()
+		d.Status = "delivered"
+		if err != nil {
+			d.Status = "failed"
+			d.Error = err.Error()
+		}
+		results = append(results, d)
+	}
+
+	s.mu.Lock()
+	if e, ok := s.entries[sc.Name]; ok {
+		history := make([]Delivery, 0, historyLimit)
+		for i := len(results) - 1; i >= 0; i-- {
+			history = append(history, results[i])
+		}
+		history = append(history, e.history...)
+		if len(history) > historyLimit {
+			history = history[:historyLimit]
+		}
+		e.history = history
+	}
+	s.mu.Unlock()
+	return results
+}
+
+// render builds the trend report for [from, to) in the subscription's
+// format
+func (s *Service) render(sc *schedule, from, to time.Time) (export.Document, []byte, error) {
+	trends, err := s.detector.Trends(anomaly.TrendQuery{From: from, To: to, Bucket: models.BucketDay})
+	if err != nil {
+		return export.Document{}, nil, err
+	}
+
+	var anomalies []*models.Anomaly
+	for _, a := range s.detector.GetAllAnomalies() {
+		if !a.DetectedAt.Before(from) && a.DetectedAt.Before(to) {
+			anomalies = append(anomalies, a)
+		}
+	}
+	sort.Slice(anomalies, func(i, j int) bool {
+		return anomalies[i].DetectedAt.After(anomalies[j].DetectedAt)
+	})
+
+	doc := export.Document{
+		Title:     title(sc, from, to),
+		Report:    s.detector.GenerateReport(),
+		Trends:    trends,
+		Anomalies: anomalies,
+	}
+	var buf bytes.Buffer
+	if err := export.Render(&buf, sc.Format, doc); err != nil {
+		return doc, nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return doc, buf.Bytes(), nil
+}
+
+// sendEmail mails a summary with the report attached, or as the body when
+// it is HTML
+func (s *Service) sendEmail(ctx context.Context, sc *schedule, doc export.Document, body []byte, to time.Time) error {
+	msg := mail.Message{
+		To:      sc.Email,
+		Subject: doc.Title,
+		Text:    summary(doc.Trends.Current),
+	}
+	if sc.Format == export.FormatHTML {
+		msg.HTML = string(body)
+	} else {
+		msg.Attachments = []mail.Attachment{{
+			Filename:    fmt.Sprintf("anomaly-report-%s.%s", to.Format("2006-01-02"), sc.Format.Extension()),
+			ContentType: sc.Format.ContentType(),
+			Data:        body,
+		}}
+	}
+	return s.mailer.Send(ctx, msg)
+}
+
+// postWebhook posts the rendered report; any non-2xx response is an error
+func (s *Service) postWebhook(ctx context.Context, sc *schedule, body []byte, from, to time.Time) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sc.Webhook, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create report request: %w", err)
+	}
+	req.Header.Set("Content-Type", sc.Format.ContentType())
+	req.Header.Set("X-Report-Subscription", sc.Name)
+	req.Header.Set("X-Report-From", from.Format(time.RFC3339))
+	req.Header.Set("X-Report-To", to.Format(time.RFC3339))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("report request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("report webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func title(sc *schedule, from, to time.Time) string {
+	if sc.Frequency == Weekly {
+		return fmt.Sprintf("Weekly Anomaly Report %s to %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("Daily Anomaly Report %s", from.Format("2006-01-02"))
+}
+
+func summary(p models.PeriodSummary) string {
+	return fmt.Sprintf("%d new and %d resolved anomalies between %s and %s.\n"+
+		"Mean time to acknowledge: %s. Mean time to resolve: %s.\n"+
+		"%d regressions (%.0f%% of new anomalies).\n",
+		p.New, p.Resolved, p.From.Format(time.RFC1123), p.To.Format(time.RFC1123),
+		seconds(p.MTTA), seconds(p.MTTR), p.Regressions, p.RegressionRate*100)
+}
+
+func seconds(d models.DurationStats) string {
+	if d.Count == 0 {
+		return "n/a"
+	}
+	return time.Duration(d.Mean * float64(time.Second)).Round(time.Second).String()
+}
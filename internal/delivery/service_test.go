@@ -0,0 +1,191 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/export"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/mail"
+)
+
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []mail.Message
+}
+
+func (f *fakeMailer) Send(_ context.Context, m mail.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, m)
+	return nil
+}
+
+func newDetector() *anomaly.Detector {
+	registry, _ := anomaly.NewRegistry(anomaly.DefaultTypes...)
+	detector := anomaly.NewDetector()
+	detector.SetRegistry(registry)
+	return detector
+}
+
+func TestNextKeepsLocalTimeAcrossDaylightSaving(t *testing.T) {
+	sc, err := compile(Subscription{Name: "ops", Frequency: Daily, At: "09:00", Timezone: "America/New_York", Webhook: "https://example.com"})
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	// Clocks go forward on 8 March 2026 in New York
+	after := time.Date(2026, 3, 7, 12, 0, 0, 0, sc.location)
+	next := sc.next(after)
+	following := sc.next(next)
+	for _, run := range []time.Time{next, following} {
+		if local := run.In(sc.location); local.Hour() != 9 || local.Minute() != 0 {
+			t.Errorf("Expected delivery at 09:00 local time, got %v", local)
+		}
+	}
+	if got := following.Sub(next); got != 24*time.Hour {
+		t.Errorf("Expected 24h between runs after the change, got %v", got)
+	}
+	if got := next.Sub(sc.next(after.AddDate(0, 0, -1))); got != 23*time.Hour {
+		t.Errorf("Expected a 23h day across the change, got %v", got)
+	}
+}
+
+func TestWeeklyNext(t *testing.T) {
+	sc, err := compile(Subscription{Name: "weekly", Frequency: Weekly, Weekday: "Friday", At: "17:30", Webhook: "https://example.com"})
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+
+	// Friday 16 October 2026 after the delivery time
+	next := sc.next(time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, 10, 23, 17, 30, 0, 0, time.UTC); !next.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, next)
+	}
+	from, to := sc.period(next)
+	if to.Sub(from) != 7*24*time.Hour {
+		t.Errorf("Expected a week long period, got %v to %v", from, to)
+	}
+}
+
+func TestInvalidSubscriptions(t *testing.T) {
+	for _, sub := range []Subscription{
+		{Frequency: Daily, Webhook: "https://example.com"},
+		{Name: "a", Frequency: "hourly", Webhook: "https://example.com"},
+		{Name: "a", Frequency: Daily, At: "9am", Webhook: "https://example.com"},
+		{Name: "a", Frequency: Daily, Weekday: "monday", Webhook: "https://example.com"},
+		{Name: "a", Frequency: Weekly, Weekday: "someday", Webhook: "https://example.com"},
+		{Name: "a", Frequency: Daily, Timezone: "Mars/Olympus", Webhook: "https://example.com"},
+		{Name: "a", Frequency: Daily, Format: "docx", Webhook: "https://example.com"},
+		{Name: "a", Frequency: Daily},
+		{Name: "a", Frequency: Daily, Webhook: "ftp://example.com"},
+	} {
+		if _, err := compile(sub); err == nil {
+			t.Errorf("Expected %+v to be rejected", sub)
+		}
+	}
+
+	service := NewService(newDetector(), nil, nil)
+	err := service.Add(Subscription{Name: "a", Frequency: Daily, Email: []string{"ops@example.com"}})
+	if !errors.Is(err, ErrEmailDisabled) {
+		t.Errorf("Expected email without a relay to be rejected, got %v", err)
+	}
+}
+
+func TestRunDeliversDueSubscriptions(t *testing.T) {
+	detector := newDetector()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	detector.Record(&models.Anomaly{
+		Type:       models.AnomalyTypeNodeDesynchronization,
+		DetectedAt: now.Add(-2 * time.Hour),
+		Metadata:   map[string]interface{}{"affected_route": "Earth-Mars"},
+	})
+
+	var mu sync.Mutex
+	var posted []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		posted = append(posted, r)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	mailer := &fakeMailer{}
+	service := NewService(detector, mailer, server.Client())
+	service.now = func() time.Time { return now }
+	err := service.Add(Subscription{
+		Name:      "daily",
+		Frequency: Daily,
+		Format:    export.FormatCSV,
+		Email:     []string{"ops@example.com"},
+		Webhook:   server.URL,
+	})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if n, err := service.Run(context.Background()); n != 0 || err != nil {
+		t.Fatalf("Expected nothing due yet, got %d, %v", n, err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if n, err := service.Run(context.Background()); n != 2 || err != nil {
+		t.Fatalf("Expected email and webhook deliveries, got %d, %v", n, err)
+	}
+	if n, _ := service.Run(context.Background()); n != 0 {
+		t.Errorf("Expected a delivery only once per period, got %d", n)
+	}
+
+	if len(mailer.sent) != 1 || len(mailer.sent[0].Attachments) != 1 {
+		t.Fatalf("Expected one email with the report attached, got %+v", mailer.sent)
+	}
+	attachment := mailer.sent[0].Attachments[0]
+	if attachment.Filename != "anomaly-report-2026-10-19.csv" || !strings.Contains(string(attachment.Data), "node_desync") {
+		t.Errorf("Unexpected attachment %s: %s", attachment.Filename, attachment.Data)
+	}
+
+	if len(posted) != 1 || posted[0].Header.Get("X-Report-Subscription") != "daily" ||
+		posted[0].Header.Get("X-Report-From") != "2026-10-18T09:00:00Z" {
+		t.Errorf("Unexpected webhook requests %+v", posted)
+	}
+
+	history, _ := service.History("daily")
+	if len(history) != 2 || history[0].Status != "delivered" || history[0].Trigger != "schedule" {
+		t.Errorf("Unexpected history %+v", history)
+	}
+	if status := service.Subscriptions()[0]; !status.NextRun.Equal(time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)) || status.LastDelivery == nil {
+		t.Errorf("Unexpected status %+v", status)
+	}
+}
+
+func TestSendNowRecordsFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	service := NewService(newDetector(), nil, server.Client())
+	if _, err := service.SendNow(context.Background(), "missing"); !errors.Is(err, ErrUnknownSubscription) {
+		t.Errorf("Expected an unknown subscription error, got %v", err)
+	}
+
+	service.Add(Subscription{Name: "weekly", Frequency: Weekly, Format: export.FormatJSON, Webhook: server.URL})
+	deliveries, err := service.SendNow(context.Background(), "weekly")
+	if err != nil {
+		t.Fatalf("SendNow failed: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Status != "failed" || deliveries[0].Trigger != "manual" ||
+		!strings.Contains(deliveries[0].Error, "502") {
+		t.Errorf("Expected a failed manual delivery, got %+v", deliveries)
+	}
+	if history, _ := service.History(""); len(history) != 1 {
+		t.Errorf("Expected the failure in the history, got %+v", history)
+	}
+}
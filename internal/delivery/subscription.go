@@ -0,0 +1,151 @@
+// Package delivery sends recurring anomaly reports. Subscriptions render
+// the trend report for the day or week just ended in their time zone and
+// deliver it by email through an SMTP relay or by POSTing it to a
+// webhook, keeping a history of deliveries.
+package delivery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/export"
+)
+
+// Frequency is how often a subscription is delivered
+type Frequency string
+
+const (
+	Daily  Frequency = "daily"
+	Weekly Frequency = "weekly"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// Subscription delivers a report at a local time every day or week. Each
+// delivery covers the day or week up to that time.
+type Subscription struct {
+	Name      string    `json:"name"`
+	Frequency Frequency `json:"frequency"`
+	// At is the local delivery time as HH:MM, 09:00 by default
+	At string `json:"at,omitempty"`
+	// Weekday is the day weekly reports are delivered, Monday by default
+	Weekday string `json:"weekday,omitempty"`
+	// Timezone the delivery time is in, UTC by default
+	Timezone  string        `json:"timezone,omitempty"`
+	Format    export.Format `json:"format,omitempty"`
+	Email     []string      `json:"email,omitempty"`
+	Webhook   string        `json:"webhook,omitempty"`
+	CreatedBy string        `json:"created_by,omitempty"`
+}
+
+// schedule is a validated subscription ready to compute delivery times
+type schedule struct {
+	Subscription
+	location *time.Location
+	hour     int
+	minute   int
+	weekday  time.Weekday
+}
+
+func compile(s Subscription) (*schedule, error) {
+	if s.Name == "" {
+		return nil, fmt.Errorf("subscription name is required")
+	}
+	if s.At == "" {
+		s.At = "09:00"
+	}
+	if s.Format == "" {
+		s.Format = export.FormatHTML
+	}
+	sc := &schedule{Subscription: s, location: time.UTC, weekday: time.Monday}
+
+	switch s.Frequency {
+	case Daily:
+		if s.Weekday != "" {
+			return nil, fmt.Errorf("subscription %s: weekday only applies to weekly reports", s.Name)
+		}
+	case Weekly:
+		if s.Weekday != "" {
+			day, ok := weekdays[strings.ToLower(s.Weekday)]
+			if !ok {
+				return nil, fmt.Errorf("subscription %s: invalid weekday %q", s.Name, s.Weekday)
+			}
+			sc.weekday = day
+		}
+	default:
+		return nil, fmt.Errorf("subscription %s: frequency must be daily or weekly", s.Name)
+	}
+
+	at, err := time.Parse("15:04", s.At)
+	if err != nil {
+		return nil, fmt.Errorf("subscription %s: at must be HH:MM", s.Name)
+	}
+	sc.hour, sc.minute = at.Hour(), at.Minute()
+
+	if s.Timezone != "" {
+		if sc.location, err = time.LoadLocation(s.Timezone); err != nil {
+			return nil, fmt.Errorf("subscription %s: %w", s.Name, err)
+		}
+	}
+	if _, err := export.ParseFormat(string(s.Format)); err != nil {
+		return nil, fmt.Errorf("subscription %s: %w", s.Name, err)
+	}
+
+	if len(s.Email) == 0 && s.Webhook == "" {
+		return nil, fmt.Errorf("subscription %s: email or webhook is required", s.Name)
+	}
+	if s.Webhook != "" {
+		u, err := url.Parse(s.Webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("subscription %s: webhook must be an http or https URL", s.Name)
+		}
+	}
+	return sc, nil
+}
+
+// next returns the first delivery time after after. Dates are advanced
+// in the subscription's time zone so deliveries keep their local time
+// across daylight saving changes.
+func (sc *schedule) next(after time.Time) time.Time {
+	local := after.In(sc.location)
+	t := time.Date(local.Year(), local.Month(), local.Day(), sc.hour, sc.minute, 0, 0, sc.location)
+	step := 1
+	if sc.Frequency == Weekly {
+		step = 7
+		t = t.AddDate(0, 0, (int(sc.weekday)-int(t.Weekday())+7)%7)
+	}
+	if !t.After(after) {
+		t = t.AddDate(0, 0, step)
+	}
+	return t
+}
+
+// period returns the day or week a delivery at "to" covers
+func (sc *schedule) period(to time.Time) (time.Time, time.Time) {
+	to = to.In(sc.location)
+	if sc.Frequency == Weekly {
+		return to.AddDate(0, 0, -7), to
+	}
+	return to.AddDate(0, 0, -1), to
+}
+
+// LoadFile reads subscriptions from a JSON array
+func LoadFile(path string) ([]Subscription, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report subscriptions: %w", err)
+	}
+
+	var subs []Subscription
+	if err := json.Unmarshal(data, &subs); err != nil {
+		return nil, fmt.Errorf("invalid report subscriptions file %s: %w", path, err)
+	}
+	return subs, nil
+}
@@ -0,0 +1,307 @@
+package enrichment
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/search"
+)
+
+// DefaultTemplates holds the type-specific query templates. Templates are
+// executed against the anomaly, so fields such as {{.Source}} and
+// {{index .Metadata "affected_route"}} are available.
+var DefaultTemplates = map[models.AnomalyType][]string{
+	models.AnomalyTypeLedgerDivergence: {
+		"MANUS Blockchain ledger hash divergence resynchronize planetary nodes",
+		"distributed ledger fork resolution {{.Source}}",
+	},
+	models.AnomalyTypeDAOVoteFailure: {
+		"DAO vote propagation failure {{index .Metadata \"proposal_id\"}}",
+		"DAO governance vote rebroadcast interplanetary network",
+	},
+	models.AnomalyTypeCommitAnomaly: {
+		"GitHub Copilot anomalous commit pattern verification",
+	},
+	models.AnomalyTypeNodeDesynchronization: {
+		"interplanetary node synchronization latency {{index .Metadata \"affected_route\"}}",
+	},
+}
+
+// Config controls the enrichment pipeline
+type Config struct {
+	Workers         int
+	QueueSize       int
+	ResultsPerQuery int
+	MaxResults      int
+	SimilarLimit    int
+	DisabledTypes   []models.AnomalyType
+	Templates       map[models.AnomalyType][]string
+}
+
+// DefaultConfig returns the default enrichment configuration
+func DefaultConfig() Config {
+	return Config{
+		Workers:         4,
+		QueueSize:       100,
+		ResultsPerQuery: 5,
+		MaxResults:      5,
+		SimilarLimit:    3,
+		Templates:       DefaultTemplates,
+	}
+}
+
+// Stats reports enrichment pipeline counters
+type Stats struct {
+	Queued    int `json:"queued"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+	Dropped   int `json:"dropped"`
+}
+
+// Enricher attaches search context and similar past anomalies to newly
+// detected anomalies using a bounded pool of workers
+type Enricher struct {
+	detector  *anomaly.Detector
+	provider  search.Provider
+	local     *search.LocalSearchClient
+	cfg       Config
+	templates map[models.AnomalyType][]*template.Template
+	disabled  map[models.AnomalyType]bool
+
+	queue   chan string
+	stopped bool
+	wg      sync.WaitGroup
+	stats   Stats
+	mu      sync.Mutex
+}
+
+// NewEnricher creates an enricher. local may be nil, in which case no
+// similar anomalies are attached.
+func NewEnricher(detector *anomaly.Detector, provider search.Provider, local *search.LocalSearchClient, cfg Config) (*Enricher, error) {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.Templates == nil {
+		cfg.Templates = DefaultTemplates
+	}
+
+	e := &Enricher{
+		detector:  detector,
+		provider:  provider,
+		local:     local,
+		cfg:       cfg,
+		templates: make(map[models.AnomalyType][]*template.Template),
+		disabled:  make(map[models.AnomalyType]bool),
+		queue:     make(chan string, cfg.QueueSize),
+	}
+
+	for anomalyType, texts := range cfg.Templates {
+		for i, text := range texts {
+			tmpl, err := template.New(fmt.Sprintf("%s-%d", anomalyType, i)).Option("missingkey=zero").Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid query template for %s: %w", anomalyType, err)
+			}
+			e.templates[anomalyType] = append(e.templates[anomalyType], tmpl)
+		}
+	}
+	for _, anomalyType := range cfg.DisabledTypes {
+		e.disabled[anomalyType] = true
+	}
+
+	return e, nil
+}
+
+// Start launches the worker pool
+func (e *Enricher) Start() {
+	for i := 0; i < e.cfg.Workers; i++ {
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			for id := range e.queue {
+				e.Enrich(context.Background(), id)
+			}
+		}()
+	}
+}
+
+// Stop drains the queue and waits for workers to finish
+func (e *Enricher) Stop() {
+	e.mu.Lock()
+	if !e.stopped {
+		e.stopped = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+
+	e.wg.Wait()
+}
+
+// HandleEvent queues newly detected anomalies; it is meant to be passed
+// to Detector.Subscribe
+func (e *Enricher) HandleEvent(event anomaly.Event) {
+	if event.Type != anomaly.EventDetected {
+		return
+	}
+
+	id := event.Anomaly.ID
+	if e.disabled[event.Anomaly.Type] {
+		e.count(func(s *Stats) { s.Skipped++ })
+		e.setContext(id, &models.AnomalyContext{Status: models.EnrichmentSkipped})
+		return
+	}
+
+	// Mark pending before queueing so a fast worker's result is not
+	// overwritten afterwards
+	e.setContext(id, &models.AnomalyContext{Status: models.EnrichmentPending})
+
+	if e.enqueue(id) {
+		return
+	}
+	e.setContext(id, &models.AnomalyContext{
+		Status: models.EnrichmentFailed,
+		Error:  "enrichment queue is full",
+	})
+}
+
+// enqueue hands id to the workers without blocking the detector
+func (e *Enricher) enqueue(id string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.stopped {
+		select {
+		case e.queue <- id:
+			e.stats.Queued++
+			return true
+		default:
+		}
+	}
+
+	e.stats.Dropped++
+	return false
+}
+
+// Enrich gathers context for one anomaly synchronously
+func (e *Enricher) Enrich(ctx context.Context, id string) error {
+	a, err := e.detector.GetAnomaly(id)
+	if err != nil {
+		return err
+	}
+
+	e.setContext(id, &models.AnomalyContext{Status: models.EnrichmentRunning})
+
+	result := &models.AnomalyContext{Status: models.EnrichmentCompleted}
+	seen := make(map[string]bool)
+	var failures []string
+
+	for _, query := range e.queries(a) {
+		result.Queries = append(result.Queries, query)
+
+		resp, err := e.provider.Search(ctx, search.SearchRequest{Query: query, Count: e.cfg.ResultsPerQuery})
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		for _, hit := range resp.Results {
+			if seen[hit.URL] || (e.cfg.MaxResults > 0 && len(result.Results) >= e.cfg.MaxResults) {
+				continue
+			}
+			seen[hit.URL] = true
+			result.Results = append(result.Results, hit)
+		}
+	}
+
+	result.SimilarAnomalies = e.similar(a)
+
+	if len(failures) > 0 && len(failures) == len(result.Queries) {
+		result.Status = models.EnrichmentFailed
+		result.Error = failures[0]
+		e.count(func(s *Stats) { s.Failed++ })
+	} else {
+		e.count(func(s *Stats) { s.Completed++ })
+	}
+
+	e.setContext(id, result)
+	if result.Status == models.EnrichmentFailed {
+		return fmt.Errorf("enrichment failed: %s", result.Error)
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the pipeline counters
+func (e *Enricher) Stats() Stats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.stats
+}
+
+func (e *Enricher) queries(a *models.Anomaly) []string {
+	templates := e.templates[a.Type]
+	if len(templates) == 0 {
+		return []string{search.AnomalyContextQuery(a.Type)}
+	}
+
+	queries := make([]string, 0, len(templates))
+	for _, tmpl := range templates {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, a); err != nil {
+			continue
+		}
+		query := strings.Join(strings.Fields(strings.ReplaceAll(buf.String(), "<no value>", "")), " ")
+		if query != "" {
+			queries = append(queries, query)
+		}
+	}
+	return queries
+}
+
+func (e *Enricher) similar(a *models.Anomaly) []models.SimilarAnomaly {
+	if e.local == nil || e.cfg.SimilarLimit <= 0 {
+		return nil
+	}
+
+	var similar []models.SimilarAnomaly
+	for _, hit := range e.local.Index().Query(a.Description, 0) {
+		doc := hit.Document
+		if doc.Kind != search.KindAnomaly {
+			continue
+		}
+		id, _ := search.AnomalyIDFromURL(doc.URL)
+		if id == a.ID {
+			continue
+		}
+		similar = append(similar, models.SimilarAnomaly{
+			ID:          id,
+			Description: doc.Fields[search.FieldDescription],
+			Resolution:  doc.Fields[search.FieldResolution],
+			Score:       hit.Score,
+		})
+		if len(similar) == e.cfg.SimilarLimit {
+			break
+		}
+	}
+	return similar
+}
+
+func (e *Enricher) setContext(id string, ctx *models.AnomalyContext) {
+	ctx.UpdatedAt = time.Now()
+	e.detector.Update(id, func(a *models.Anomaly) {
+		a.Context = ctx
+	})
+}
+
+func (e *Enricher) count(fn func(*Stats)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	fn(&e.stats)
+}
@@ -0,0 +1,168 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/search"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/search/replay"
+)
+
+type fakeProvider struct {
+	err     error
+	queries []string
+	mu      sync.Mutex
+}
+
+func (f *fakeProvider) Name() string { return "fake" }
+
+func (f *fakeProvider) Search(ctx context.Context, req search.SearchRequest) (*models.SearchResponse, error) {
+	f.mu.Lock()
+	f.queries = append(f.queries, req.Query)
+	f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.SearchResponse{
+		Query:   req.Query,
+		Results: []models.SearchResult{{Title: req.Query, URL: "https://example.com/" + req.Query}},
+	}, nil
+}
+
+func waitForStatus(t *testing.T, detector *anomaly.Detector, id string, status models.EnrichmentStatus) *models.Anomaly {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		a, err := detector.GetAnomaly(id)
+		if err != nil {
+			t.Fatalf("Failed to get anomaly: %v", err)
+		}
+		if a.Context != nil && a.Context.Status == status {
+			return a
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Anomaly %s never reached enrichment status %s", id, status)
+	return nil
+}
+
+func TestEnricherAttachesContext(t *testing.T) {
+	detector := anomaly.NewDetector()
+	local := search.NewLocalSearchClient(search.NewIndex(nil))
+	detector.Subscribe(func(event anomaly.Event) { local.IndexAnomaly(event.Anomaly) })
+
+	provider := &fakeProvider{}
+	cfg := DefaultConfig()
+	cfg.DisabledTypes = []models.AnomalyType{models.AnomalyTypeCommitAnomaly}
+
+	enricher, err := NewEnricher(detector, provider, local, cfg)
+	if err != nil {
+		t.Fatalf("NewEnricher failed: %v", err)
+	}
+	enricher.Start()
+	detector.Subscribe(enricher.HandleEvent)
+
+	// A first round gives the second round past anomalies to match
+	detector.DetectAnomalies()
+	anomalies := detector.DetectAnomalies()
+
+	for _, a := range anomalies {
+		if a.Type == models.AnomalyTypeCommitAnomaly {
+			waitForStatus(t, detector, a.ID, models.EnrichmentSkipped)
+			continue
+		}
+
+		enriched := waitForStatus(t, detector, a.ID, models.EnrichmentCompleted)
+		if len(enriched.Context.Queries) == 0 || len(enriched.Context.Results) == 0 {
+			t.Errorf("Expected queries and results for %s", a.Type)
+		}
+		for _, similar := range enriched.Context.SimilarAnomalies {
+			if similar.ID == a.ID {
+				t.Errorf("Anomaly %s listed as similar to itself", a.ID)
+			}
+		}
+		if len(enriched.Context.SimilarAnomalies) == 0 {
+			t.Errorf("Expected similar anomalies for %s", a.Type)
+		}
+	}
+
+	enricher.Stop()
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	for _, q := range provider.queries {
+		if q == "" {
+			t.Error("Expected templates to render non-empty queries")
+		}
+	}
+}
+
+func TestEnricherRecordsFailure(t *testing.T) {
+	detector := anomaly.NewDetector()
+	enricher, err := NewEnricher(detector, &fakeProvider{err: errors.New("upstream down")}, nil, DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewEnricher failed: %v", err)
+	}
+
+	a := detector.DetectAnomalies()[0]
+	if err := enricher.Enrich(context.Background(), a.ID); err == nil {
+		t.Fatal("Expected enrichment error")
+	}
+
+	failed, _ := detector.GetAnomaly(a.ID)
+	if failed.Context.Status != models.EnrichmentFailed || failed.Context.Error == "" {
+		t.Errorf("Expected failed status with error, got %+v", failed.Context)
+	}
+	if enricher.Stats().Failed != 1 {
+		t.Errorf("Expected 1 failure, got %d", enricher.Stats().Failed)
+	}
+}
+
+func TestNewEnricherRejectsBadTemplate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Templates = map[models.AnomalyType][]string{models.AnomalyTypeUnknown: {"{{.Broken"}}
+
+	if _, err := NewEnricher(anomaly.NewDetector(), &fakeProvider{}, nil, cfg); err == nil {
+		t.Error("Expected template parse error")
+	}
+}
+
+func TestEnricherReplaysRecordedSearches(t *testing.T) {
+	transport, err := replay.NewTransport(replay.ModeReplay, "testdata/cassettes/enrichment.json", nil)
+	if err != nil {
+		t.Fatalf("Failed to open cassette: %v", err)
+	}
+	bing := search.NewBingSearchClient("replay-key", "https://api.bing.microsoft.com/v7.0/search", search.WithTransport(transport))
+
+	detector := anomaly.NewDetector()
+	enricher, err := NewEnricher(detector, bing, nil, DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewEnricher failed: %v", err)
+	}
+
+	var dao *models.Anomaly
+	for _, a := range detector.DetectAnomalies() {
+		if a.Type == models.AnomalyTypeDAOVoteFailure {
+			dao = a
+		}
+	}
+
+	if err := enricher.Enrich(context.Background(), dao.ID); err != nil {
+		t.Fatalf("Enrich failed: %v", err)
+	}
+
+	enriched, _ := detector.GetAnomaly(dao.ID)
+	results := enriched.Context.Results
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 deduplicated results, got %d", len(results))
+	}
+	if results[0].URL != "https://dao.governance/incidents/prop-2026-001" {
+		t.Errorf("Expected proposal-specific result first, got %s", results[0].URL)
+	}
+}
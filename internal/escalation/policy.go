@@ -0,0 +1,110 @@
+package escalation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// Step is taken AfterSeconds after an anomaly first breaches a deadline:
+// the named targets, e.g. on-call tiers, are notified and the severity is
+// optionally raised one level. The acknowledge and resolve deadlines each
+// run through the steps, timed from their own breach.
+type Step struct {
+	AfterSeconds int      `json:"after_seconds"`
+	Notify       []string `json:"notify"`
+	BumpSeverity bool     `json:"bump_severity,omitempty"`
+}
+
+// Policy sets the acknowledge and resolve deadlines for anomalies of a
+// severity and the steps taken once one passes
+type Policy struct {
+	Severity             models.AnomalySeverity `json:"severity"`
+	AckWithinSeconds     int                    `json:"ack_within_seconds"`
+	ResolveWithinSeconds int                    `json:"resolve_within_seconds"`
+	Steps                []Step                 `json:"steps"`
+}
+
+// AckWithin returns the acknowledge deadline relative to detection
+func (p Policy) AckWithin() time.Duration {
+	return time.Duration(p.AckWithinSeconds) * time.Second
+}
+
+// ResolveWithin returns the resolve deadline relative to detection
+func (p Policy) ResolveWithin() time.Duration {
+	return time.Duration(p.ResolveWithinSeconds) * time.Second
+}
+
+func (p Policy) validate() error {
+	if severityRank[p.Severity] == 0 {
+		return fmt.Errorf("invalid severity %q", p.Severity)
+	}
+	if p.AckWithinSeconds <= 0 || p.ResolveWithinSeconds < p.AckWithinSeconds {
+		return fmt.Errorf("%s policy: ack_within_seconds must be positive and no later than resolve_within_seconds", p.Severity)
+	}
+	for i, step := range p.Steps {
+		if len(step.Notify) == 0 && !step.BumpSeverity {
+			return fmt.Errorf("%s policy: step %d neither notifies nor bumps severity", p.Severity, i+1)
+		}
+		if step.AfterSeconds < 0 || (i > 0 && step.AfterSeconds < p.Steps[i-1].AfterSeconds) {
+			return fmt.Errorf("%s policy: step %d must not come before the previous step", p.Severity, i+1)
+		}
+	}
+	return nil
+}
+
+// DefaultPolicies holds the built-in escalation policies
+var DefaultPolicies = []Policy{
+	{
+		Severity:             models.SeverityCritical,
+		AckWithinSeconds:     300,
+		ResolveWithinSeconds: 3600,
+		Steps: []Step{
+			{AfterSeconds: 0, Notify: []string{"oncall-primary"}},
+			{AfterSeconds: 600, Notify: []string{"oncall-secondary"}},
+			{AfterSeconds: 1800, Notify: []string{"engineering-lead"}},
+		},
+	},
+	{
+		Severity:             models.SeverityHigh,
+		AckWithinSeconds:     900,
+		ResolveWithinSeconds: 14400,
+		Steps: []Step{
+			{AfterSeconds: 0, Notify: []string{"oncall-primary"}},
+			{AfterSeconds: 1800, Notify: []string{"oncall-secondary"}, BumpSeverity: true},
+		},
+	},
+	{
+		Severity:             models.SeverityMedium,
+		AckWithinSeconds:     3600,
+		ResolveWithinSeconds: 86400,
+		Steps: []Step{
+			{AfterSeconds: 0, Notify: []string{"oncall-primary"}, BumpSeverity: true},
+		},
+	},
+	{
+		Severity:             models.SeverityLow,
+		AckWithinSeconds:     14400,
+		ResolveWithinSeconds: 259200,
+		Steps: []Step{
+			{AfterSeconds: 0, Notify: []string{"triage"}},
+		},
+	},
+}
+
+// LoadFile reads escalation policies from a JSON array
+func LoadFile(path string) ([]Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read escalation policies: %w", err)
+	}
+
+	var policies []Policy
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return nil, fmt.Errorf("invalid escalation policies file %s: %w", path, err)
+	}
+	return policies, nil
+}
@@ -0,0 +1,200 @@
+// Package escalation tracks acknowledge and resolve deadlines for
+// anomalies per severity and escalates anomalies that miss them by
+// notifying the next tier and raising their severity.
+package escalation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/notify"
+)
+
+// severityRank orders severities from least to most severe
+var severityRank = map[models.AnomalySeverity]int{
+	models.SeverityLow:      1,
+	models.SeverityMedium:   2,
+	models.SeverityHigh:     3,
+	models.SeverityCritical: 4,
+}
+
+// bump returns the next severity up, or s if it is already critical
+func bump(s models.AnomalySeverity) models.AnomalySeverity {
+	switch s {
+	case models.SeverityLow:
+		return models.SeverityMedium
+	case models.SeverityMedium:
+		return models.SeverityHigh
+	default:
+		return models.SeverityCritical
+	}
+}
+
+// Tracker attaches SLAs to new anomalies and escalates breaches when
+// Check runs
+type Tracker struct {
+	detector *anomaly.Detector
+	notifier notify.Notifier
+	policies map[models.AnomalySeverity]Policy
+	now      func() time.Time
+}
+
+// NewTracker creates a tracker applying policies, at most one per
+// severity, and sending escalations through notifier
+func NewTracker(detector *anomaly.Detector, notifier notify.Notifier, policies ...Policy) (*Tracker, error) {
+	t := &Tracker{
+		detector: detector,
+		notifier: notifier,
+		policies: make(map[models.AnomalySeverity]Policy),
+		now:      time.Now,
+	}
+	for _, p := range policies {
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+		if _, exists := t.policies[p.Severity]; exists {
+			return nil, fmt.Errorf("duplicate escalation policy for %s", p.Severity)
+		}
+		t.policies[p.Severity] = p
+	}
+	return t, nil
+}
+
+// Policies returns the policies from most to least severe
+func (t *Tracker) Policies() []Policy {
+	policies := make([]Policy, 0, len(t.policies))
+	for _, p := range t.policies {
+		policies = append(policies, p)
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return severityRank[policies[i].Severity] > severityRank[policies[j].Severity]
+	})
+	return policies
+}
+
+// HandleEvent attaches an SLA to newly detected anomalies whose severity
+// has a policy. Suppressed and already resolved anomalies are not tracked.
+func (t *Tracker) HandleEvent(event anomaly.Event) {
+	a := event.Anomaly
+	if event.Type != anomaly.EventDetected || a.Suppressed || a.Status == models.StatusResolved || a.SLA != nil {
+		return
+	}
+	policy, ok := t.policies[a.Severity]
+	if !ok {
+		return
+	}
+
+	sla := &models.SLA{
+		Policy:          policy.Severity,
+		AckDeadline:     a.DetectedAt.Add(policy.AckWithin()),
+		ResolveDeadline: a.DetectedAt.Add(policy.ResolveWithin()),
+	}
+	t.detector.Update(a.ID, func(a *models.Anomaly) {
+		a.SLA = sla
+	})
+}
+
+// Check flags SLA breaches and takes escalation steps that are due,
+// returning how many steps were taken
+func (t *Tracker) Check(ctx context.Context) (int, error) {
+	now := t.now()
+	taken := 0
+
+	for _, a := range t.detector.GetAllAnomalies() {
+		if ctx.Err() != nil {
+			return taken, ctx.Err()
+		}
+		if a.SLA == nil || a.Status == models.StatusResolved {
+			continue
+		}
+
+		ackBreached, resolveBreached := anomaly.SLABreaches(a, now)
+		steps := t.policies[a.SLA.Policy].Steps
+		severity := a.Severity
+
+		// Escalate each breached deadline while it is still unmet
+		var ackSteps, resolveSteps []models.Escalation
+		if ackBreached && a.AcknowledgedAt == nil {
+			ackSteps = t.escalate(ctx, a, steps, models.DeadlineAck, a.SLA.AckDeadline, a.SLA.AckLevel, now, &severity)
+		}
+		if resolveBreached {
+			resolveSteps = t.escalate(ctx, a, steps, models.DeadlineResolve, a.SLA.ResolveDeadline, a.SLA.ResolveLevel, now, &severity)
+		}
+		escalations := append(ackSteps, resolveSteps...)
+
+		if ackBreached == a.SLA.AckBreached && resolveBreached == a.SLA.ResolveBreached && len(escalations) == 0 {
+			continue
+		}
+		taken += len(escalations)
+		t.detector.Update(a.ID, func(a *models.Anomaly) {
+			// Replace rather than mutate the SLA readers may share
+			sla := *a.SLA
+			sla.AckBreached = ackBreached
+			sla.ResolveBreached = resolveBreached
+			sla.Level += len(escalations)
+			sla.AckLevel += len(ackSteps)
+			sla.ResolveLevel += len(resolveSteps)
+			sla.Escalations = append(append([]models.Escalation(nil), sla.Escalations...), escalations...)
+			a.SLA = &sla
+			a.Severity = severity
+		})
+	}
+	return taken, nil
+}
+
+// escalate takes the steps from level on that are due for a deadline
+// breached at since, raising *severity as steps require
+func (t *Tracker) escalate(ctx context.Context, a *models.Anomaly, steps []Step, deadline string, since time.Time, level int, now time.Time, severity *models.AnomalySeverity) []models.Escalation {
+	reason := "not acknowledged"
+	if deadline == models.DeadlineResolve {
+		reason = "not resolved"
+	}
+
+	var escalations []models.Escalation
+	for ; level < len(steps); level++ {
+		step := steps[level]
+		if now.Before(since.Add(time.Duration(step.AfterSeconds) * time.Second)) {
+			break
+		}
+		escalation := models.Escalation{
+			Deadline: deadline,
+			Level:    level + 1,
+			At:       now,
+			Reason:   fmt.Sprintf("%s within %s SLA", reason, a.SLA.Policy),
+		}
+		if step.BumpSeverity && *severity != models.SeverityCritical {
+			*severity = bump(*severity)
+			escalation.Severity = *severity
+		}
+		if len(step.Notify) > 0 {
+			escalation.Notified = step.Notify
+			if err := t.notify(ctx, a, escalation, *severity); err != nil {
+				escalation.Error = err.Error()
+			}
+		}
+		escalations = append(escalations, escalation)
+	}
+	return escalations
+}
+
+func (t *Tracker) notify(ctx context.Context, a *models.Anomaly, e models.Escalation, severity models.AnomalySeverity) error {
+	escalated := *a
+	escalated.Severity = severity
+
+	message := fmt.Sprintf("Escalation level %d for %s anomaly %s (%s): %s", e.Level, severity, a.ID, a.Type, e.Reason)
+	if e.Severity != "" {
+		message += fmt.Sprintf("; severity raised to %s", e.Severity)
+	}
+	return t.notifier.Notify(ctx, notify.Notification{
+		Kind:    notify.KindEscalation,
+		Targets: e.Notified,
+		Message: message + ". " + strings.TrimSpace(a.Description),
+		Anomaly: escalated,
+		SentAt:  e.At,
+	})
+}
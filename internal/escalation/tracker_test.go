@@ -0,0 +1,215 @@
+package escalation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/notify"
+)
+
+type recorder struct {
+	sent []notify.Notification
+	err  error
+}
+
+func (r *recorder) Notify(ctx context.Context, n notify.Notification) error {
+	r.sent = append(r.sent, n)
+	return r.err
+}
+
+var testPolicy = Policy{
+	Severity:             models.SeverityHigh,
+	AckWithinSeconds:     600,
+	ResolveWithinSeconds: 3600,
+	Steps: []Step{
+		{AfterSeconds: 0, Notify: []string{"oncall-primary"}},
+		{AfterSeconds: 900, Notify: []string{"oncall-secondary"}, BumpSeverity: true},
+	},
+}
+
+func setup(t *testing.T, notifier notify.Notifier) (*anomaly.Detector, *Tracker, *time.Time) {
+	t.Helper()
+	detector := anomaly.NewDetector()
+	tracker, err := NewTracker(detector, notifier, testPolicy)
+	if err != nil {
+		t.Fatalf("NewTracker failed: %v", err)
+	}
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+	detector.Subscribe(tracker.HandleEvent)
+	return detector, tracker, &now
+}
+
+func TestUnacknowledgedAnomalyEscalates(t *testing.T) {
+	notifier := &recorder{}
+	detector, tracker, now := setup(t, notifier)
+	detected := *now
+	a := detector.Record(&models.Anomaly{Type: models.AnomalyTypeDAOVoteFailure, Severity: models.SeverityHigh, DetectedAt: detected})[0]
+
+	stored, _ := detector.GetAnomaly(a.ID)
+	if stored.SLA == nil || !stored.SLA.AckDeadline.Equal(detected.Add(10*time.Minute)) {
+		t.Fatalf("Expected SLA with 10m ack deadline, got %+v", stored.SLA)
+	}
+
+	if n, _ := tracker.Check(context.Background()); n != 0 {
+		t.Errorf("Expected no escalation before the deadline, got %d", n)
+	}
+
+	*now = detected.Add(11 * time.Minute)
+	if n, _ := tracker.Check(context.Background()); n != 1 {
+		t.Fatalf("Expected first step, got %d", n)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].Targets[0] != "oncall-primary" {
+		t.Errorf("Expected primary to be notified, got %+v", notifier.sent)
+	}
+	if n, _ := tracker.Check(context.Background()); n != 0 {
+		t.Errorf("Expected steps to be taken once, got %d", n)
+	}
+
+	*now = detected.Add(26 * time.Minute)
+	tracker.Check(context.Background())
+	stored, _ = detector.GetAnomaly(a.ID)
+	if stored.Severity != models.SeverityCritical || stored.SLA.Level != 2 || !stored.SLA.AckBreached {
+		t.Errorf("Expected second step to bump severity, got severity %s and SLA %+v", stored.Severity, stored.SLA)
+	}
+	if e := stored.SLA.Escalations[1]; e.Severity != models.SeverityCritical || e.Notified[0] != "oncall-secondary" {
+		t.Errorf("Unexpected escalation record: %+v", e)
+	}
+	if stored.SLA.Policy != models.SeverityHigh {
+		t.Error("Expected the original policy to stay in force after a bump")
+	}
+}
+
+func TestAcknowledgedAnomalyEscalatesOnlyWhenResolveDeadlinePasses(t *testing.T) {
+	notifier := &recorder{}
+	detector, tracker, now := setup(t, notifier)
+	detected := now.Add(-5 * time.Minute)
+	a := detector.Record(&models.Anomaly{Type: models.AnomalyTypeDAOVoteFailure, Severity: models.SeverityHigh, DetectedAt: detected})[0]
+
+	if err := detector.Acknowledge(a.ID, "alice"); err != nil {
+		t.Fatalf("Acknowledge failed: %v", err)
+	}
+	if err := detector.Acknowledge(a.ID, "bob"); !errors.Is(err, anomaly.ErrAcknowledged) {
+		t.Errorf("Expected ErrAcknowledged, got %v", err)
+	}
+
+	*now = detected.Add(30 * time.Minute)
+	if n, _ := tracker.Check(context.Background()); n != 0 {
+		t.Errorf("Expected acknowledged anomaly not to escalate, got %d", n)
+	}
+
+	*now = detected.Add(61 * time.Minute)
+	tracker.Check(context.Background())
+	stored, _ := detector.GetAnomaly(a.ID)
+	if stored.SLA.AckBreached || !stored.SLA.ResolveBreached || stored.SLA.Level != 1 {
+		t.Errorf("Expected only a resolve breach, got %+v", stored.SLA)
+	}
+}
+
+func TestResolveDeadlineRunsItsOwnSteps(t *testing.T) {
+	notifier := &recorder{}
+	detector, tracker, now := setup(t, notifier)
+	detected := *now
+	a := detector.Record(&models.Anomaly{Type: models.AnomalyTypeDAOVoteFailure, Severity: models.SeverityHigh, DetectedAt: detected})[0]
+
+	// Both steps are used up on the acknowledge deadline
+	*now = detected.Add(26 * time.Minute)
+	if n, _ := tracker.Check(context.Background()); n != 2 {
+		t.Fatalf("Expected both acknowledge steps, got %d", n)
+	}
+	if err := detector.Acknowledge(a.ID, "alice"); err != nil {
+		t.Fatalf("Acknowledge failed: %v", err)
+	}
+
+	// The resolve deadline starts again from the first step
+	*now = detected.Add(61 * time.Minute)
+	if n, _ := tracker.Check(context.Background()); n != 1 {
+		t.Fatalf("Expected the first resolve step, got %d", n)
+	}
+	if last := notifier.sent[len(notifier.sent)-1]; last.Targets[0] != "oncall-primary" {
+		t.Errorf("Expected primary to be notified of the resolve breach, got %v", last.Targets)
+	}
+
+	// and times the next one from the resolve deadline, not the first breach
+	*now = detected.Add(74 * time.Minute)
+	if n, _ := tracker.Check(context.Background()); n != 0 {
+		t.Errorf("Expected the second resolve step to wait, got %d", n)
+	}
+	*now = detected.Add(76 * time.Minute)
+	if n, _ := tracker.Check(context.Background()); n != 1 {
+		t.Errorf("Expected the second resolve step, got %d", n)
+	}
+
+	stored, _ := detector.GetAnomaly(a.ID)
+	sla := stored.SLA
+	if sla.AckLevel != 2 || sla.ResolveLevel != 2 || sla.Level != 4 {
+		t.Errorf("Expected 2 steps per deadline, got %+v", sla)
+	}
+	if e := sla.Escalations[2]; e.Deadline != models.DeadlineResolve || e.Level != 1 || e.Reason != "not resolved within high SLA" {
+		t.Errorf("Unexpected resolve escalation %+v", e)
+	}
+}
+
+func TestNotificationFailuresAreRecorded(t *testing.T) {
+	notifier := &recorder{err: errors.New("pager unreachable")}
+	detector, tracker, now := setup(t, notifier)
+	a := detector.Record(&models.Anomaly{Type: models.AnomalyTypeDAOVoteFailure, Severity: models.SeverityHigh, DetectedAt: now.Add(-11 * time.Minute)})[0]
+
+	tracker.Check(context.Background())
+	stored, _ := detector.GetAnomaly(a.ID)
+	if len(stored.SLA.Escalations) != 1 || stored.SLA.Escalations[0].Error != "pager unreachable" {
+		t.Errorf("Expected failed notification to be recorded, got %+v", stored.SLA.Escalations)
+	}
+}
+
+func TestUntrackedAnomalies(t *testing.T) {
+	detector, _, _ := setup(t, &recorder{})
+	recorded := detector.Record(
+		&models.Anomaly{Type: models.AnomalyTypeUnknown, Severity: models.SeverityLow},
+		&models.Anomaly{Type: models.AnomalyTypeUnknown, Severity: models.SeverityHigh, Suppressed: true},
+	)
+	for _, a := range recorded {
+		if stored, _ := detector.GetAnomaly(a.ID); stored.SLA != nil {
+			t.Errorf("Expected no SLA for %s anomaly (suppressed %v)", a.Severity, a.Suppressed)
+		}
+	}
+}
+
+func TestSLAReport(t *testing.T) {
+	detector, tracker, now := setup(t, &recorder{})
+	late := detector.Record(&models.Anomaly{Type: models.AnomalyTypeUnknown, Severity: models.SeverityHigh, DetectedAt: now.Add(-2 * time.Hour)})[0]
+	onTime := detector.Record(&models.Anomaly{Type: models.AnomalyTypeUnknown, Severity: models.SeverityHigh, DetectedAt: now.Add(-time.Minute)})[0]
+	detector.Acknowledge(onTime.ID, "alice")
+	detector.ResolveAnomaly(late.ID, "fixed", "")
+	tracker.Check(context.Background())
+
+	sla := detector.GenerateReport().SLA
+	if sla == nil || sla.Tracked != 2 || sla.Met != 1 || sla.AckBreached != 1 || sla.ResolveBreached != 1 {
+		t.Fatalf("Unexpected SLA report: %+v", sla)
+	}
+	if sla.Compliance != 0.5 || sla.BySeverity[models.SeverityHigh] != 0.5 {
+		t.Errorf("Expected 50%% compliance, got %+v", sla)
+	}
+}
+
+func TestInvalidPolicies(t *testing.T) {
+	for _, p := range []Policy{
+		{Severity: "urgent", AckWithinSeconds: 60, ResolveWithinSeconds: 120},
+		{Severity: models.SeverityLow, AckWithinSeconds: 120, ResolveWithinSeconds: 60},
+		{Severity: models.SeverityLow, AckWithinSeconds: 60, ResolveWithinSeconds: 120, Steps: []Step{{AfterSeconds: 60}}},
+		{Severity: models.SeverityLow, AckWithinSeconds: 60, ResolveWithinSeconds: 120, Steps: []Step{
+			{AfterSeconds: 60, BumpSeverity: true}, {AfterSeconds: 0, BumpSeverity: true},
+		}},
+	} {
+		if _, err := NewTracker(anomaly.NewDetector(), &recorder{}, p); err == nil {
+			t.Errorf("Expected policy %+v to be rejected", p)
+		}
+	}
+	if _, err := NewTracker(anomaly.NewDetector(), &recorder{}, DefaultPolicies...); err != nil {
+		t.Errorf("Expected default policies to be valid: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{
+	"id", "type", "severity", "status", "suppressed", "detected_at", "acknowledged_at", "resolved_at",
+	"source", "assignee", "fingerprint", "incident_id", "description", "resolution",
+}
+
+func renderCSV(w io.Writer, doc Document) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvHeader)
+	for _, a := range doc.Anomalies {
+		cw.Write([]string{
+			a.ID,
+			string(a.Type),
+			string(a.Severity),
+			string(a.Status),
+			strconv.FormatBool(a.Suppressed),
+			a.DetectedAt.UTC().Format(time.RFC3339),
+			formatTime(a.AcknowledgedAt),
+			formatTime(a.ResolvedAt),
+			csvSafe(a.Source),
+			csvSafe(assignee(a)),
+			a.Fingerprint,
+			a.IncidentID,
+			csvSafe(a.Description),
+			csvSafe(a.Resolution),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// csvSafe stops spreadsheets from evaluating free text as a formula
+func csvSafe(s string) string {
+	if s != "" && (s[0] == '=' || s[0] == '+' || s[0] == '-' || s[0] == '@') {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,296 @@
+// Package export renders anomaly reports as documents. Every format
+// except CSV is drawn from the same sections, so Markdown, HTML and PDF
+// reports carry the same figures; CSV lists the underlying anomalies.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// Format is a document format
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	FormatCSV      Format = "csv"
+	FormatPDF      Format = "pdf"
+)
+
+var contentTypes = map[Format]string{
+	FormatJSON:     "application/json",
+	FormatMarkdown: "text/markdown; charset=utf-8",
+	FormatHTML:     "text/html; charset=utf-8",
+	FormatCSV:      "text/csv; charset=utf-8",
+	FormatPDF:      "application/pdf",
+}
+
+var extensions = map[Format]string{
+	FormatMarkdown: "md",
+	FormatHTML:     "html",
+	FormatCSV:      "csv",
+	FormatPDF:      "pdf",
+}
+
+// ContentType returns the MIME type of f
+func (f Format) ContentType() string {
+	return contentTypes[f]
+}
+
+// Extension returns the file extension for f
+func (f Format) Extension() string {
+	if ext, ok := extensions[f]; ok {
+		return ext
+	}
+	return string(f)
+}
+
+// ParseFormat returns the format named s, accepting "md" for Markdown
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(s))
+	if f == "md" {
+		f = FormatMarkdown
+	}
+	if _, ok := contentTypes[f]; !ok {
+		return "", fmt.Errorf("unsupported format %q", s)
+	}
+	return f, nil
+}
+
+// Negotiate picks the format an Accept header prefers, defaulting to JSON
+func Negotiate(accept string) Format {
+	best, bestQ := FormatJSON, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		for f, ct := range contentTypes {
+			if strings.HasPrefix(ct, mediaType) && q > bestQ {
+				best, bestQ = f, q
+			}
+		}
+	}
+	return best
+}
+
+// Document is everything a report export contains
+type Document struct {
+	Title     string                `json:"title"`
+	Report    *models.AnomalyReport `json:"report"`
+	Trends    *models.TrendReport   `json:"trends,omitempty"`
+	Anomalies []*models.Anomaly     `json:"anomalies"`
+}
+
+// Render writes doc to w in format f. JSON encodes the document as is.
+func Render(w io.Writer, f Format, doc Document) error {
+	switch f {
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(doc)
+	case FormatMarkdown:
+		return renderMarkdown(w, doc)
+	case FormatHTML:
+		return renderHTML(w, doc)
+	case FormatCSV:
+		return renderCSV(w, doc)
+	case FormatPDF:
+		return renderPDF(w, doc)
+	default:
+		return fmt.Errorf("unsupported format %q", f)
+	}
+}
+
+// Table is a header row with data rows
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Series is one named set of values in a chart
+type Series struct {
+	Name   string
+	Values []float64
+}
+
+// Chart is a bar chart with one bar per series for each label
+type Chart struct {
+	Labels []string
+	Series []Series
+}
+
+// Section is one titled part of a report
+type Section struct {
+	Title string
+	Text  string
+	Table *Table
+	Chart *Chart
+}
+
+// severityOrder lists severities from most to least severe
+var severityOrder = []models.AnomalySeverity{
+	models.SeverityCritical, models.SeverityHigh, models.SeverityMedium, models.SeverityLow,
+}
+
+// Sections returns the parts of doc shared by every document format
+func (doc Document) Sections() []Section {
+	r := doc.Report
+	sections := []Section{{
+		Title: "Summary",
+		Table: &Table{
+			Header: []string{"Total", "Resolved", "Pending", "Suppressed"},
+			Rows: [][]string{{
+				strconv.Itoa(r.TotalAnomalies),
+				strconv.Itoa(r.ResolvedAnomalies),
+				strconv.Itoa(r.PendingAnomalies),
+				strconv.Itoa(r.SuppressedAnomalies),
+			}},
+		},
+	}}
+
+	severities := Section{Title: "By Severity", Table: &Table{Header: []string{"Severity", "Anomalies"}}, Chart: &Chart{Series: []Series{{Name: "Anomalies"}}}}
+	for _, s := range severityOrder {
+		severities.Table.Rows = append(severities.Table.Rows, []string{string(s), strconv.Itoa(r.BySeverity[s])})
+		severities.Chart.Labels = append(severities.Chart.Labels, string(s))
+		severities.Chart.Series[0].Values = append(severities.Chart.Series[0].Values, float64(r.BySeverity[s]))
+	}
+	sections = append(sections, severities)
+
+	types := make([]string, 0, len(r.ByType))
+	for t := range r.ByType {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+	byType := Section{Title: "By Type", Table: &Table{Header: []string{"Type", "Anomalies"}}, Chart: &Chart{Series: []Series{{Name: "Anomalies"}}}}
+	for _, t := range types {
+		n := r.ByType[models.AnomalyType(t)]
+		byType.Table.Rows = append(byType.Table.Rows, []string{t, strconv.Itoa(n)})
+		byType.Chart.Labels = append(byType.Chart.Labels, t)
+		byType.Chart.Series[0].Values = append(byType.Chart.Series[0].Values, float64(n))
+	}
+	sections = append(sections, byType)
+
+	if r.SLA != nil {
+		sla := Section{
+			Title: "SLA Compliance",
+			Text: fmt.Sprintf("%d of %d tracked anomalies met their SLA (%s). %d missed the acknowledge deadline and %d the resolve deadline.",
+				r.SLA.Met, r.SLA.Tracked, percent(r.SLA.Compliance), r.SLA.AckBreached, r.SLA.ResolveBreached),
+			Table: &Table{Header: []string{"Policy", "Compliance"}},
+		}
+		for _, s := range severityOrder {
+			if c, ok := r.SLA.BySeverity[s]; ok {
+				sla.Table.Rows = append(sla.Table.Rows, []string{string(s), percent(c)})
+			}
+		}
+		sections = append(sections, sla)
+	}
+
+	if t := doc.Trends; t != nil {
+		sections = append(sections, trendSections(t)...)
+	}
+
+	list := Section{Title: "Anomalies", Table: &Table{Header: []string{"Detected", "Type", "Severity", "Status", "Assignee", "Description"}}}
+	for _, a := range doc.Anomalies {
+		list.Table.Rows = append(list.Table.Rows, []string{
+			a.DetectedAt.UTC().Format("2006-01-02 15:04"),
+			string(a.Type),
+			string(a.Severity),
+			status(a),
+			assignee(a),
+			a.Description,
+		})
+	}
+	return append(sections, list)
+}
+
+func trendSections(t *models.TrendReport) []Section {
+	cur, prev := t.Current, t.Previous
+	change := fmt.Sprintf("%+d", t.Change.New)
+	if t.Change.NewPercent != nil {
+		change += fmt.Sprintf(" (%+.1f%%)", *t.Change.NewPercent)
+	}
+
+	trend := Section{
+		Title: "Trend",
+		Text: fmt.Sprintf("%s to %s compared with the previous %s.",
+			cur.From.UTC().Format("2006-01-02"), cur.To.UTC().Format("2006-01-02"), cur.To.Sub(cur.From).Round(time.Hour)),
+		Table: &Table{
+			Header: []string{"", "New", "Resolved", "MTTA", "MTTR", "Regression rate"},
+			Rows: [][]string{
+				{"Current", strconv.Itoa(cur.New), strconv.Itoa(cur.Resolved), duration(cur.MTTA.Mean), duration(cur.MTTR.Mean), percent(cur.RegressionRate)},
+				{"Previous", strconv.Itoa(prev.New), strconv.Itoa(prev.Resolved), duration(prev.MTTA.Mean), duration(prev.MTTR.Mean), percent(prev.RegressionRate)},
+				{"Change", change, fmt.Sprintf("%+d", t.Change.Resolved), signedDuration(t.Change.MTTA), signedDuration(t.Change.MTTR), fmt.Sprintf("%+.1f pts", t.Change.RegressionRate*100)},
+			},
+		},
+		Chart: &Chart{Series: []Series{{Name: "New"}, {Name: "Resolved"}}},
+	}
+	layout := "01-02"
+	for _, b := range t.Buckets {
+		trend.Chart.Labels = append(trend.Chart.Labels, b.Start.UTC().Format(layout))
+		trend.Chart.Series[0].Values = append(trend.Chart.Series[0].Values, float64(b.New))
+		trend.Chart.Series[1].Values = append(trend.Chart.Series[1].Values, float64(b.Resolved))
+	}
+	sections := []Section{trend}
+
+	if len(t.TopFingerprints) > 0 {
+		recurring := Section{Title: "Recurring Problems", Table: &Table{Header: []string{"Fingerprint", "Type", "Count", "Last seen"}}}
+		for _, f := range t.TopFingerprints {
+			recurring.Table.Rows = append(recurring.Table.Rows, []string{
+				f.Fingerprint, string(f.Type), strconv.Itoa(f.Count), f.LastSeen.UTC().Format("2006-01-02 15:04"),
+			})
+		}
+		sections = append(sections, recurring)
+	}
+	return sections
+}
+
+func status(a *models.Anomaly) string {
+	if a.Suppressed && a.Status != models.StatusResolved {
+		return "suppressed"
+	}
+	return string(a.Status)
+}
+
+func assignee(a *models.Anomaly) string {
+	if a.Assignee == nil {
+		return ""
+	}
+	return a.Assignee.Name
+}
+
+func percent(ratio float64) string {
+	return strconv.FormatFloat(ratio*100, 'f', 1, 64) + "%"
+}
+
+// duration formats seconds for people, e.g. "1h30m"
+func duration(seconds float64) string {
+	if seconds == 0 {
+		return "-"
+	}
+	return (time.Duration(seconds) * time.Second).Round(time.Second).String()
+}
+
+func signedDuration(seconds float64) string {
+	if seconds < 0 {
+		return "-" + duration(-seconds)
+	}
+	if seconds == 0 {
+		return "0s"
+	}
+	return "+" + duration(seconds)
+}
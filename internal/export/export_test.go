@@ -0,0 +1,128 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+func testDocument() Document {
+	detected := time.Date(2026, 2, 18, 22, 0, 0, 0, time.UTC)
+	resolved := detected.Add(30 * time.Minute)
+	return Document{
+		Title: "Anomaly Report",
+		Report: &models.AnomalyReport{
+			TotalAnomalies:    2,
+			ResolvedAnomalies: 1,
+			PendingAnomalies:  1,
+			BySeverity:        map[models.AnomalySeverity]int{models.SeverityCritical: 1, models.SeverityLow: 1},
+			ByType:            map[models.AnomalyType]int{models.AnomalyTypeLedgerDivergence: 1, models.AnomalyTypeCommitAnomaly: 1},
+			SLA:               &models.SLAReport{Tracked: 1, Met: 1, Compliance: 1, BySeverity: map[models.AnomalySeverity]float64{models.SeverityCritical: 1}},
+			GeneratedAt:       detected.Add(time.Hour),
+		},
+		Trends: &models.TrendReport{
+			Bucket:  models.BucketDay,
+			Current: models.PeriodSummary{From: detected.AddDate(0, 0, -1), To: detected.AddDate(0, 0, 1), New: 2, Resolved: 1, MTTR: models.DurationStats{Count: 1, Mean: 1800}},
+			Buckets: []models.TrendBucket{{Start: detected.AddDate(0, 0, -1), New: 0}, {Start: detected, New: 2, Resolved: 1}},
+		},
+		Anomalies: []*models.Anomaly{
+			{ID: "a1", Type: models.AnomalyTypeLedgerDivergence, Severity: models.SeverityCritical, Status: models.StatusResolved, DetectedAt: detected, ResolvedAt: &resolved, Description: "Hashes | diverge <on> Mars"},
+			{ID: "a2", Type: models.AnomalyTypeCommitAnomaly, Severity: models.SeverityLow, Status: models.StatusDetected, DetectedAt: detected, Source: "=HYPERLINK(\"x\")", Description: "Ünïcode commit"},
+		},
+	}
+}
+
+func render(t *testing.T, f Format) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Render(&buf, f, testDocument()); err != nil {
+		t.Fatalf("Render %s failed: %v", f, err)
+	}
+	return buf.String()
+}
+
+func TestMarkdown(t *testing.T) {
+	md := render(t, FormatMarkdown)
+	for _, want := range []string{"# Anomaly Report", "## By Severity", "| critical | 1 |", "## SLA Compliance", "| Current | 2 | 1 | - | 30m0s |", `Hashes \| diverge`} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected Markdown to contain %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	page := render(t, FormatHTML)
+	if strings.Count(page, "<svg") != 3 {
+		t.Errorf("Expected severity, type and trend charts, got %d", strings.Count(page, "<svg"))
+	}
+	if strings.Contains(page, "<on>") || !strings.Contains(page, "&lt;on&gt;") {
+		t.Error("Expected anomaly text to be escaped")
+	}
+	if strings.Contains(page, "<link") || strings.Contains(page, "<script") {
+		t.Error("Expected a self-contained page")
+	}
+}
+
+func TestCSV(t *testing.T) {
+	records, err := csv.NewReader(strings.NewReader(render(t, FormatCSV))).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if len(records) != 3 || records[0][0] != "id" || records[1][7] != "2026-02-18T22:30:00Z" {
+		t.Fatalf("Unexpected CSV: %v", records)
+	}
+	if source := records[2][8]; source != `'=HYPERLINK("x")` {
+		t.Errorf("Expected formula to be neutralised, got %q", source)
+	}
+}
+
+func TestPDF(t *testing.T) {
+	pdf := render(t, FormatPDF)
+	if !strings.HasPrefix(pdf, "%PDF-1.4") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatal("Expected a PDF header and trailer")
+	}
+
+	// Every xref entry must point at the object it numbers
+	m := regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(pdf)
+	xref, _ := strconv.Atoi(m[1])
+	if !strings.HasPrefix(pdf[xref:], "xref") {
+		t.Fatal("startxref does not point at the xref table")
+	}
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(pdf[xref:], -1)
+	for i, o := range offsets {
+		off, _ := strconv.Atoi(o[1])
+		if want := strconv.Itoa(i+1) + " 0 obj"; !strings.HasPrefix(pdf[off:], want) {
+			t.Errorf("Object %d offset points at %q", i+1, pdf[off:off+10])
+		}
+	}
+	if !strings.Contains(pdf, "(SLA Compliance) Tj") || !strings.Contains(pdf, "Hashes | diverge <on> Mars") {
+		t.Error("Expected report text in the content stream")
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	for accept, want := range map[string]Format{
+		"":                                 FormatJSON,
+		"*/*":                              FormatJSON,
+		"application/pdf":                  FormatPDF,
+		"text/html,application/xml;q=0.9":  FormatHTML,
+		"text/csv;q=0.5, text/markdown":    FormatMarkdown,
+		"application/json;q=0.1, text/csv": FormatCSV,
+	} {
+		if got := Negotiate(accept); got != want {
+			t.Errorf("Negotiate(%q) = %s, want %s", accept, got, want)
+		}
+	}
+	if _, err := ParseFormat("docx"); err == nil {
+		t.Error("Expected unsupported format to be rejected")
+	}
+	if f, _ := ParseFormat("md"); f != FormatMarkdown {
+		t.Errorf("Expected md to mean Markdown, got %s", f)
+	}
+}
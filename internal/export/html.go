@@ -0,0 +1,143 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+)
+
+var palette = []string{"#2f6fde", "#e0803a", "#3aa57a", "#c8414b"}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"chart": svgChart,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1d2330; max-width: 960px; margin: 2rem auto; padding: 0 1rem; }
+h1 { margin-bottom: 0.25rem; }
+.generated { color: #6b7280; margin-top: 0; }
+table { border-collapse: collapse; margin: 0.5rem 0 1rem; width: 100%; }
+th, td { border-bottom: 1px solid #e5e7eb; padding: 0.35rem 0.6rem; text-align: left; font-size: 0.9rem; }
+th { background: #f3f4f6; }
+svg { display: block; margin: 0.5rem 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="generated">Generated {{.Generated}}</p>
+{{range .Sections}}
+<section>
+<h2>{{.Title}}</h2>
+{{if .Text}}<p>{{.Text}}</p>{{end}}
+{{if .Chart}}{{chart .Chart}}{{end}}
+{{if .Table}}{{if .Table.Rows}}
+<table>
+<thead><tr>{{range .Table.Header}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Table.Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{else}}<p>None.</p>{{end}}{{end}}
+</section>
+{{end}}
+</body>
+</html>
+`))
+
+func renderHTML(w io.Writer, doc Document) error {
+	return htmlTemplate.Execute(w, map[string]interface{}{
+		"Title":     doc.Title,
+		"Generated": doc.Report.GeneratedAt.UTC().Format("2006-01-02 15:04 MST"),
+		"Sections":  doc.Sections(),
+	})
+}
+
+// svgChart draws c as a grouped bar chart. Labels are escaped here since
+// the result is inserted into the page unescaped.
+func svgChart(c *Chart) template.HTML {
+	const width, height = 640.0, 220.0
+	const left, right, top, bottom = 40.0, 10.0, 10.0, 40.0
+	plotW, plotH := width-left-right, height-top-bottom
+
+	if len(c.Labels) == 0 {
+		return ""
+	}
+	peak := 0.0
+	for _, s := range c.Series {
+		for _, v := range s.Values {
+			peak = math.Max(peak, v)
+		}
+	}
+	scale := niceCeiling(peak)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" role="img" font-size="11" font-family="sans-serif">`, width, height, width, height)
+
+	// Horizontal grid lines with their values
+	for i := 0; i <= 4; i++ {
+		y := top + plotH - plotH*float64(i)/4
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e5e7eb"/>`, left, y, width-right, y)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="end" fill="#6b7280">%s</text>`, left-4, y+4, formatTick(scale*float64(i)/4))
+	}
+
+	group := plotW / float64(len(c.Labels))
+	bar := group * 0.8 / float64(len(c.Series))
+	// Thin out labels so they do not overlap
+	every := int(math.Ceil(float64(len(c.Labels)) * 60 / plotW))
+	for i, label := range c.Labels {
+		x := left + group*float64(i) + group*0.1
+		for j, s := range c.Series {
+			if i >= len(s.Values) {
+				continue
+			}
+			h := 0.0
+			if scale > 0 {
+				h = plotH * s.Values[i] / scale
+			}
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s %s: %s</title></rect>`,
+				x+bar*float64(j), top+plotH-h, math.Max(bar-1, 1), h, palette[j%len(palette)],
+				html.EscapeString(label), html.EscapeString(s.Name), formatTick(s.Values[i]))
+		}
+		if i%every == 0 {
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="#374151">%s</text>`, x+group*0.4, height-bottom+16, html.EscapeString(label))
+		}
+	}
+
+	if len(c.Series) > 1 {
+		for j, s := range c.Series {
+			x := left + float64(j)*100
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="10" height="10" fill="%s"/>`, x, height-14, palette[j%len(palette)])
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" fill="#374151">%s</text>`, x+14, height-5, html.EscapeString(s.Name))
+		}
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// niceCeiling rounds v up to 1, 2 or 5 times a power of ten
+func niceCeiling(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, step := range []float64{1, 2, 5, 10} {
+		if v <= step*magnitude {
+			return step * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+func formatTick(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
@@ -0,0 +1,47 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var markdownCell = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ")
+
+func renderMarkdown(w io.Writer, doc Document) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s\n\nGenerated %s\n", doc.Title, doc.Report.GeneratedAt.UTC().Format("2006-01-02 15:04 MST"))
+
+	for _, s := range doc.Sections() {
+		fmt.Fprintf(bw, "\n## %s\n\n", s.Title)
+		if s.Text != "" {
+			fmt.Fprintf(bw, "%s\n\n", s.Text)
+		}
+		if s.Table == nil {
+			continue
+		}
+		if len(s.Table.Rows) == 0 {
+			fmt.Fprint(bw, "None.\n")
+			continue
+		}
+		writeMarkdownRow(bw, s.Table.Header)
+		separator := make([]string, len(s.Table.Header))
+		for i := range separator {
+			separator[i] = "---"
+		}
+		writeMarkdownRow(bw, separator)
+		for _, row := range s.Table.Rows {
+			writeMarkdownRow(bw, row)
+		}
+	}
+	return bw.Flush()
+}
+
+func writeMarkdownRow(w io.Writer, cells []string) {
+	escaped := make([]string, len(cells))
+	for i, c := range cells {
+		escaped[i] = markdownCell.Replace(c)
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(escaped, " | "))
+}
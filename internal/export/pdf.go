@@ -0,0 +1,306 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// A4 portrait in points
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+	margin     = 50.0
+)
+
+// The standard fonts every PDF reader has, so nothing is embedded
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+	fontMono    = "F3"
+)
+
+var pdfFonts = []struct{ name, base string }{
+	{fontRegular, "Helvetica"},
+	{fontBold, "Helvetica-Bold"},
+	{fontMono, "Courier"},
+}
+
+// monoWidth is the advance of a Courier glyph relative to the font size
+const monoWidth = 0.6
+
+var pdfEscaper = strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`, "\r", " ", "\n", " ")
+
+// pdfDoc lays out text, tables and charts top to bottom on A4 pages
+type pdfDoc struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64
+}
+
+func (p *pdfDoc) newPage() {
+	p.page = &bytes.Buffer{}
+	p.pages = append(p.pages, p.page)
+	p.y = pageHeight - margin
+}
+
+// reserve starts a new page unless h points fit below the cursor
+func (p *pdfDoc) reserve(h float64) {
+	if p.page == nil || p.y-h < margin {
+		p.newPage()
+	}
+}
+
+// line writes one line of text and moves the cursor down
+func (p *pdfDoc) line(font string, size float64, s string) {
+	leading := size * 1.35
+	p.reserve(leading)
+	p.y -= leading
+	fmt.Fprintf(p.page, "BT /%s %.1f Tf %.1f %.1f Td (%s) Tj ET\n", font, size, margin, p.y, pdfString(s))
+}
+
+// paragraph wraps s to the page width. Helvetica glyphs average about
+// half the font size wide.
+func (p *pdfDoc) paragraph(size float64, s string) {
+	perLine := int((pageWidth - 2*margin) / (size * 0.5))
+	for _, l := range wrap(s, perLine) {
+		p.line(fontRegular, size, l)
+	}
+}
+
+func (p *pdfDoc) gap(h float64) {
+	p.y -= h
+}
+
+// table draws t in Courier with columns padded to line up, shrinking the
+// widest columns until the table fits the page
+func (p *pdfDoc) table(t *Table, size float64) {
+	fit := int((pageWidth - 2*margin) / (size * monoWidth))
+	widths := make([]int, len(t.Header))
+	for i, h := range t.Header {
+		widths[i] = len([]rune(h))
+	}
+	for _, row := range t.Rows {
+		for i, c := range row {
+			widths[i] = max(widths[i], len([]rune(c)))
+		}
+	}
+	for total(widths)+2*(len(widths)-1) > fit {
+		widest := 0
+		for i := range widths {
+			if widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= 4 {
+			break
+		}
+		widths[widest]--
+	}
+
+	format := func(cells []string) string {
+		padded := make([]string, len(cells))
+		for i, c := range cells {
+			padded[i] = pad(truncate(c, widths[i]), widths[i])
+		}
+		return strings.TrimRight(strings.Join(padded, "  "), " ")
+	}
+	p.line(fontMono, size, format(t.Header))
+	rule := make([]string, len(widths))
+	for i, w := range widths {
+		rule[i] = strings.Repeat("-", w)
+	}
+	p.line(fontMono, size, strings.Join(rule, "  "))
+	for _, row := range t.Rows {
+		p.line(fontMono, size, format(row))
+	}
+}
+
+// chart draws c as a grouped bar chart
+func (p *pdfDoc) chart(c *Chart) {
+	const height = 110.0
+	if len(c.Labels) == 0 {
+		return
+	}
+	p.reserve(height + 24)
+	bottom := p.y - height
+	plotW := pageWidth - 2*margin
+
+	peak := 0.0
+	for _, s := range c.Series {
+		for _, v := range s.Values {
+			peak = math.Max(peak, v)
+		}
+	}
+	scale := niceCeiling(peak)
+
+	fmt.Fprintf(p.page, "0.85 g %.1f %.1f %.1f 0.5 re f\n", margin, bottom, plotW)
+	fmt.Fprintf(p.page, "0.4 g BT /%s 7 Tf %.1f %.1f Td (%s) Tj ET\n", fontRegular, margin, p.y-6, formatTick(scale))
+
+	group := plotW / float64(len(c.Labels))
+	bar := group * 0.8 / float64(len(c.Series))
+	every := int(math.Ceil(float64(len(c.Labels)) * 40 / plotW))
+	for i, label := range c.Labels {
+		x := margin + group*float64(i) + group*0.1
+		for j, s := range c.Series {
+			if i >= len(s.Values) || s.Values[i] == 0 {
+				continue
+			}
+			r, g, b := rgb(palette[j%len(palette)])
+			h := (height - 10) * s.Values[i] / scale
+			fmt.Fprintf(p.page, "%.3f %.3f %.3f rg %.1f %.1f %.1f %.1f re f\n", r, g, b, x+bar*float64(j), bottom, math.Max(bar-0.5, 0.5), h)
+		}
+		if i%every == 0 {
+			fmt.Fprintf(p.page, "0.2 g BT /%s 7 Tf %.1f %.1f Td (%s) Tj ET\n", fontRegular, x, bottom-10, pdfString(label))
+		}
+	}
+
+	if len(c.Series) > 1 {
+		for j, s := range c.Series {
+			r, g, b := rgb(palette[j%len(palette)])
+			x := margin + float64(j)*80
+			fmt.Fprintf(p.page, "%.3f %.3f %.3f rg %.1f %.1f 7 7 re f\n", r, g, b, x, bottom-22)
+			fmt.Fprintf(p.page, "0.2 g BT /%s 7 Tf %.1f %.1f Td (%s) Tj ET\n", fontRegular, x+10, bottom-21, pdfString(s.Name))
+		}
+	}
+	fmt.Fprint(p.page, "0 g\n")
+	p.y = bottom - 26
+}
+
+// write serialises the pages as a PDF 1.4 file
+func (p *pdfDoc) write(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1 and 2 are the catalog and page tree, fonts follow, then
+	// each page and its content stream
+	firstPage := 3 + len(pdfFonts)
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	fonts := make([]string, len(pdfFonts))
+	for i, f := range pdfFonts {
+		fonts[i] = fmt.Sprintf("/%s %d 0 R", f.name, 3+i)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	for _, f := range pdfFonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.base))
+	}
+	for i, page := range p.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, strings.Join(fonts, " "), firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+func renderPDF(w io.Writer, doc Document) error {
+	p := &pdfDoc{}
+	p.line(fontBold, 18, doc.Title)
+	p.line(fontRegular, 9, "Generated "+doc.Report.GeneratedAt.UTC().Format("2006-01-02 15:04 MST"))
+
+	for _, s := range doc.Sections() {
+		// Keep a title with at least the first lines of its section
+		p.reserve(60)
+		p.gap(10)
+		p.line(fontBold, 13, s.Title)
+		p.gap(2)
+		if s.Text != "" {
+			p.paragraph(10, s.Text)
+			p.gap(4)
+		}
+		if s.Chart != nil {
+			p.gap(4)
+			p.chart(s.Chart)
+		}
+		if s.Table != nil {
+			if len(s.Table.Rows) == 0 {
+				p.line(fontRegular, 10, "None.")
+				continue
+			}
+			p.table(s.Table, 8)
+		}
+	}
+	return p.write(w)
+}
+
+// pdfString encodes s as WinAnsi for the standard fonts, replacing what
+// it cannot represent, and escapes it for a literal string
+func pdfString(s string) string {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < 0x20 || (r > 0x7e && r < 0xa0) || r > 0xff {
+			r = '?'
+		}
+		b = append(b, byte(r))
+	}
+	return pdfEscaper.Replace(string(b))
+}
+
+func wrap(s string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(r[:n])
+	}
+	return string(r[:n-3]) + "..."
+}
+
+func pad(s string, n int) string {
+	return s + strings.Repeat(" ", max(n-len([]rune(s)), 0))
+}
+
+func total(ns []int) int {
+	sum := 0
+	for _, n := range ns {
+		sum += n
+	}
+	return sum
+}
+
+// rgb converts a #rrggbb colour to PDF's 0-1 components
+func rgb(hex string) (r, g, b float64) {
+	var ri, gi, bi int
+	fmt.Sscanf(hex, "#%02x%02x%02x", &ri, &gi, &bi)
+	return float64(ri) / 255, float64(gi) / 255, float64(bi) / 255
+}
@@ -0,0 +1,109 @@
+package incident
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// Rule correlates two anomalies detected within Window of each other when
+// both have one of Types (any type if empty) and, if Keys lists key
+// namespaces such as "node" or "route", they share a key in one of them.
+type Rule struct {
+	Name          string               `json:"name"`
+	Types         []models.AnomalyType `json:"types,omitempty"`
+	Keys          []string             `json:"keys,omitempty"`
+	WindowSeconds int                  `json:"window_seconds"`
+}
+
+// Window returns the rule's time window
+func (r Rule) Window() time.Duration {
+	return time.Duration(r.WindowSeconds) * time.Second
+}
+
+func (r Rule) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("correlation rule name is required")
+	}
+	if r.WindowSeconds <= 0 {
+		return fmt.Errorf("correlation rule %s: window_seconds must be positive", r.Name)
+	}
+	if len(r.Types) == 0 && len(r.Keys) == 0 {
+		return fmt.Errorf("correlation rule %s: types or keys are required", r.Name)
+	}
+	return nil
+}
+
+// Config controls how anomalies are correlated
+type Config struct {
+	// Fields maps metadata keys to the key namespace their values are
+	// compared in, so "nodes_affected" and "nodes" both yield node keys
+	Fields map[string]string
+	Rules  []Rule
+	// CausalOrder lists types that tend to cause the types after them;
+	// earlier types rank higher as root-cause candidates
+	CausalOrder []models.AnomalyType
+}
+
+// DefaultFields are the metadata keys correlated out of the box
+var DefaultFields = map[string]string{
+	"nodes_affected": "node",
+	"nodes":          "node",
+	"node":           "node",
+	"affected_route": "route",
+	"route":          "route",
+	"proposal_id":    "proposal",
+}
+
+// DefaultRules correlates anomalies that share a node, route or proposal,
+// and the symptoms of a planetary partition even without shared keys
+func DefaultRules(window time.Duration) []Rule {
+	seconds := int(window / time.Second)
+	return []Rule{
+		{
+			Name:          "shared-resource",
+			Keys:          []string{"node", "route", "proposal"},
+			WindowSeconds: seconds,
+		},
+		{
+			Name: "planetary-partition",
+			Types: []models.AnomalyType{
+				models.AnomalyTypeNodeDesynchronization,
+				models.AnomalyTypeLedgerDivergence,
+				models.AnomalyTypeDAOVoteFailure,
+			},
+			WindowSeconds: seconds,
+		},
+	}
+}
+
+// DefaultConfig correlates with the default fields and rules
+func DefaultConfig(window time.Duration) Config {
+	return Config{
+		Fields: DefaultFields,
+		Rules:  DefaultRules(window),
+		CausalOrder: []models.AnomalyType{
+			models.AnomalyTypeNodeDesynchronization,
+			models.AnomalyTypeLedgerDivergence,
+			models.AnomalyTypeDAOVoteFailure,
+			models.AnomalyTypeCommitAnomaly,
+		},
+	}
+}
+
+// LoadRulesFile reads correlation rules from a JSON array
+func LoadRulesFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read correlation rules: %w", err)
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid correlation rules file %s: %w", path, err)
+	}
+	return rules, nil
+}
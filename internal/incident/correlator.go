@@ -0,0 +1,557 @@
+// Package incident correlates related anomalies into incidents: anomalies
+// that share nodes, routes or proposals in their metadata, or that match
+// a correlation rule, within a time window are grouped so a planetary
+// partition shows up as one incident with ranked root-cause candidates
+// instead of unrelated alerts.
+package incident
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotFound is returned for an unknown incident ID
+	ErrNotFound = errors.New("incident not found")
+	// ErrResolved is returned when changing a resolved incident
+	ErrResolved = errors.New("incident is already resolved")
+	// ErrNotMember is returned when marking a root cause outside the incident
+	ErrNotMember = errors.New("anomaly is not part of the incident")
+)
+
+// severityRank orders severities from least to most severe
+var severityRank = map[models.AnomalySeverity]int{
+	models.SeverityLow:      1,
+	models.SeverityMedium:   2,
+	models.SeverityHigh:     3,
+	models.SeverityCritical: 4,
+}
+
+// ResolveFunc resolves one member anomaly and returns its resulting
+// status, e.g. "resolved" or "pending_approval"
+type ResolveFunc func(anomalyID, resolution string) (string, error)
+
+// MemberResult is the outcome of resolving one member of an incident
+type MemberResult struct {
+	AnomalyID string `json:"anomaly_id"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// member is what the correlator remembers about an anomaly
+type member struct {
+	id       string
+	typ      models.AnomalyType
+	severity models.AnomalySeverity
+	at       time.Time
+	keys     map[string]bool
+	resolved bool
+}
+
+type state struct {
+	incident models.Incident
+	members  []*member
+	rules    map[string]bool
+	keys     map[string]bool
+}
+
+// Correlator groups anomalies recorded by the detector into incidents
+type Correlator struct {
+	detector  *anomaly.Detector
+	cfg       Config
+	incidents map[string]*state
+	order     []string
+	byAnomaly map[string]string
+	// loose holds recent anomalies not yet in an incident
+	loose     []*member
+	maxWindow time.Duration
+	mu        sync.Mutex
+}
+
+// NewCorrelator creates a correlator; subscribe HandleEvent to the
+// detector to feed it
+func NewCorrelator(detector *anomaly.Detector, cfg Config) (*Correlator, error) {
+	c := &Correlator{
+		detector:  detector,
+		cfg:       cfg,
+		incidents: make(map[string]*state),
+		byAnomaly: make(map[string]string),
+	}
+	for _, rule := range cfg.Rules {
+		if err := rule.validate(); err != nil {
+			return nil, err
+		}
+		if w := rule.Window(); w > c.maxWindow {
+			c.maxWindow = w
+		}
+	}
+	return c, nil
+}
+
+// HandleEvent correlates newly detected anomalies and resolves incidents
+// whose members have all been resolved
+func (c *Correlator) HandleEvent(event anomaly.Event) {
+	switch event.Type {
+	case anomaly.EventDetected:
+		if event.Anomaly.Status != models.StatusResolved {
+			c.correlate(event.Anomaly)
+		}
+	case anomaly.EventResolved:
+		c.memberResolved(event.Anomaly)
+	}
+}
+
+// Incidents returns incidents newest first, optionally only those with
+// status
+func (c *Correlator) Incidents(status models.IncidentStatus) []models.Incident {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	incidents := make([]models.Incident, 0, len(c.order))
+	for i := len(c.order) - 1; i >= 0; i-- {
+		s := c.incidents[c.order[i]]
+		if status == "" || s.incident.Status == status {
+			incidents = append(incidents, snapshot(s))
+		}
+	}
+	return incidents
+}
+
+// Get returns one incident
+func (c *Correlator) Get(id string) (models.Incident, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.incidents[id]
+	if !ok {
+		return models.Incident{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return snapshot(s), nil
+}
+
+// MarkRootCause confirms a member anomaly as the incident's root cause
+func (c *Correlator) MarkRootCause(id, anomalyID, markedBy string) (models.Incident, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.incidents[id]
+	if !ok {
+		return models.Incident{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if c.byAnomaly[anomalyID] != id {
+		return models.Incident{}, fmt.Errorf("%w: %s", ErrNotMember, anomalyID)
+	}
+	s.incident.RootCause = anomalyID
+	s.incident.ConfirmedBy = markedBy
+	s.incident.UpdatedAt = time.Now()
+	return snapshot(s), nil
+}
+
+// Resolve resolves every unresolved member of an incident with resolve,
+// or directly on the detector if resolve is nil. The incident itself is
+// resolved once all members are.
+func (c *Correlator) Resolve(id, resolution string, resolve ResolveFunc) ([]MemberResult, error) {
+	if resolve == nil {
+		resolve = func(anomalyID, resolution string) (string, error) {
+			if err := c.detector.ResolveAnomaly(anomalyID, resolution, ""); err != nil {
+				return "", err
+			}
+			return string(models.StatusResolved), nil
+		}
+	}
+
+	c.mu.Lock()
+	s, ok := c.incidents[id]
+	if !ok {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if s.incident.Status == models.IncidentResolved {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrResolved, id)
+	}
+	s.incident.Resolution = resolution
+	var pending []string
+	for _, m := range s.members {
+		if !m.resolved {
+			pending = append(pending, m.id)
+		}
+	}
+	c.mu.Unlock()
+
+	// Resolve outside the lock: resolution events call back into HandleEvent
+	results := make([]MemberResult, 0, len(pending))
+	for _, anomalyID := range pending {
+		status, err := resolve(anomalyID, resolution)
+		result := MemberResult{AnomalyID: anomalyID, Status: status}
+		if err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// correlate adds a to an incident if it matches one or more recent
+// anomalies, merging incidents it bridges
+func (c *Correlator) correlate(a models.Anomaly) {
+	m := &member{
+		id:       a.ID,
+		typ:      a.Type,
+		severity: a.Severity,
+		at:       a.DetectedAt,
+		keys:     c.keys(a.Metadata),
+	}
+
+	c.mu.Lock()
+
+	matchedRules := make(map[string]bool)
+	shared := make(map[string]bool)
+	var matched []*state
+	for _, id := range c.order {
+		s := c.incidents[id]
+		if s.incident.Status != models.IncidentOpen {
+			continue
+		}
+		hit := false
+		for _, other := range s.members {
+			if c.match(m, other, matchedRules, shared) {
+				hit = true
+			}
+		}
+		if hit {
+			matched = append(matched, s)
+		}
+	}
+
+	c.trimLoose(m.at)
+	var joined []*member
+	remaining := c.loose[:0]
+	for _, other := range c.loose {
+		if c.match(m, other, matchedRules, shared) {
+			joined = append(joined, other)
+		} else {
+			remaining = append(remaining, other)
+		}
+	}
+	c.loose = remaining
+
+	if len(matched) == 0 && len(joined) == 0 {
+		c.loose = append(c.loose, m)
+		c.mu.Unlock()
+		return
+	}
+
+	// Join the oldest matching incident and fold the others into it
+	var target *state
+	if len(matched) > 0 {
+		target = matched[0]
+		for _, other := range matched[1:] {
+			c.merge(target, other)
+		}
+	} else {
+		now := time.Now()
+		target = &state{
+			incident: models.Incident{
+				ID:       uuid.New().String(),
+				Status:   models.IncidentOpen,
+				OpenedAt: now,
+			},
+			rules: make(map[string]bool),
+			keys:  make(map[string]bool),
+		}
+		c.incidents[target.incident.ID] = target
+		c.order = append(c.order, target.incident.ID)
+	}
+
+	added := append(joined, m)
+	target.members = append(target.members, added...)
+	for rule := range matchedRules {
+		target.rules[rule] = true
+	}
+	for key := range shared {
+		target.keys[key] = true
+	}
+	c.refresh(target)
+
+	// Every member needs its incident ID updated after a merge
+	incidentID := target.incident.ID
+	var update []string
+	for _, m := range target.members {
+		if c.byAnomaly[m.id] != incidentID {
+			c.byAnomaly[m.id] = incidentID
+			update = append(update, m.id)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, anomalyID := range update {
+		c.detector.Update(anomalyID, func(a *models.Anomaly) {
+			a.IncidentID = incidentID
+		})
+	}
+}
+
+// match reports whether any rule correlates a and b, recording the rules
+// and keys that did; callers hold c.mu
+func (c *Correlator) match(a, b *member, rules, keys map[string]bool) bool {
+	gap := a.at.Sub(b.at)
+	if gap < 0 {
+		gap = -gap
+	}
+
+	hit := false
+	for _, rule := range c.cfg.Rules {
+		if gap > rule.Window() {
+			continue
+		}
+		if len(rule.Types) > 0 && !(hasType(rule.Types, a.typ) && hasType(rule.Types, b.typ)) {
+			continue
+		}
+		if len(rule.Keys) > 0 {
+			shared := sharedKeys(a, b, rule.Keys)
+			if len(shared) == 0 {
+				continue
+			}
+			for _, key := range shared {
+				keys[key] = true
+			}
+		}
+		rules[rule.Name] = true
+		hit = true
+	}
+	return hit
+}
+
+// merge moves src's members into dst and drops src; callers hold c.mu
+func (c *Correlator) merge(dst, src *state) {
+	dst.members = append(dst.members, src.members...)
+	for rule := range src.rules {
+		dst.rules[rule] = true
+	}
+	for key := range src.keys {
+		dst.keys[key] = true
+	}
+	if src.incident.OpenedAt.Before(dst.incident.OpenedAt) {
+		dst.incident.OpenedAt = src.incident.OpenedAt
+	}
+
+	delete(c.incidents, src.incident.ID)
+	for i, id := range c.order {
+		if id == src.incident.ID {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// refresh recomputes an incident's summary fields; callers hold c.mu
+func (c *Correlator) refresh(s *state) {
+	sort.Slice(s.members, func(i, j int) bool {
+		return s.members[i].at.Before(s.members[j].at)
+	})
+
+	inc := &s.incident
+	inc.Anomalies = make([]string, 0, len(s.members))
+	inc.Severity = ""
+	var types []string
+	seen := make(map[models.AnomalyType]bool)
+	for _, m := range s.members {
+		inc.Anomalies = append(inc.Anomalies, m.id)
+		if severityRank[m.severity] > severityRank[inc.Severity] {
+			inc.Severity = m.severity
+		}
+		if !seen[m.typ] {
+			seen[m.typ] = true
+			types = append(types, string(m.typ))
+		}
+	}
+	inc.Rules = sortedSet(s.rules)
+	inc.Keys = sortedSet(s.keys)
+	inc.Candidates = c.rank(s.members)
+	if inc.ConfirmedBy == "" {
+		inc.RootCause = inc.Candidates[0].AnomalyID
+	}
+	inc.UpdatedAt = time.Now()
+
+	inc.Title = strings.Join(types, ", ")
+	if len(inc.Keys) > 0 {
+		inc.Title += " on " + strings.Join(inc.Keys, ", ")
+	}
+}
+
+// rank scores members as root-cause candidates: types earlier in the
+// causal order and anomalies detected earlier score higher
+func (c *Correlator) rank(members []*member) []models.RootCauseCandidate {
+	first, last := members[0].at, members[len(members)-1].at
+	span := last.Sub(first)
+
+	candidates := make([]models.RootCauseCandidate, 0, len(members))
+	for _, m := range members {
+		causal := 0.0
+		position := len(c.cfg.CausalOrder)
+		for i, typ := range c.cfg.CausalOrder {
+			if typ == m.typ {
+				causal = 1 - float64(i)/float64(len(c.cfg.CausalOrder))
+				position = i
+				break
+			}
+		}
+		earliness := 1.0
+		if span > 0 {
+			earliness = 1 - float64(m.at.Sub(first))/float64(span)
+		}
+
+		var reasons []string
+		if m.at.Equal(first) {
+			reasons = append(reasons, "detected first")
+		}
+		if position < len(c.cfg.CausalOrder) {
+			reasons = append(reasons, fmt.Sprintf("%s ranks %d in causal order", m.typ, position+1))
+		}
+		if len(reasons) == 0 {
+			reasons = append(reasons, "detected after other members")
+		}
+
+		candidates = append(candidates, models.RootCauseCandidate{
+			AnomalyID: m.id,
+			Type:      m.typ,
+			Score:     math.Round((0.6*causal+0.4*earliness)*1000) / 1000,
+			Reason:    strings.Join(reasons, "; "),
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
+
+// memberResolved marks a member resolved and resolves its incident once
+// every member is
+func (c *Correlator) memberResolved(a models.Anomaly) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, m := range c.loose {
+		if m.id == a.ID {
+			c.loose = append(c.loose[:i], c.loose[i+1:]...)
+			break
+		}
+	}
+
+	s, ok := c.incidents[c.byAnomaly[a.ID]]
+	if !ok || s.incident.Status == models.IncidentResolved {
+		return
+	}
+	open := false
+	for _, m := range s.members {
+		if m.id == a.ID {
+			m.resolved = true
+		}
+		open = open || !m.resolved
+	}
+	if open {
+		return
+	}
+
+	now := time.Now()
+	s.incident.Status = models.IncidentResolved
+	s.incident.ResolvedAt = &now
+	s.incident.UpdatedAt = now
+	if s.incident.Resolution == "" {
+		s.incident.Resolution = "All member anomalies resolved"
+	}
+}
+
+// trimLoose forgets unincidented anomalies too old to correlate with one
+// detected at; callers hold c.mu
+func (c *Correlator) trimLoose(at time.Time) {
+	kept := c.loose[:0]
+	for _, m := range c.loose {
+		if at.Sub(m.at) <= c.maxWindow {
+			kept = append(kept, m)
+		}
+	}
+	c.loose = kept
+}
+
+// keys extracts namespaced correlation keys such as "node:mars-node-1"
+// from metadata
+func (c *Correlator) keys(metadata map[string]interface{}) map[string]bool {
+	keys := make(map[string]bool)
+	for field, namespace := range c.cfg.Fields {
+		var values []string
+		switch v := metadata[field].(type) {
+		case string:
+			values = []string{v}
+		case []string:
+			values = v
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					values = append(values, s)
+				}
+			}
+		}
+		for _, value := range values {
+			if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+				keys[namespace+":"+value] = true
+			}
+		}
+	}
+	return keys
+}
+
+func sharedKeys(a, b *member, namespaces []string) []string {
+	var shared []string
+	for key := range a.keys {
+		if !b.keys[key] {
+			continue
+		}
+		for _, ns := range namespaces {
+			if strings.HasPrefix(key, ns+":") {
+				shared = append(shared, key)
+				break
+			}
+		}
+	}
+	return shared
+}
+
+func hasType(types []models.AnomalyType, t models.AnomalyType) bool {
+	for _, candidate := range types {
+		if candidate == t {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedSet(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// snapshot copies an incident so callers can read it without the lock
+func snapshot(s *state) models.Incident {
+	inc := s.incident
+	inc.Anomalies = append([]string(nil), inc.Anomalies...)
+	inc.Rules = append([]string(nil), inc.Rules...)
+	inc.Keys = append([]string(nil), inc.Keys...)
+	inc.Candidates = append([]models.RootCauseCandidate(nil), inc.Candidates...)
+	return inc
+}
@@ -0,0 +1,207 @@
+package incident
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+func newCorrelator(t *testing.T, cfg Config) (*anomaly.Detector, *Correlator) {
+	t.Helper()
+	detector := anomaly.NewDetector()
+	c, err := NewCorrelator(detector, cfg)
+	if err != nil {
+		t.Fatalf("NewCorrelator failed: %v", err)
+	}
+	detector.Subscribe(c.HandleEvent)
+	return detector, c
+}
+
+func TestMarsPartitionBecomesOneIncident(t *testing.T) {
+	detector, c := newCorrelator(t, DefaultConfig(10*time.Minute))
+	start := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	recorded := detector.Record(
+		&models.Anomaly{
+			Type:       models.AnomalyTypeLedgerDivergence,
+			Severity:   models.SeverityCritical,
+			DetectedAt: start.Add(2 * time.Minute),
+			Metadata:   map[string]interface{}{"nodes_affected": []string{"earth-node-1", "mars-node-1"}},
+		},
+		&models.Anomaly{
+			Type:       models.AnomalyTypeNodeDesynchronization,
+			Severity:   models.SeverityLow,
+			DetectedAt: start,
+			Metadata:   map[string]interface{}{"affected_route": "Earth-Mars"},
+		},
+		&models.Anomaly{
+			Type:       models.AnomalyTypeDAOVoteFailure,
+			Severity:   models.SeverityHigh,
+			DetectedAt: start.Add(4 * time.Minute),
+			Metadata:   map[string]interface{}{"proposal_id": "PROP-2026-001"},
+		},
+		// Shares a node with the divergence but is outside the window
+		&models.Anomaly{
+			Type:       models.AnomalyTypeCommitAnomaly,
+			Severity:   models.SeverityMedium,
+			DetectedAt: start.Add(time.Hour),
+			Metadata:   map[string]interface{}{"commit_hash": "a3f5b2c1", "node": "mars-node-1"},
+		},
+	)
+
+	incidents := c.Incidents("")
+	if len(incidents) != 1 {
+		t.Fatalf("Expected one incident, got %d", len(incidents))
+	}
+	inc := incidents[0]
+	if len(inc.Anomalies) != 3 || inc.Severity != models.SeverityCritical || inc.Status != models.IncidentOpen {
+		t.Errorf("Unexpected incident: %+v", inc)
+	}
+	if inc.RootCause != recorded[1].ID || inc.Candidates[0].Type != models.AnomalyTypeNodeDesynchronization {
+		t.Errorf("Expected the earlier node desync to be the root cause, got %+v", inc.Candidates)
+	}
+	if len(inc.Rules) != 1 || inc.Rules[0] != "planetary-partition" {
+		t.Errorf("Expected partition rule to correlate, got %v", inc.Rules)
+	}
+
+	for _, a := range recorded[:3] {
+		stored, _ := detector.GetAnomaly(a.ID)
+		if stored.IncidentID != inc.ID {
+			t.Errorf("Expected anomaly %s to reference incident %s, got %q", a.ID, inc.ID, stored.IncidentID)
+		}
+	}
+	if stored, _ := detector.GetAnomaly(recorded[3].ID); stored.IncidentID != "" {
+		t.Error("Expected late commit anomaly to stay out of the incident")
+	}
+}
+
+func TestSharedKeysCorrelateAndMergeIncidents(t *testing.T) {
+	cfg := DefaultConfig(10 * time.Minute)
+	cfg.Rules = cfg.Rules[:1]
+	detector, c := newCorrelator(t, cfg)
+	now := time.Now()
+
+	record := func(node string, offset time.Duration) {
+		detector.Record(&models.Anomaly{
+			Type:       models.AnomalyTypeUnknown,
+			Severity:   models.SeverityLow,
+			DetectedAt: now.Add(offset),
+			Metadata:   map[string]interface{}{"nodes": []interface{}{node}},
+		})
+	}
+	record("moon-node-2", 0)
+	record("mars-node-1", time.Second)
+	record("Moon-Node-2", 2*time.Second)
+	record("mars-node-1", 3*time.Second)
+	if n := len(c.Incidents("")); n != 2 {
+		t.Fatalf("Expected two incidents, got %d", n)
+	}
+
+	// A bridging anomaly folds both incidents into one
+	detector.Record(&models.Anomaly{
+		Type:       models.AnomalyTypeUnknown,
+		Severity:   models.SeverityMedium,
+		DetectedAt: now.Add(4 * time.Second),
+		Metadata:   map[string]interface{}{"nodes_affected": []string{"moon-node-2", "mars-node-1"}},
+	})
+	incidents := c.Incidents("")
+	if len(incidents) != 1 || len(incidents[0].Anomalies) != 5 {
+		t.Fatalf("Expected one merged incident of 5, got %+v", incidents)
+	}
+	want := []string{"node:mars-node-1", "node:moon-node-2"}
+	if keys := incidents[0].Keys; len(keys) != 2 || keys[0] != want[0] || keys[1] != want[1] {
+		t.Errorf("Expected shared keys %v, got %v", want, keys)
+	}
+	for _, id := range incidents[0].Anomalies {
+		if a, _ := detector.GetAnomaly(id); a.IncidentID != incidents[0].ID {
+			t.Errorf("Expected %s to be moved to the merged incident", id)
+		}
+	}
+}
+
+func TestResolveCascadesToMembers(t *testing.T) {
+	detector, c := newCorrelator(t, DefaultConfig(10*time.Minute))
+	detector.Record(
+		&models.Anomaly{Type: models.AnomalyTypeNodeDesynchronization, Severity: models.SeverityLow, Metadata: map[string]interface{}{"route": "Earth-Mars"}},
+		&models.Anomaly{Type: models.AnomalyTypeLedgerDivergence, Severity: models.SeverityCritical, Metadata: map[string]interface{}{"route": "Earth-Mars"}},
+	)
+	inc := c.Incidents(models.IncidentOpen)[0]
+
+	results, err := c.Resolve(inc.ID, "Restored Earth-Mars relay", nil)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Status != "resolved" {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+	for _, id := range inc.Anomalies {
+		if a, _ := detector.GetAnomaly(id); a.Status != models.StatusResolved || a.Resolution != "Restored Earth-Mars relay" {
+			t.Errorf("Expected member %s to be resolved, got %+v", id, a)
+		}
+	}
+
+	resolved, _ := c.Get(inc.ID)
+	if resolved.Status != models.IncidentResolved || resolved.ResolvedAt == nil {
+		t.Errorf("Expected incident to be resolved, got %+v", resolved)
+	}
+	if _, err := c.Resolve(inc.ID, "again", nil); !errors.Is(err, ErrResolved) {
+		t.Errorf("Expected ErrResolved, got %v", err)
+	}
+	if _, err := c.Resolve("missing", "x", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestIncidentResolvesWhenMembersResolveIndividually(t *testing.T) {
+	detector, c := newCorrelator(t, DefaultConfig(10*time.Minute))
+	recorded := detector.Record(
+		&models.Anomaly{Type: models.AnomalyTypeUnknown, Severity: models.SeverityLow, Metadata: map[string]interface{}{"proposal_id": "PROP-7"}},
+		&models.Anomaly{Type: models.AnomalyTypeUnknown, Severity: models.SeverityLow, Metadata: map[string]interface{}{"proposal_id": "PROP-7"}},
+	)
+	inc := c.Incidents("")[0]
+
+	detector.ResolveAnomaly(recorded[0].ID, "fixed", "")
+	if got, _ := c.Get(inc.ID); got.Status != models.IncidentOpen {
+		t.Fatal("Expected incident to stay open while a member is unresolved")
+	}
+	detector.ResolveAnomaly(recorded[1].ID, "fixed", "")
+	if got, _ := c.Get(inc.ID); got.Status != models.IncidentResolved || got.Resolution != "All member anomalies resolved" {
+		t.Errorf("Expected incident to resolve with its last member, got %+v", got)
+	}
+}
+
+func TestMarkRootCause(t *testing.T) {
+	detector, c := newCorrelator(t, DefaultConfig(10*time.Minute))
+	recorded := detector.Record(
+		&models.Anomaly{Type: models.AnomalyTypeNodeDesynchronization, Severity: models.SeverityLow, Metadata: map[string]interface{}{"node": "mars-node-1"}},
+		&models.Anomaly{Type: models.AnomalyTypeCommitAnomaly, Severity: models.SeverityLow, Metadata: map[string]interface{}{"node": "mars-node-1"}},
+		&models.Anomaly{Type: models.AnomalyTypeUnknown, Severity: models.SeverityLow},
+	)
+	inc := c.Incidents("")[0]
+
+	marked, err := c.MarkRootCause(inc.ID, recorded[1].ID, "alice")
+	if err != nil {
+		t.Fatalf("MarkRootCause failed: %v", err)
+	}
+	if marked.RootCause != recorded[1].ID || marked.ConfirmedBy != "alice" {
+		t.Errorf("Unexpected root cause: %+v", marked)
+	}
+	if _, err := c.MarkRootCause(inc.ID, recorded[2].ID, "alice"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("Expected ErrNotMember, got %v", err)
+	}
+}
+
+func TestInvalidRulesAreRejected(t *testing.T) {
+	for _, rule := range []Rule{
+		{Name: "", Keys: []string{"node"}, WindowSeconds: 60},
+		{Name: "no-window", Keys: []string{"node"}},
+		{Name: "matches-everything", WindowSeconds: 60},
+	} {
+		if _, err := NewCorrelator(anomaly.NewDetector(), Config{Rules: []Rule{rule}}); err == nil {
+			t.Errorf("Expected rule %+v to be rejected", rule)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package leader
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Status describes this replica's view of the election
+type Status struct {
+	Lease     string     `json:"lease"`
+	Identity  string     `json:"identity"`
+	Leader    bool       `json:"leader"`
+	Holder    string     `json:"holder,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// Elector campaigns for a lease and renews it while held
+type Elector struct {
+	store      Store
+	name       string
+	identity   string
+	ttl        time.Duration
+	lease      Lease
+	validUntil time.Time
+	lastErr    error
+	reported   bool
+	listeners  []func(leader bool)
+	now        func() time.Time
+	stop       chan struct{}
+	done       chan struct{}
+	mu         sync.Mutex
+}
+
+// NewElector creates an elector for the named lease. identity must be
+// unique per replica, e.g. the pod name.
+func NewElector(store Store, name, identity string, ttl time.Duration) *Elector {
+	return &Elector{
+		store:    store,
+		name:     name,
+		identity: identity,
+		ttl:      ttl,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Subscribe registers a callback for leadership changes
+func (e *Elector) Subscribe(fn func(leader bool)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.listeners = append(e.listeners, fn)
+}
+
+// Start campaigns once synchronously, so IsLeader is meaningful on return,
+// then keeps renewing in the background at a third of the lease TTL
+func (e *Elector) Start() {
+	e.campaign()
+
+	go func() {
+		defer close(e.done)
+		ticker := time.NewTicker(e.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				e.campaign()
+			case <-e.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops renewing and releases the lease if held, letting another
+// replica take over without waiting for expiry
+func (e *Elector) Stop() {
+	close(e.stop)
+	<-e.done
+
+	e.mu.Lock()
+	wasLeader := e.isLeader()
+	reported := e.reported
+	e.validUntil = time.Time{}
+	e.reported = false
+	e.mu.Unlock()
+
+	if wasLeader {
+		ctx, cancel := context.WithTimeout(context.Background(), e.ttl)
+		defer cancel()
+		if err := e.store.Release(ctx, e.name, e.identity); err != nil {
+			log.Printf("⚠️  Failed to release leadership: %v", err)
+		}
+	}
+	if reported {
+		e.notify(false)
+	}
+}
+
+// IsLeader reports whether this replica holds an unexpired lease
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.isLeader()
+}
+
+// Status returns the current election state
+func (e *Elector) Status() Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	status := Status{
+		Lease:     e.name,
+		Identity:  e.identity,
+		Leader:    e.isLeader(),
+		Holder:    e.lease.Holder,
+	}
+	if !e.lease.ExpiresAt.IsZero() {
+		expires := e.lease.ExpiresAt
+		status.ExpiresAt = &expires
+	}
+	if e.lastErr != nil {
+		status.Error = e.lastErr.Error()
+	}
+	return status
+}
+
+// campaign tries to take or renew the lease. On store errors a leader
+// keeps leading until its lease runs out, since no one else can take it
+// before then.
+func (e *Elector) campaign() {
+	started := e.now()
+	ctx, cancel := context.WithTimeout(context.Background(), e.ttl/3)
+	lease, err := e.store.TryAcquire(ctx, e.name, e.identity, e.ttl)
+	cancel()
+
+	e.mu.Lock()
+	e.lastErr = err
+	if err == nil {
+		e.lease = lease
+		if lease.Holder == e.identity {
+			// Measured from before the call so the local view never
+			// outlives the stored lease
+			e.validUntil = started.Add(e.ttl)
+		} else {
+			e.validUntil = time.Time{}
+		}
+	}
+	is := e.isLeader()
+	changed := is != e.reported
+	e.reported = is
+	e.mu.Unlock()
+
+	if err != nil {
+		log.Printf("⚠️  Leader election for %s failed: %v", e.name, err)
+	}
+	if changed {
+		e.notify(is)
+	}
+}
+
+func (e *Elector) isLeader() bool {
+	return e.now().Before(e.validUntil)
+}
+
+func (e *Elector) notify(leader bool) {
+	e.mu.Lock()
+	listeners := append([]func(bool){}, e.listeners...)
+	e.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(leader)
+	}
+}
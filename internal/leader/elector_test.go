@@ -0,0 +1,148 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type clock struct {
+	t  time.Time
+	mu sync.Mutex
+}
+
+func (c *clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// newTestElectors returns electors sharing a store and a fake clock; they
+// are not started so tests drive campaigns directly
+func newTestElectors(store Store, c *clock, identities ...string) []*Elector {
+	var electors []*Elector
+	for _, id := range identities {
+		e := NewElector(store, "detection", id, 15*time.Second)
+		e.now = c.now
+		electors = append(electors, e)
+	}
+	return electors
+}
+
+func newTestStore(c *clock) *MemoryStore {
+	store := NewMemoryStore()
+	store.now = c.now
+	return store
+}
+
+func TestOnlyOneLeader(t *testing.T) {
+	c := &clock{t: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+	electors := newTestElectors(newTestStore(c), c, "pod-a", "pod-b")
+	a, b := electors[0], electors[1]
+
+	a.campaign()
+	b.campaign()
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("Expected pod-a to lead alone, got a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+	if status := b.Status(); status.Holder != "pod-a" || status.Leader {
+		t.Errorf("Expected standby to see pod-a as holder, got %+v", status)
+	}
+
+	// Renewal keeps the lease past its original expiry
+	c.advance(10 * time.Second)
+	a.campaign()
+	c.advance(10 * time.Second)
+	b.campaign()
+	if !a.IsLeader() || b.IsLeader() {
+		t.Error("Expected renewed lease to keep pod-a leading")
+	}
+}
+
+func TestFailover(t *testing.T) {
+	c := &clock{t: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+	electors := newTestElectors(newTestStore(c), c, "pod-a", "pod-b")
+	a, b := electors[0], electors[1]
+
+	var changes []bool
+	a.Subscribe(func(leader bool) { changes = append(changes, leader) })
+
+	a.campaign()
+	b.campaign()
+
+	// pod-a stops renewing; its lease lapses locally before pod-b takes over
+	c.advance(16 * time.Second)
+	if a.IsLeader() {
+		t.Error("Expected expired lease to end leadership")
+	}
+	b.campaign()
+	if !b.IsLeader() {
+		t.Fatal("Expected pod-b to take over the expired lease")
+	}
+	a.campaign()
+	if a.IsLeader() {
+		t.Error("Expected pod-a to stay standby")
+	}
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("Expected gained then lost, got %v", changes)
+	}
+}
+
+func TestStopReleasesLease(t *testing.T) {
+	store := NewMemoryStore()
+	a := NewElector(store, "detection", "pod-a", time.Minute)
+	b := NewElector(store, "detection", "pod-b", time.Minute)
+
+	a.Start()
+	b.Start()
+	defer b.Stop()
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatal("Expected pod-a to lead")
+	}
+
+	a.Stop()
+	if a.IsLeader() {
+		t.Error("Expected stopped elector to give up leadership")
+	}
+	b.campaign()
+	if !b.IsLeader() {
+		t.Error("Expected pod-b to take the released lease immediately")
+	}
+}
+
+type failingStore struct{ Store }
+
+func (failingStore) TryAcquire(ctx context.Context, name, holder string, ttl time.Duration) (Lease, error) {
+	return Lease{}, errors.New("connection refused")
+}
+
+func TestLeaderRidesOutStoreErrorsUntilExpiry(t *testing.T) {
+	c := &clock{t: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+	store := newTestStore(c)
+	a := newTestElectors(store, c, "pod-a")[0]
+	a.campaign()
+
+	a.store = failingStore{store}
+	c.advance(5 * time.Second)
+	a.campaign()
+	if !a.IsLeader() {
+		t.Error("Expected leader to keep its unexpired lease through store errors")
+	}
+	if a.Status().Error != "connection refused" {
+		t.Errorf("Expected store error in status, got %+v", a.Status())
+	}
+
+	c.advance(11 * time.Second)
+	a.campaign()
+	if a.IsLeader() {
+		t.Error("Expected leadership to end when the lease expires")
+	}
+}
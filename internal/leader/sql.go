@@ -0,0 +1,69 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SQLStore keeps leases in a PostgreSQL table shared by all replicas.
+// Expiry is judged by the database clock so replicas need not agree on time.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// NewSQLStore wraps an open database handle
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+// Migrate creates the lease table if it does not exist
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS leader_leases (
+			name       TEXT PRIMARY KEY,
+			holder     TEXT NOT NULL,
+			expires_at TIMESTAMPTZ NOT NULL
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to create lease table: %w", err)
+	}
+	return nil
+}
+
+// TryAcquire takes or renews the lease if it is free, expired or held by holder
+func (s *SQLStore) TryAcquire(ctx context.Context, name, holder string, ttl time.Duration) (Lease, error) {
+	lease := Lease{Name: name}
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO leader_leases (name, holder, expires_at)
+		VALUES ($1, $2, now() + $3 * interval '1 millisecond')
+		ON CONFLICT (name) DO UPDATE
+			SET holder = EXCLUDED.holder, expires_at = EXCLUDED.expires_at
+			WHERE leader_leases.holder = EXCLUDED.holder OR leader_leases.expires_at <= now()
+		RETURNING holder, expires_at`,
+		name, holder, ttl.Milliseconds(),
+	).Scan(&lease.Holder, &lease.ExpiresAt)
+
+	// Another replica holds a live lease, so nothing was returned
+	if errors.Is(err, sql.ErrNoRows) {
+		err = s.db.QueryRowContext(ctx,
+			`SELECT holder, expires_at FROM leader_leases WHERE name = $1`, name,
+		).Scan(&lease.Holder, &lease.ExpiresAt)
+	}
+	if err != nil {
+		return Lease{}, fmt.Errorf("failed to acquire lease %s: %w", name, err)
+	}
+	return lease, nil
+}
+
+// Release gives up the lease if holder still holds it
+func (s *SQLStore) Release(ctx context.Context, name, holder string) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM leader_leases WHERE name = $1 AND holder = $2`, name, holder)
+	if err != nil {
+		return fmt.Errorf("failed to release lease %s: %w", name, err)
+	}
+	return nil
+}
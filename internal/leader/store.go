@@ -0,0 +1,64 @@
+// Package leader elects a single replica to run singleton work such as
+// scheduled detection, using leases held in a shared store.
+package leader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Lease records which replica holds a named lease and until when
+type Lease struct {
+	Name      string    `json:"name"`
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Store grants leases. TryAcquire takes or renews the lease when it is
+// free, expired or already held by holder, and returns the current lease
+// either way.
+type Store interface {
+	TryAcquire(ctx context.Context, name, holder string, ttl time.Duration) (Lease, error)
+	Release(ctx context.Context, name, holder string) error
+}
+
+// MemoryStore keeps leases in process, for tests and single-replica runs
+type MemoryStore struct {
+	leases map[string]Lease
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+// NewMemoryStore creates an empty in-memory lease store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		leases: make(map[string]Lease),
+		now:    time.Now,
+	}
+}
+
+// TryAcquire takes or renews the lease if it is free, expired or held by holder
+func (s *MemoryStore) TryAcquire(ctx context.Context, name, holder string, ttl time.Duration) (Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	lease, ok := s.leases[name]
+	if !ok || lease.Holder == holder || !now.Before(lease.ExpiresAt) {
+		lease = Lease{Name: name, Holder: holder, ExpiresAt: now.Add(ttl)}
+		s.leases[name] = lease
+	}
+	return lease, nil
+}
+
+// Release gives up the lease if holder still holds it
+func (s *MemoryStore) Release(ctx context.Context, name, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lease, ok := s.leases[name]; ok && lease.Holder == holder {
+		delete(s.leases, name)
+	}
+	return nil
+}
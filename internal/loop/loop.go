@@ -0,0 +1,466 @@
+// Package loop implements the Superintelligence Loop: for each new anomaly
+// it gathers context, scores candidate resolutions, auto-resolves or
+// proposes a resolution, and logs the decision and its evidence to the
+// ledger with a trace of every step.
+package loop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blockchain"
+	"github.com/google/uuid"
+)
+
+// ErrNoPendingProposal is returned when reviewing an anomaly without a
+// pending proposal
+var ErrNoPendingProposal = errors.New("no pending proposal")
+
+// Gate holds back resolutions that need approval from more than one
+// reviewer; the consensus board implements it
+type Gate interface {
+	Requires(a *models.Anomaly) bool
+	Open(ctx context.Context, id, resolution, requestedBy string) (*models.Approval, error)
+}
+
+// Config controls when the loop may resolve anomalies on its own
+type Config struct {
+	Workers           int
+	QueueSize         int
+	SafeTypes         []models.AnomalyType
+	SafeSeverities    []models.AnomalySeverity
+	MinConfidence     float64
+	WaitForEnrichment bool
+	TraceLimit        int
+}
+
+// DefaultConfig returns the default loop configuration
+func DefaultConfig() Config {
+	return Config{
+		Workers:        2,
+		QueueSize:      100,
+		SafeTypes:      []models.AnomalyType{models.AnomalyTypeCommitAnomaly, models.AnomalyTypeNodeDesynchronization},
+		SafeSeverities: []models.AnomalySeverity{models.SeverityLow, models.SeverityMedium},
+		MinConfidence:  0.6,
+		TraceLimit:     500,
+	}
+}
+
+// Stats counts anomalies handed to the loop
+type Stats struct {
+	Queued  int `json:"queued"`
+	Dropped int `json:"dropped"`
+	// Tracked is the number of open anomalies already queued once, which
+	// are not queued again until they resolve
+	Tracked int `json:"tracked"`
+}
+
+// Loop orchestrates triage from detection to ledger
+type Loop struct {
+	detector *anomaly.Detector
+	ledger   *blockchain.ManusClient
+	sources  []CandidateSource
+	gate     Gate
+	cfg      Config
+	safeType map[models.AnomalyType]bool
+	safeSev  map[models.AnomalySeverity]bool
+	traces   *traceStore
+
+	queue     chan string
+	processed map[string]bool
+	reviewing map[string]bool
+	stats     Stats
+	stopped   bool
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+}
+
+// New creates a loop drawing candidates from sources
+func New(detector *anomaly.Detector, ledger *blockchain.ManusClient, cfg Config, sources ...CandidateSource) *Loop {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.TraceLimit <= 0 {
+		cfg.TraceLimit = 500
+	}
+
+	l := &Loop{
+		detector:  detector,
+		ledger:    ledger,
+		sources:   sources,
+		cfg:       cfg,
+		safeType:  make(map[models.AnomalyType]bool),
+		safeSev:   make(map[models.AnomalySeverity]bool),
+		traces:    newTraceStore(cfg.TraceLimit),
+		queue:     make(chan string, cfg.QueueSize),
+		processed: make(map[string]bool),
+		reviewing: make(map[string]bool),
+	}
+	for _, t := range cfg.SafeTypes {
+		l.safeType[t] = true
+	}
+	for _, s := range cfg.SafeSeverities {
+		l.safeSev[s] = true
+	}
+	return l
+}
+
+// AddSource registers an additional candidate source
+func (l *Loop) AddSource(source CandidateSource) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sources = append(l.sources, source)
+}
+
+// SetGate routes approved proposals that need consensus through g
+func (l *Loop) SetGate(g Gate) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.gate = g
+}
+
+// Start launches the loop workers
+func (l *Loop) Start() {
+	for i := 0; i < l.cfg.Workers; i++ {
+		l.wg.Add(1)
+		go func() {
+			defer l.wg.Done()
+			for id := range l.queue {
+				l.Process(context.Background(), id)
+			}
+		}()
+	}
+}
+
+// Stop drains queued anomalies and waits for workers to finish
+func (l *Loop) Stop() {
+	l.mu.Lock()
+	if !l.stopped {
+		l.stopped = true
+		close(l.queue)
+	}
+	l.mu.Unlock()
+
+	l.wg.Wait()
+}
+
+// HandleEvent queues anomalies for triage; it is meant to be passed to
+// Detector.Subscribe. With WaitForEnrichment set, an anomaly is queued
+// once its enrichment finishes rather than when it is detected.
+func (l *Loop) HandleEvent(event anomaly.Event) {
+	a := event.Anomaly
+	if a.Status == models.StatusResolved || a.Status == models.StatusIgnored {
+		// Closed anomalies are never triaged again
+		l.mu.Lock()
+		delete(l.processed, a.ID)
+		l.mu.Unlock()
+		return
+	}
+
+	if l.cfg.WaitForEnrichment {
+		if event.Type != anomaly.EventUpdated || a.Context == nil {
+			return
+		}
+		switch a.Context.Status {
+		case models.EnrichmentCompleted, models.EnrichmentFailed, models.EnrichmentSkipped:
+		default:
+			return
+		}
+	} else if event.Type != anomaly.EventDetected {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stopped || l.processed[a.ID] {
+		return
+	}
+	select {
+	case l.queue <- a.ID:
+		l.processed[a.ID] = true
+		l.stats.Queued++
+	default:
+		l.stats.Dropped++
+		log.Printf("⚠️  Loop queue full, dropped anomaly %s", a.ID)
+	}
+}
+
+// Stats returns a snapshot of the loop's queue counters
+func (l *Loop) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := l.stats
+	stats.Tracked = len(l.processed)
+	return stats
+}
+
+// Process runs one loop iteration for an anomaly and returns its trace
+func (l *Loop) Process(ctx context.Context, id string) (*Trace, error) {
+	trace := &Trace{
+		ID:        uuid.New().String(),
+		AnomalyID: id,
+		StartedAt: time.Now(),
+	}
+	defer func() {
+		trace.FinishedAt = time.Now()
+		l.traces.put(trace)
+	}()
+
+	var a *models.Anomaly
+	err := trace.step("gather_context", func() (string, error) {
+		var err error
+		a, err = l.detector.GetAnomaly(id)
+		if err != nil {
+			return "", err
+		}
+		if a.Context == nil {
+			return "no enrichment context", nil
+		}
+		return fmt.Sprintf("enrichment %s: %d results, %d similar anomalies",
+			a.Context.Status, len(a.Context.Results), len(a.Context.SimilarAnomalies)), nil
+	})
+	if err != nil {
+		trace.Decision = DecisionFailed
+		return trace, err
+	}
+	if a.Status == models.StatusResolved || a.Status == models.StatusIgnored {
+		trace.Decision = DecisionSkipped
+		return trace, nil
+	}
+
+	trace.step("score_candidates", func() (string, error) {
+		trace.Candidates = l.candidates(ctx, a)
+		return fmt.Sprintf("%d candidates", len(trace.Candidates)), nil
+	})
+
+	err = trace.step("decide", func() (string, error) {
+		if len(trace.Candidates) == 0 {
+			trace.Decision = DecisionNoCandidate
+			return "no candidate resolutions", nil
+		}
+
+		top := trace.Candidates[0]
+		trace.Resolution = top.Resolution
+		if l.autoResolvable(a, top) {
+			if err := l.detector.ResolveAnomaly(a.ID, top.Resolution, ""); err != nil {
+				return "", err
+			}
+			trace.Decision = DecisionAutoResolved
+			return fmt.Sprintf("auto-resolved with score %.2f", top.Score), nil
+		}
+
+		proposal := &models.ResolutionProposal{
+			Resolution: top.Resolution,
+			Score:      top.Score,
+			Source:     fmt.Sprint(top.Sources),
+			TraceID:    trace.ID,
+			Status:     models.ProposalPending,
+			ProposedAt: time.Now(),
+		}
+		if err := l.detector.Update(a.ID, func(stored *models.Anomaly) {
+			stored.Proposal = proposal
+		}); err != nil {
+			return "", err
+		}
+		trace.Decision = DecisionProposed
+		return l.proposalReason(a, top), nil
+	})
+	if err != nil {
+		trace.Decision = DecisionFailed
+	}
+
+	l.logDecision(trace, trace.Decision, "")
+	return trace, err
+}
+
+// Review approves or rejects the pending proposal for an anomaly
+func (l *Loop) Review(ctx context.Context, anomalyID, reviewer string, approve bool) (*Trace, error) {
+	// Claim the proposal so concurrent reviews cannot both apply it
+	if !l.claim(anomalyID) {
+		return nil, fmt.Errorf("%w for anomaly %s: another review is in progress", ErrNoPendingProposal, anomalyID)
+	}
+	defer l.release(anomalyID)
+
+	a, err := l.detector.GetAnomaly(anomalyID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Proposal == nil || a.Proposal.Status != models.ProposalPending {
+		return nil, fmt.Errorf("%w for anomaly %s", ErrNoPendingProposal, anomalyID)
+	}
+
+	proposal := *a.Proposal
+	now := time.Now()
+	proposal.DecidedBy = reviewer
+	proposal.DecidedAt = &now
+	decision := DecisionRejected
+	detail := fmt.Sprintf("rejected by %s", reviewer)
+	proposal.Status = models.ProposalRejected
+	if approve {
+		decision = DecisionApproved
+		detail = fmt.Sprintf("approved by %s", reviewer)
+		proposal.Status = models.ProposalApproved
+		if gate := l.currentGate(); gate != nil && gate.Requires(a) {
+			approval, err := gate.Open(ctx, anomalyID, proposal.Resolution, reviewer)
+			if err != nil {
+				return nil, err
+			}
+			detail += fmt.Sprintf("; consensus %s with %d of %d approvals", approval.Status, approvals(approval), approval.Required)
+		} else if err := l.detector.ResolveAnomaly(anomalyID, proposal.Resolution, reviewer); err != nil {
+			return nil, err
+		}
+	}
+	if err := l.detector.Update(anomalyID, func(stored *models.Anomaly) {
+		stored.Proposal = &proposal
+	}); err != nil {
+		return nil, err
+	}
+
+	review := func(t *Trace) {
+		t.step("review", func() (string, error) {
+			return detail, nil
+		})
+		t.Decision = decision
+		l.logDecision(t, decision, reviewer)
+		t.FinishedAt = time.Now()
+	}
+
+	var reviewed Trace
+	if !l.traces.update(proposal.TraceID, func(t *Trace) {
+		review(t)
+		reviewed = copyTrace(t)
+	}) {
+		// The original trace has been evicted; record the review on its own
+		t := &Trace{ID: proposal.TraceID, AnomalyID: anomalyID, StartedAt: now, Resolution: proposal.Resolution}
+		review(t)
+		l.traces.put(t)
+		reviewed = copyTrace(t)
+	}
+	return &reviewed, nil
+}
+
+// Traces returns recent traces, newest first, optionally for one anomaly
+func (l *Loop) Traces(anomalyID string) []Trace {
+	return l.traces.list(anomalyID)
+}
+
+// Trace returns a single trace by ID
+func (l *Loop) Trace(id string) (Trace, bool) {
+	return l.traces.get(id)
+}
+
+func (l *Loop) candidates(ctx context.Context, a *models.Anomaly) []Candidate {
+	l.mu.Lock()
+	sources := append([]CandidateSource(nil), l.sources...)
+	l.mu.Unlock()
+
+	var all []Candidate
+	for _, source := range sources {
+		candidates, err := source.Candidates(ctx, a)
+		if err != nil {
+			continue
+		}
+		all = append(all, candidates...)
+	}
+
+	merged := merge(all)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Score > merged[j].Score
+	})
+	return merged
+}
+
+func (l *Loop) autoResolvable(a *models.Anomaly, top Candidate) bool {
+	if gate := l.currentGate(); gate != nil && gate.Requires(a) {
+		return false
+	}
+	return l.safeType[a.Type] && l.safeSev[a.Severity] && top.Score >= l.cfg.MinConfidence
+}
+
+func (l *Loop) claim(id string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.reviewing[id] {
+		return false
+	}
+	l.reviewing[id] = true
+	return true
+}
+
+func (l *Loop) release(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.reviewing, id)
+}
+
+func (l *Loop) currentGate() Gate {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.gate
+}
+
+func approvals(approval *models.Approval) int {
+	var n int
+	for _, v := range approval.Votes {
+		if v.Approve {
+			n++
+		}
+	}
+	return n
+}
+
+func (l *Loop) proposalReason(a *models.Anomaly, top Candidate) string {
+	switch {
+	case !l.safeType[a.Type]:
+		return fmt.Sprintf("proposed for approval: type %s is not configured for auto-resolution", a.Type)
+	case !l.safeSev[a.Severity]:
+		return fmt.Sprintf("proposed for approval: severity %s is not configured for auto-resolution", a.Severity)
+	case top.Score >= l.cfg.MinConfidence:
+		return fmt.Sprintf("proposed for approval: severity %s requires consensus", a.Severity)
+	default:
+		return fmt.Sprintf("proposed for approval: score %.2f below threshold %.2f", top.Score, l.cfg.MinConfidence)
+	}
+}
+
+// logDecision writes the decision and its evidence to the ledger
+func (l *Loop) logDecision(t *Trace, decision Decision, reviewer string) {
+	payload := map[string]interface{}{
+		"trace_id":   t.ID,
+		"decision":   string(decision),
+		"resolution": t.Resolution,
+	}
+	if reviewer != "" {
+		payload["reviewer"] = reviewer
+	}
+	if len(t.Candidates) > 0 {
+		payload["candidates"] = t.Candidates
+	}
+
+	t.step("ledger", func() (string, error) {
+		txHash, err := l.ledger.LogEntry(blockchain.LedgerEntry{
+			AnomalyID:   t.AnomalyID,
+			Description: fmt.Sprintf("Superintelligence loop %s: %s", decision, t.Resolution),
+			Actor:       reviewer,
+			Payload:     payload,
+		})
+		if err != nil {
+			return "", err
+		}
+		t.TxHashes = append(t.TxHashes, txHash)
+		return txHash, nil
+	})
+}
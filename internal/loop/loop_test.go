@@ -0,0 +1,277 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blockchain"
+)
+
+func newTestLoop(t *testing.T) (*Loop, *anomaly.Detector, *blockchain.ManusClient, map[models.AnomalyType]string) {
+	t.Helper()
+	detector := anomaly.NewDetector()
+	ledger := blockchain.NewManusClient("http://localhost:9545", "1", false)
+
+	ids := make(map[models.AnomalyType]string)
+	for _, a := range detector.DetectAnomalies() {
+		ids[a.Type] = a.ID
+	}
+
+	l := New(detector, ledger, DefaultConfig(), PlaybookSource{Playbook: DefaultPlaybook})
+	return l, detector, ledger, ids
+}
+
+func stepNames(trace *Trace) []string {
+	var names []string
+	for _, step := range trace.Steps {
+		names = append(names, step.Name)
+	}
+	return names
+}
+
+func TestProcessAutoResolvesSafeAnomaly(t *testing.T) {
+	l, detector, ledger, ids := newTestLoop(t)
+	id := ids[models.AnomalyTypeNodeDesynchronization]
+
+	trace, err := l.Process(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if trace.Decision != DecisionAutoResolved {
+		t.Fatalf("Expected auto_resolved, got %s", trace.Decision)
+	}
+
+	a, _ := detector.GetAnomaly(id)
+	if a.Status != models.StatusResolved || a.Resolution != trace.Resolution {
+		t.Errorf("Expected anomaly resolved with %q, got %s %q", trace.Resolution, a.Status, a.Resolution)
+	}
+
+	want := []string{"gather_context", "score_candidates", "decide", "ledger"}
+	if got := stepNames(trace); len(got) != len(want) {
+		t.Fatalf("Expected steps %v, got %v", want, got)
+	}
+	if len(trace.TxHashes) != 1 {
+		t.Fatalf("Expected one ledger transaction, got %v", trace.TxHashes)
+	}
+
+	entries := ledger.Entries()
+	if len(entries) != 1 || entries[0].Payload["trace_id"] != trace.ID {
+		t.Errorf("Expected ledger entry referencing trace %s, got %+v", trace.ID, entries)
+	}
+}
+
+func TestProcessSkipsResolvedAnomaly(t *testing.T) {
+	l, _, ledger, ids := newTestLoop(t)
+
+	trace, err := l.Process(context.Background(), ids[models.AnomalyTypeCommitAnomaly])
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if trace.Decision != DecisionSkipped || len(ledger.Entries()) != 0 {
+		t.Errorf("Expected resolved anomaly to be skipped without a ledger entry, got %s", trace.Decision)
+	}
+}
+
+func TestProcessProposesUnsafeAnomaly(t *testing.T) {
+	l, detector, _, ids := newTestLoop(t)
+	id := ids[models.AnomalyTypeLedgerDivergence]
+
+	trace, err := l.Process(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if trace.Decision != DecisionProposed {
+		t.Fatalf("Expected proposed, got %s", trace.Decision)
+	}
+
+	a, _ := detector.GetAnomaly(id)
+	if a.Status == models.StatusResolved {
+		t.Error("Expected critical anomaly not to be auto-resolved")
+	}
+	if a.Proposal == nil || a.Proposal.Status != models.ProposalPending || a.Proposal.TraceID != trace.ID {
+		t.Fatalf("Expected pending proposal for trace %s, got %+v", trace.ID, a.Proposal)
+	}
+}
+
+func TestReviewApprovesProposal(t *testing.T) {
+	l, detector, ledger, ids := newTestLoop(t)
+	id := ids[models.AnomalyTypeLedgerDivergence]
+	proposed, _ := l.Process(context.Background(), id)
+
+	reviewed, err := l.Review(context.Background(), id, "alice", true)
+	if err != nil {
+		t.Fatalf("Review failed: %v", err)
+	}
+	if reviewed.ID != proposed.ID || reviewed.Decision != DecisionApproved {
+		t.Errorf("Expected approval recorded on trace %s, got %s on %s", proposed.ID, reviewed.Decision, reviewed.ID)
+	}
+
+	a, _ := detector.GetAnomaly(id)
+	if a.Status != models.StatusResolved || a.Proposal.Status != models.ProposalApproved || a.Proposal.DecidedBy != "alice" {
+		t.Errorf("Expected approved and resolved anomaly, got %s %+v", a.Status, a.Proposal)
+	}
+
+	entries := ledger.Entries()
+	if len(entries) != 2 || entries[1].Payload["reviewer"] != "alice" {
+		t.Errorf("Expected review logged to ledger, got %+v", entries)
+	}
+
+	if _, err := l.Review(context.Background(), id, "bob", false); !errors.Is(err, ErrNoPendingProposal) {
+		t.Errorf("Expected ErrNoPendingProposal on second review, got %v", err)
+	}
+}
+
+func TestReviewRejectsProposal(t *testing.T) {
+	l, detector, _, ids := newTestLoop(t)
+	id := ids[models.AnomalyTypeDAOVoteFailure]
+	l.Process(context.Background(), id)
+
+	if _, err := l.Review(context.Background(), id, "alice", false); err != nil {
+		t.Fatalf("Review failed: %v", err)
+	}
+
+	a, _ := detector.GetAnomaly(id)
+	if a.Status == models.StatusResolved || a.Proposal.Status != models.ProposalRejected {
+		t.Errorf("Expected rejected proposal on unresolved anomaly, got %s %+v", a.Status, a.Proposal)
+	}
+}
+
+func TestMergeCombinesAgreeingSources(t *testing.T) {
+	merged := merge([]Candidate{
+		{Resolution: "Resync nodes", Score: 0.5, Sources: []string{SourcePlaybook}},
+		{Resolution: "resync nodes ", Score: 0.5, Sources: []string{SourceHistory}},
+		{Resolution: "Re-anchor", Score: 0.4, Sources: []string{SourcePlaybook}},
+	})
+
+	if len(merged) != 2 {
+		t.Fatalf("Expected 2 candidates, got %d", len(merged))
+	}
+	if merged[0].Score != 0.75 || len(merged[0].Sources) != 2 {
+		t.Errorf("Expected noisy-or score 0.75 from 2 sources, got %+v", merged[0])
+	}
+}
+
+func TestHandleEventQueuesOnce(t *testing.T) {
+	detector := anomaly.NewDetector()
+	ledger := blockchain.NewManusClient("http://localhost:9545", "1", false)
+	l := New(detector, ledger, DefaultConfig(), PlaybookSource{Playbook: DefaultPlaybook})
+	l.Start()
+	detector.Subscribe(l.HandleEvent)
+
+	detector.DetectAnomalies()
+	l.Stop()
+
+	traces := l.Traces("")
+	if len(traces) != 3 {
+		t.Fatalf("Expected one trace per unresolved anomaly, got %d", len(traces))
+	}
+
+	// Resolution and proposal updates must not queue anomalies again
+	seen := make(map[string]bool)
+	for _, trace := range traces {
+		if seen[trace.AnomalyID] {
+			t.Errorf("Anomaly %s processed twice", trace.AnomalyID)
+		}
+		seen[trace.AnomalyID] = true
+	}
+}
+
+func TestHandleEventCountsDropsAndForgetsResolved(t *testing.T) {
+	detector := anomaly.NewDetector()
+	ledger := blockchain.NewManusClient("http://localhost:9545", "1", false)
+	cfg := DefaultConfig()
+	cfg.QueueSize = 1
+	l := New(detector, ledger, cfg)
+
+	for _, id := range []string{"a1", "a2", "a3"} {
+		l.HandleEvent(anomaly.Event{Type: anomaly.EventDetected, Anomaly: models.Anomaly{ID: id, Status: models.StatusDetected}})
+	}
+	if stats := l.Stats(); stats.Queued != 1 || stats.Dropped != 2 || stats.Tracked != 1 {
+		t.Errorf("Expected 1 queued and 2 dropped, got %+v", stats)
+	}
+
+	l.HandleEvent(anomaly.Event{Type: anomaly.EventResolved, Anomaly: models.Anomaly{ID: "a1", Status: models.StatusResolved}})
+	if stats := l.Stats(); stats.Tracked != 0 {
+		t.Errorf("Expected the resolved anomaly to be forgotten, got %+v", stats)
+	}
+}
+
+type stubGate struct {
+	opened []string
+}
+
+func (g *stubGate) Requires(a *models.Anomaly) bool {
+	return a.Severity == models.SeverityCritical
+}
+
+func (g *stubGate) Open(ctx context.Context, id, resolution, requestedBy string) (*models.Approval, error) {
+	g.opened = append(g.opened, requestedBy)
+	return &models.Approval{Resolution: resolution, Required: 2, Status: models.ApprovalPending,
+		Votes: []models.Vote{{Voter: requestedBy, Approve: true}}}, nil
+}
+
+func TestReviewDefersToGate(t *testing.T) {
+	l, detector, _, ids := newTestLoop(t)
+	gate := &stubGate{}
+	l.SetGate(gate)
+	id := ids[models.AnomalyTypeLedgerDivergence]
+	l.Process(context.Background(), id)
+
+	trace, err := l.Review(context.Background(), id, "alice", true)
+	if err != nil {
+		t.Fatalf("Review failed: %v", err)
+	}
+	if len(gate.opened) != 1 || gate.opened[0] != "alice" {
+		t.Fatalf("Expected approval to open a ballot for alice, got %v", gate.opened)
+	}
+	if a, _ := detector.GetAnomaly(id); a.Status == models.StatusResolved {
+		t.Error("Expected gated anomaly to wait for consensus")
+	}
+
+	review := trace.Steps[len(trace.Steps)-2]
+	if review.Name != "review" || review.Detail != "approved by alice; consensus pending with 1 of 2 approvals" {
+		t.Errorf("Unexpected review step: %+v", review)
+	}
+}
+
+type blockingGate struct {
+	stubGate
+	entered chan struct{}
+	proceed chan struct{}
+}
+
+func (g *blockingGate) Open(ctx context.Context, id, resolution, requestedBy string) (*models.Approval, error) {
+	close(g.entered)
+	<-g.proceed
+	return g.stubGate.Open(ctx, id, resolution, requestedBy)
+}
+
+func TestConcurrentReviewsApplyOnce(t *testing.T) {
+	l, _, _, ids := newTestLoop(t)
+	gate := &blockingGate{entered: make(chan struct{}), proceed: make(chan struct{})}
+	l.SetGate(gate)
+	id := ids[models.AnomalyTypeLedgerDivergence]
+	l.Process(context.Background(), id)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := l.Review(context.Background(), id, "alice", true)
+		done <- err
+	}()
+	<-gate.entered
+
+	if _, err := l.Review(context.Background(), id, "bob", true); !errors.Is(err, ErrNoPendingProposal) {
+		t.Errorf("Expected ErrNoPendingProposal while another review is in progress, got %v", err)
+	}
+
+	close(gate.proceed)
+	if err := <-done; err != nil {
+		t.Fatalf("Review failed: %v", err)
+	}
+	if len(gate.opened) != 1 || gate.opened[0] != "alice" {
+		t.Errorf("Expected a single ballot opened by alice, got %v", gate.opened)
+	}
+}
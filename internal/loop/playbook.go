@@ -0,0 +1,205 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/search"
+)
+
+// Candidate sources
+const (
+	SourcePlaybook = "playbook"
+	SourceHistory  = "history"
+)
+
+// Candidate is a scored resolution considered by the loop
+type Candidate struct {
+	Resolution string   `json:"resolution"`
+	Score      float64  `json:"score"`
+	Sources    []string `json:"sources"`
+	Evidence   []string `json:"evidence,omitempty"`
+}
+
+// CandidateSource proposes resolutions for an anomaly. Scores are in [0, 1].
+type CandidateSource interface {
+	Name() string
+	Candidates(ctx context.Context, a *models.Anomaly) ([]Candidate, error)
+}
+
+// PlaybookEntry is a known resolution for an anomaly type. Keywords found
+// in the anomaly description or its search context raise the confidence.
+type PlaybookEntry struct {
+	Resolution string   `json:"resolution"`
+	Confidence float64  `json:"confidence"`
+	Keywords   []string `json:"keywords,omitempty"`
+}
+
+// Playbook maps anomaly types to known resolutions
+type Playbook map[models.AnomalyType][]PlaybookEntry
+
+// DefaultPlaybook holds the built-in resolutions
+var DefaultPlaybook = Playbook{
+	models.AnomalyTypeLedgerDivergence: {
+		{Resolution: "Resynchronize divergent nodes from the canonical Earth peer", Confidence: 0.6, Keywords: []string{"resync", "resynchronize", "peer", "checkpoint"}},
+		{Resolution: "Re-anchor the latest checkpoint on all planetary nodes", Confidence: 0.5, Keywords: []string{"anchor", "checkpoint", "fork"}},
+	},
+	models.AnomalyTypeDAOVoteFailure: {
+		{Resolution: "Re-broadcast the DAO vote from the nearest healthy relay", Confidence: 0.6, Keywords: []string{"rebroadcast", "re-broadcast", "relay", "gossip"}},
+	},
+	models.AnomalyTypeCommitAnomaly: {
+		{Resolution: "Commit verified and logged immutably on blockchain", Confidence: 0.7, Keywords: []string{"verified", "verification", "commit"}},
+	},
+	models.AnomalyTypeNodeDesynchronization: {
+		{Resolution: "Latency within interplanetary tolerance; monitor route", Confidence: 0.6, Keywords: []string{"latency", "delay", "tolerant"}},
+		{Resolution: "Resynchronize node clock and replay missed blocks", Confidence: 0.5, Keywords: []string{"clock", "replay", "synchronization"}},
+	},
+}
+
+// PlaybookSource scores playbook entries for the anomaly type
+type PlaybookSource struct {
+	Playbook Playbook
+}
+
+// Name identifies the playbook source
+func (p PlaybookSource) Name() string {
+	return SourcePlaybook
+}
+
+// Candidates returns the playbook entries for a's type
+func (p PlaybookSource) Candidates(ctx context.Context, a *models.Anomaly) ([]Candidate, error) {
+	text := strings.ToLower(a.Description)
+	if a.Context != nil {
+		for _, result := range a.Context.Results {
+			text += " " + strings.ToLower(result.Title+" "+result.Snippet)
+		}
+	}
+
+	var candidates []Candidate
+	for _, entry := range p.Playbook[a.Type] {
+		score := entry.Confidence
+		var evidence []string
+		for _, keyword := range entry.Keywords {
+			if strings.Contains(text, strings.ToLower(keyword)) {
+				evidence = append(evidence, fmt.Sprintf("keyword %q found in anomaly context", keyword))
+			}
+		}
+		matched := len(evidence)
+		if matched > 3 {
+			matched = 3
+		}
+		score += 0.1 * float64(matched)
+		if score > 1 {
+			score = 1
+		}
+
+		candidates = append(candidates, Candidate{
+			Resolution: entry.Resolution,
+			Score:      score,
+			Sources:    []string{SourcePlaybook},
+			Evidence:   append([]string{fmt.Sprintf("playbook entry for %s", a.Type)}, evidence...),
+		})
+	}
+	return candidates, nil
+}
+
+// HistorySource reuses resolutions of similar past anomalies, taken from
+// the enrichment context or, failing that, from the local index
+type HistorySource struct {
+	Local *search.LocalSearchClient
+	Limit int
+}
+
+// Name identifies the history source
+func (h HistorySource) Name() string {
+	return SourceHistory
+}
+
+// Candidates returns past resolutions weighted by similarity
+func (h HistorySource) Candidates(ctx context.Context, a *models.Anomaly) ([]Candidate, error) {
+	var similar []models.SimilarAnomaly
+	if a.Context != nil {
+		similar = a.Context.SimilarAnomalies
+	}
+	if len(similar) == 0 && h.Local != nil {
+		for _, hit := range h.Local.Index().Query(a.Description, 0) {
+			id, ok := search.AnomalyIDFromURL(hit.Document.URL)
+			if !ok || id == a.ID {
+				continue
+			}
+			similar = append(similar, models.SimilarAnomaly{
+				ID:          id,
+				Description: hit.Document.Fields[search.FieldDescription],
+				Resolution:  hit.Document.Fields[search.FieldResolution],
+				Score:       hit.Score,
+			})
+		}
+	}
+
+	limit := h.Limit
+	if limit <= 0 {
+		limit = 5
+	}
+
+	var best float64
+	for _, s := range similar {
+		if s.Resolution != "" && s.Score > best {
+			best = s.Score
+		}
+	}
+
+	var candidates []Candidate
+	for _, s := range similar {
+		if s.Resolution == "" {
+			continue
+		}
+		candidates = append(candidates, Candidate{
+			Resolution: s.Resolution,
+			Score:      0.4 + 0.5*s.Score/best,
+			Sources:    []string{SourceHistory},
+			Evidence:   []string{fmt.Sprintf("resolved similar anomaly %s (similarity %.2f)", s.ID, s.Score)},
+		})
+		if len(candidates) == limit {
+			break
+		}
+	}
+	return candidates, nil
+}
+
+// merge combines candidates with the same resolution using a noisy-or of
+// their scores, so agreement between sources raises confidence
+func merge(candidates []Candidate) []Candidate {
+	var merged []Candidate
+	index := make(map[string]int)
+
+	for _, c := range candidates {
+		key := strings.ToLower(strings.TrimSpace(c.Resolution))
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, c)
+			continue
+		}
+
+		m := &merged[i]
+		m.Score = 1 - (1-m.Score)*(1-c.Score)
+		m.Evidence = append(m.Evidence, c.Evidence...)
+		for _, source := range c.Sources {
+			if !contains(m.Sources, source) {
+				m.Sources = append(m.Sources, source)
+			}
+		}
+	}
+	return merged
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
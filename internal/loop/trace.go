@@ -0,0 +1,132 @@
+package loop
+
+import (
+	"sync"
+	"time"
+)
+
+// Decision is the outcome of a loop iteration
+type Decision string
+
+const (
+	DecisionAutoResolved Decision = "auto_resolved"
+	DecisionProposed     Decision = "proposed"
+	DecisionNoCandidate  Decision = "no_candidate"
+	DecisionApproved     Decision = "approved"
+	DecisionRejected     Decision = "rejected"
+	DecisionFailed       Decision = "failed"
+	DecisionSkipped      Decision = "skipped"
+)
+
+// TraceStep records one stage of a loop iteration
+type TraceStep struct {
+	Name      string        `json:"name"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration_ns"`
+	Detail    string        `json:"detail,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Trace records everything one loop iteration saw and decided
+type Trace struct {
+	ID         string      `json:"id"`
+	AnomalyID  string      `json:"anomaly_id"`
+	StartedAt  time.Time   `json:"started_at"`
+	FinishedAt time.Time   `json:"finished_at"`
+	Steps      []TraceStep `json:"steps"`
+	Candidates []Candidate `json:"candidates,omitempty"`
+	Decision   Decision    `json:"decision"`
+	Resolution string      `json:"resolution,omitempty"`
+	TxHashes   []string    `json:"ledger_tx,omitempty"`
+}
+
+// step runs fn as a named trace step, recording its duration and error
+func (t *Trace) step(name string, fn func() (string, error)) error {
+	started := time.Now()
+	detail, err := fn()
+
+	s := TraceStep{
+		Name:      name,
+		StartedAt: started,
+		Duration:  time.Since(started),
+		Detail:    detail,
+	}
+	if err != nil {
+		s.Error = err.Error()
+	}
+	t.Steps = append(t.Steps, s)
+	return err
+}
+
+// traceStore keeps the most recent traces
+type traceStore struct {
+	limit  int
+	order  []string
+	traces map[string]*Trace
+	mu     sync.RWMutex
+}
+
+func newTraceStore(limit int) *traceStore {
+	return &traceStore{limit: limit, traces: make(map[string]*Trace)}
+}
+
+func (s *traceStore) put(t *Trace) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.traces[t.ID]; !ok {
+		s.order = append(s.order, t.ID)
+	}
+	s.traces[t.ID] = t
+
+	for len(s.order) > s.limit {
+		delete(s.traces, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+func (s *traceStore) get(id string) (Trace, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.traces[id]
+	if !ok {
+		return Trace{}, false
+	}
+	return copyTrace(t), true
+}
+
+// list returns traces newest first, optionally only for one anomaly
+func (s *traceStore) list(anomalyID string) []Trace {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	traces := make([]Trace, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		t := s.traces[s.order[i]]
+		if anomalyID == "" || t.AnomalyID == anomalyID {
+			traces = append(traces, copyTrace(t))
+		}
+	}
+	return traces
+}
+
+// update applies fn to a stored trace under the store lock
+func (s *traceStore) update(id string, fn func(*Trace)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.traces[id]
+	if ok {
+		fn(t)
+	}
+	return ok
+}
+
+func copyTrace(t *Trace) Trace {
+	c := *t
+	c.Steps = append([]TraceStep(nil), t.Steps...)
+	c.Candidates = append([]Candidate(nil), t.Candidates...)
+	c.TxHashes = append([]string(nil), t.TxHashes...)
+	return c
+}
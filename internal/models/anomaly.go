@@ -42,9 +42,118 @@ type Anomaly struct {
 	Status      AnomalyStatus   `json:"status"`
 	DetectedAt  time.Time       `json:"detected_at"`
 	ResolvedAt  *time.Time      `json:"resolved_at,omitempty"`
+	ResolvedBy  string          `json:"resolved_by,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 	Source      string          `json:"source"`
+	Fingerprint string          `json:"fingerprint,omitempty"`
 	Resolution  string          `json:"resolution,omitempty"`
+	Context     *AnomalyContext `json:"context,omitempty"`
+	Proposal    *ResolutionProposal `json:"proposal,omitempty"`
+	Approval    *Approval       `json:"approval,omitempty"`
+	Runbook     *RunbookExecution `json:"runbook,omitempty"`
+	IncidentID  string          `json:"incident_id,omitempty"`
+	Suppressed  bool            `json:"suppressed,omitempty"`
+	SilencedBy  string          `json:"silenced_by,omitempty"`
+	AcknowledgedAt *time.Time   `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string       `json:"acknowledged_by,omitempty"`
+	SLA         *SLA            `json:"sla,omitempty"`
+	Assignee    *Assignment     `json:"assignee,omitempty"`
+	Timeline    []TimelineEntry `json:"timeline,omitempty"`
+}
+
+// EnrichmentStatus represents the progress of context enrichment
+type EnrichmentStatus string
+
+const (
+	EnrichmentPending   EnrichmentStatus = "pending"
+	EnrichmentRunning   EnrichmentStatus = "running"
+	EnrichmentCompleted EnrichmentStatus = "completed"
+	EnrichmentFailed    EnrichmentStatus = "failed"
+	EnrichmentSkipped   EnrichmentStatus = "skipped"
+)
+
+// AnomalyContext holds search context gathered for an anomaly
+type AnomalyContext struct {
+	Status           EnrichmentStatus `json:"status"`
+	Queries          []string         `json:"queries,omitempty"`
+	Results          []SearchResult   `json:"results,omitempty"`
+	SimilarAnomalies []SimilarAnomaly `json:"similar_anomalies,omitempty"`
+	Error            string           `json:"error,omitempty"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+}
+
+// SimilarAnomaly references a past anomaly that matches a new one
+type SimilarAnomaly struct {
+	ID          string  `json:"id"`
+	Description string  `json:"description"`
+	Resolution  string  `json:"resolution,omitempty"`
+	Score       float64 `json:"score"`
+}
+
+// ProposalStatus represents the approval state of a proposed resolution
+type ProposalStatus string
+
+const (
+	ProposalPending  ProposalStatus = "pending"
+	ProposalApproved ProposalStatus = "approved"
+	ProposalRejected ProposalStatus = "rejected"
+)
+
+// ResolutionProposal is a resolution suggested by the superintelligence
+// loop that awaits human approval
+type ResolutionProposal struct {
+	Resolution string         `json:"resolution"`
+	Score      float64        `json:"score"`
+	Source     string         `json:"source"`
+	TraceID    string         `json:"trace_id"`
+	Status     ProposalStatus `json:"status"`
+	ProposedAt time.Time      `json:"proposed_at"`
+	DecidedBy  string         `json:"decided_by,omitempty"`
+	DecidedAt  *time.Time     `json:"decided_at,omitempty"`
+}
+
+// ApprovalStatus represents the state of a consensus vote on a resolution
+type ApprovalStatus string
+
+const (
+	ApprovalPending  ApprovalStatus = "pending"
+	ApprovalApproved ApprovalStatus = "approved"
+	ApprovalRejected ApprovalStatus = "rejected"
+)
+
+// VoterKind distinguishes human voters from agents
+type VoterKind string
+
+const (
+	VoterHuman VoterKind = "human"
+	VoterAgent VoterKind = "agent"
+)
+
+// Vote is one voter's decision on a pending resolution
+type Vote struct {
+	Voter   string    `json:"voter"`
+	Kind    VoterKind `json:"kind"`
+	Approve bool      `json:"approve"`
+	Comment string    `json:"comment,omitempty"`
+	CastAt  time.Time `json:"cast_at"`
+}
+
+// Approval tracks the N-of-M votes required before a resolution is applied
+type Approval struct {
+	Resolution  string         `json:"resolution"`
+	RequestedBy string         `json:"requested_by,omitempty"`
+	Required    int            `json:"required"`
+	Eligible    []string       `json:"eligible,omitempty"`
+	Votes       []Vote         `json:"votes"`
+	Expired     int            `json:"expired_votes,omitempty"`
+	Status      ApprovalStatus `json:"status"`
+	OpenedAt    time.Time      `json:"opened_at"`
+	ClosedAt    *time.Time     `json:"closed_at,omitempty"`
+	TxHash      string         `json:"ledger_tx,omitempty"`
+	// AnchorNotes records the notes digest with the tally when the ballot
+	// closes; NotesDigest is the digest recorded
+	AnchorNotes bool           `json:"anchor_notes,omitempty"`
+	NotesDigest string         `json:"notes_digest,omitempty"`
 }
 
 // AnomalyReport represents a summary report of anomalies
@@ -52,6 +161,8 @@ type AnomalyReport struct {
 	TotalAnomalies    int                       `json:"total_anomalies"`
 	ResolvedAnomalies int                       `json:"resolved_anomalies"`
 	PendingAnomalies  int                       `json:"pending_anomalies"`
+	SuppressedAnomalies int                     `json:"suppressed_anomalies"`
+	SLA               *SLAReport                `json:"sla,omitempty"`
 	BySeverity        map[AnomalySeverity]int   `json:"by_severity"`
 	ByType            map[AnomalyType]int       `json:"by_type"`
 	GeneratedAt       time.Time                 `json:"generated_at"`
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// AssigneeKind distinguishes assignments to a person from a team
+type AssigneeKind string
+
+const (
+	AssigneeUser AssigneeKind = "user"
+	AssigneeTeam AssigneeKind = "team"
+)
+
+// Assignment records who owns an anomaly
+type Assignment struct {
+	Name       string       `json:"name"`
+	Kind       AssigneeKind `json:"kind"`
+	AssignedBy string       `json:"assigned_by"`
+	AssignedAt time.Time    `json:"assigned_at"`
+	Reason     string       `json:"reason,omitempty"`
+}
+
+// TimelineEntry is one thing that happened to an anomaly
+type TimelineEntry struct {
+	At     time.Time `json:"at"`
+	Actor  string    `json:"actor,omitempty"`
+	Action string    `json:"action"`
+	Detail string    `json:"detail,omitempty"`
+}
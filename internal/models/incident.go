@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// IncidentStatus represents the state of an incident
+type IncidentStatus string
+
+const (
+	IncidentOpen     IncidentStatus = "open"
+	IncidentResolved IncidentStatus = "resolved"
+)
+
+// RootCauseCandidate is a member anomaly ranked by how likely it caused
+// the rest of its incident
+type RootCauseCandidate struct {
+	AnomalyID string      `json:"anomaly_id"`
+	Type      AnomalyType `json:"type"`
+	Score     float64     `json:"score"`
+	Reason    string      `json:"reason"`
+}
+
+// Incident groups correlated anomalies that share a cause
+type Incident struct {
+	ID          string               `json:"id"`
+	Title       string               `json:"title"`
+	Status      IncidentStatus       `json:"status"`
+	Severity    AnomalySeverity      `json:"severity"`
+	Anomalies   []string             `json:"anomalies"`
+	Rules       []string             `json:"correlated_by"`
+	Keys        []string             `json:"shared_keys,omitempty"`
+	Candidates  []RootCauseCandidate `json:"root_cause_candidates"`
+	RootCause   string               `json:"root_cause,omitempty"`
+	ConfirmedBy string               `json:"root_cause_confirmed_by,omitempty"`
+	OpenedAt    time.Time            `json:"opened_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+	ResolvedAt  *time.Time           `json:"resolved_at,omitempty"`
+	Resolution  string               `json:"resolution,omitempty"`
+}
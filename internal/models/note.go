@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Comment is a markdown note in an anomaly's discussion thread
+type Comment struct {
+	ID        string     `json:"id"`
+	AnomalyID string     `json:"anomaly_id"`
+	Author    string     `json:"author"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+}
+
+// Attachment is a file such as a log or screenshot attached to an anomaly.
+// Its content is kept in the blob store under Key.
+type Attachment struct {
+	ID          string    `json:"id"`
+	AnomalyID   string    `json:"anomaly_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Digest      string    `json:"digest"`
+	Key         string    `json:"-"`
+	UploadedBy  string    `json:"uploaded_by"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// RunbookStatus represents the state of a runbook execution
+type RunbookStatus string
+
+const (
+	RunbookRunning          RunbookStatus = "running"
+	RunbookAwaitingApproval RunbookStatus = "awaiting_approval"
+	RunbookSucceeded        RunbookStatus = "succeeded"
+	RunbookFailed           RunbookStatus = "failed"
+	RunbookRolledBack       RunbookStatus = "rolled_back"
+	RunbookCancelled        RunbookStatus = "cancelled"
+)
+
+// StepStatus represents the outcome of a single runbook step
+type StepStatus string
+
+const (
+	StepSucceeded StepStatus = "succeeded"
+	StepFailed    StepStatus = "failed"
+	StepDryRun    StepStatus = "dry_run"
+)
+
+// StepResult records what a runbook step did
+type StepResult struct {
+	Name       string     `json:"name"`
+	Action     string     `json:"action"`
+	Params     Params     `json:"params,omitempty"`
+	Status     StepStatus `json:"status"`
+	Output     string     `json:"output,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Rollback   bool       `json:"rollback,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt time.Time  `json:"finished_at"`
+}
+
+// Params are the rendered parameters passed to a runbook action
+type Params map[string]string
+
+// StepApproval records who approved a destructive step
+type StepApproval struct {
+	Step       string    `json:"step"`
+	ApprovedBy string    `json:"approved_by"`
+	ApprovedAt time.Time `json:"approved_at"`
+}
+
+// RunbookExecution is the latest runbook run for an anomaly
+type RunbookExecution struct {
+	ID          string            `json:"id"`
+	Runbook     string            `json:"runbook"`
+	DryRun      bool              `json:"dry_run"`
+	RequestedBy string            `json:"requested_by,omitempty"`
+	Status      RunbookStatus     `json:"status"`
+	Next        int               `json:"next_step"`
+	Steps       []StepResult      `json:"steps"`
+	Outputs     map[string]string `json:"outputs,omitempty"`
+	Approvals   []StepApproval    `json:"approvals,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	TxHash      string            `json:"ledger_tx,omitempty"`
+	StartedAt   time.Time         `json:"started_at"`
+	FinishedAt  *time.Time        `json:"finished_at,omitempty"`
+}
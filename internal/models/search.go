@@ -12,6 +12,7 @@ type SearchResult struct {
 type SearchResponse struct {
 	Query       string         `json:"query"`
 	TotalResults int           `json:"total_results"`
+	Offset       int           `json:"offset"`
 	Results     []SearchResult `json:"results"`
 	Source      string         `json:"source"`
 }
@@ -26,6 +27,7 @@ type BingWebPage struct {
 // BingResponse represents the response from Bing Search API
 type BingResponse struct {
 	WebPages struct {
-		Value []BingWebPage `json:"value"`
+		TotalEstimatedMatches int           `json:"totalEstimatedMatches"`
+		Value                 []BingWebPage `json:"value"`
 	} `json:"webPages"`
 }
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// SLA tracks an anomaly's acknowledge and resolve deadlines under the
+// escalation policy for its severity at detection
+type SLA struct {
+	Policy          AnomalySeverity `json:"policy"`
+	AckDeadline     time.Time       `json:"ack_deadline"`
+	ResolveDeadline time.Time       `json:"resolve_deadline"`
+	AckBreached     bool            `json:"ack_breached"`
+	ResolveBreached bool            `json:"resolve_breached"`
+	// Level counts every escalation step taken; AckLevel and
+	// ResolveLevel count the steps taken for each deadline
+	Level        int          `json:"escalation_level"`
+	AckLevel     int          `json:"ack_escalation_level"`
+	ResolveLevel int          `json:"resolve_escalation_level"`
+	Escalations  []Escalation `json:"escalations,omitempty"`
+}
+
+// Deadlines an escalation can be taken for
+const (
+	DeadlineAck     = "ack"
+	DeadlineResolve = "resolve"
+)
+
+// Escalation records one escalation step taken on an anomaly
+type Escalation struct {
+	Deadline string          `json:"deadline"`
+	Level    int             `json:"level"`
+	At       time.Time       `json:"at"`
+	Reason   string          `json:"reason"`
+	Notified []string        `json:"notified,omitempty"`
+	Severity AnomalySeverity `json:"severity,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// SLAReport summarises SLA compliance. Compliance is the fraction of
+// tracked anomalies that breached neither deadline.
+type SLAReport struct {
+	Tracked         int                         `json:"tracked"`
+	Met             int                         `json:"met"`
+	AckBreached     int                         `json:"ack_breached"`
+	ResolveBreached int                         `json:"resolve_breached"`
+	Compliance      float64                     `json:"compliance"`
+	BySeverity      map[AnomalySeverity]float64 `json:"compliance_by_severity"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// TrendBucketSize is the width of the buckets in a trend report
+type TrendBucketSize string
+
+const (
+	BucketDay  TrendBucketSize = "day"
+	BucketWeek TrendBucketSize = "week"
+)
+
+// DurationStats summarises durations such as time to acknowledge, in
+// seconds
+type DurationStats struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean_seconds"`
+	P50   float64 `json:"p50_seconds"`
+	P90   float64 `json:"p90_seconds"`
+	P99   float64 `json:"p99_seconds"`
+}
+
+// BreakdownStats holds time to acknowledge and resolve for a subset of
+// anomalies
+type BreakdownStats struct {
+	New  int           `json:"new"`
+	MTTA DurationStats `json:"mtta"`
+	MTTR DurationStats `json:"mttr"`
+}
+
+// PeriodSummary summarises anomalies over a time range. New anomalies
+// were detected in the range and resolved ones resolved in it.
+type PeriodSummary struct {
+	From           time.Time     `json:"from"`
+	To             time.Time     `json:"to"`
+	New            int           `json:"new"`
+	Resolved       int           `json:"resolved"`
+	Regressions    int           `json:"regressions"`
+	RegressionRate float64       `json:"regression_rate"`
+	MTTA           DurationStats `json:"mtta"`
+	MTTR           DurationStats `json:"mttr"`
+}
+
+// TrendBucket is one day or week of a trend report
+type TrendBucket struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	New      int       `json:"new"`
+	Resolved int       `json:"resolved"`
+	MTTA     float64   `json:"mtta_seconds"`
+	MTTR     float64   `json:"mttr_seconds"`
+}
+
+// FingerprintCount is how often a recurring problem was detected
+type FingerprintCount struct {
+	Fingerprint string      `json:"fingerprint"`
+	Type        AnomalyType `json:"type"`
+	Count       int         `json:"count"`
+	LastSeen    time.Time   `json:"last_seen"`
+	LastID      string      `json:"last_anomaly_id"`
+}
+
+// PeriodChange compares a period with the one before it. Percentages are
+// nil when the previous value was zero.
+type PeriodChange struct {
+	New            int      `json:"new"`
+	NewPercent     *float64 `json:"new_percent,omitempty"`
+	Resolved       int      `json:"resolved"`
+	MTTA           float64  `json:"mtta_seconds"`
+	MTTR           float64  `json:"mttr_seconds"`
+	RegressionRate float64  `json:"regression_rate"`
+}
+
+// TrendReport describes how anomalies developed over a time range
+type TrendReport struct {
+	Bucket          TrendBucketSize                    `json:"bucket"`
+	Current         PeriodSummary                      `json:"current"`
+	Previous        PeriodSummary                      `json:"previous"`
+	Change          PeriodChange                       `json:"change"`
+	Buckets         []TrendBucket                      `json:"buckets"`
+	BySeverity      map[AnomalySeverity]BreakdownStats `json:"by_severity"`
+	ByType          map[AnomalyType]BreakdownStats     `json:"by_type"`
+	TopFingerprints []FingerprintCount                 `json:"top_fingerprints"`
+	GeneratedAt     time.Time                          `json:"generated_at"`
+}
@@ -0,0 +1,228 @@
+// Package notes keeps the investigation record of anomalies: a comment
+// thread and attached files such as logs and screenshots. Comments and
+// uploads are added to the anomaly's timeline, and a digest of the notes
+// can be anchored in the ledger with the resolution.
+package notes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blob"
+)
+
+var (
+	// ErrNotFound is returned for an unknown comment or attachment
+	ErrNotFound = errors.New("not found")
+	// ErrNotAuthor is returned when editing someone else's comment
+	ErrNotAuthor = errors.New("only the author can edit a comment")
+	// ErrTooLarge is returned for an attachment over the size limit
+	ErrTooLarge = errors.New("attachment is too large")
+)
+
+// Store holds comments and attachment records per anomaly
+type Store struct {
+	detector    *anomaly.Detector
+	blobs       blob.Store
+	maxSize     int64
+	comments    map[string][]models.Comment
+	attachments map[string][]models.Attachment
+	now         func() time.Time
+	mu          sync.RWMutex
+}
+
+// NewStore creates a store keeping attachment content in blobs and
+// rejecting attachments larger than maxSize bytes
+func NewStore(detector *anomaly.Detector, blobs blob.Store, maxSize int64) *Store {
+	return &Store{
+		detector:    detector,
+		blobs:       blobs,
+		maxSize:     maxSize,
+		comments:    make(map[string][]models.Comment),
+		attachments: make(map[string][]models.Attachment),
+		now:         time.Now,
+	}
+}
+
+// MaxSize returns the attachment size limit in bytes
+func (s *Store) MaxSize() int64 {
+	return s.maxSize
+}
+
+// AddComment adds a comment to an anomaly's thread
+func (s *Store) AddComment(anomalyID, author, body string) (models.Comment, error) {
+	if author == "" || strings.TrimSpace(body) == "" {
+		return models.Comment{}, fmt.Errorf("author and body are required")
+	}
+	if _, err := s.detector.GetAnomaly(anomalyID); err != nil {
+		return models.Comment{}, err
+	}
+
+	c := models.Comment{
+		ID:        uuid.New().String(),
+		AnomalyID: anomalyID,
+		Author:    author,
+		Body:      body,
+		CreatedAt: s.now(),
+	}
+
+	s.mu.Lock()
+	s.comments[anomalyID] = append(s.comments[anomalyID], c)
+	s.mu.Unlock()
+
+	s.record(anomalyID, models.TimelineEntry{At: c.CreatedAt, Actor: author, Action: "commented", Detail: c.ID})
+	return c, nil
+}
+
+// EditComment replaces the body of a comment. Only its author may edit it.
+func (s *Store) EditComment(anomalyID, commentID, author, body string) (models.Comment, error) {
+	if strings.TrimSpace(body) == "" {
+		return models.Comment{}, fmt.Errorf("body is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, c := range s.comments[anomalyID] {
+		if c.ID != commentID {
+			continue
+		}
+		if c.Author != author {
+			return models.Comment{}, ErrNotAuthor
+		}
+		now := s.now()
+		c.Body = body
+		c.EditedAt = &now
+		// Replace rather than mutate the slice readers may share
+		comments := append([]models.Comment(nil), s.comments[anomalyID]...)
+		comments[i] = c
+		s.comments[anomalyID] = comments
+		return c, nil
+	}
+	return models.Comment{}, fmt.Errorf("comment %w: %s", ErrNotFound, commentID)
+}
+
+// Comments returns an anomaly's comments, oldest first
+func (s *Store) Comments(anomalyID string) []models.Comment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]models.Comment{}, s.comments[anomalyID]...)
+}
+
+// Attach stores r as a file attached to an anomaly
+func (s *Store) Attach(ctx context.Context, anomalyID, name, contentType, by string, r io.Reader) (models.Attachment, error) {
+	if name == "" || by == "" {
+		return models.Attachment{}, fmt.Errorf("name and uploaded_by are required")
+	}
+	if _, err := s.detector.GetAnomaly(anomalyID); err != nil {
+		return models.Attachment{}, err
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	a := models.Attachment{
+		ID:          uuid.New().String(),
+		AnomalyID:   anomalyID,
+		Name:        name,
+		ContentType: contentType,
+		UploadedBy:  by,
+	}
+	a.Key = anomalyID + "/" + a.ID
+
+	// Read one byte past the limit to tell a full-size file from a larger one
+	digest := sha256.New()
+	n, err := s.blobs.Put(ctx, a.Key, io.TeeReader(io.LimitReader(r, s.maxSize+1), digest))
+	if err == nil && n > s.maxSize {
+		err = fmt.Errorf("%w: limit is %d bytes", ErrTooLarge, s.maxSize)
+	}
+	if err != nil {
+		s.blobs.Delete(ctx, a.Key)
+		return models.Attachment{}, err
+	}
+	a.Size = n
+	a.Digest = "sha256:" + hex.EncodeToString(digest.Sum(nil))
+	a.UploadedAt = s.now()
+
+	s.mu.Lock()
+	s.attachments[anomalyID] = append(s.attachments[anomalyID], a)
+	s.mu.Unlock()
+
+	s.record(anomalyID, models.TimelineEntry{At: a.UploadedAt, Actor: by, Action: "attached", Detail: a.Name})
+	return a, nil
+}
+
+// Attachments returns an anomaly's attachments, oldest first
+func (s *Store) Attachments(anomalyID string) []models.Attachment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]models.Attachment{}, s.attachments[anomalyID]...)
+}
+
+// Open returns an attachment and its content. The caller must close the
+// content.
+func (s *Store) Open(ctx context.Context, anomalyID, attachmentID string) (models.Attachment, io.ReadCloser, error) {
+	s.mu.RLock()
+	var found *models.Attachment
+	for _, a := range s.attachments[anomalyID] {
+		if a.ID == attachmentID {
+			found = &a
+			break
+		}
+	}
+	s.mu.RUnlock()
+
+	if found == nil {
+		return models.Attachment{}, nil, fmt.Errorf("attachment %w: %s", ErrNotFound, attachmentID)
+	}
+	content, err := s.blobs.Get(ctx, found.Key)
+	if err != nil {
+		return models.Attachment{}, nil, err
+	}
+	return *found, content, nil
+}
+
+// Digest returns a SHA-256 digest of an anomaly's comments, in their final
+// edited form, and attachment contents. It changes whenever the notes do,
+// so anchoring it in the ledger makes later edits detectable.
+func (s *Store) Digest(anomalyID string) (digest string, comments, attachments int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	h := sha256.New()
+	for _, c := range s.comments[anomalyID] {
+		writeField(h, "comment", c.ID, c.Author, c.Body)
+	}
+	for _, a := range s.attachments[anomalyID] {
+		writeField(h, "attachment", a.ID, a.Name, a.Digest)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), len(s.comments[anomalyID]), len(s.attachments[anomalyID])
+}
+
+// writeField writes length-prefixed values so no two sequences of values
+// hash the same
+func writeField(h hash.Hash, values ...string) {
+	for _, v := range values {
+		fmt.Fprintf(h, "%d:%s", len(v), v)
+	}
+}
+
+func (s *Store) record(anomalyID string, entry models.TimelineEntry) {
+	s.detector.Update(anomalyID, func(a *models.Anomaly) {
+		a.Timeline = append(a.Timeline, entry)
+	})
+}
@@ -0,0 +1,103 @@
+package notes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blob"
+)
+
+func setup(t *testing.T) (*anomaly.Detector, *Store, string) {
+	t.Helper()
+	blobs, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal failed: %v", err)
+	}
+	detector := anomaly.NewDetector()
+	a := detector.Record(&models.Anomaly{Type: models.AnomalyTypeUnknown, Severity: models.SeverityLow})[0]
+	return detector, NewStore(detector, blobs, 16), a.ID
+}
+
+func TestComments(t *testing.T) {
+	detector, store, id := setup(t)
+
+	c, err := store.AddComment(id, "alice", "Node **mars-1** lagging")
+	if err != nil {
+		t.Fatalf("AddComment failed: %v", err)
+	}
+	if _, err := store.EditComment(id, c.ID, "bob", "mine now"); !errors.Is(err, ErrNotAuthor) {
+		t.Errorf("Expected ErrNotAuthor, got %v", err)
+	}
+	edited, err := store.EditComment(id, c.ID, "alice", "Node **mars-1** lagging 40 blocks")
+	if err != nil || edited.EditedAt == nil {
+		t.Fatalf("Expected edit to be recorded, got %+v, %v", edited, err)
+	}
+	if _, err := store.EditComment(id, "missing", "alice", "x"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := store.AddComment("missing", "alice", "x"); err == nil {
+		t.Error("Expected comment on unknown anomaly to fail")
+	}
+
+	comments := store.Comments(id)
+	if len(comments) != 1 || comments[0].Body != edited.Body {
+		t.Errorf("Unexpected comments %+v", comments)
+	}
+	a, _ := detector.GetAnomaly(id)
+	if last := a.Timeline[len(a.Timeline)-1]; last.Action != "commented" || last.Actor != "alice" {
+		t.Errorf("Expected comment in timeline, got %+v", last)
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	_, store, id := setup(t)
+	ctx := context.Background()
+
+	a, err := store.Attach(ctx, id, "node.log", "text/plain", "alice", strings.NewReader("16 bytes exactly"))
+	if err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+	if a.Size != 16 || !strings.HasPrefix(a.Digest, "sha256:") {
+		t.Errorf("Unexpected attachment %+v", a)
+	}
+
+	if _, err := store.Attach(ctx, id, "big.log", "", "alice", strings.NewReader("seventeen bytes!!")); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+	if len(store.Attachments(id)) != 1 {
+		t.Errorf("Expected rejected attachment not to be listed, got %+v", store.Attachments(id))
+	}
+
+	opened, content, err := store.Open(ctx, id, a.ID)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	data, _ := io.ReadAll(content)
+	content.Close()
+	if string(data) != "16 bytes exactly" || opened.Name != "node.log" {
+		t.Errorf("Unexpected content %q for %+v", data, opened)
+	}
+}
+
+func TestDigestChangesWithNotes(t *testing.T) {
+	_, store, id := setup(t)
+
+	empty, _, _ := store.Digest(id)
+	c, _ := store.AddComment(id, "alice", "root cause: clock skew")
+	commented, comments, _ := store.Digest(id)
+	if commented == empty || comments != 1 {
+		t.Fatalf("Expected digest to change with a comment")
+	}
+	if again, _, _ := store.Digest(id); again != commented {
+		t.Error("Expected digest to be stable")
+	}
+	store.EditComment(id, c.ID, "alice", "root cause: NTP outage")
+	if edited, _, _ := store.Digest(id); edited == commented {
+		t.Error("Expected digest to change with an edit")
+	}
+}
@@ -0,0 +1,202 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// ChatStyle is the payload format of a chat incoming webhook
+type ChatStyle string
+
+const (
+	// StyleSlack is Slack's incoming webhook format with attachments,
+	// which Mattermost also accepts
+	StyleSlack      ChatStyle = "slack"
+	StyleMattermost ChatStyle = "mattermost"
+	// StyleTeams is the Microsoft Teams connector message card
+	StyleTeams ChatStyle = "teams"
+)
+
+// digestLimit is how many grouped notifications a digest lists
+const digestLimit = 20
+
+var severityColors = map[models.AnomalySeverity]string{
+	models.SeverityCritical: "#c8414b",
+	models.SeverityHigh:     "#e0803a",
+	models.SeverityMedium:   "#e3b341",
+	models.SeverityLow:      "#2f6fde",
+}
+
+// ChatNotifier posts notifications to a Slack, Mattermost or Teams
+// incoming webhook
+type ChatNotifier struct {
+	style  ChatStyle
+	url    string
+	client *http.Client
+}
+
+// NewChatNotifier creates a notifier posting in style to url using
+// client, or a client with a 10 second timeout
+func NewChatNotifier(style ChatStyle, url string, client *http.Client) (*ChatNotifier, error) {
+	switch style {
+	case StyleSlack, StyleMattermost, StyleTeams:
+	default:
+		return nil, fmt.Errorf("unsupported chat style %q", style)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &ChatNotifier{style: style, url: url, client: client}, nil
+}
+
+// Notify posts n formatted for the chat service
+func (c *ChatNotifier) Notify(ctx context.Context, n Notification) error {
+	if c.style == StyleTeams {
+		return postJSON(ctx, c.client, c.url, teamsCard(n))
+	}
+	return postJSON(ctx, c.client, c.url, slackMessage(n))
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackAttachment struct {
+	Fallback string       `json:"fallback"`
+	Color    string       `json:"color,omitempty"`
+	Title    string       `json:"title"`
+	Text     string       `json:"text,omitempty"`
+	Fields   []slackField `json:"fields,omitempty"`
+	Footer   string       `json:"footer,omitempty"`
+	Ts       int64        `json:"ts,omitempty"`
+}
+
+func slackMessage(n Notification) map[string]interface{} {
+	if n.Kind == KindDigest {
+		return map[string]interface{}{
+			"text": n.Message,
+			"attachments": []slackAttachment{{
+				Fallback: n.Message,
+				Color:    severityColors[highestSeverity(n.Digest)],
+				Title:    Title(n),
+				Text:     strings.Join(digestLines(n, "• "), "\n"),
+			}},
+		}
+	}
+
+	a := n.Anomaly
+	fields := []slackField{
+		{Title: "Severity", Value: string(a.Severity), Short: true},
+		{Title: "Type", Value: string(a.Type), Short: true},
+	}
+	if a.Source != "" {
+		fields = append(fields, slackField{Title: "Source", Value: a.Source, Short: true})
+	}
+	if len(n.Targets) > 0 {
+		fields = append(fields, slackField{Title: "Notify", Value: strings.Join(n.Targets, ", "), Short: true})
+	}
+	return map[string]interface{}{
+		"text": n.Message,
+		"attachments": []slackAttachment{{
+			Fallback: n.Message,
+			Color:    severityColors[a.Severity],
+			Title:    Title(n),
+			Text:     a.Description,
+			Fields:   fields,
+			Footer:   "Anomaly " + a.ID,
+			Ts:       a.DetectedAt.Unix(),
+		}},
+	}
+}
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type teamsSection struct {
+	ActivityTitle string      `json:"activityTitle,omitempty"`
+	Text          string      `json:"text,omitempty"`
+	Facts         []teamsFact `json:"facts,omitempty"`
+}
+
+func teamsCard(n Notification) map[string]interface{} {
+	card := map[string]interface{}{
+		"@type":    "MessageCard",
+		"@context": "https://schema.org/extensions",
+		"summary":  n.Message,
+		"title":    Title(n),
+	}
+	if n.Kind == KindDigest {
+		card["themeColor"] = strings.TrimPrefix(severityColors[highestSeverity(n.Digest)], "#")
+		card["sections"] = []teamsSection{{
+			ActivityTitle: n.Message,
+			Text:          strings.Join(digestLines(n, "- "), "\n\n"),
+		}}
+		return card
+	}
+
+	a := n.Anomaly
+	facts := []teamsFact{
+		{Name: "Severity", Value: string(a.Severity)},
+		{Name: "Type", Value: string(a.Type)},
+		{Name: "Anomaly", Value: a.ID},
+	}
+	if a.Source != "" {
+		facts = append(facts, teamsFact{Name: "Source", Value: a.Source})
+	}
+	if len(n.Targets) > 0 {
+		facts = append(facts, teamsFact{Name: "Notify", Value: strings.Join(n.Targets, ", ")})
+	}
+	card["themeColor"] = strings.TrimPrefix(severityColors[a.Severity], "#")
+	card["sections"] = []teamsSection{{ActivityTitle: n.Message, Text: a.Description, Facts: facts}}
+	return card
+}
+
+// Title is a one line heading for n, used as a chat title or email
+// subject
+func Title(n Notification) string {
+	switch n.Kind {
+	case KindDigest:
+		return fmt.Sprintf("%d grouped anomaly notifications", len(n.Digest))
+	case KindResolved:
+		return fmt.Sprintf("Resolved: %s anomaly %s", n.Anomaly.Type, n.Anomaly.ID)
+	default:
+		return fmt.Sprintf("[%s] %s anomaly %s", strings.ToUpper(string(n.Anomaly.Severity)), n.Anomaly.Type, n.Anomaly.ID)
+	}
+}
+
+// digestLines lists the grouped notifications, noting any beyond the
+// digest limit
+func digestLines(n Notification, bullet string) []string {
+	lines := make([]string, 0, min(len(n.Digest), digestLimit)+1)
+	for i, item := range n.Digest {
+		if i == digestLimit {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(n.Digest)-digestLimit))
+			break
+		}
+		lines = append(lines, bullet+item.Message)
+	}
+	return lines
+}
+
+// highestSeverity returns the most severe anomaly in notifications
+func highestSeverity(notifications []Notification) models.AnomalySeverity {
+	rank := map[models.AnomalySeverity]int{
+		models.SeverityLow: 1, models.SeverityMedium: 2, models.SeverityHigh: 3, models.SeverityCritical: 4,
+	}
+	highest := models.SeverityLow
+	for _, n := range notifications {
+		if rank[n.Anomaly.Severity] > rank[highest] {
+			highest = n.Anomaly.Severity
+		}
+	}
+	return highest
+}
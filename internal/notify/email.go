@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"strings"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/mail"
+)
+
+var emailTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html lang="en">
+<body style="font-family: -apple-system, 'Segoe UI', Helvetica, Arial, sans-serif; color: #1d2330;">
+<h2 style="border-left: 6px solid {{.Color}}; padding-left: 0.5rem;">{{.Title}}</h2>
+<p>{{.Message}}</p>
+{{if .Lines}}<ul>{{range .Lines}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .Facts}}<table style="border-collapse: collapse;">
+{{range .Facts}}<tr><th style="text-align: left; padding: 0.2rem 1rem 0.2rem 0;">{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+</body>
+</html>
+`))
+
+// EmailNotifier mails notifications as HTML with a plain text alternative
+type EmailNotifier struct {
+	sender mail.Sender
+	to     []string
+}
+
+// NewEmailNotifier creates a notifier mailing to through sender
+func NewEmailNotifier(sender mail.Sender, to []string) *EmailNotifier {
+	return &EmailNotifier{sender: sender, to: to}
+}
+
+// Notify mails n
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	data := map[string]interface{}{
+		"Title":   Title(n),
+		"Message": n.Message,
+	}
+	text := []string{n.Message, ""}
+
+	if n.Kind == KindDigest {
+		lines := digestLines(n, "")
+		data["Color"] = severityColors[highestSeverity(n.Digest)]
+		data["Lines"] = lines
+		for _, l := range lines {
+			text = append(text, "- "+l)
+		}
+	} else {
+		a := n.Anomaly
+		facts := [][2]string{{"Severity", string(a.Severity)}, {"Type", string(a.Type)}, {"Anomaly", a.ID}}
+		if a.Source != "" {
+			facts = append(facts, [2]string{"Source", a.Source})
+		}
+		if len(n.Targets) > 0 {
+			facts = append(facts, [2]string{"Notify", strings.Join(n.Targets, ", ")})
+		}
+		data["Color"] = severityColors[a.Severity]
+		data["Facts"] = facts
+		data["Description"] = a.Description
+		for _, f := range facts {
+			text = append(text, f[0]+": "+f[1])
+		}
+		if a.Description != "" {
+			text = append(text, "", a.Description)
+		}
+	}
+
+	var html bytes.Buffer
+	if err := emailTemplate.Execute(&html, data); err != nil {
+		return err
+	}
+	return e.sender.Send(ctx, mail.Message{
+		To:      e.to,
+		Subject: Title(n),
+		Text:    strings.Join(text, "\n") + "\n",
+		HTML:    html.String(),
+	})
+}
@@ -0,0 +1,115 @@
+// Package notify delivers notifications about anomalies, such as SLA
+// escalations, to people and external systems.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// Kind identifies why a notification was sent
+type Kind string
+
+const (
+	KindEscalation Kind = "escalation"
+	KindDetected   Kind = "detected"
+	KindResolved   Kind = "resolved"
+	// KindDigest groups notifications held back by a channel's rate limit
+	KindDigest Kind = "digest"
+)
+
+// Notification is a message about an anomaly for a set of targets, e.g.
+// the on-call tiers named by an escalation step. A digest carries the
+// grouped notifications instead of an anomaly.
+type Notification struct {
+	Kind    Kind           `json:"kind"`
+	Targets []string       `json:"targets"`
+	Message string         `json:"message"`
+	Anomaly models.Anomaly `json:"anomaly"`
+	SentAt  time.Time      `json:"sent_at"`
+	Digest  []Notification `json:"digest,omitempty"`
+}
+
+// Notifier delivers notifications
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// LogNotifier writes notifications to the server log
+type LogNotifier struct{}
+
+// Notify logs n
+func (LogNotifier) Notify(ctx context.Context, n Notification) error {
+	log.Printf("📣 [%s] %s → %s", n.Kind, n.Message, strings.Join(n.Targets, ", "))
+	return nil
+}
+
+// WebhookNotifier posts notifications as JSON to a URL
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier posting to url using client, or a
+// client with a 10 second timeout
+func NewWebhookNotifier(url string, client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookNotifier{url: url, client: client}
+}
+
+// Notify posts n; any non-2xx response is an error
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	return postJSON(ctx, w.client, w.url, n)
+}
+
+// postJSON posts payload as JSON to url; any non-2xx response is an error
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("notification request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Multi delivers to every notifier, returning the first error after
+// trying them all
+type Multi []Notifier
+
+// Notify delivers n to each notifier
+func (m Multi) Notify(ctx context.Context, n Notification) error {
+	var first error
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, n); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var got Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected content type %q", r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	n := Notification{
+		Kind:    KindEscalation,
+		Targets: []string{"oncall-primary"},
+		Message: "Escalation level 1",
+		Anomaly: models.Anomaly{ID: "a1", Type: models.AnomalyTypeDAOVoteFailure},
+	}
+	if err := NewWebhookNotifier(server.URL, nil).Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if got.Message != n.Message || got.Anomaly.ID != "a1" || got.Targets[0] != "oncall-primary" {
+		t.Errorf("Unexpected payload: %+v", got)
+	}
+}
+
+func TestWebhookNotifierFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL, nil).Notify(context.Background(), Notification{}); err == nil {
+		t.Error("Expected non-2xx response to fail")
+	}
+}
+
+type failing struct{ calls int }
+
+func (f *failing) Notify(ctx context.Context, n Notification) error {
+	f.calls++
+	return errors.New("unreachable")
+}
+
+func TestMultiTriesEveryNotifier(t *testing.T) {
+	first, second := &failing{}, &failing{}
+	if err := (Multi{first, LogNotifier{}, second}).Notify(context.Background(), Notification{}); err == nil {
+		t.Error("Expected the first error to be returned")
+	}
+	if first.calls != 1 || second.calls != 1 {
+		t.Errorf("Expected every notifier to be tried, got %d and %d", first.calls, second.calls)
+	}
+}
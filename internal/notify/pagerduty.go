@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// PagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+var pagerDutySeverities = map[models.AnomalySeverity]string{
+	models.SeverityCritical: "critical",
+	models.SeverityHigh:     "error",
+	models.SeverityMedium:   "warning",
+	models.SeverityLow:      "info",
+}
+
+// PagerDutyNotifier sends Events API v2 compatible alerts. Alerts are
+// deduplicated by anomaly ID so escalations update the same alert and
+// resolving the anomaly resolves it.
+type PagerDutyNotifier struct {
+	url        string
+	routingKey string
+	client     *http.Client
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp,omitempty"`
+	Component     string                 `json:"component,omitempty"`
+	Class         string                 `json:"class,omitempty"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+// NewPagerDutyNotifier creates a notifier sending to url, or the PagerDuty
+// Events API when empty, with the integration's routing key
+func NewPagerDutyNotifier(url, routingKey string, client *http.Client) *PagerDutyNotifier {
+	if url == "" {
+		url = PagerDutyEventsURL
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &PagerDutyNotifier{url: url, routingKey: routingKey, client: client}
+}
+
+// Notify triggers an alert for n, or resolves it for a resolved anomaly
+func (p *PagerDutyNotifier) Notify(ctx context.Context, n Notification) error {
+	event := pagerDutyEvent{RoutingKey: p.routingKey, EventAction: "trigger", DedupKey: n.Anomaly.ID}
+
+	switch n.Kind {
+	case KindResolved:
+		event.EventAction = "resolve"
+	case KindDigest:
+		ids := make([]string, len(n.Digest))
+		for i, item := range n.Digest {
+			ids[i] = item.Anomaly.ID
+		}
+		event.DedupKey = fmt.Sprintf("digest-%d", n.SentAt.Unix())
+		event.Payload = &pagerDutyPayload{
+			Summary:       truncateSummary(n.Message),
+			Source:        "anomaly-detector",
+			Severity:      pagerDutySeverities[highestSeverity(n.Digest)],
+			Timestamp:     n.SentAt.UTC().Format(time.RFC3339),
+			Class:         string(KindDigest),
+			CustomDetails: map[string]interface{}{"anomalies": ids, "notifications": digestLines(n, "")},
+		}
+	default:
+		a := n.Anomaly
+		source := a.Source
+		if source == "" {
+			source = "anomaly-detector"
+		}
+		details := map[string]interface{}{"kind": n.Kind, "description": a.Description}
+		if len(n.Targets) > 0 {
+			details["targets"] = n.Targets
+		}
+		for k, v := range a.Metadata {
+			details[k] = v
+		}
+		event.Payload = &pagerDutyPayload{
+			Summary:       truncateSummary(n.Message),
+			Source:        source,
+			Severity:      pagerDutySeverities[a.Severity],
+			Timestamp:     a.DetectedAt.UTC().Format(time.RFC3339),
+			Component:     a.Fingerprint,
+			Class:         string(a.Type),
+			CustomDetails: details,
+		}
+	}
+	if event.Payload != nil && event.Payload.Severity == "" {
+		event.Payload.Severity = "error"
+	}
+	return postJSON(ctx, p.client, p.url, event)
+}
+
+// truncateSummary keeps a summary within PagerDuty's 1024 character limit
+func truncateSummary(s string) string {
+	r := []rune(s)
+	if len(r) <= 1024 {
+		return s
+	}
+	return string(r[:1021]) + "..."
+}
@@ -0,0 +1,397 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/mail"
+)
+
+// ChannelType selects how a channel delivers notifications
+type ChannelType string
+
+const (
+	ChannelLog        ChannelType = "log"
+	ChannelWebhook    ChannelType = "webhook"
+	ChannelSlack      ChannelType = "slack"
+	ChannelMattermost ChannelType = "mattermost"
+	ChannelTeams      ChannelType = "teams"
+	ChannelEmail      ChannelType = "email"
+	ChannelPagerDuty  ChannelType = "pagerduty"
+)
+
+// eventTimeout bounds deliveries started by detector events
+const eventTimeout = 30 * time.Second
+
+// Route selects notifications for a channel. Every non-empty field must
+// match; an empty route matches everything.
+type Route struct {
+	Kinds      []Kind                   `json:"kinds,omitempty"`
+	Types      []models.AnomalyType     `json:"types,omitempty"`
+	Severities []models.AnomalySeverity `json:"severities,omitempty"`
+	Sources    []string                 `json:"sources,omitempty"`
+}
+
+// Matches reports whether n is selected by the route
+func (r Route) Matches(n Notification) bool {
+	return matches(r.Kinds, n.Kind) &&
+		matches(r.Types, n.Anomaly.Type) &&
+		matches(r.Severities, n.Anomaly.Severity) &&
+		matches(r.Sources, n.Anomaly.Source)
+}
+
+func matches[T comparable](allowed []T, v T) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == v {
+			return true
+		}
+	}
+	return false
+}
+
+// RateLimit caps how many notifications a channel sends per window.
+// Notifications over the limit are grouped into one digest sent when the
+// window ends.
+type RateLimit struct {
+	Max           int `json:"max"`
+	WindowSeconds int `json:"window_seconds"`
+}
+
+// Window returns the rate limit window as a duration
+func (l RateLimit) Window() time.Duration {
+	return time.Duration(l.WindowSeconds) * time.Second
+}
+
+// ChannelConfig describes a notification channel. URL is the webhook for
+// webhook and chat channels, or overrides the PagerDuty Events API
+// endpoint. Template, when set, is a Go text/template executed with the
+// Notification that replaces its message.
+type ChannelConfig struct {
+	Name       string      `json:"name"`
+	Type       ChannelType `json:"type"`
+	URL        string      `json:"url,omitempty"`
+	To         []string    `json:"to,omitempty"`
+	RoutingKey string      `json:"routing_key,omitempty"`
+	Routes     []Route     `json:"routes,omitempty"`
+	Template   string      `json:"template,omitempty"`
+	RateLimit  *RateLimit  `json:"rate_limit,omitempty"`
+}
+
+// ChannelStatus summarises a channel for the API, leaving out URLs and
+// keys since they grant access to the destination
+type ChannelStatus struct {
+	Name      string      `json:"name"`
+	Type      ChannelType `json:"type"`
+	Routes    []Route     `json:"routes,omitempty"`
+	RateLimit *RateLimit  `json:"rate_limit,omitempty"`
+	Sent      int         `json:"sent"`
+	Grouped   int         `json:"grouped"`
+	Failed    int         `json:"failed"`
+	Pending   int         `json:"pending"`
+	LastError string      `json:"last_error,omitempty"`
+}
+
+// channel routes, templates and rate limits notifications for one
+// notifier
+type channel struct {
+	config   ChannelConfig
+	notifier Notifier
+	template *template.Template
+
+	mu          sync.Mutex
+	windowStart time.Time
+	inWindow    int
+	pending     []Notification
+	sent        int
+	grouped     int
+	failed      int
+	lastError   string
+}
+
+// Router delivers notifications to the channels whose routes match them
+type Router struct {
+	channels []*channel
+	now      func() time.Time
+}
+
+// NewRouter creates a router for configs. Email channels send through
+// mailer and fail validation when it is nil; HTTP channels use client or
+// a client with a 10 second timeout.
+func NewRouter(configs []ChannelConfig, mailer mail.Sender, client *http.Client) (*Router, error) {
+	r := &Router{now: time.Now}
+	names := make(map[string]bool)
+	for _, cfg := range configs {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("channel name is required")
+		}
+		if names[cfg.Name] {
+			return nil, fmt.Errorf("duplicate channel %s", cfg.Name)
+		}
+		names[cfg.Name] = true
+
+		notifier, err := newNotifier(cfg, mailer, client)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %w", cfg.Name, err)
+		}
+		c := &channel{config: cfg, notifier: notifier}
+		if cfg.Template != "" {
+			if c.template, err = template.New(cfg.Name).Option("missingkey=zero").Parse(cfg.Template); err != nil {
+				return nil, fmt.Errorf("channel %s: invalid template: %w", cfg.Name, err)
+			}
+		}
+		if l := cfg.RateLimit; l != nil && (l.Max <= 0 || l.WindowSeconds <= 0) {
+			return nil, fmt.Errorf("channel %s: rate limit needs a positive max and window_seconds", cfg.Name)
+		}
+		r.channels = append(r.channels, c)
+	}
+	return r, nil
+}
+
+func newNotifier(cfg ChannelConfig, mailer mail.Sender, client *http.Client) (Notifier, error) {
+	switch cfg.Type {
+	case ChannelLog:
+		return LogNotifier{}, nil
+	case ChannelWebhook, ChannelSlack, ChannelMattermost, ChannelTeams:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("url is required")
+		}
+		if cfg.Type == ChannelWebhook {
+			return NewWebhookNotifier(cfg.URL, client), nil
+		}
+		return NewChatNotifier(ChatStyle(cfg.Type), cfg.URL, client)
+	case ChannelEmail:
+		if mailer == nil {
+			return nil, fmt.Errorf("email requires an SMTP relay")
+		}
+		if len(cfg.To) == 0 {
+			return nil, fmt.Errorf("to is required")
+		}
+		return NewEmailNotifier(mailer, cfg.To), nil
+	case ChannelPagerDuty:
+		if cfg.RoutingKey == "" {
+			return nil, fmt.Errorf("routing_key is required")
+		}
+		return NewPagerDutyNotifier(cfg.URL, cfg.RoutingKey, client), nil
+	default:
+		return nil, fmt.Errorf("unsupported channel type %q", cfg.Type)
+	}
+}
+
+// Notify delivers n to every matching channel, returning the first error
+// after trying them all. Channels over their rate limit hold n for their
+// next digest.
+func (r *Router) Notify(ctx context.Context, n Notification) error {
+	if n.SentAt.IsZero() {
+		n.SentAt = r.now()
+	}
+	var first error
+	for _, c := range r.channels {
+		if err := r.notifyChannel(ctx, c, n); err != nil && first == nil {
+			first = fmt.Errorf("channel %s: %w", c.config.Name, err)
+		}
+	}
+	return first
+}
+
+func (r *Router) notifyChannel(ctx context.Context, c *channel, n Notification) error {
+	if !c.routes(n) {
+		return nil
+	}
+	if c.template != nil {
+		var buf bytes.Buffer
+		if err := c.template.Execute(&buf, n); err != nil {
+			return fmt.Errorf("template failed: %w", err)
+		}
+		n.Message = strings.TrimSpace(buf.String())
+	}
+
+	var digest *Notification
+	c.mu.Lock()
+	if l := c.config.RateLimit; l != nil {
+		now := r.now()
+		if now.Sub(c.windowStart) >= l.Window() {
+			digest = c.takeDigest(now)
+			c.windowStart, c.inWindow = now, 0
+		}
+		if c.inWindow >= l.Max {
+			c.pending = append(c.pending, n)
+			c.grouped++
+			c.mu.Unlock()
+			return nil
+		}
+		c.inWindow++
+	}
+	c.mu.Unlock()
+
+	// Held notifications go out before the one that opened a new window
+	if digest != nil {
+		if err := c.deliver(ctx, *digest); err != nil {
+			return err
+		}
+	}
+	return c.deliver(ctx, n)
+}
+
+func (c *channel) routes(n Notification) bool {
+	if len(c.config.Routes) == 0 {
+		return true
+	}
+	for _, route := range c.config.Routes {
+		if route.Matches(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// takeDigest groups the pending notifications; callers hold c.mu
+func (c *channel) takeDigest(now time.Time) *Notification {
+	if len(c.pending) == 0 {
+		return nil
+	}
+	targets := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, p := range c.pending {
+		for _, t := range p.Targets {
+			if !seen[t] {
+				seen[t] = true
+				targets = append(targets, t)
+			}
+		}
+	}
+	digest := &Notification{
+		Kind:    KindDigest,
+		Targets: targets,
+		Message: fmt.Sprintf("%d anomaly notifications were held back by the rate limit of %s", len(c.pending), c.config.Name),
+		SentAt:  now,
+		Digest:  c.pending,
+	}
+	c.pending = nil
+	return digest
+}
+
+func (c *channel) deliver(ctx context.Context, n Notification) error {
+	err := c.notifier.Notify(ctx, n)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.failed++
+		c.lastError = err.Error()
+		return err
+	}
+	c.sent++
+	return nil
+}
+
+// Flush sends the digests of channels whose rate limit window has ended
+// and returns how many were sent
+func (r *Router) Flush(ctx context.Context) (int, error) {
+	now := r.now()
+	sent := 0
+	var first error
+	for _, c := range r.channels {
+		c.mu.Lock()
+		var digest *Notification
+		if l := c.config.RateLimit; l != nil && now.Sub(c.windowStart) >= l.Window() {
+			digest = c.takeDigest(now)
+			if digest != nil {
+				// The digest opens a new window
+				c.windowStart, c.inWindow = now, 1
+			}
+		}
+		c.mu.Unlock()
+
+		if digest == nil {
+			continue
+		}
+		if err := c.deliver(ctx, *digest); err != nil {
+			if first == nil {
+				first = fmt.Errorf("channel %s: %w", c.config.Name, err)
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, first
+}
+
+// HandleEvent notifies channels of newly detected and resolved anomalies.
+// Suppressed anomalies are left out. Delivery happens in the background
+// so detection is not held up by slow destinations.
+func (r *Router) HandleEvent(event anomaly.Event) {
+	a := event.Anomaly
+	var n Notification
+	switch {
+	case event.Type == anomaly.EventDetected && !a.Suppressed:
+		n = Notification{
+			Kind:    KindDetected,
+			Message: fmt.Sprintf("Detected %s anomaly %s (%s): %s", a.Severity, a.ID, a.Type, strings.TrimSpace(a.Description)),
+		}
+	case event.Type == anomaly.EventResolved && !a.Suppressed:
+		n = Notification{
+			Kind:    KindResolved,
+			Message: fmt.Sprintf("Resolved %s anomaly %s (%s): %s", a.Severity, a.ID, a.Type, strings.TrimSpace(a.Resolution)),
+		}
+	default:
+		return
+	}
+	n.Anomaly = a
+	n.SentAt = r.now()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), eventTimeout)
+		defer cancel()
+		if err := r.Notify(ctx, n); err != nil {
+			log.Printf("⚠️  Failed to notify %s of anomaly %s: %v", n.Kind, a.ID, err)
+		}
+	}()
+}
+
+// Channels returns the status of every channel in configuration order
+func (r *Router) Channels() []ChannelStatus {
+	statuses := make([]ChannelStatus, len(r.channels))
+	for i, c := range r.channels {
+		c.mu.Lock()
+		statuses[i] = ChannelStatus{
+			Name:      c.config.Name,
+			Type:      c.config.Type,
+			Routes:    c.config.Routes,
+			RateLimit: c.config.RateLimit,
+			Sent:      c.sent,
+			Grouped:   c.grouped,
+			Failed:    c.failed,
+			Pending:   len(c.pending),
+			LastError: c.lastError,
+		}
+		c.mu.Unlock()
+	}
+	return statuses
+}
+
+// LoadFile reads channel configurations from a JSON array
+func LoadFile(path string) ([]ChannelConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification channels: %w", err)
+	}
+
+	var configs []ChannelConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("invalid notification channels file %s: %w", path, err)
+	}
+	return configs, nil
+}
@@ -0,0 +1,187 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/mail"
+)
+
+// recorder is a webhook endpoint keeping the JSON bodies it receives
+type recorder struct {
+	*httptest.Server
+	mu     sync.Mutex
+	bodies []map[string]interface{}
+}
+
+func newRecorder(t *testing.T) *recorder {
+	r := &recorder{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(req.Body).Decode(&body)
+		r.mu.Lock()
+		r.bodies = append(r.bodies, body)
+		r.mu.Unlock()
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *recorder) received() []map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]map[string]interface{}(nil), r.bodies...)
+}
+
+type fakeMailer struct {
+	sent []mail.Message
+}
+
+func (f *fakeMailer) Send(_ context.Context, m mail.Message) error {
+	f.sent = append(f.sent, m)
+	return nil
+}
+
+func detected(id string, severity models.AnomalySeverity) Notification {
+	return Notification{
+		Kind:    KindDetected,
+		Message: "Detected " + string(severity) + " anomaly " + id,
+		Anomaly: models.Anomaly{
+			ID:          id,
+			Type:        models.AnomalyTypeLedgerDivergence,
+			Severity:    severity,
+			Source:      "manus",
+			Description: "Ledger hashes diverged",
+		},
+	}
+}
+
+func TestRouterRoutesAndTemplates(t *testing.T) {
+	critical, all := newRecorder(t), newRecorder(t)
+	router, err := NewRouter([]ChannelConfig{
+		{
+			Name:     "pager-chat",
+			Type:     ChannelSlack,
+			URL:      critical.URL,
+			Routes:   []Route{{Severities: []models.AnomalySeverity{models.SeverityCritical}, Sources: []string{"manus"}}},
+			Template: "{{.Anomaly.Severity}} on {{.Anomaly.Source}}: {{.Anomaly.Description}}",
+		},
+		{Name: "everything", Type: ChannelWebhook, URL: all.URL},
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+
+	router.Notify(context.Background(), detected("a1", models.SeverityCritical))
+	router.Notify(context.Background(), detected("a2", models.SeverityLow))
+
+	if got := critical.received(); len(got) != 1 || got[0]["text"] != "critical on manus: Ledger hashes diverged" {
+		t.Errorf("Expected only the templated critical anomaly, got %v", got)
+	}
+	if got := all.received(); len(got) != 2 || got[1]["message"] != "Detected low anomaly a2" {
+		t.Errorf("Expected both anomalies unchanged, got %v", got)
+	}
+}
+
+func TestRouterGroupsBurstsIntoDigest(t *testing.T) {
+	teams := newRecorder(t)
+	router, err := NewRouter([]ChannelConfig{
+		{Name: "ops", Type: ChannelTeams, URL: teams.URL, RateLimit: &RateLimit{Max: 2, WindowSeconds: 60}},
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	router.now = func() time.Time { return now }
+
+	for _, id := range []string{"a1", "a2", "a3", "a4", "a5"} {
+		router.Notify(context.Background(), detected(id, models.SeverityHigh))
+	}
+	if got := teams.received(); len(got) != 2 {
+		t.Fatalf("Expected 2 notifications within the limit, got %d", len(got))
+	}
+	if n, _ := router.Flush(context.Background()); n != 0 {
+		t.Errorf("Expected no digest before the window ends, got %d", n)
+	}
+
+	now = now.Add(time.Minute)
+	if n, err := router.Flush(context.Background()); n != 1 || err != nil {
+		t.Fatalf("Expected one digest, got %d, %v", n, err)
+	}
+	got := teams.received()
+	if len(got) != 3 || got[2]["title"] != "3 grouped anomaly notifications" || got[2]["@type"] != "MessageCard" {
+		t.Errorf("Unexpected digest %v", got[len(got)-1])
+	}
+	if status := router.Channels()[0]; status.Sent != 3 || status.Grouped != 3 || status.Pending != 0 {
+		t.Errorf("Unexpected status %+v", status)
+	}
+}
+
+func TestPagerDutyTriggersAndResolves(t *testing.T) {
+	events := newRecorder(t)
+	pd := NewPagerDutyNotifier(events.URL, "routing-key", nil)
+
+	trigger := detected("a1", models.SeverityHigh)
+	resolved := trigger
+	resolved.Kind = KindResolved
+	pd.Notify(context.Background(), trigger)
+	pd.Notify(context.Background(), resolved)
+
+	got := events.received()
+	if len(got) != 2 {
+		t.Fatalf("Expected two events, got %v", got)
+	}
+	payload, _ := got[0]["payload"].(map[string]interface{})
+	if got[0]["event_action"] != "trigger" || got[0]["dedup_key"] != "a1" || got[0]["routing_key"] != "routing-key" ||
+		payload["severity"] != "error" || payload["source"] != "manus" {
+		t.Errorf("Unexpected trigger %v", got[0])
+	}
+	if got[1]["event_action"] != "resolve" || got[1]["dedup_key"] != "a1" || got[1]["payload"] != nil {
+		t.Errorf("Unexpected resolve %v", got[1])
+	}
+}
+
+func TestEmailNotifier(t *testing.T) {
+	mailer := &fakeMailer{}
+	router, err := NewRouter([]ChannelConfig{{Name: "leads", Type: ChannelEmail, To: []string{"leads@example.com"}}}, mailer, nil)
+	if err != nil {
+		t.Fatalf("NewRouter failed: %v", err)
+	}
+	n := detected("a1", models.SeverityCritical)
+	n.Anomaly.Description = "<script>alert(1)</script>"
+	router.Notify(context.Background(), n)
+
+	if len(mailer.sent) != 1 {
+		t.Fatalf("Expected one email, got %d", len(mailer.sent))
+	}
+	m := mailer.sent[0]
+	if m.Subject != "[CRITICAL] ledger_divergence anomaly a1" || !strings.Contains(m.Text, "Source: manus") {
+		t.Errorf("Unexpected email %+v", m)
+	}
+	if strings.Contains(m.HTML, "<script>") {
+		t.Error("Expected the description to be escaped in HTML")
+	}
+}
+
+func TestInvalidChannels(t *testing.T) {
+	for _, cfg := range []ChannelConfig{
+		{Type: ChannelLog},
+		{Name: "a", Type: "fax"},
+		{Name: "a", Type: ChannelSlack},
+		{Name: "a", Type: ChannelEmail, To: []string{"ops@example.com"}},
+		{Name: "a", Type: ChannelPagerDuty},
+		{Name: "a", Type: ChannelLog, Template: "{{.Nope"},
+		{Name: "a", Type: ChannelLog, RateLimit: &RateLimit{Max: 1}},
+	} {
+		if _, err := NewRouter([]ChannelConfig{cfg}, nil, nil); err == nil {
+			t.Errorf("Expected %+v to be rejected", cfg)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package oncall
+
+import (
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// autoAssigner is recorded as the actor for on-call assignments
+const autoAssigner = "oncall"
+
+// Assigner assigns anomalies to users and teams
+type Assigner struct {
+	detector *anomaly.Detector
+	roster   *Roster
+	now      func() time.Time
+}
+
+// NewAssigner creates an assigner using roster's teams and rotations
+func NewAssigner(detector *anomaly.Detector, roster *Roster) *Assigner {
+	return &Assigner{detector: detector, roster: roster, now: time.Now}
+}
+
+// Roster returns the teams and rotations the assigner uses
+func (as *Assigner) Roster() *Roster {
+	return as.roster
+}
+
+// HandleEvent assigns newly detected anomalies to whoever is on call for
+// the first rotation covering them. Suppressed, resolved and already
+// assigned anomalies are left alone.
+func (as *Assigner) HandleEvent(event anomaly.Event) {
+	a := event.Anomaly
+	if event.Type != anomaly.EventDetected || a.Suppressed || a.Status == models.StatusResolved || a.Assignee != nil {
+		return
+	}
+	rotation, user, ok := as.roster.Route(&a)
+	if !ok {
+		return
+	}
+
+	as.detector.Assign(a.ID, &models.Assignment{
+		Name:       user,
+		Kind:       models.AssigneeUser,
+		AssignedBy: autoAssigner,
+		AssignedAt: as.now(),
+		Reason:     "on call for " + rotation,
+	})
+}
+
+// Assign makes assignee, a team name or a user, the owner of an anomaly.
+// An empty assignee clears the owner.
+func (as *Assigner) Assign(id, assignee, by string) error {
+	if assignee == "" {
+		return as.detector.Assign(id, nil)
+	}
+
+	kind := models.AssigneeUser
+	if as.roster.IsTeam(assignee) {
+		kind = models.AssigneeTeam
+	}
+	return as.detector.Assign(id, &models.Assignment{
+		Name:       assignee,
+		Kind:       kind,
+		AssignedBy: by,
+		AssignedAt: as.now(),
+	})
+}
@@ -0,0 +1,268 @@
+// Package oncall assigns anomalies to owners. Rotations hand off between
+// their members on a fixed shift, weekly by default, with overrides for
+// swaps and absences; new anomalies are assigned to whoever is on call
+// for their source or type.
+package oncall
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// ErrUnknownRotation is returned for a rotation name that is not defined
+var ErrUnknownRotation = errors.New("unknown rotation")
+
+// defaultShift is how long each member is on call when a rotation does
+// not say
+const defaultShift = 7 * 24 * time.Hour
+
+// Team is a named group of users anomalies can be assigned to
+type Team struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+}
+
+// Override puts User on call for a rotation from Start until End
+type Override struct {
+	User      string    `json:"user"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	CreatedBy string    `json:"created_by,omitempty"`
+}
+
+// Rotation hands on-call duty between Members every ShiftDays, starting
+// with the first member at Start. It covers anomalies from Sources or of
+// Types; with neither set it covers every anomaly.
+type Rotation struct {
+	Name      string               `json:"name"`
+	Team      string               `json:"team,omitempty"`
+	Members   []string             `json:"members"`
+	Start     time.Time            `json:"start"`
+	ShiftDays int                  `json:"shift_days,omitempty"`
+	Sources   []string             `json:"sources,omitempty"`
+	Types     []models.AnomalyType `json:"types,omitempty"`
+	Overrides []Override           `json:"overrides,omitempty"`
+}
+
+// Config defines teams and rotations
+type Config struct {
+	Teams     []Team     `json:"teams"`
+	Rotations []Rotation `json:"rotations"`
+}
+
+// RotationStatus is a rotation with who is on call now
+type RotationStatus struct {
+	Rotation
+	OnCall      string    `json:"on_call"`
+	OnCallUntil time.Time `json:"on_call_until"`
+}
+
+func (r *Rotation) shift() time.Duration {
+	if r.ShiftDays <= 0 {
+		return defaultShift
+	}
+	return time.Duration(r.ShiftDays) * 24 * time.Hour
+}
+
+// onCall returns who is on call at and until when
+func (r *Rotation) onCall(at time.Time) (string, time.Time) {
+	// The most recently added override wins
+	for i := len(r.Overrides) - 1; i >= 0; i-- {
+		o := r.Overrides[i]
+		if !at.Before(o.Start) && at.Before(o.End) {
+			return o.User, o.End
+		}
+	}
+
+	shift := r.shift()
+	n := at.Sub(r.Start) / shift
+	if at.Before(r.Start) && at.Sub(r.Start)%shift != 0 {
+		n--
+	}
+	i := int(n) % len(r.Members)
+	if i < 0 {
+		i += len(r.Members)
+	}
+	return r.Members[i], r.Start.Add((n + 1) * shift)
+}
+
+func (r *Rotation) covers(a *models.Anomaly) bool {
+	if len(r.Sources) == 0 && len(r.Types) == 0 {
+		return true
+	}
+	return slices.Contains(r.Sources, a.Source) || slices.Contains(r.Types, a.Type)
+}
+
+// Roster holds teams and rotations
+type Roster struct {
+	teams     map[string]Team
+	rotations []*Rotation
+	now       func() time.Time
+	mu        sync.RWMutex
+}
+
+// NewRoster validates cfg and creates a roster
+func NewRoster(cfg Config) (*Roster, error) {
+	r := &Roster{teams: make(map[string]Team), now: time.Now}
+	for _, team := range cfg.Teams {
+		if team.Name == "" {
+			return nil, fmt.Errorf("team name is required")
+		}
+		if _, exists := r.teams[team.Name]; exists {
+			return nil, fmt.Errorf("duplicate team %s", team.Name)
+		}
+		r.teams[team.Name] = team
+	}
+
+	names := make(map[string]bool)
+	for _, rotation := range cfg.Rotations {
+		rotation := rotation
+		switch {
+		case rotation.Name == "":
+			return nil, fmt.Errorf("rotation name is required")
+		case names[rotation.Name]:
+			return nil, fmt.Errorf("duplicate rotation %s", rotation.Name)
+		case len(rotation.Members) == 0:
+			return nil, fmt.Errorf("rotation %s: members are required", rotation.Name)
+		case rotation.Start.IsZero():
+			return nil, fmt.Errorf("rotation %s: start is required", rotation.Name)
+		}
+		if rotation.Team != "" {
+			if _, ok := r.teams[rotation.Team]; !ok {
+				return nil, fmt.Errorf("rotation %s: unknown team %s", rotation.Name, rotation.Team)
+			}
+		}
+		for _, o := range rotation.Overrides {
+			if err := validateOverride(o); err != nil {
+				return nil, fmt.Errorf("rotation %s: %w", rotation.Name, err)
+			}
+		}
+		names[rotation.Name] = true
+		r.rotations = append(r.rotations, &rotation)
+	}
+	return r, nil
+}
+
+func validateOverride(o Override) error {
+	if o.User == "" {
+		return fmt.Errorf("override user is required")
+	}
+	if !o.End.After(o.Start) {
+		return fmt.Errorf("override end must be after start")
+	}
+	return nil
+}
+
+// Route returns the first rotation covering a and who is on call for it
+func (r *Roster) Route(a *models.Anomaly) (rotation, user string, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rot := range r.rotations {
+		if rot.covers(a) {
+			user, _ := rot.onCall(r.now())
+			return rot.Name, user, true
+		}
+	}
+	return "", "", false
+}
+
+// AddOverride puts o.User on call for a rotation during o's time range
+func (r *Roster) AddOverride(rotation string, o Override) error {
+	if err := validateOverride(o); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rot := range r.rotations {
+		if rot.Name == rotation {
+			// Replace rather than append to a slice readers may share
+			rot.Overrides = append(slices.Clip(rot.Overrides), o)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownRotation, rotation)
+}
+
+// Rotations returns every rotation with who is on call now
+func (r *Roster) Rotations() []RotationStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := r.now()
+	statuses := make([]RotationStatus, 0, len(r.rotations))
+	for _, rot := range r.rotations {
+		user, until := rot.onCall(now)
+		statuses = append(statuses, RotationStatus{Rotation: *rot, OnCall: user, OnCallUntil: until})
+	}
+	return statuses
+}
+
+// Teams returns every team
+func (r *Roster) Teams() []Team {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	teams := make([]Team, 0, len(r.teams))
+	for _, team := range r.teams {
+		teams = append(teams, team)
+	}
+	slices.SortFunc(teams, func(a, b Team) int {
+		if a.Name < b.Name {
+			return -1
+		}
+		if a.Name > b.Name {
+			return 1
+		}
+		return 0
+	})
+	return teams
+}
+
+// IsTeam reports whether name is a team
+func (r *Roster) IsTeam(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.teams[name]
+	return ok
+}
+
+// Owns reports whether user is a's assignee or a member of its assigned
+// team
+func (r *Roster) Owns(user string, a *models.Anomaly) bool {
+	if a.Assignee == nil || user == "" {
+		return false
+	}
+	if a.Assignee.Kind != models.AssigneeTeam {
+		return a.Assignee.Name == user
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Contains(r.teams[a.Assignee.Name].Members, user)
+}
+
+// LoadFile reads teams and rotations from a JSON object
+func LoadFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read on-call config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("invalid on-call file %s: %w", path, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,137 @@
+package oncall
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+var start = time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+var testConfig = Config{
+	Teams: []Team{{Name: "ledger", Members: []string{"alice", "bob", "carol"}}},
+	Rotations: []Rotation{
+		{
+			Name:    "ledger-primary",
+			Team:    "ledger",
+			Members: []string{"alice", "bob", "carol"},
+			Start:   start,
+			Types:   []models.AnomalyType{models.AnomalyTypeLedgerDivergence},
+		},
+		{Name: "catch-all", Members: []string{"dave"}, Start: start, ShiftDays: 1},
+	},
+}
+
+func newRoster(t *testing.T, at time.Time) *Roster {
+	t.Helper()
+	roster, err := NewRoster(testConfig)
+	if err != nil {
+		t.Fatalf("NewRoster failed: %v", err)
+	}
+	roster.now = func() time.Time { return at }
+	return roster
+}
+
+func TestWeeklyRotation(t *testing.T) {
+	rotation := testConfig.Rotations[0]
+	for _, tc := range []struct {
+		at   time.Time
+		want string
+	}{
+		{start, "alice"},
+		{start.Add(7*24*time.Hour - time.Second), "alice"},
+		{start.Add(7 * 24 * time.Hour), "bob"},
+		{start.Add(3 * 7 * 24 * time.Hour), "alice"},
+		{start.Add(-time.Hour), "carol"},
+	} {
+		if got, _ := rotation.onCall(tc.at); got != tc.want {
+			t.Errorf("At %s expected %s, got %s", tc.at, tc.want, got)
+		}
+	}
+	if _, until := rotation.onCall(start.Add(time.Hour)); !until.Equal(start.Add(7 * 24 * time.Hour)) {
+		t.Errorf("Expected handoff after a week, got %s", until)
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	roster := newRoster(t, start.Add(2*24*time.Hour))
+	err := roster.AddOverride("ledger-primary", Override{User: "erin", Start: start.Add(24 * time.Hour), End: start.Add(3 * 24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("AddOverride failed: %v", err)
+	}
+
+	status := roster.Rotations()[0]
+	if status.OnCall != "erin" || !status.OnCallUntil.Equal(start.Add(3*24*time.Hour)) {
+		t.Errorf("Expected override to be on call, got %s until %s", status.OnCall, status.OnCallUntil)
+	}
+
+	if err := roster.AddOverride("missing", Override{User: "erin", Start: start, End: start.Add(time.Hour)}); !errors.Is(err, ErrUnknownRotation) {
+		t.Errorf("Expected ErrUnknownRotation, got %v", err)
+	}
+	if err := roster.AddOverride("ledger-primary", Override{User: "erin", Start: start, End: start}); err == nil {
+		t.Error("Expected an empty override to be rejected")
+	}
+}
+
+func TestNewAnomaliesAreAssignedToOnCall(t *testing.T) {
+	detector := anomaly.NewDetector()
+	assigner := NewAssigner(detector, newRoster(t, start.Add(8*24*time.Hour)))
+	detector.Subscribe(assigner.HandleEvent)
+
+	recorded := detector.Record(
+		&models.Anomaly{Type: models.AnomalyTypeLedgerDivergence, Severity: models.SeverityHigh},
+		&models.Anomaly{Type: models.AnomalyTypeUnknown, Severity: models.SeverityLow},
+		&models.Anomaly{Type: models.AnomalyTypeUnknown, Severity: models.SeverityLow, Suppressed: true},
+	)
+
+	for i, want := range []string{"bob", "dave", ""} {
+		stored, _ := detector.GetAnomaly(recorded[i].ID)
+		switch {
+		case want == "" && stored.Assignee != nil:
+			t.Errorf("Expected suppressed anomaly to stay unassigned, got %+v", stored.Assignee)
+		case want != "" && (stored.Assignee == nil || stored.Assignee.Name != want):
+			t.Errorf("Expected %s to be assigned, got %+v", want, stored.Assignee)
+		}
+	}
+}
+
+func TestReassignmentHistory(t *testing.T) {
+	detector := anomaly.NewDetector()
+	roster := newRoster(t, start)
+	assigner := NewAssigner(detector, roster)
+	a := detector.Record(&models.Anomaly{Type: models.AnomalyTypeUnknown, Severity: models.SeverityLow})[0]
+
+	if err := assigner.Assign(a.ID, "alice", "carol"); err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+	if err := assigner.Assign(a.ID, "ledger", "alice"); err != nil {
+		t.Fatalf("Reassign failed: %v", err)
+	}
+
+	stored, _ := detector.GetAnomaly(a.ID)
+	if stored.Assignee.Kind != models.AssigneeTeam || !roster.Owns("bob", stored) || roster.Owns("dave", stored) {
+		t.Errorf("Expected the ledger team to own the anomaly, got %+v", stored.Assignee)
+	}
+
+	var history []string
+	for _, e := range stored.Timeline {
+		history = append(history, e.Action+" "+e.Detail)
+	}
+	want := []string{"detected ", "assigned to alice", "reassigned from alice to ledger"}
+	if len(history) != len(want) {
+		t.Fatalf("Expected timeline %q, got %q", want, history)
+	}
+	for i := range want {
+		if history[i] != want[i] {
+			t.Errorf("Expected timeline %q, got %q", want, history)
+		}
+	}
+
+	detector.ResolveAnomaly(a.ID, "fixed", "")
+	if err := assigner.Assign(a.ID, "bob", "alice"); !errors.Is(err, anomaly.ErrResolved) {
+		t.Errorf("Expected ErrResolved, got %v", err)
+	}
+}
@@ -0,0 +1,369 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/timeseries"
+)
+
+// SourcePrefix starts the source of anomalies raised by rules. Anomaly
+// rules ignore events for these so rules cannot trigger each other in a
+// cycle.
+const SourcePrefix = "rule:"
+
+// ErrNoRule is returned for a rule name that is not loaded
+var ErrNoRule = errors.New("rule not found")
+
+// Stats counts a rule's evaluations
+type Stats struct {
+	Evaluations int        `json:"evaluations"`
+	Matches     int        `json:"matches"`
+	Fires       int        `json:"fires"`
+	Errors      int        `json:"errors"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastFired   *time.Time `json:"last_fired,omitempty"`
+	// MeanEvalTime is the average time to evaluate the expression
+	MeanEvalTime time.Duration `json:"mean_eval_time_ns"`
+}
+
+// Status is a loaded rule with its statistics
+type Status struct {
+	Rule
+	Pending int   `json:"pending"`
+	Stats   Stats `json:"stats"`
+}
+
+// FixtureResult reports one fixture run
+type FixtureResult struct {
+	Name         string   `json:"name"`
+	Passed       bool     `json:"passed"`
+	Expected     int      `json:"expected"`
+	Fired        int      `json:"fired"`
+	Descriptions []string `json:"descriptions,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// group tracks a condition holding for one series or anomaly
+type group struct {
+	since time.Time
+	fired bool
+}
+
+type ruleState struct {
+	rule     *compiled
+	groups   map[string]*group
+	stats    Stats
+	evalTime time.Duration
+}
+
+// Engine evaluates rules against metric samples and anomaly events and
+// records the anomalies they raise
+type Engine struct {
+	detector *anomaly.Detector
+	rules    map[string]*ruleState
+	order    []string
+	now      func() time.Time
+	mu       sync.Mutex
+}
+
+// NewEngine creates an engine that records anomalies with detector. A nil
+// detector only returns them, as fixtures do.
+func NewEngine(detector *anomaly.Detector) *Engine {
+	return &Engine{
+		detector: detector,
+		rules:    make(map[string]*ruleState),
+		now:      time.Now,
+	}
+}
+
+// Add validates rules, runs their fixtures and loads them, replacing rules
+// of the same name. Nothing is loaded if any rule is invalid or fails a
+// fixture.
+func (e *Engine) Add(rules ...Rule) error {
+	var loaded []*compiled
+	for _, r := range rules {
+		c, err := compile(r)
+		if err != nil {
+			return err
+		}
+		if e.detector != nil {
+			if registry := e.detector.Registry(); registry != nil {
+				if _, ok := registry.Get(r.Type); !ok {
+					return fmt.Errorf("rule %s: %w: %s", r.Name, anomaly.ErrUnknownType, r.Type)
+				}
+			}
+		}
+		for _, result := range runFixtures(c) {
+			if !result.Passed {
+				return fmt.Errorf("rule %s: fixture %q failed: expected %d anomalies, fired %d%s",
+					r.Name, result.Name, result.Expected, result.Fired, errorSuffix(result.Error))
+			}
+		}
+		loaded = append(loaded, c)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, c := range loaded {
+		if _, exists := e.rules[c.Name]; !exists {
+			e.order = append(e.order, c.Name)
+		}
+		e.rules[c.Name] = &ruleState{rule: c, groups: make(map[string]*group)}
+	}
+	return nil
+}
+
+// Remove unloads a rule
+func (e *Engine) Remove(name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.rules[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrNoRule, name)
+	}
+	delete(e.rules, name)
+	for i, n := range e.order {
+		if n == name {
+			e.order = append(e.order[:i:i], e.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Rules returns loaded rules with their statistics
+func (e *Engine) Rules() []Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	statuses := make([]Status, 0, len(e.order))
+	for _, name := range e.order {
+		state := e.rules[name]
+		status := Status{Rule: state.rule.Rule, Stats: state.stats}
+		for _, g := range state.groups {
+			if !g.fired {
+				status.Pending++
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Test validates a rule and runs its fixtures without loading it
+func Test(r Rule) ([]FixtureResult, error) {
+	c, err := compile(r)
+	if err != nil {
+		return nil, err
+	}
+	return runFixtures(c), nil
+}
+
+// EvaluateMetrics evaluates metric rules against samples and records the
+// anomalies raised
+func (e *Engine) EvaluateMetrics(samples ...timeseries.Sample) []*models.Anomaly {
+	var raised []*models.Anomaly
+	for _, sample := range samples {
+		at := sample.Time
+		if at.IsZero() {
+			at = e.now()
+		}
+		raised = append(raised, e.evaluate(InputMetric, timeseries.SeriesKey(sample.Metric, sample.Labels), at, metricEnv(sample, at))...)
+	}
+	return e.record(raised)
+}
+
+// HandleEvent evaluates anomaly rules against a detector event. It is
+// meant to be registered with Detector.Subscribe.
+func (e *Engine) HandleEvent(event anomaly.Event) {
+	if strings.HasPrefix(event.Anomaly.Source, SourcePrefix) {
+		return
+	}
+	e.record(e.evaluate(InputAnomaly, event.Anomaly.ID, e.now(), anomalyEnv(string(event.Type), event.Anomaly)))
+}
+
+// evaluate runs every rule for input against env and returns the anomalies
+// raised, without recording them
+func (e *Engine) evaluate(input Input, key string, at time.Time, env map[string]interface{}) []*models.Anomaly {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var raised []*models.Anomaly
+	for _, name := range e.order {
+		state := e.rules[name]
+		r := state.rule
+		if r.On != input {
+			continue
+		}
+
+		started := time.Now()
+		matched, err := r.expr.Match(env)
+		state.evalTime += time.Since(started)
+		state.stats.Evaluations++
+		state.stats.MeanEvalTime = state.evalTime / time.Duration(state.stats.Evaluations)
+		if err != nil {
+			state.stats.Errors++
+			state.stats.LastError = err.Error()
+		}
+		if !matched {
+			delete(state.groups, key)
+			continue
+		}
+		state.stats.Matches++
+
+		g, ok := state.groups[key]
+		if !ok {
+			g = &group{since: at}
+			state.groups[key] = g
+		}
+		if g.fired || at.Sub(g.since) < time.Duration(r.For) {
+			continue
+		}
+		g.fired = true
+		state.stats.Fires++
+		fired := at
+		state.stats.LastFired = &fired
+
+		raised = append(raised, r.anomaly(key, g.since, at, env))
+	}
+	return raised
+}
+
+func (e *Engine) record(raised []*models.Anomaly) []*models.Anomaly {
+	if e.detector == nil || len(raised) == 0 {
+		return raised
+	}
+	return e.detector.Record(raised...)
+}
+
+func (c *compiled) anomaly(key string, since, at time.Time, env map[string]interface{}) *models.Anomaly {
+	metadata := map[string]interface{}{
+		"rule":          c.Name,
+		"expr":          c.Expr,
+		"group":         key,
+		"pending_since": since,
+	}
+	switch c.On {
+	case InputMetric:
+		metadata["metric"] = env["metric"]
+		metadata["value"] = env["value"]
+		if labels, ok := env["labels"].(map[string]string); ok && len(labels) > 0 {
+			metadata["labels"] = labels
+			for k, v := range labels {
+				if _, taken := metadata[k]; !taken {
+					metadata[k] = v
+				}
+			}
+		}
+	case InputAnomaly:
+		metadata["anomaly_id"] = env["id"]
+		metadata["anomaly_type"] = env["type"]
+	}
+
+	return &models.Anomaly{
+		Type:        c.Type,
+		Description: c.render(env),
+		Severity:    c.Severity,
+		Status:      models.StatusDetected,
+		DetectedAt:  at,
+		Metadata:    metadata,
+		Source:      SourcePrefix + c.Name,
+	}
+}
+
+// fixtureStart anchors fixture input offsets
+var fixtureStart = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// runFixtures feeds each fixture to a fresh engine holding only c
+func runFixtures(c *compiled) []FixtureResult {
+	results := make([]FixtureResult, 0, len(c.Tests))
+	for i, fixture := range c.Tests {
+		result := FixtureResult{Name: fixture.Name, Expected: fixture.Fires}
+		if result.Name == "" {
+			result.Name = fmt.Sprintf("fixture %d", i+1)
+		}
+
+		engine := NewEngine(nil)
+		engine.rules[c.Name] = &ruleState{rule: c, groups: make(map[string]*group)}
+		engine.order = []string{c.Name}
+
+		var raised []*models.Anomaly
+		for _, input := range fixture.Inputs {
+			at := fixtureStart.Add(time.Duration(input.At))
+			switch {
+			case input.Anomaly != nil:
+				a := *input.Anomaly
+				if a.ID == "" {
+					a.ID = "fixture"
+				}
+				event := input.Event
+				if event == "" {
+					event = string(anomaly.EventDetected)
+				}
+				raised = append(raised, engine.evaluate(InputAnomaly, a.ID, at, anomalyEnv(event, a))...)
+			default:
+				sample := timeseries.Sample{Metric: input.Metric, Labels: input.Labels, Time: at, Value: input.Value}
+				raised = append(raised, engine.evaluate(InputMetric, timeseries.SeriesKey(sample.Metric, sample.Labels), at, metricEnv(sample, at))...)
+			}
+		}
+
+		result.Fired = len(raised)
+		for _, a := range raised {
+			result.Descriptions = append(result.Descriptions, a.Description)
+		}
+		result.Passed = result.Fired == fixture.Fires
+		if result.Passed && fixture.Description != "" && (len(raised) == 0 || raised[0].Description != fixture.Description) {
+			result.Passed = false
+			result.Error = "expected description " + fixture.Description
+		}
+		if state := engine.rules[c.Name]; state.stats.Errors > 0 && result.Error == "" {
+			result.Error = state.stats.LastError
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func metricEnv(sample timeseries.Sample, at time.Time) map[string]interface{} {
+	labels := sample.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	return map[string]interface{}{
+		"metric": sample.Metric,
+		"value":  sample.Value,
+		"labels": labels,
+		"time":   at.Format(time.RFC3339),
+	}
+}
+
+func anomalyEnv(event string, a models.Anomaly) map[string]interface{} {
+	metadata := a.Metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"event":       event,
+		"id":          a.ID,
+		"type":        string(a.Type),
+		"severity":    string(a.Severity),
+		"status":      string(a.Status),
+		"source":      a.Source,
+		"description": a.Description,
+		"metadata":    metadata,
+	}
+}
+
+func errorSuffix(msg string) string {
+	if msg == "" {
+		return ""
+	}
+	return " (" + msg + ")"
+}
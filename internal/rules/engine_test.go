@@ -0,0 +1,220 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/timeseries"
+)
+
+const latencyRule = `{
+	"name": "mars-latency",
+	"on": "metric",
+	"expr": "metric == \"node_latency_ms\" && labels.route == \"Earth-Mars\" && value > 400",
+	"for": "2m",
+	"type": "node_desync",
+	"severity": "high",
+	"description": "{{.labels.route}} latency {{.value}}ms above 400ms{{.labels.missing}}",
+	"tests": [
+		{"name": "sustained", "fires": 1, "description": "Earth-Mars latency 460ms above 400ms", "inputs": [
+			{"at": "0s", "metric": "node_latency_ms", "labels": {"route": "Earth-Mars"}, "value": 450},
+			{"at": "1m", "metric": "node_latency_ms", "labels": {"route": "Earth-Mars"}, "value": 455},
+			{"at": "2m", "metric": "node_latency_ms", "labels": {"route": "Earth-Mars"}, "value": 460},
+			{"at": "3m", "metric": "node_latency_ms", "labels": {"route": "Earth-Mars"}, "value": 470}
+		]},
+		{"name": "blip", "fires": 0, "inputs": [
+			{"at": "0s", "metric": "node_latency_ms", "labels": {"route": "Earth-Mars"}, "value": 450},
+			{"at": "1m", "metric": "node_latency_ms", "labels": {"route": "Earth-Mars"}, "value": 300},
+			{"at": "2m", "metric": "node_latency_ms", "labels": {"route": "Earth-Mars"}, "value": 450}
+		]}
+	]
+}`
+
+func parseRule(t *testing.T, data string) Rule {
+	t.Helper()
+	var r Rule
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		t.Fatalf("Failed to parse rule: %v", err)
+	}
+	return r
+}
+
+func TestMetricRuleFiresAfterFor(t *testing.T) {
+	detector := anomaly.NewDetector()
+	engine := NewEngine(detector)
+	if err := engine.Add(parseRule(t, latencyRule)); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	sample := func(offset time.Duration, value float64) timeseries.Sample {
+		return timeseries.Sample{
+			Metric: "node_latency_ms",
+			Labels: map[string]string{"route": "Earth-Mars"},
+			Time:   start.Add(offset),
+			Value:  value,
+		}
+	}
+
+	if raised := engine.EvaluateMetrics(sample(0, 450), sample(time.Minute, 455)); len(raised) != 0 {
+		t.Fatalf("Expected rule to be pending, got %d anomalies", len(raised))
+	}
+	if status := engine.Rules()[0]; status.Pending != 1 {
+		t.Errorf("Expected one pending group, got %d", status.Pending)
+	}
+
+	raised := engine.EvaluateMetrics(sample(2*time.Minute, 460), sample(3*time.Minute, 470))
+	if len(raised) != 1 {
+		t.Fatalf("Expected one anomaly, got %d", len(raised))
+	}
+	a := raised[0]
+	if a.ID == "" || a.Source != "rule:mars-latency" || a.Type != models.AnomalyTypeNodeDesynchronization || a.Severity != models.SeverityHigh {
+		t.Errorf("Unexpected anomaly: %+v", a)
+	}
+	if a.Metadata["route"] != "Earth-Mars" || a.Metadata["group"] != "node_latency_ms{route=Earth-Mars}" {
+		t.Errorf("Unexpected metadata: %v", a.Metadata)
+	}
+	if _, err := detector.GetAnomaly(a.ID); err != nil {
+		t.Errorf("Expected anomaly to be recorded: %v", err)
+	}
+
+	stats := engine.Rules()[0].Stats
+	if stats.Evaluations != 4 || stats.Matches != 4 || stats.Fires != 1 || stats.LastFired == nil {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// Clearing re-arms the group
+	engine.EvaluateMetrics(sample(4*time.Minute, 100))
+	if raised := engine.EvaluateMetrics(sample(5*time.Minute, 500), sample(7*time.Minute, 500)); len(raised) != 1 {
+		t.Errorf("Expected a new anomaly after recovery, got %d", len(raised))
+	}
+}
+
+func TestAnomalyRule(t *testing.T) {
+	detector := anomaly.NewDetector()
+	engine := NewEngine(detector)
+	detector.Subscribe(engine.HandleEvent)
+
+	err := engine.Add(Rule{
+		Name:        "wide-divergence",
+		On:          InputAnomaly,
+		Expr:        `event == "detected" && type == "ledger_divergence" && len(metadata.nodes_affected) >= 3`,
+		Type:        models.AnomalyTypeUnknown,
+		Severity:    models.SeverityCritical,
+		Description: "{{.type}} spans {{len .metadata.nodes_affected}} nodes",
+	})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	detector.DetectAnomalies()
+
+	var raised []*models.Anomaly
+	for _, a := range detector.GetAllAnomalies() {
+		if a.Source == "rule:wide-divergence" {
+			raised = append(raised, a)
+		}
+	}
+	if len(raised) != 1 {
+		t.Fatalf("Expected one rule anomaly, got %d", len(raised))
+	}
+	if raised[0].Description != "ledger_divergence spans 3 nodes" || raised[0].Metadata["anomaly_type"] != "ledger_divergence" {
+		t.Errorf("Unexpected anomaly: %+v", raised[0])
+	}
+	if stats := engine.Rules()[0].Stats; stats.Evaluations != 4 {
+		t.Errorf("Expected the rule's own anomaly to be skipped, got %d evaluations", stats.Evaluations)
+	}
+}
+
+func TestAddValidatesRules(t *testing.T) {
+	engine := NewEngine(nil)
+	valid := parseRule(t, latencyRule)
+
+	broken := []Rule{
+		{},
+		{Name: "x", On: "log", Expr: "true", Type: "t", Severity: models.SeverityLow},
+		{Name: "x", On: InputMetric, Expr: "value >", Type: "t", Severity: models.SeverityLow},
+		{Name: "x", On: InputMetric, Expr: "severity == \"high\"", Type: "t", Severity: models.SeverityLow},
+		{Name: "x", On: InputMetric, Expr: "true", Type: "t", Severity: "urgent"},
+		{Name: "x", On: InputMetric, Expr: "true", Severity: models.SeverityLow},
+		{Name: "x", On: InputMetric, Expr: "true", Type: "t", Severity: models.SeverityLow, Description: "{{.value"},
+	}
+	for _, r := range broken {
+		if err := engine.Add(valid, r); err == nil {
+			t.Errorf("Expected %+v to be rejected", r)
+		}
+	}
+	if len(engine.Rules()) != 0 {
+		t.Error("Expected nothing loaded when any rule is invalid")
+	}
+
+	failing := valid
+	failing.Tests = append([]Fixture(nil), valid.Tests...)
+	failing.Tests[1].Fires = 1
+	err := engine.Add(failing)
+	if err == nil || !strings.Contains(err.Error(), `fixture "blip" failed`) {
+		t.Errorf("Expected failing fixture to be reported, got %v", err)
+	}
+}
+
+func TestAddRejectsUnregisteredTypes(t *testing.T) {
+	registry, _ := anomaly.NewRegistry(anomaly.DefaultTypes...)
+	detector := anomaly.NewDetector()
+	detector.SetRegistry(registry)
+	engine := NewEngine(detector)
+
+	r := parseRule(t, latencyRule)
+	if err := engine.Add(r); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	r.Type = "gamma_burst"
+	if err := engine.Add(r); !errors.Is(err, anomaly.ErrUnknownType) {
+		t.Errorf("Expected ErrUnknownType, got %v", err)
+	}
+}
+
+func TestReplaceAndRemove(t *testing.T) {
+	engine := NewEngine(nil)
+	r := parseRule(t, latencyRule)
+	engine.Add(r)
+
+	r.Severity = models.SeverityCritical
+	engine.Add(r)
+	if rules := engine.Rules(); len(rules) != 1 || rules[0].Severity != models.SeverityCritical {
+		t.Errorf("Expected rule to be replaced, got %+v", rules)
+	}
+
+	if err := engine.Remove(r.Name); err != nil {
+		t.Errorf("Remove failed: %v", err)
+	}
+	if err := engine.Remove(r.Name); !errors.Is(err, ErrNoRule) {
+		t.Errorf("Expected ErrNoRule, got %v", err)
+	}
+}
+
+func TestFixtureResults(t *testing.T) {
+	results, err := Test(parseRule(t, latencyRule))
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+	if len(results) != 2 || !results[0].Passed || !results[1].Passed {
+		t.Fatalf("Expected both fixtures to pass, got %+v", results)
+	}
+	if results[0].Descriptions[0] != "Earth-Mars latency 460ms above 400ms" {
+		t.Errorf("Unexpected description: %q", results[0].Descriptions[0])
+	}
+
+	r := Rule{
+		Name: "ratio", On: InputMetric, Expr: "100 / value > 2", Type: "t", Severity: models.SeverityLow,
+		Tests: []Fixture{{Inputs: []Sample{{Metric: "m", Value: 0}}}},
+	}
+	results, _ = Test(r)
+	if !results[0].Passed || results[0].Error != "division by zero" || results[0].Name != "fixture 1" {
+		t.Errorf("Expected evaluation error to be reported, got %+v", results[0])
+	}
+}
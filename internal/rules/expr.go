@@ -0,0 +1,799 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression limits keep rules cheap to evaluate
+const (
+	maxExprLength = 2048
+	maxExprDepth  = 32
+)
+
+// Expr is a compiled rule expression. The language has number, string,
+// boolean, null and list literals; dotted field references such as
+// labels.route; the operators ! not && and || or == != < <= > >= + - * /
+// % in contains matches; and the functions abs, min, max, len, lower,
+// upper and has. There are no loops or assignments, and regular
+// expressions must be literals so they are compiled once.
+type Expr struct {
+	source string
+	root   node
+}
+
+// Compile parses an expression and returns the field roots it references
+func Compile(source string) (*Expr, error) {
+	if len(source) > maxExprLength {
+		return nil, fmt.Errorf("expression longer than %d characters", maxExprLength)
+	}
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", t.text, t.pos)
+	}
+	return &Expr{source: source, root: root}, nil
+}
+
+// String returns the source expression
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Fields returns the root of every field the expression references
+func (e *Expr) Fields() []string {
+	seen := make(map[string]bool)
+	var roots []string
+	walk(e.root, func(n node) {
+		if f, ok := n.(fieldNode); ok && !seen[f.path[0]] {
+			seen[f.path[0]] = true
+			roots = append(roots, f.path[0])
+		}
+	})
+	return roots
+}
+
+// Eval evaluates the expression against env
+func (e *Expr) Eval(env map[string]interface{}) (interface{}, error) {
+	return e.root.eval(env)
+}
+
+// Match evaluates the expression as a condition. A null result, e.g. from
+// a missing field, does not match.
+func (e *Expr) Match(env map[string]interface{}) (bool, error) {
+	v, err := e.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case nil:
+		return false, nil
+	}
+	return false, fmt.Errorf("expression result is %s, not a boolean", typeName(v))
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "%", "(", ")", "[", "]", ","}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.' || src[i] == 'e' || src[i] == 'E' ||
+				(src[i] == '-' || src[i] == '+') && (src[i-1] == 'e' || src[i-1] == 'E')) {
+				i++
+			}
+			n, err := strconv.ParseFloat(src[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at offset %d", src[start:i], start)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[start:i], num: n, pos: start})
+		case c == '"' || c == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(src) {
+					return nil, fmt.Errorf("unterminated string at offset %d", start)
+				}
+				if rune(src[i]) == c {
+					i++
+					break
+				}
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+				}
+				sb.WriteByte(src[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), pos: start})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || src[i] == '.' || src[i] < 128 && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[start:i], pos: start})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(src[i:], op) {
+					tokens = append(tokens, token{kind: tokenOp, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+// Parser, lowest precedence first: or, and, comparison, additive,
+// multiplicative, unary, primary
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is one of the given operators or
+// keywords
+func (p *parser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokenOp && t.kind != tokenIdent {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		t := p.peek()
+		return fmt.Errorf("expected %q at offset %d", op, t.pos)
+	}
+	return nil
+}
+
+func (p *parser) parseOr(depth int) (node, error) {
+	if depth > maxExprDepth {
+		return nil, fmt.Errorf("expression nested deeper than %d", maxExprDepth)
+	}
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||", "or"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{and: false, left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd(depth int) (node, error) {
+	left, err := p.parseComparison(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&", "and"); !ok {
+			return left, nil
+		}
+		right, err := p.parseComparison(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{and: true, left: left, right: right}
+	}
+}
+
+func (p *parser) parseComparison(depth int) (node, error) {
+	left, err := p.parseAdditive(depth)
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "!=", "<=", ">=", "<", ">", "in", "contains", "matches")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseAdditive(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	if op == "matches" {
+		lit, ok := right.(literalNode)
+		pattern, isString := lit.value.(string)
+		if !ok || !isString {
+			return nil, fmt.Errorf("matches requires a string literal pattern")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		return matchNode{left: left, re: re}, nil
+	}
+	return binaryNode{op: op, left: left, right: right}, nil
+}
+
+func (p *parser) parseAdditive(depth int) (node, error) {
+	left, err := p.parseMultiplicative(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative(depth int) (node, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("*", "/", "%")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary(depth int) (node, error) {
+	if op, ok := p.accept("!", "not", "-"); ok {
+		if depth > maxExprDepth {
+			return nil, fmt.Errorf("expression nested deeper than %d", maxExprDepth)
+		}
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{negate: op == "-", operand: operand}, nil
+	}
+	return p.parsePrimary(depth)
+}
+
+func (p *parser) parsePrimary(depth int) (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		return literalNode{value: t.num}, nil
+	case tokenString:
+		return literalNode{value: t.text}, nil
+	case tokenOp:
+		switch t.text {
+		case "(":
+			inner, err := p.parseOr(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		case "[":
+			items, err := p.parseList(depth, "]")
+			if err != nil {
+				return nil, err
+			}
+			return listNode{items: items}, nil
+		}
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		}
+		if _, ok := p.accept("("); ok {
+			return p.parseCall(t, depth)
+		}
+		path := strings.Split(t.text, ".")
+		for _, part := range path {
+			if part == "" {
+				return nil, fmt.Errorf("invalid field %q at offset %d", t.text, t.pos)
+			}
+		}
+		return fieldNode{path: path}, nil
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", t.text, t.pos)
+}
+
+func (p *parser) parseList(depth int, end string) ([]node, error) {
+	var items []node
+	if _, ok := p.accept(end); ok {
+		return items, nil
+	}
+	for {
+		item, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if _, ok := p.accept(","); ok {
+			continue
+		}
+		return items, p.expect(end)
+	}
+}
+
+var functionArity = map[string][2]int{
+	"abs":   {1, 1},
+	"min":   {1, -1},
+	"max":   {1, -1},
+	"len":   {1, 1},
+	"lower": {1, 1},
+	"upper": {1, 1},
+	"has":   {1, 1},
+}
+
+func (p *parser) parseCall(name token, depth int) (node, error) {
+	arity, ok := functionArity[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at offset %d", name.text, name.pos)
+	}
+	args, err := p.parseList(depth, ")")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < arity[0] || arity[1] >= 0 && len(args) > arity[1] {
+		return nil, fmt.Errorf("wrong number of arguments to %s", name.text)
+	}
+	if name.text == "has" {
+		if _, ok := args[0].(fieldNode); !ok {
+			return nil, fmt.Errorf("has requires a field")
+		}
+	}
+	return callNode{name: name.text, args: args}, nil
+}
+
+// AST
+
+type node interface {
+	eval(env map[string]interface{}) (interface{}, error)
+}
+
+type literalNode struct{ value interface{} }
+
+type fieldNode struct{ path []string }
+
+type unaryNode struct {
+	negate  bool
+	operand node
+}
+
+type logicalNode struct {
+	and         bool
+	left, right node
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+type matchNode struct {
+	left node
+	re   *regexp.Regexp
+}
+
+type listNode struct{ items []node }
+
+type callNode struct {
+	name string
+	args []node
+}
+
+func walk(n node, fn func(node)) {
+	fn(n)
+	switch n := n.(type) {
+	case unaryNode:
+		walk(n.operand, fn)
+	case logicalNode:
+		walk(n.left, fn)
+		walk(n.right, fn)
+	case binaryNode:
+		walk(n.left, fn)
+		walk(n.right, fn)
+	case matchNode:
+		walk(n.left, fn)
+	case listNode:
+		for _, item := range n.items {
+			walk(item, fn)
+		}
+	case callNode:
+		for _, arg := range n.args {
+			walk(arg, fn)
+		}
+	}
+}
+
+func (n literalNode) eval(env map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+// eval looks the path up through nested maps; missing fields are null
+func (n fieldNode) eval(env map[string]interface{}) (interface{}, error) {
+	var v interface{} = env
+	for _, part := range n.path {
+		switch m := v.(type) {
+		case map[string]interface{}:
+			v = m[part]
+		case map[string]string:
+			s, ok := m[part]
+			if !ok {
+				return nil, nil
+			}
+			v = s
+		default:
+			return nil, nil
+		}
+	}
+	return normalize(v), nil
+}
+
+func (n unaryNode) eval(env map[string]interface{}) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	if n.negate {
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot negate %s", typeName(v))
+		}
+		return -f, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("cannot apply not to %s", typeName(v))
+	}
+	return !b, nil
+}
+
+func (n logicalNode) eval(env map[string]interface{}) (interface{}, error) {
+	left, err := truthy(n.left, env)
+	if err != nil {
+		return nil, err
+	}
+	if left != n.and {
+		return left, nil
+	}
+	return truthy(n.right, env)
+}
+
+// truthy evaluates a boolean operand, treating null as false
+func truthy(n node, env map[string]interface{}) (bool, error) {
+	v, err := n.eval(env)
+	if err != nil || v == nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a boolean, got %s", typeName(v))
+	}
+	return b, nil
+}
+
+func (n binaryNode) eval(env map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		return contains(right, left)
+	case "contains":
+		return contains(left, right)
+	}
+
+	// Ordering and arithmetic on missing data yield null
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	if ls, ok := left.(string); ok {
+		rs, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot apply %s to string and %s", n.op, typeName(right))
+		}
+		switch n.op {
+		case "<":
+			return ls < rs, nil
+		case "<=":
+			return ls <= rs, nil
+		case ">":
+			return ls > rs, nil
+		case ">=":
+			return ls >= rs, nil
+		case "+":
+			return ls + rs, nil
+		}
+		return nil, fmt.Errorf("cannot apply %s to strings", n.op)
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("cannot apply %s to %s and %s", n.op, typeName(left), typeName(right))
+	}
+	switch n.op {
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/", "%":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		if n.op == "%" {
+			return math.Mod(l, r), nil
+		}
+		return l / r, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+func (n matchNode) eval(env map[string]interface{}) (interface{}, error) {
+	v, err := n.left.eval(env)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("matches requires a string, got %s", typeName(v))
+	}
+	return n.re.MatchString(s), nil
+}
+
+func (n listNode) eval(env map[string]interface{}) (interface{}, error) {
+	items := make([]interface{}, len(n.items))
+	for i, item := range n.items {
+		v, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = v
+	}
+	return items, nil
+}
+
+func (n callNode) eval(env map[string]interface{}) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+
+	switch n.name {
+	case "has":
+		return args[0] != nil, nil
+	case "len":
+		switch v := args[0].(type) {
+		case nil:
+			return nil, nil
+		case string:
+			return float64(len(v)), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		case map[string]string:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("len of %s", typeName(args[0]))
+	case "lower", "upper":
+		if args[0] == nil {
+			return nil, nil
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("%s of %s", n.name, typeName(args[0]))
+		}
+		if n.name == "lower" {
+			return strings.ToLower(s), nil
+		}
+		return strings.ToUpper(s), nil
+	}
+
+	// Numeric functions
+	nums := make([]float64, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			return nil, nil
+		}
+		f, ok := arg.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%s of %s", n.name, typeName(arg))
+		}
+		nums[i] = f
+	}
+	switch n.name {
+	case "abs":
+		return math.Abs(nums[0]), nil
+	case "min":
+		m := nums[0]
+		for _, f := range nums[1:] {
+			m = math.Min(m, f)
+		}
+		return m, nil
+	case "max":
+		m := nums[0]
+		for _, f := range nums[1:] {
+			m = math.Max(m, f)
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unknown function %s", n.name)
+}
+
+// normalize converts Go values from anomaly metadata into expression
+// values: every number becomes float64 and string slices become lists
+func normalize(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	case []string:
+		items := make([]interface{}, len(n))
+		for i, s := range n {
+			items[i] = s
+		}
+		return items
+	case fmt.Stringer:
+		return n.String()
+	}
+	return v
+}
+
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case float64, string, bool:
+		return a == b
+	}
+	return false
+}
+
+func contains(collection, item interface{}) (interface{}, error) {
+	switch c := collection.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		for _, v := range c {
+			if equal(normalize(v), item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case string:
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot search a string for %s", typeName(item))
+		}
+		return strings.Contains(c, s), nil
+	}
+	return nil, fmt.Errorf("cannot search %s", typeName(collection))
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}, map[string]string:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExprEval(t *testing.T) {
+	env := map[string]interface{}{
+		"value":  450.0,
+		"metric": "node_latency_ms",
+		"labels": map[string]string{"route": "Earth-Mars"},
+		"metadata": map[string]interface{}{
+			"latency_ms":     450,
+			"nodes_affected": []string{"earth-node-1", "mars-node-1"},
+			"hash_mismatch":  true,
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want interface{}
+	}{
+		{`value > 300 && labels.route == "Earth-Mars"`, true},
+		{`value > 300 and not (labels.route == 'Earth-Moon')`, true},
+		{`metadata.latency_ms - 300 >= 150`, true},
+		{`(value + 50) / 2 * 3 % 7`, 750.0 - 107*7},
+		{`-value < 0`, true},
+		{`"mars-node-1" in metadata.nodes_affected`, true},
+		{`labels.route in ["Earth-Moon", "Earth-Mars"]`, true},
+		{`metric contains "latency"`, true},
+		{`labels.route matches "^Earth-(Mars|Moon)$"`, true},
+		{`metadata.hash_mismatch || missing > 1`, true},
+		{`abs(300 - value) == max(1, 150, 2) && min(3, 1) == 1`, true},
+		{`len(metadata.nodes_affected) == 2 && upper(lower("AbC")) == "ABC"`, true},
+		{`has(labels.route) && !has(labels.node)`, true},
+		{`missing > 1`, nil},
+		{`missing == null`, true},
+		{`1.5e2 == 150`, true},
+	}
+
+	for _, tt := range tests {
+		expr, err := Compile(tt.expr)
+		if err != nil {
+			t.Errorf("Compile(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		got, err := expr.Eval(env)
+		if err != nil {
+			t.Errorf("Eval(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestExprErrors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"value >",
+		"value > 1)",
+		`"unterminated`,
+		"value @ 1",
+		"exec(value)",
+		"has(1)",
+		`labels.route matches labels.pattern`,
+		`labels.route matches "("`,
+		"min()",
+		"labels..route",
+	} {
+		if _, err := Compile(src); err == nil {
+			t.Errorf("Expected Compile(%q) to fail", src)
+		}
+	}
+
+	deep := strings.Repeat("(", 40) + "1" + strings.Repeat(")", 40)
+	if _, err := Compile(deep); err == nil {
+		t.Error("Expected deeply nested expression to be rejected")
+	}
+
+	env := map[string]interface{}{"value": 1.0, "metric": "m"}
+	for _, src := range []string{"value / 0", "metric > 1", "!value", "value"} {
+		expr, err := Compile(src)
+		if err != nil {
+			t.Fatalf("Compile(%q) failed: %v", src, err)
+		}
+		if _, err := expr.Match(env); err == nil {
+			t.Errorf("Expected Match(%q) to fail", src)
+		}
+	}
+}
+
+func TestExprFields(t *testing.T) {
+	expr, _ := Compile(`labels.route == "x" && value > abs(labels.n) || has(metric)`)
+	fields := expr.Fields()
+	if len(fields) != 3 || fields[0] != "labels" || fields[1] != "value" || fields[2] != "metric" {
+		t.Errorf("Unexpected fields: %v", fields)
+	}
+}
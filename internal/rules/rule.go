@@ -0,0 +1,179 @@
+// Package rules raises anomalies from declarative rules: an expression
+// over incoming metric samples or anomaly events that must hold for a
+// duration, evaluated by a small safe expression language.
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// Input selects what a rule is evaluated against
+type Input string
+
+const (
+	// InputMetric evaluates a rule for each metric sample, with the fields
+	// metric, value, labels and time
+	InputMetric Input = "metric"
+	// InputAnomaly evaluates a rule for each anomaly event, with the fields
+	// event, id, type, severity, status, source, description and metadata
+	InputAnomaly Input = "anomaly"
+)
+
+// fields lists the field roots available to each input
+var fields = map[Input][]string{
+	InputMetric:  {"metric", "value", "labels", "time"},
+	InputAnomaly: {"event", "id", "type", "severity", "status", "source", "description", "metadata"},
+}
+
+// Duration is a time.Duration written as a string such as "5m" in JSON
+type Duration time.Duration
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON reads a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"5m\"")
+	}
+	if s == "" {
+		*d = 0
+		return nil
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Rule raises an anomaly when Expr has held for For on the same group:
+// the series of a metric sample or the anomaly of an event. A group
+// fires once and again only after the condition has cleared.
+type Rule struct {
+	Name     string                 `json:"name"`
+	On       Input                  `json:"on"`
+	Expr     string                 `json:"expr"`
+	For      Duration               `json:"for,omitempty"`
+	Type     models.AnomalyType     `json:"type"`
+	Severity models.AnomalySeverity `json:"severity"`
+	// Description is a text/template over the input fields, e.g.
+	// "{{.labels.route}} latency {{.value}}ms"
+	Description string `json:"description"`
+	// Tests are fixtures the rule must pass before it is accepted
+	Tests []Fixture `json:"tests,omitempty"`
+}
+
+// Fixture feeds inputs to a rule in isolation and states how many
+// anomalies it should raise
+type Fixture struct {
+	Name   string   `json:"name"`
+	Inputs []Sample `json:"inputs"`
+	Fires  int      `json:"fires"`
+	// Description optionally checks the first raised anomaly
+	Description string `json:"description,omitempty"`
+}
+
+// Sample is a fixture input at an offset from the start of the fixture.
+// Metric inputs set Metric, Labels and Value; anomaly inputs set Anomaly.
+type Sample struct {
+	At      Duration          `json:"at"`
+	Metric  string            `json:"metric,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Value   float64           `json:"value,omitempty"`
+	Event   string            `json:"event,omitempty"`
+	Anomaly *models.Anomaly   `json:"anomaly,omitempty"`
+}
+
+// compiled is a validated rule ready to evaluate
+type compiled struct {
+	Rule
+	expr        *Expr
+	description *template.Template
+}
+
+var validSeverities = map[models.AnomalySeverity]bool{
+	models.SeverityCritical: true,
+	models.SeverityHigh:     true,
+	models.SeverityMedium:   true,
+	models.SeverityLow:      true,
+}
+
+// compile validates r and prepares it for evaluation. Fixtures are run
+// separately by Test.
+func compile(r Rule) (*compiled, error) {
+	if r.Name == "" {
+		return nil, fmt.Errorf("rule name is required")
+	}
+	allowed, ok := fields[r.On]
+	if !ok {
+		return nil, fmt.Errorf("rule %s: on must be %q or %q", r.Name, InputMetric, InputAnomaly)
+	}
+	if r.For < 0 {
+		return nil, fmt.Errorf("rule %s: for must not be negative", r.Name)
+	}
+	if r.Type == "" {
+		return nil, fmt.Errorf("rule %s: type is required", r.Name)
+	}
+	if !validSeverities[r.Severity] {
+		return nil, fmt.Errorf("rule %s: invalid severity %q", r.Name, r.Severity)
+	}
+
+	expr, err := Compile(r.Expr)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+	}
+	for _, field := range expr.Fields() {
+		if !slices.Contains(allowed, field) {
+			return nil, fmt.Errorf("rule %s: unknown field %q for %s rules, expected one of %s",
+				r.Name, field, r.On, strings.Join(allowed, ", "))
+		}
+	}
+
+	description := r.Description
+	if description == "" {
+		description = "Rule " + r.Name + " matched"
+	}
+	tmpl, err := template.New(r.Name).Option("missingkey=zero").Parse(description)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: invalid description: %w", r.Name, err)
+	}
+
+	return &compiled{Rule: r, expr: expr, description: tmpl}, nil
+}
+
+// render fills in the description template, leaving missing fields blank
+func (c *compiled) render(env map[string]interface{}) string {
+	var buf bytes.Buffer
+	if err := c.description.Execute(&buf, env); err != nil {
+		return c.Rule.Description
+	}
+	return strings.ReplaceAll(buf.String(), "<no value>", "")
+}
+
+// LoadFile reads a JSON array of rules
+func LoadFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules: %w", err)
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules %s: %w", path, err)
+	}
+	return rules, nil
+}
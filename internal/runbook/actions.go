@@ -0,0 +1,138 @@
+package runbook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blockchain"
+)
+
+// Built-in actions
+const (
+	ActionSnapshotNodes   = "blockchain.snapshot_nodes"
+	ActionRestoreNodes    = "blockchain.restore_nodes"
+	ActionResyncNodes     = "blockchain.resync_nodes"
+	ActionCheckSync       = "blockchain.check_sync"
+	ActionRebroadcastVote = "blockchain.rebroadcast_vote"
+	ActionVerifyCommit    = "blockchain.verify_commit"
+	ActionAnchorCommit    = "blockchain.anchor_commit"
+	ActionLedgerLog       = "ledger.log"
+	ActionWebhook         = "webhook"
+)
+
+// maxWebhookOutput caps how much of a webhook response is recorded
+const maxWebhookOutput = 512
+
+// Call is the input to an action
+type Call struct {
+	Anomaly *models.Anomaly
+	Params  models.Params
+	// RequestedBy is who started the execution
+	RequestedBy string
+}
+
+// Action performs one runbook step and returns its output, which later
+// steps can reference as {{.Outputs.<step name>}}
+type Action func(ctx context.Context, call Call) (string, error)
+
+// blockchainActions returns the actions backed by the Manus ledger
+func blockchainActions(ledger *blockchain.ManusClient) map[string]Action {
+	return map[string]Action{
+		ActionSnapshotNodes: func(ctx context.Context, call Call) (string, error) {
+			return ledger.SnapshotNodes(split(call.Params["nodes"]))
+		},
+		ActionRestoreNodes: func(ctx context.Context, call Call) (string, error) {
+			if err := ledger.RestoreNodes(call.Params["snapshot"]); err != nil {
+				return "", err
+			}
+			return "restored " + call.Params["snapshot"], nil
+		},
+		ActionResyncNodes: func(ctx context.Context, call Call) (string, error) {
+			height, err := ledger.ResyncNodes(split(call.Params["nodes"]), call.Params["peer"])
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("synced to block %d", height), nil
+		},
+		ActionCheckSync: func(ctx context.Context, call Call) (string, error) {
+			status, err := ledger.GetStatus()
+			if err != nil {
+				return "", err
+			}
+			if status.SyncStatus != "synchronized" {
+				return "", fmt.Errorf("ledger sync status is %q", status.SyncStatus)
+			}
+			return fmt.Sprintf("%s at block %d", status.SyncStatus, status.CurrentBlock), nil
+		},
+		ActionRebroadcastVote: func(ctx context.Context, call Call) (string, error) {
+			return ledger.RebroadcastVote(call.Anomaly.ID, call.Params["proposal"], call.Params["relay"], call.RequestedBy)
+		},
+		ActionVerifyCommit: func(ctx context.Context, call Call) (string, error) {
+			hash := call.Params["hash"]
+			verified, err := ledger.VerifyCommit(hash)
+			if err != nil {
+				return "", err
+			}
+			if !verified {
+				return "", fmt.Errorf("commit %s not found on ledger", hash)
+			}
+			return "verified " + hash, nil
+		},
+		ActionAnchorCommit: func(ctx context.Context, call Call) (string, error) {
+			return ledger.AnchorCommit(call.Anomaly.ID, call.Params["hash"], call.RequestedBy)
+		},
+		ActionLedgerLog: func(ctx context.Context, call Call) (string, error) {
+			return ledger.LogEntry(blockchain.LedgerEntry{
+				AnomalyID:   call.Anomaly.ID,
+				Description: call.Params["description"],
+				Actor:       call.RequestedBy,
+			})
+		},
+	}
+}
+
+// webhookAction posts the anomaly and step params as JSON to the "url"
+// param. Any non-2xx response fails the step.
+func webhookAction(client *http.Client) Action {
+	return func(ctx context.Context, call Call) (string, error) {
+		url := call.Params["url"]
+		if url == "" {
+			return "", fmt.Errorf("webhook url is required")
+		}
+		method := call.Params["method"]
+		if method == "" {
+			method = http.MethodPost
+		}
+
+		body, err := json.Marshal(map[string]interface{}{
+			"anomaly": call.Anomaly,
+			"params":  call.Params,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+		if err != nil {
+			return "", fmt.Errorf("failed to create webhook request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("webhook request failed: %w", err)
+		}
+		defer resp.Body.Close()
+
+		out, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookOutput))
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return string(out), fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		}
+		return fmt.Sprintf("%d %s", resp.StatusCode, out), nil
+	}
+}
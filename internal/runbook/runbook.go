@@ -0,0 +1,173 @@
+// Package runbook executes declarative remediation runbooks against
+// anomalies: ordered steps that call into the Manus ledger or external
+// webhooks, with dry runs, manual approval before destructive steps and
+// rollback of completed steps on failure.
+package runbook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// Step is one action in a runbook. Param values are text/template strings
+// rendered against the anomaly and the outputs of earlier steps, e.g.
+// {{.Anomaly.Metadata.proposal_id}} or {{.Outputs.snapshot}}.
+type Step struct {
+	Name        string            `json:"name"`
+	Action      string            `json:"action"`
+	Params      map[string]string `json:"params,omitempty"`
+	Destructive bool              `json:"destructive,omitempty"`
+	Rollback    *Step             `json:"rollback,omitempty"`
+}
+
+// Runbook is a remediation procedure for an anomaly type. Resolution is
+// applied to the anomaly when every step succeeds.
+type Runbook struct {
+	Name        string             `json:"name"`
+	Type        models.AnomalyType `json:"type"`
+	Description string             `json:"description,omitempty"`
+	Resolution  string             `json:"resolution"`
+	Steps       []Step             `json:"steps"`
+}
+
+// DefaultRunbooks holds the built-in runbooks
+var DefaultRunbooks = []Runbook{
+	{
+		Name:        "resync-divergent-nodes",
+		Type:        models.AnomalyTypeLedgerDivergence,
+		Description: "Snapshot divergent nodes, then resync them from the canonical Earth peer",
+		Resolution:  "Resynchronized divergent nodes from the canonical Earth peer",
+		Steps: []Step{
+			{
+				Name:   "snapshot",
+				Action: ActionSnapshotNodes,
+				Params: map[string]string{"nodes": `{{join .Anomaly.Metadata.nodes_affected}}`},
+			},
+			{
+				Name:        "resync",
+				Action:      ActionResyncNodes,
+				Params:      map[string]string{"nodes": `{{join .Anomaly.Metadata.nodes_affected}}`, "peer": "earth-node-1"},
+				Destructive: true,
+				Rollback: &Step{
+					Name:   "restore",
+					Action: ActionRestoreNodes,
+					Params: map[string]string{"snapshot": `{{.Outputs.snapshot}}`},
+				},
+			},
+			{
+				Name:   "verify",
+				Action: ActionCheckSync,
+			},
+		},
+	},
+	{
+		Name:        "rebroadcast-dao-vote",
+		Type:        models.AnomalyTypeDAOVoteFailure,
+		Description: "Re-broadcast the failed DAO vote from the Moon relay",
+		Resolution:  "Re-broadcast the DAO vote from the nearest healthy relay",
+		Steps: []Step{
+			{
+				Name:   "rebroadcast",
+				Action: ActionRebroadcastVote,
+				Params: map[string]string{"proposal": `{{.Anomaly.Metadata.proposal_id}}`, "relay": "moon-relay-1"},
+			},
+			{
+				Name:   "verify",
+				Action: ActionCheckSync,
+			},
+		},
+	},
+	{
+		Name:        "re-anchor-commit",
+		Type:        models.AnomalyTypeCommitAnomaly,
+		Description: "Verify the commit and anchor it on the Manus ledger",
+		Resolution:  "Commit re-anchored on the Manus ledger",
+		Steps: []Step{
+			{
+				Name:   "verify",
+				Action: ActionVerifyCommit,
+				Params: map[string]string{"hash": `{{.Anomaly.Metadata.commit_hash}}`},
+			},
+			{
+				Name:        "anchor",
+				Action:      ActionAnchorCommit,
+				Params:      map[string]string{"hash": `{{.Anomaly.Metadata.commit_hash}}`},
+				Destructive: true,
+			},
+		},
+	},
+}
+
+// LoadFile reads runbooks from a JSON array
+func LoadFile(path string) ([]Runbook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read runbooks: %w", err)
+	}
+
+	var runbooks []Runbook
+	if err := json.Unmarshal(data, &runbooks); err != nil {
+		return nil, fmt.Errorf("invalid runbooks file %s: %w", path, err)
+	}
+	return runbooks, nil
+}
+
+var funcs = template.FuncMap{
+	"join": join,
+}
+
+// renderData is what step parameters are rendered against
+type renderData struct {
+	Anomaly *models.Anomaly
+	Outputs map[string]string
+}
+
+// render expands step parameter templates; missing values render empty
+func render(params map[string]string, data renderData) (models.Params, error) {
+	rendered := make(models.Params, len(params))
+	for name, text := range params {
+		tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for param %s: %w", name, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render param %s: %w", name, err)
+		}
+		rendered[name] = strings.TrimSpace(strings.ReplaceAll(buf.String(), "<no value>", ""))
+	}
+	return rendered, nil
+}
+
+// join renders a metadata list as a comma-separated string
+func join(v interface{}) string {
+	switch list := v.(type) {
+	case []string:
+		return strings.Join(list, ",")
+	case []interface{}:
+		parts := make([]string, 0, len(list))
+		for _, item := range list {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, ",")
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func split(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
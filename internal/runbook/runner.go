@@ -0,0 +1,455 @@
+package runbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blockchain"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNoRunbook is returned when no runbook matches the request
+	ErrNoRunbook = errors.New("no runbook")
+	// ErrInProgress is returned when the anomaly already has an unfinished execution
+	ErrInProgress = errors.New("runbook execution in progress")
+	// ErrNotAwaitingApproval is returned when approving an execution that is not paused
+	ErrNotAwaitingApproval = errors.New("runbook execution is not awaiting approval")
+	// ErrAlreadyResolved is returned when running a runbook against a resolved anomaly
+	ErrAlreadyResolved = errors.New("anomaly is already resolved")
+	// ErrSelfApproval is returned when the requester approves their own step
+	ErrSelfApproval = errors.New("a destructive step must be approved by someone other than the requester")
+)
+
+// Gate holds back resolutions that need approval from more than one
+// reviewer; the consensus board implements it
+type Gate interface {
+	Requires(a *models.Anomaly) bool
+	Open(ctx context.Context, id, resolution, requestedBy string) (*models.Approval, error)
+}
+
+// Runner executes runbooks and records each execution on its anomaly
+type Runner struct {
+	detector *anomaly.Detector
+	ledger   *blockchain.ManusClient
+	runbooks map[string]Runbook
+	order    []string
+	actions  map[string]Action
+	gate     Gate
+	busy     map[string]bool
+	mu       sync.Mutex
+}
+
+// NewRunner creates a runner with the built-in blockchain actions and a
+// webhook action using client, or a client with a 10 second timeout
+func NewRunner(detector *anomaly.Detector, ledger *blockchain.ManusClient, client *http.Client) *Runner {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	r := &Runner{
+		detector: detector,
+		ledger:   ledger,
+		runbooks: make(map[string]Runbook),
+		actions:  blockchainActions(ledger),
+		busy:     make(map[string]bool),
+	}
+	r.actions[ActionWebhook] = webhookAction(client)
+	return r
+}
+
+// Register adds or replaces an action
+func (r *Runner) Register(name string, action Action) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.actions[name] = action
+}
+
+// SetGate routes runbook resolutions that need consensus through g
+func (r *Runner) SetGate(g Gate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.gate = g
+}
+
+// Add validates and registers runbooks, replacing any with the same name
+func (r *Runner) Add(runbooks ...Runbook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rb := range runbooks {
+		if err := r.validate(rb); err != nil {
+			return fmt.Errorf("runbook %q: %w", rb.Name, err)
+		}
+		if _, exists := r.runbooks[rb.Name]; !exists {
+			r.order = append(r.order, rb.Name)
+		}
+		r.runbooks[rb.Name] = rb
+	}
+	return nil
+}
+
+// Runbooks returns registered runbooks, optionally only for one type
+func (r *Runner) Runbooks(anomalyType models.AnomalyType) []Runbook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	runbooks := make([]Runbook, 0, len(r.order))
+	for _, name := range r.order {
+		if rb := r.runbooks[name]; anomalyType == "" || rb.Type == anomalyType {
+			runbooks = append(runbooks, rb)
+		}
+	}
+	return runbooks
+}
+
+// Start runs a runbook against an anomaly. With an empty name the first
+// runbook for the anomaly's type is used, preferring the type's registered
+// default. Execution stops before the first destructive step until Approve
+// is called; a dry run renders and records every step without calling any
+// action.
+func (r *Runner) Start(ctx context.Context, anomalyID, name string, dryRun bool, requestedBy string) (*models.RunbookExecution, error) {
+	if !r.claim(anomalyID) {
+		return nil, fmt.Errorf("%w for anomaly %s", ErrInProgress, anomalyID)
+	}
+	defer r.release(anomalyID)
+
+	a, err := r.detector.GetAnomaly(anomalyID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Status == models.StatusResolved {
+		return nil, fmt.Errorf("%w: %s", ErrAlreadyResolved, anomalyID)
+	}
+	if a.Runbook != nil && (a.Runbook.Status == models.RunbookRunning || a.Runbook.Status == models.RunbookAwaitingApproval) {
+		return nil, fmt.Errorf("%w for anomaly %s", ErrInProgress, anomalyID)
+	}
+
+	rb, err := r.find(a, name)
+	if err != nil {
+		return nil, err
+	}
+
+	exec := &models.RunbookExecution{
+		ID:          uuid.New().String(),
+		Runbook:     rb.Name,
+		DryRun:      dryRun,
+		RequestedBy: requestedBy,
+		Status:      models.RunbookRunning,
+		Outputs:     make(map[string]string),
+		StartedAt:   time.Now(),
+	}
+	return r.run(ctx, a, rb, exec)
+}
+
+// Approve resumes an execution paused before a destructive step, or
+// cancels it and rolls back completed steps when approve is false
+func (r *Runner) Approve(ctx context.Context, anomalyID, approver string, approve bool) (*models.RunbookExecution, error) {
+	if !r.claim(anomalyID) {
+		return nil, fmt.Errorf("%w for anomaly %s", ErrInProgress, anomalyID)
+	}
+	defer r.release(anomalyID)
+
+	a, err := r.detector.GetAnomaly(anomalyID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Runbook == nil || a.Runbook.Status != models.RunbookAwaitingApproval {
+		return nil, fmt.Errorf("%w for anomaly %s", ErrNotAwaitingApproval, anomalyID)
+	}
+
+	r.mu.Lock()
+	rb, ok := r.runbooks[a.Runbook.Runbook]
+	r.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoRunbook, a.Runbook.Runbook)
+	}
+
+	exec := copyExecution(a.Runbook)
+	// The runbook may have been replaced with fewer steps while paused
+	if exec.Next < 0 || exec.Next >= len(rb.Steps) {
+		return nil, fmt.Errorf("%w for anomaly %s: runbook %s has no step %d", ErrNotAwaitingApproval, anomalyID, rb.Name, exec.Next)
+	}
+	step := rb.Steps[exec.Next]
+	if approve {
+		if exec.RequestedBy != "" && approver == exec.RequestedBy {
+			return nil, fmt.Errorf("%w: %s", ErrSelfApproval, approver)
+		}
+		// The anomaly may have been resolved by other means while paused
+		if a.Status == models.StatusResolved {
+			return nil, fmt.Errorf("%w: %s", ErrAlreadyResolved, anomalyID)
+		}
+		exec.Approvals = append(exec.Approvals, models.StepApproval{
+			Step:       step.Name,
+			ApprovedBy: approver,
+			ApprovedAt: time.Now(),
+		})
+		exec.Status = models.RunbookRunning
+		return r.run(ctx, a, rb, exec)
+	}
+
+	exec.Error = fmt.Sprintf("step %s rejected by %s", step.Name, approver)
+	exec.Status = models.RunbookCancelled
+	if err := r.rollback(ctx, a, rb, exec); err != nil {
+		exec.Status = models.RunbookFailed
+	}
+	return r.finish(ctx, a, rb, exec)
+}
+
+// run executes steps from exec.Next until the runbook completes, fails or
+// reaches a destructive step that has not been approved
+func (r *Runner) run(ctx context.Context, a *models.Anomaly, rb Runbook, exec *models.RunbookExecution) (*models.RunbookExecution, error) {
+	for exec.Next < len(rb.Steps) {
+		step := rb.Steps[exec.Next]
+		if step.Destructive && !exec.DryRun && !approved(exec, step.Name) {
+			exec.Status = models.RunbookAwaitingApproval
+			if err := r.save(a.ID, exec); err != nil {
+				return nil, err
+			}
+			return copyExecution(exec), nil
+		}
+
+		result := r.step(ctx, a, step, exec, false)
+		exec.Steps = append(exec.Steps, result)
+		if result.Status == models.StepFailed {
+			exec.Error = fmt.Sprintf("step %s failed: %s", step.Name, result.Error)
+			exec.Status = models.RunbookRolledBack
+			if err := r.rollback(ctx, a, rb, exec); err != nil {
+				exec.Status = models.RunbookFailed
+			}
+			return r.finish(ctx, a, rb, exec)
+		}
+
+		exec.Next++
+		if err := r.save(a.ID, exec); err != nil {
+			return nil, err
+		}
+	}
+
+	exec.Status = models.RunbookSucceeded
+	return r.finish(ctx, a, rb, exec)
+}
+
+// step renders and runs one step, or only renders it for a dry run
+func (r *Runner) step(ctx context.Context, a *models.Anomaly, step Step, exec *models.RunbookExecution, rollback bool) models.StepResult {
+	result := models.StepResult{
+		Name:      step.Name,
+		Action:    step.Action,
+		Rollback:  rollback,
+		StartedAt: time.Now(),
+	}
+
+	params, err := render(step.Params, renderData{Anomaly: a, Outputs: exec.Outputs})
+	result.Params = params
+	if err != nil {
+		result.Status = models.StepFailed
+		result.Error = err.Error()
+		result.FinishedAt = time.Now()
+		return result
+	}
+
+	if exec.DryRun {
+		result.Status = models.StepDryRun
+		result.Output = "would run " + step.Action
+		if step.Destructive {
+			result.Output += " after manual approval"
+		}
+		result.FinishedAt = time.Now()
+		return result
+	}
+
+	r.mu.Lock()
+	action := r.actions[step.Action]
+	r.mu.Unlock()
+
+	output, err := action(ctx, Call{Anomaly: a, Params: params, RequestedBy: exec.RequestedBy})
+	result.Output = output
+	if err != nil {
+		result.Status = models.StepFailed
+		result.Error = err.Error()
+	} else {
+		result.Status = models.StepSucceeded
+		outputs := make(map[string]string, len(exec.Outputs)+1)
+		for k, v := range exec.Outputs {
+			outputs[k] = v
+		}
+		outputs[step.Name] = output
+		exec.Outputs = outputs
+	}
+	result.FinishedAt = time.Now()
+	return result
+}
+
+// rollback runs the rollback of every completed step, newest first. It
+// keeps going after a failed rollback and returns the first error.
+func (r *Runner) rollback(ctx context.Context, a *models.Anomaly, rb Runbook, exec *models.RunbookExecution) error {
+	// Roll back even when the caller has gone away
+	ctx = context.WithoutCancel(ctx)
+
+	var first error
+	for i := exec.Next - 1; i >= 0; i-- {
+		undo := rb.Steps[i].Rollback
+		if undo == nil {
+			continue
+		}
+		result := r.step(ctx, a, *undo, exec, true)
+		exec.Steps = append(exec.Steps, result)
+		if result.Status == models.StepFailed && first == nil {
+			first = fmt.Errorf("rollback %s failed: %s", undo.Name, result.Error)
+		}
+	}
+	return first
+}
+
+// finish records a closed execution on the ledger and applies the
+// runbook's resolution when it succeeded
+func (r *Runner) finish(ctx context.Context, a *models.Anomaly, rb Runbook, exec *models.RunbookExecution) (*models.RunbookExecution, error) {
+	now := time.Now()
+	exec.FinishedAt = &now
+
+	if !exec.DryRun {
+		txHash, err := r.ledger.LogEntry(blockchain.LedgerEntry{
+			AnomalyID:   a.ID,
+			Description: fmt.Sprintf("Runbook %s %s", rb.Name, exec.Status),
+			Actor:       exec.RequestedBy,
+			Payload: map[string]interface{}{
+				"execution_id": exec.ID,
+				"runbook":      rb.Name,
+				"status":       string(exec.Status),
+				"steps":        exec.Steps,
+				"approvals":    exec.Approvals,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		exec.TxHash = txHash
+	}
+	if err := r.save(a.ID, exec); err != nil {
+		return nil, err
+	}
+
+	if exec.Status != models.RunbookSucceeded || exec.DryRun {
+		return copyExecution(exec), nil
+	}
+
+	r.mu.Lock()
+	gate := r.gate
+	r.mu.Unlock()
+	if gate != nil && gate.Requires(a) {
+		if _, err := gate.Open(ctx, a.ID, rb.Resolution, exec.RequestedBy); err != nil {
+			return nil, err
+		}
+	} else if err := r.detector.ResolveAnomaly(a.ID, rb.Resolution, exec.RequestedBy); err != nil {
+		return nil, err
+	}
+	return copyExecution(exec), nil
+}
+
+func (r *Runner) save(id string, exec *models.RunbookExecution) error {
+	stored := copyExecution(exec)
+	return r.detector.Update(id, func(a *models.Anomaly) {
+		a.Runbook = stored
+	})
+}
+
+func (r *Runner) find(a *models.Anomaly, name string) (Runbook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if name != "" {
+		rb, ok := r.runbooks[name]
+		if !ok {
+			return Runbook{}, fmt.Errorf("%w named %s", ErrNoRunbook, name)
+		}
+		if rb.Type != a.Type {
+			return Runbook{}, fmt.Errorf("runbook %s handles %s, not %s", name, rb.Type, a.Type)
+		}
+		return rb, nil
+	}
+
+	// Prefer the default runbook registered for the anomaly type
+	if registry := r.detector.Registry(); registry != nil {
+		if def, ok := registry.Get(a.Type); ok && def.Runbook != "" {
+			if rb, ok := r.runbooks[def.Runbook]; ok && rb.Type == a.Type {
+				return rb, nil
+			}
+		}
+	}
+
+	for _, n := range r.order {
+		if rb := r.runbooks[n]; rb.Type == a.Type {
+			return rb, nil
+		}
+	}
+	return Runbook{}, fmt.Errorf("%w for anomaly type %s", ErrNoRunbook, a.Type)
+}
+
+// validate checks a runbook's actions exist; callers hold r.mu
+func (r *Runner) validate(rb Runbook) error {
+	if rb.Name == "" || rb.Type == "" || len(rb.Steps) == 0 {
+		return fmt.Errorf("name, type and steps are required")
+	}
+	seen := make(map[string]bool)
+	for _, step := range rb.Steps {
+		if step.Name == "" || seen[step.Name] {
+			return fmt.Errorf("step names must be unique and non-empty")
+		}
+		seen[step.Name] = true
+		if _, ok := r.actions[step.Action]; !ok {
+			return fmt.Errorf("step %s: unknown action %q", step.Name, step.Action)
+		}
+		if step.Rollback != nil {
+			if _, ok := r.actions[step.Rollback.Action]; !ok {
+				return fmt.Errorf("step %s: unknown rollback action %q", step.Name, step.Rollback.Action)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Runner) claim(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.busy[id] {
+		return false
+	}
+	r.busy[id] = true
+	return true
+}
+
+func (r *Runner) release(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.busy, id)
+}
+
+func approved(exec *models.RunbookExecution, step string) bool {
+	for _, approval := range exec.Approvals {
+		if approval.Step == step {
+			return true
+		}
+	}
+	return false
+}
+
+func copyExecution(e *models.RunbookExecution) *models.RunbookExecution {
+	c := *e
+	c.Steps = append([]models.StepResult(nil), e.Steps...)
+	c.Approvals = append([]models.StepApproval(nil), e.Approvals...)
+	c.Outputs = make(map[string]string, len(e.Outputs))
+	for k, v := range e.Outputs {
+		c.Outputs[k] = v
+	}
+	return &c
+}
@@ -0,0 +1,299 @@
+package runbook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blockchain"
+)
+
+func newTestRunner(t *testing.T) (*Runner, *anomaly.Detector, *blockchain.ManusClient, map[models.AnomalyType]string) {
+	t.Helper()
+	detector := anomaly.NewDetector()
+	ledger := blockchain.NewManusClient("http://localhost:9545", "1", false)
+
+	ids := make(map[models.AnomalyType]string)
+	for _, a := range detector.DetectAnomalies() {
+		ids[a.Type] = a.ID
+	}
+
+	runner := NewRunner(detector, ledger, nil)
+	if err := runner.Add(DefaultRunbooks...); err != nil {
+		t.Fatalf("Failed to add default runbooks: %v", err)
+	}
+	return runner, detector, ledger, ids
+}
+
+func TestRunbookResolvesAnomaly(t *testing.T) {
+	runner, detector, ledger, ids := newTestRunner(t)
+	id := ids[models.AnomalyTypeDAOVoteFailure]
+
+	exec, err := runner.Start(context.Background(), id, "", false, "alice")
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if exec.Status != models.RunbookSucceeded || len(exec.Steps) != 2 {
+		t.Fatalf("Expected 2 successful steps, got %+v", exec)
+	}
+	if exec.Steps[0].Params["proposal"] != "PROP-2026-001" {
+		t.Errorf("Expected proposal rendered from metadata, got %v", exec.Steps[0].Params)
+	}
+
+	a, _ := detector.GetAnomaly(id)
+	if a.Status != models.StatusResolved || a.Runbook == nil || a.Runbook.TxHash != exec.TxHash {
+		t.Errorf("Expected resolved anomaly carrying the execution, got %s %+v", a.Status, a.Runbook)
+	}
+
+	// Re-broadcast and execution summary
+	if n := len(ledger.Entries()); n != 2 {
+		t.Errorf("Expected 2 ledger entries, got %d", n)
+	}
+
+	// Running again must not repeat the actions or the resolution
+	if _, err := runner.Start(context.Background(), id, "", false, "bob"); !errors.Is(err, ErrAlreadyResolved) {
+		t.Errorf("Expected ErrAlreadyResolved, got %v", err)
+	}
+	if a, _ := detector.GetAnomaly(id); a.ResolvedBy != "alice" || len(ledger.Entries()) != 2 {
+		t.Errorf("Expected the first resolution to stand, got %q with %d ledger entries", a.ResolvedBy, len(ledger.Entries()))
+	}
+}
+
+func TestLedgerStepsRecordRequester(t *testing.T) {
+	runner, _, ledger, ids := newTestRunner(t)
+	err := runner.Add(Runbook{
+		Name:       "note-desync",
+		Type:       models.AnomalyTypeNodeDesynchronization,
+		Resolution: "Noted",
+		Steps:      []Step{{Name: "note", Action: ActionLedgerLog, Params: map[string]string{"description": "checked {{.Anomaly.Type}}"}}},
+	})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if _, err := runner.Start(context.Background(), ids[models.AnomalyTypeNodeDesynchronization], "note-desync", false, "alice"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	entries := ledger.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected the logged step and the execution summary, got %d entries", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.Actor != "alice" {
+			t.Errorf("Expected ledger entry %q to be recorded as alice, got %q", entry.Description, entry.Actor)
+		}
+	}
+}
+
+func TestLedgerActionsRecordAnomalyAndRequester(t *testing.T) {
+	steps := map[string]Step{
+		"rebroadcast": {Name: "rebroadcast", Action: ActionRebroadcastVote, Params: map[string]string{"proposal": "PROP-1", "relay": "moon-relay"}},
+		"anchor":      {Name: "anchor", Action: ActionAnchorCommit, Params: map[string]string{"hash": "a3f5b2c1"}},
+	}
+	for name, step := range steps {
+		t.Run(name, func(t *testing.T) {
+			runner, _, ledger, ids := newTestRunner(t)
+			id := ids[models.AnomalyTypeNodeDesynchronization]
+			if err := runner.Add(Runbook{Name: name, Type: models.AnomalyTypeNodeDesynchronization, Resolution: "Done", Steps: []Step{step}}); err != nil {
+				t.Fatalf("Add failed: %v", err)
+			}
+
+			if _, err := runner.Start(context.Background(), id, name, false, "alice"); err != nil {
+				t.Fatalf("Start failed: %v", err)
+			}
+			entries := ledger.Entries()
+			if len(entries) != 2 {
+				t.Fatalf("Expected the step and the execution summary, got %d entries", len(entries))
+			}
+			if entry := entries[0]; entry.AnomalyID != id || entry.Actor != "alice" {
+				t.Errorf("Expected %s entry for %s by alice, got anomaly %q actor %q", name, id, entry.AnomalyID, entry.Actor)
+			}
+		})
+	}
+}
+
+func TestDestructiveStepWaitsForApproval(t *testing.T) {
+	runner, detector, _, ids := newTestRunner(t)
+	id := ids[models.AnomalyTypeLedgerDivergence]
+	ctx := context.Background()
+
+	exec, err := runner.Start(ctx, id, "", false, "alice")
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if exec.Status != models.RunbookAwaitingApproval || exec.Next != 1 || len(exec.Steps) != 1 {
+		t.Fatalf("Expected pause before resync, got %+v", exec)
+	}
+	if _, err := runner.Start(ctx, id, "", false, "bob"); !errors.Is(err, ErrInProgress) {
+		t.Errorf("Expected ErrInProgress, got %v", err)
+	}
+	if _, err := runner.Approve(ctx, id, "alice", true); !errors.Is(err, ErrSelfApproval) {
+		t.Errorf("Expected ErrSelfApproval, got %v", err)
+	}
+
+	exec, err = runner.Approve(ctx, id, "bob", true)
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if exec.Status != models.RunbookSucceeded || len(exec.Approvals) != 1 || exec.Approvals[0].ApprovedBy != "bob" {
+		t.Fatalf("Expected approved run to succeed, got %+v", exec)
+	}
+	if exec.Steps[1].Params["nodes"] != "earth-node-1,moon-node-2,mars-node-1" {
+		t.Errorf("Expected nodes joined from metadata, got %q", exec.Steps[1].Params["nodes"])
+	}
+
+	if a, _ := detector.GetAnomaly(id); a.Status != models.StatusResolved {
+		t.Error("Expected anomaly resolved")
+	}
+}
+
+func TestApproveRejectsStepMissingFromReplacedRunbook(t *testing.T) {
+	runner, _, _, ids := newTestRunner(t)
+	id := ids[models.AnomalyTypeLedgerDivergence]
+	ctx := context.Background()
+
+	exec, err := runner.Start(ctx, id, "", false, "alice")
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	rb := runner.Runbooks(models.AnomalyTypeLedgerDivergence)[0]
+	rb.Steps = rb.Steps[:exec.Next]
+	if err := runner.Add(rb); err != nil {
+		t.Fatalf("Failed to replace runbook: %v", err)
+	}
+
+	if _, err := runner.Approve(ctx, id, "bob", true); !errors.Is(err, ErrNotAwaitingApproval) {
+		t.Errorf("Expected ErrNotAwaitingApproval, got %v", err)
+	}
+}
+
+func TestFailedStepRollsBack(t *testing.T) {
+	runner, detector, _, ids := newTestRunner(t)
+	runner.Register(ActionCheckSync, func(ctx context.Context, call Call) (string, error) {
+		return "", errors.New("moon-node-2 still diverged")
+	})
+	id := ids[models.AnomalyTypeLedgerDivergence]
+	ctx := context.Background()
+
+	runner.Start(ctx, id, "", false, "alice")
+	exec, err := runner.Approve(ctx, id, "bob", true)
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if exec.Status != models.RunbookRolledBack {
+		t.Fatalf("Expected rolled_back, got %s", exec.Status)
+	}
+
+	last := exec.Steps[len(exec.Steps)-1]
+	if !last.Rollback || last.Name != "restore" || last.Params["snapshot"] != exec.Outputs["snapshot"] {
+		t.Errorf("Expected restore from snapshot %q, got %+v", exec.Outputs["snapshot"], last)
+	}
+	if a, _ := detector.GetAnomaly(id); a.Status == models.StatusResolved {
+		t.Error("Expected failed runbook not to resolve the anomaly")
+	}
+}
+
+func TestRejectedStepCancels(t *testing.T) {
+	runner, _, _, ids := newTestRunner(t)
+	id := ids[models.AnomalyTypeLedgerDivergence]
+	ctx := context.Background()
+
+	runner.Start(ctx, id, "", false, "alice")
+	exec, err := runner.Approve(ctx, id, "bob", false)
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if exec.Status != models.RunbookCancelled || len(exec.Steps) != 1 {
+		t.Errorf("Expected cancellation after the snapshot, got %+v", exec)
+	}
+
+	if _, err := runner.Approve(ctx, id, "bob", true); !errors.Is(err, ErrNotAwaitingApproval) {
+		t.Errorf("Expected ErrNotAwaitingApproval, got %v", err)
+	}
+}
+
+func TestDryRunCallsNoActions(t *testing.T) {
+	runner, detector, ledger, ids := newTestRunner(t)
+	id := ids[models.AnomalyTypeLedgerDivergence]
+
+	exec, err := runner.Start(context.Background(), id, "", true, "alice")
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if exec.Status != models.RunbookSucceeded || len(exec.Steps) != 3 {
+		t.Fatalf("Expected all steps planned, got %+v", exec)
+	}
+	for _, step := range exec.Steps {
+		if step.Status != models.StepDryRun {
+			t.Errorf("Expected dry_run step, got %+v", step)
+		}
+	}
+	if exec.Steps[1].Output != "would run blockchain.resync_nodes after manual approval" {
+		t.Errorf("Expected approval note on destructive step, got %q", exec.Steps[1].Output)
+	}
+
+	if len(ledger.Entries()) != 0 {
+		t.Error("Expected dry run not to touch the ledger")
+	}
+	if a, _ := detector.GetAnomaly(id); a.Status == models.StatusResolved {
+		t.Error("Expected dry run not to resolve the anomaly")
+	}
+}
+
+func TestWebhookStep(t *testing.T) {
+	var received struct {
+		Anomaly models.Anomaly    `json:"anomaly"`
+		Params  map[string]string `json:"params"`
+	}
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+		w.Write([]byte("queued"))
+	}))
+	defer server.Close()
+
+	runner, _, _, ids := newTestRunner(t)
+	err := runner.Add(Runbook{
+		Name:       "page-route-owner",
+		Type:       models.AnomalyTypeNodeDesynchronization,
+		Resolution: "Route owner paged",
+		Steps: []Step{{
+			Name:   "page",
+			Action: ActionWebhook,
+			Params: map[string]string{"url": server.URL, "route": "{{.Anomaly.Metadata.affected_route}}"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	id := ids[models.AnomalyTypeNodeDesynchronization]
+
+	exec, err := runner.Start(context.Background(), id, "page-route-owner", false, "")
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if exec.Status != models.RunbookSucceeded || exec.Outputs["page"] != "200 queued" {
+		t.Fatalf("Expected webhook step to succeed, got %+v", exec)
+	}
+	if received.Anomaly.ID != id || received.Params["route"] != "Earth-Mars" {
+		t.Errorf("Unexpected webhook payload: %+v", received)
+	}
+}
+
+func TestAddRejectsUnknownAction(t *testing.T) {
+	runner, _, _, _ := newTestRunner(t)
+	err := runner.Add(Runbook{
+		Name:  "broken",
+		Type:  models.AnomalyTypeUnknown,
+		Steps: []Step{{Name: "x", Action: "blockchain.teleport"}},
+	})
+	if err == nil {
+		t.Error("Expected unknown action to be rejected")
+	}
+}
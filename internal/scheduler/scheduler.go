@@ -0,0 +1,346 @@
+// Package scheduler runs background jobs such as detection sources on
+// their own interval or cron schedule, with jitter, overlap prevention,
+// per-run timeouts and a run history.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// historyLimit is how many runs are kept per job
+const historyLimit = 50
+
+var (
+	// ErrUnknownJob is returned for a job name that was never added
+	ErrUnknownJob = errors.New("unknown job")
+	// ErrRunning is returned when triggering a job that is already running
+	ErrRunning = errors.New("job is already running")
+	// ErrStopped is returned when triggering a job after Stop
+	ErrStopped = errors.New("scheduler is stopped")
+	// ErrNotLeader is returned when triggering a job on a standby replica
+	ErrNotLeader = errors.New("not the leader replica")
+)
+
+// Leader reports whether this replica should run jobs
+type Leader interface {
+	IsLeader() bool
+}
+
+// Job is a unit of scheduled work. Run returns how many findings it
+// produced, e.g. anomalies detected.
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Jitter   time.Duration
+	Timeout  time.Duration
+	Run      func(ctx context.Context) (int, error)
+	// EveryReplica runs the job on standby replicas too, for work that is
+	// local to each process
+	EveryReplica bool
+}
+
+// Run records one execution of a job
+type Run struct {
+	Job       string        `json:"job"`
+	Trigger   string        `json:"trigger"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration_ns"`
+	Findings  int           `json:"findings"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// JobStatus summarises a job for the API
+type JobStatus struct {
+	Name     string    `json:"name"`
+	Schedule string    `json:"schedule"`
+	Jitter   string    `json:"jitter,omitempty"`
+	Timeout  string    `json:"timeout,omitempty"`
+	Running  bool      `json:"running"`
+	NextRun  time.Time `json:"next_run"`
+	Runs     int       `json:"runs"`
+	Failures int       `json:"failures"`
+	Skipped  int       `json:"skipped_overlaps"`
+	Standby  int       `json:"skipped_standby"`
+	LastRun  *Run      `json:"last_run,omitempty"`
+}
+
+type jobState struct {
+	job      Job
+	running  bool
+	nextRun  time.Time
+	runs     int
+	failures int
+	skipped  int
+	standby  int
+	history  []Run
+}
+
+// Scheduler runs jobs until stopped
+type Scheduler struct {
+	jobs    map[string]*jobState
+	order   []string
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+	leader  Leader
+	wg      sync.WaitGroup
+	rand    *rand.Rand
+	mu      sync.Mutex
+}
+
+// New creates an empty scheduler
+func New() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		jobs:   make(map[string]*jobState),
+		ctx:    ctx,
+		cancel: cancel,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Add registers a job. Jobs added after Start begin immediately.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil {
+		return fmt.Errorf("job name, schedule and run function are required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("job %s already exists", job.Name)
+	}
+	state := &jobState{job: job}
+	s.jobs[job.Name] = state
+	s.order = append(s.order, job.Name)
+
+	if s.started {
+		s.launch(state)
+	}
+	return nil
+}
+
+// SetLeader restricts runs to when leader reports this replica leads, so
+// only one of several replicas runs each job
+func (s *Scheduler) SetLeader(leader Leader) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leader = leader
+}
+
+// Start begins running jobs on their schedules
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+	for _, name := range s.order {
+		s.launch(s.jobs[name])
+	}
+}
+
+// Stop cancels running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	// Cancel under the lock so execute cannot start a run after Wait begins
+	s.mu.Lock()
+	s.cancel()
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// Trigger runs a job now, outside its schedule
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	state, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	if s.ctx.Err() != nil {
+		return ErrStopped
+	}
+	if !state.job.EveryReplica && !s.leading() {
+		return ErrNotLeader
+	}
+
+	if !s.execute(state, "manual") {
+		return fmt.Errorf("%w: %s", ErrRunning, name)
+	}
+	return nil
+}
+
+// Jobs returns the status of every job
+func (s *Scheduler) Jobs() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.order))
+	for _, name := range s.order {
+		state := s.jobs[name]
+		status := JobStatus{
+			Name:     name,
+			Schedule: state.job.Schedule.String(),
+			Running:  state.running,
+			NextRun:  state.nextRun,
+			Runs:     state.runs,
+			Failures: state.failures,
+			Skipped:  state.skipped,
+			Standby:  state.standby,
+		}
+		if state.job.Jitter > 0 {
+			status.Jitter = state.job.Jitter.String()
+		}
+		if state.job.Timeout > 0 {
+			status.Timeout = state.job.Timeout.String()
+		}
+		if n := len(state.history); n > 0 {
+			last := state.history[n-1]
+			status.LastRun = &last
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// History returns recent runs, newest first, optionally for one job
+func (s *Scheduler) History(job string) ([]Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job != "" {
+		state, ok := s.jobs[job]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownJob, job)
+		}
+		return reversed(state.history), nil
+	}
+
+	var runs []Run
+	for _, state := range s.jobs {
+		runs = append(runs, state.history...)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+// launch starts the timing loop for a job; callers hold s.mu
+func (s *Scheduler) launch(state *jobState) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			next := s.plan(state)
+			if next.IsZero() {
+				return
+			}
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-s.ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				if !state.job.EveryReplica && !s.leading() {
+					s.mu.Lock()
+					state.standby++
+					s.mu.Unlock()
+					continue
+				}
+				if !s.execute(state, "schedule") {
+					s.mu.Lock()
+					state.skipped++
+					s.mu.Unlock()
+				}
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) leading() bool {
+	s.mu.Lock()
+	leader := s.leader
+	s.mu.Unlock()
+	return leader == nil || leader.IsLeader()
+}
+
+// plan records and returns the job's next run time including jitter
+func (s *Scheduler) plan(state *jobState) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := state.job.Schedule.Next(time.Now())
+	if !next.IsZero() && state.job.Jitter > 0 {
+		next = next.Add(time.Duration(s.rand.Int63n(int64(state.job.Jitter))))
+	}
+	state.nextRun = next
+	return next
+}
+
+// execute runs a job in the background unless it is already running, and
+// reports whether it started
+func (s *Scheduler) execute(state *jobState, trigger string) bool {
+	s.mu.Lock()
+	if state.running || s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return false
+	}
+	state.running = true
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+
+		ctx := s.ctx
+		if state.job.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, state.job.Timeout)
+			defer cancel()
+		}
+
+		run := Run{Job: state.job.Name, Trigger: trigger, StartedAt: time.Now()}
+		findings, err := state.job.Run(ctx)
+		run.Duration = time.Since(run.StartedAt)
+		run.Findings = findings
+		if err == nil && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			run.Error = err.Error()
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		state.running = false
+		state.runs++
+		if err != nil {
+			state.failures++
+		}
+		state.history = append(state.history, run)
+		if len(state.history) > historyLimit {
+			state.history = state.history[len(state.history)-historyLimit:]
+		}
+	}()
+	return true
+}
+
+func reversed(runs []Run) []Run {
+	out := make([]Run, len(runs))
+	for i, run := range runs {
+		out[len(runs)-1-i] = run
+	}
+	return out
+}
@@ -0,0 +1,227 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestParse(t *testing.T) {
+	for spec, want := range map[string]string{
+		"@every 30s":   "@every 30s",
+		"5m":           "@every 5m0s",
+		"*/15 * * * *": "*/15 * * * *",
+	} {
+		s, err := Parse(spec)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", spec, err)
+			continue
+		}
+		if s.String() != want {
+			t.Errorf("Parse(%q) = %q, want %q", spec, s.String(), want)
+		}
+	}
+
+	for _, spec := range []string{"", "@every -1s", "* * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Expected Parse(%q) to fail", spec)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// Wednesday
+	after := time.Date(2026, 3, 4, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 3, 4, 10, 15, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", time.Date(2026, 3, 5, 2, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Day of month or Sunday
+		{"0 12 15 * 0", time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.spec, err)
+		}
+		if got := s.Next(after); !got.Equal(tt.want) {
+			t.Errorf("%q.Next = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestJobRunsOnScheduleAndRecordsHistory(t *testing.T) {
+	s := New()
+	var calls atomic.Int32
+	err := s.Add(Job{
+		Name:     "detect",
+		Schedule: Every(10 * time.Millisecond),
+		Run: func(ctx context.Context) (int, error) {
+			if calls.Add(1) == 2 {
+				return 0, errors.New("source unavailable")
+			}
+			return 3, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := s.Add(Job{Name: "detect", Schedule: Every(time.Second), Run: func(context.Context) (int, error) { return 0, nil }}); err == nil {
+		t.Error("Expected duplicate job to be rejected")
+	}
+
+	s.Start()
+	waitFor(t, func() bool { return s.Jobs()[0].Runs >= 3 })
+	s.Stop()
+
+	runs, err := s.History("detect")
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	// Newest first
+	oldest := runs[len(runs)-1]
+	if oldest.Findings != 3 || oldest.Error != "" || oldest.Trigger != "schedule" {
+		t.Errorf("Unexpected first run: %+v", oldest)
+	}
+	if failed := runs[len(runs)-2]; failed.Error != "source unavailable" {
+		t.Errorf("Expected second run to record its error, got %+v", failed)
+	}
+	if status := s.Jobs()[0]; status.Failures != 1 || status.LastRun == nil {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	if _, err := s.History("missing"); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("Expected ErrUnknownJob, got %v", err)
+	}
+}
+
+func TestOverlappingRunsAreSkipped(t *testing.T) {
+	s := New()
+	release := make(chan struct{})
+	var calls atomic.Int32
+	s.Add(Job{
+		Name:     "slow",
+		Schedule: Every(5 * time.Millisecond),
+		Run: func(ctx context.Context) (int, error) {
+			calls.Add(1)
+			<-release
+			return 0, nil
+		},
+	})
+
+	s.Start()
+	waitFor(t, func() bool { return s.Jobs()[0].Skipped >= 2 })
+	if err := s.Trigger("slow"); !errors.Is(err, ErrRunning) {
+		t.Errorf("Expected ErrRunning, got %v", err)
+	}
+	close(release)
+	s.Stop()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected a single run while the first was in flight, got %d", n)
+	}
+}
+
+func TestTimeoutIsRecorded(t *testing.T) {
+	s := New()
+	s.Add(Job{
+		Name:     "hung",
+		Schedule: Every(time.Hour),
+		Timeout:  10 * time.Millisecond,
+		Run: func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			return 0, nil
+		},
+	})
+
+	if err := s.Trigger("hung"); err != nil {
+		t.Fatalf("Trigger failed: %v", err)
+	}
+	waitFor(t, func() bool { return s.Jobs()[0].Runs == 1 })
+
+	runs, _ := s.History("hung")
+	if runs[0].Error != context.DeadlineExceeded.Error() || runs[0].Trigger != "manual" {
+		t.Errorf("Expected deadline error on manual run, got %+v", runs[0])
+	}
+	if err := s.Trigger("missing"); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("Expected ErrUnknownJob, got %v", err)
+	}
+}
+
+func TestStopCancelsRunningJobs(t *testing.T) {
+	s := New()
+	started := make(chan struct{})
+	s.Add(Job{
+		Name:     "long",
+		Schedule: Every(time.Millisecond),
+		Run: func(ctx context.Context) (int, error) {
+			close(started)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		},
+	})
+
+	s.Start()
+	<-started
+	s.Stop()
+
+	runs, _ := s.History("long")
+	if len(runs) != 1 || runs[0].Error != context.Canceled.Error() {
+		t.Errorf("Expected one cancelled run, got %+v", runs)
+	}
+	if err := s.Trigger("long"); !errors.Is(err, ErrStopped) {
+		t.Errorf("Expected stopped scheduler to refuse runs, got %v", err)
+	}
+}
+
+type stubLeader struct{ leading atomic.Bool }
+
+func (l *stubLeader) IsLeader() bool { return l.leading.Load() }
+
+func TestStandbyReplicaSkipsRuns(t *testing.T) {
+	s := New()
+	leader := &stubLeader{}
+	s.SetLeader(leader)
+	var calls atomic.Int32
+	s.Add(Job{
+		Name:     "detect",
+		Schedule: Every(5 * time.Millisecond),
+		Run: func(ctx context.Context) (int, error) {
+			calls.Add(1)
+			return 0, nil
+		},
+	})
+
+	s.Start()
+	defer s.Stop()
+	waitFor(t, func() bool { return s.Jobs()[0].Standby >= 2 })
+	if calls.Load() != 0 {
+		t.Error("Expected standby replica not to run jobs")
+	}
+	if err := s.Trigger("detect"); !errors.Is(err, ErrNotLeader) {
+		t.Errorf("Expected ErrNotLeader, got %v", err)
+	}
+
+	leader.leading.Store(true)
+	waitFor(t, func() bool { return calls.Load() > 0 })
+}
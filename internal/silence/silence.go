@@ -0,0 +1,203 @@
+// Package silence suppresses expected anomalies, such as node desyncs
+// during a planned Mars conjunction blackout. Silences cover a fixed time
+// range; maintenance windows recur on a cron schedule. Matching anomalies
+// are still recorded but flagged as suppressed.
+package silence
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/scheduler"
+)
+
+// State describes where a silence is in its lifetime
+type State string
+
+const (
+	StatePending State = "pending"
+	StateActive  State = "active"
+	StateExpired State = "expired"
+)
+
+// Matcher matches a metadata key against a value, or against an anchored
+// regular expression if Regex is set. List values match if any element
+// does; anomalies without the key never match.
+type Matcher struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Regex bool   `json:"regex,omitempty"`
+}
+
+// Selector picks the anomalies a silence or maintenance window covers.
+// Empty fields match anything; all set fields and matchers must match.
+type Selector struct {
+	Type     models.AnomalyType     `json:"type,omitempty"`
+	Source   string                 `json:"source,omitempty"`
+	Severity models.AnomalySeverity `json:"severity,omitempty"`
+	Matchers []Matcher              `json:"matchers,omitempty"`
+}
+
+// Silence suppresses matching anomalies detected between StartsAt and
+// EndsAt
+type Silence struct {
+	ID string `json:"id"`
+	Selector
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedBy string    `json:"created_by"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// State returns the silence's state at now
+func (s Silence) State(now time.Time) State {
+	switch {
+	case now.Before(s.StartsAt):
+		return StatePending
+	case now.Before(s.EndsAt):
+		return StateActive
+	default:
+		return StateExpired
+	}
+}
+
+// Window is a recurring maintenance window that opens on each run of a
+// cron schedule and stays open for DurationSeconds
+type Window struct {
+	Name string `json:"name"`
+	Selector
+	Schedule        string `json:"schedule"`
+	DurationSeconds int    `json:"duration_seconds"`
+	// Timezone the schedule is evaluated in, UTC by default
+	Timezone  string `json:"timezone,omitempty"`
+	CreatedBy string `json:"created_by"`
+	Comment   string `json:"comment"`
+}
+
+// Duration returns how long the window stays open
+func (w Window) Duration() time.Duration {
+	return time.Duration(w.DurationSeconds) * time.Second
+}
+
+// selector is a Selector with compiled matchers
+type selector struct {
+	Selector
+	patterns []*regexp.Regexp
+}
+
+func compileSelector(sel Selector) (*selector, error) {
+	if sel.Type == "" && sel.Source == "" && sel.Severity == "" && len(sel.Matchers) == 0 {
+		return nil, fmt.Errorf("at least one of type, source, severity or matchers is required")
+	}
+
+	c := &selector{Selector: sel, patterns: make([]*regexp.Regexp, len(sel.Matchers))}
+	for i, m := range sel.Matchers {
+		if m.Name == "" {
+			return nil, fmt.Errorf("matcher name is required")
+		}
+		if m.Regex {
+			re, err := regexp.Compile("^(?:" + m.Value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("matcher %s: %w", m.Name, err)
+			}
+			c.patterns[i] = re
+		}
+	}
+	return c, nil
+}
+
+func (s *selector) matches(a *models.Anomaly) bool {
+	if s.Type != "" && a.Type != s.Type {
+		return false
+	}
+	if s.Source != "" && a.Source != s.Source {
+		return false
+	}
+	if s.Severity != "" && a.Severity != s.Severity {
+		return false
+	}
+	for i, m := range s.Matchers {
+		value, ok := a.Metadata[m.Name]
+		if !ok || !s.matchValue(i, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *selector) matchValue(i int, value interface{}) bool {
+	switch v := value.(type) {
+	case []string:
+		for _, item := range v {
+			if s.matchValue(i, item) {
+				return true
+			}
+		}
+		return false
+	case []interface{}:
+		for _, item := range v {
+			if s.matchValue(i, item) {
+				return true
+			}
+		}
+		return false
+	}
+
+	text := fmt.Sprint(value)
+	if re := s.patterns[i]; re != nil {
+		return re.MatchString(text)
+	}
+	return text == s.Matchers[i].Value
+}
+
+// window is a Window with its schedule parsed
+type window struct {
+	Window
+	sel      *selector
+	schedule scheduler.Schedule
+	location *time.Location
+}
+
+func compileWindow(w Window) (*window, error) {
+	if w.Name == "" {
+		return nil, fmt.Errorf("maintenance window name is required")
+	}
+	if w.DurationSeconds <= 0 {
+		return nil, fmt.Errorf("maintenance window %s: duration_seconds must be positive", w.Name)
+	}
+	schedule, err := scheduler.Parse(w.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("maintenance window %s: %w", w.Name, err)
+	}
+	if _, ok := schedule.(scheduler.Every); ok {
+		return nil, fmt.Errorf("maintenance window %s: schedule must be a cron expression", w.Name)
+	}
+	location := time.UTC
+	if w.Timezone != "" {
+		if location, err = time.LoadLocation(w.Timezone); err != nil {
+			return nil, fmt.Errorf("maintenance window %s: %w", w.Name, err)
+		}
+	}
+	sel, err := compileSelector(w.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("maintenance window %s: %w", w.Name, err)
+	}
+	return &window{Window: w, sel: sel, schedule: schedule, location: location}, nil
+}
+
+// openedAt returns when the window last opened if it is open at now
+func (w *window) openedAt(now time.Time) (time.Time, bool) {
+	start := w.schedule.Next(now.In(w.location).Add(-w.Duration()))
+	if start.IsZero() || start.After(now) {
+		return time.Time{}, false
+	}
+	return start, true
+}
+
+// next returns when the window next opens after now
+func (w *window) next(now time.Time) time.Time {
+	return w.schedule.Next(now.In(w.location))
+}
@@ -0,0 +1,230 @@
+package silence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/google/uuid"
+)
+
+// ErrNotFound is returned for an unknown silence or maintenance window
+var ErrNotFound = errors.New("not found")
+
+// SilenceStatus is a silence with its state and how many anomalies it
+// suppressed
+type SilenceStatus struct {
+	Silence
+	State   State `json:"state"`
+	Matched int   `json:"matched"`
+}
+
+// WindowStatus is a maintenance window with whether it is open and how
+// many anomalies it suppressed
+type WindowStatus struct {
+	Window
+	Open     bool       `json:"open"`
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+	NextOpen time.Time  `json:"next_open"`
+	Matched  int        `json:"matched"`
+}
+
+type silenceState struct {
+	silence Silence
+	sel     *selector
+	matched int
+}
+
+type windowState struct {
+	window  *window
+	matched int
+}
+
+// Store holds silences and maintenance windows and decides which new
+// anomalies they suppress
+type Store struct {
+	silences map[string]*silenceState
+	order    []string
+	windows  map[string]*windowState
+	wOrder   []string
+	now      func() time.Time
+	mu       sync.Mutex
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{
+		silences: make(map[string]*silenceState),
+		windows:  make(map[string]*windowState),
+		now:      time.Now,
+	}
+}
+
+// Add validates and stores a silence. StartsAt defaults to now.
+func (s *Store) Add(silence Silence) (Silence, error) {
+	sel, err := compileSelector(silence.Selector)
+	if err != nil {
+		return Silence{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = now
+	}
+	if !silence.EndsAt.After(silence.StartsAt) {
+		return Silence{}, fmt.Errorf("ends_at must be after starts_at")
+	}
+	if !silence.EndsAt.After(now) {
+		return Silence{}, fmt.Errorf("ends_at must be in the future")
+	}
+	silence.ID = uuid.New().String()
+	silence.CreatedAt = now
+
+	s.silences[silence.ID] = &silenceState{silence: silence, sel: sel}
+	s.order = append(s.order, silence.ID)
+	return silence, nil
+}
+
+// Expire ends a silence now; expiring an expired silence is a no-op
+func (s *Store) Expire(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.silences[id]
+	if !ok {
+		return fmt.Errorf("silence %w: %s", ErrNotFound, id)
+	}
+	now := s.now()
+	if state.silence.EndsAt.After(now) {
+		state.silence.EndsAt = now
+		if state.silence.StartsAt.After(now) {
+			state.silence.StartsAt = now
+		}
+	}
+	return nil
+}
+
+// Silences returns every silence, newest first, optionally only those in
+// state
+func (s *Store) Silences(state State) []SilenceStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	statuses := make([]SilenceStatus, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		st := s.silences[s.order[i]]
+		status := SilenceStatus{Silence: st.silence, State: st.silence.State(now), Matched: st.matched}
+		if state == "" || status.State == state {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// AddWindows validates and stores maintenance windows, replacing any with
+// the same name. Nothing is stored if any window is invalid.
+func (s *Store) AddWindows(windows ...Window) error {
+	compiled := make([]*window, 0, len(windows))
+	for _, w := range windows {
+		c, err := compileWindow(w)
+		if err != nil {
+			return err
+		}
+		compiled = append(compiled, c)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, w := range compiled {
+		if _, exists := s.windows[w.Name]; !exists {
+			s.wOrder = append(s.wOrder, w.Name)
+		}
+		s.windows[w.Name] = &windowState{window: w}
+	}
+	return nil
+}
+
+// RemoveWindow deletes a maintenance window
+func (s *Store) RemoveWindow(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.windows[name]; !ok {
+		return fmt.Errorf("maintenance window %w: %s", ErrNotFound, name)
+	}
+	delete(s.windows, name)
+	for i, n := range s.wOrder {
+		if n == name {
+			s.wOrder = append(s.wOrder[:i], s.wOrder[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Windows returns every maintenance window
+func (s *Store) Windows() []WindowStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	statuses := make([]WindowStatus, 0, len(s.wOrder))
+	for _, name := range s.wOrder {
+		st := s.windows[name]
+		status := WindowStatus{Window: st.window.Window, NextOpen: st.window.next(now), Matched: st.matched}
+		if opened, ok := st.window.openedAt(now); ok {
+			status.Open = true
+			status.OpenedAt = &opened
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Suppress reports whether an active silence or open maintenance window
+// matches a, naming the first that does as "silence:<id>" or
+// "maintenance:<name>"
+func (s *Store) Suppress(a *models.Anomaly) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for _, id := range s.order {
+		st := s.silences[id]
+		if st.silence.State(now) == StateActive && st.sel.matches(a) {
+			st.matched++
+			return "silence:" + id, true
+		}
+	}
+	for _, name := range s.wOrder {
+		st := s.windows[name]
+		if _, open := st.window.openedAt(now); open && st.window.sel.matches(a) {
+			st.matched++
+			return "maintenance:" + name, true
+		}
+	}
+	return "", false
+}
+
+// LoadWindowsFile reads maintenance windows from a JSON array
+func LoadWindowsFile(path string) ([]Window, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read maintenance windows: %w", err)
+	}
+
+	var windows []Window
+	if err := json.Unmarshal(data, &windows); err != nil {
+		return nil, fmt.Errorf("invalid maintenance windows file %s: %w", path, err)
+	}
+	return windows, nil
+}
@@ -0,0 +1,185 @@
+package silence
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/anomaly"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+func newStore(now *time.Time) *Store {
+	s := NewStore()
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func desync(route string) *models.Anomaly {
+	return &models.Anomaly{
+		Type:     models.AnomalyTypeNodeDesynchronization,
+		Severity: models.SeverityLow,
+		Source:   "xAI Emissary",
+		Metadata: map[string]interface{}{"affected_route": route, "latency_ms": 450},
+	}
+}
+
+func TestSilenceMatchesDuringItsTimeRange(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	s := newStore(&now)
+
+	silence, err := s.Add(Silence{
+		Selector: Selector{
+			Type:     models.AnomalyTypeNodeDesynchronization,
+			Matchers: []Matcher{{Name: "affected_route", Value: "Earth-Mars|Moon-Mars", Regex: true}},
+		},
+		StartsAt:  now.Add(time.Hour),
+		EndsAt:    now.Add(3 * time.Hour),
+		CreatedBy: "alice",
+		Comment:   "Mars conjunction",
+	})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if _, ok := s.Suppress(desync("Earth-Mars")); ok {
+		t.Error("Expected pending silence not to suppress")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if by, ok := s.Suppress(desync("Earth-Mars")); !ok || by != "silence:"+silence.ID {
+		t.Errorf("Expected active silence to suppress, got %q %v", by, ok)
+	}
+	if _, ok := s.Suppress(desync("Earth-Moon")); ok {
+		t.Error("Expected other route not to be suppressed")
+	}
+	other := desync("Earth-Mars")
+	other.Type = models.AnomalyTypeLedgerDivergence
+	if _, ok := s.Suppress(other); ok {
+		t.Error("Expected other type not to be suppressed")
+	}
+
+	if err := s.Expire(silence.ID); err != nil {
+		t.Fatalf("Expire failed: %v", err)
+	}
+	if _, ok := s.Suppress(desync("Earth-Mars")); ok {
+		t.Error("Expected expired silence not to suppress")
+	}
+	statuses := s.Silences(StateExpired)
+	if len(statuses) != 1 || statuses[0].Matched != 1 || statuses[0].CreatedBy != "alice" {
+		t.Errorf("Unexpected silences: %+v", statuses)
+	}
+	if err := s.Expire("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMatchersCompareValuesAndLists(t *testing.T) {
+	now := time.Now()
+	s := newStore(&now)
+	s.Add(Silence{
+		Selector: Selector{Matchers: []Matcher{
+			{Name: "latency_ms", Value: "450"},
+			{Name: "nodes", Value: "mars-.*", Regex: true},
+		}},
+		EndsAt: now.Add(time.Hour),
+	})
+
+	a := desync("Earth-Mars")
+	a.Metadata["nodes"] = []interface{}{"earth-node-1", "mars-node-1"}
+	if _, ok := s.Suppress(a); !ok {
+		t.Error("Expected number and list matchers to match")
+	}
+	delete(a.Metadata, "nodes")
+	if _, ok := s.Suppress(a); ok {
+		t.Error("Expected missing metadata key not to match")
+	}
+}
+
+func TestInvalidSilencesAreRejected(t *testing.T) {
+	now := time.Now()
+	s := newStore(&now)
+	for _, silence := range []Silence{
+		{EndsAt: now.Add(time.Hour)},
+		{Selector: Selector{Source: "xAI Emissary"}, StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)},
+		{Selector: Selector{Source: "xAI Emissary"}, StartsAt: now.Add(time.Hour), EndsAt: now},
+		{Selector: Selector{Matchers: []Matcher{{Name: "route", Value: "(", Regex: true}}}, EndsAt: now.Add(time.Hour)},
+	} {
+		if _, err := s.Add(silence); err == nil {
+			t.Errorf("Expected %+v to be rejected", silence)
+		}
+	}
+}
+
+func TestMaintenanceWindowRecurs(t *testing.T) {
+	// Saturday 02:30 UTC
+	now := time.Date(2026, 3, 7, 2, 30, 0, 0, time.UTC)
+	s := newStore(&now)
+
+	err := s.AddWindows(Window{
+		Name:            "mars-conjunction",
+		Selector:        Selector{Type: models.AnomalyTypeNodeDesynchronization},
+		Schedule:        "0 2 * * 6",
+		DurationSeconds: 7200,
+	})
+	if err != nil {
+		t.Fatalf("AddWindows failed: %v", err)
+	}
+
+	if by, ok := s.Suppress(desync("Earth-Mars")); !ok || by != "maintenance:mars-conjunction" {
+		t.Errorf("Expected open window to suppress, got %q %v", by, ok)
+	}
+	windows := s.Windows()
+	if !windows[0].Open || !windows[0].OpenedAt.Equal(time.Date(2026, 3, 7, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected window status: %+v", windows[0])
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok := s.Suppress(desync("Earth-Mars")); ok {
+		t.Error("Expected closed window not to suppress")
+	}
+	if next := s.Windows()[0].NextOpen; !next.Equal(time.Date(2026, 3, 14, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected next open: %v", next)
+	}
+
+	for _, w := range []Window{
+		{Name: "interval", Selector: Selector{Source: "x"}, Schedule: "@every 1h", DurationSeconds: 60},
+		{Name: "no-duration", Selector: Selector{Source: "x"}, Schedule: "0 2 * * *"},
+		{Name: "bad-zone", Selector: Selector{Source: "x"}, Schedule: "0 2 * * *", DurationSeconds: 60, Timezone: "Mars/Olympus"},
+	} {
+		if err := s.AddWindows(w); err == nil {
+			t.Errorf("Expected window %s to be rejected", w.Name)
+		}
+	}
+	if err := s.RemoveWindow("mars-conjunction"); err != nil || len(s.Windows()) != 0 {
+		t.Errorf("Expected window to be removed, got %v", err)
+	}
+}
+
+func TestSuppressedAnomaliesAreNotPending(t *testing.T) {
+	now := time.Now()
+	s := newStore(&now)
+	s.Add(Silence{Selector: Selector{Type: models.AnomalyTypeNodeDesynchronization}, EndsAt: now.Add(time.Hour)})
+
+	detector := anomaly.NewDetector()
+	detector.SetSuppressor(s)
+	var suppressed int
+	detector.Subscribe(func(e anomaly.Event) {
+		if e.Anomaly.Suppressed {
+			suppressed++
+		}
+	})
+
+	recorded := detector.Record(desync("Earth-Mars"), &models.Anomaly{Type: models.AnomalyTypeLedgerDivergence, Severity: models.SeverityCritical})
+	if !recorded[0].Suppressed || recorded[0].SilencedBy == "" || recorded[1].Suppressed {
+		t.Errorf("Unexpected suppression: %+v", recorded)
+	}
+	if suppressed != 1 {
+		t.Errorf("Expected subscribers to see one suppressed anomaly, got %d", suppressed)
+	}
+
+	report := detector.GenerateReport()
+	if report.TotalAnomalies != 2 || report.PendingAnomalies != 1 || report.SuppressedAnomalies != 1 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
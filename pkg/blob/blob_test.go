@@ -0,0 +1,47 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalRoundTrip(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal failed: %v", err)
+	}
+	ctx := context.Background()
+
+	n, err := store.Put(ctx, "a1/log.txt", strings.NewReader("panic: divergence"))
+	if err != nil || n != 17 {
+		t.Fatalf("Put returned %d, %v", n, err)
+	}
+	r, err := store.Get(ctx, "a1/log.txt")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "panic: divergence" {
+		t.Errorf("Unexpected content %q", data)
+	}
+
+	if err := store.Delete(ctx, "a1/log.txt"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Get(ctx, "a1/log.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestLocalRejectsKeysOutsideDirectory(t *testing.T) {
+	store, _ := NewLocal(t.TempDir())
+	for _, key := range []string{"", "../escape", "/etc/passwd", "a/../../b"} {
+		if _, err := store.Put(context.Background(), key, strings.NewReader("x")); err == nil {
+			t.Errorf("Expected key %q to be rejected", key)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package blockchain
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -10,6 +13,21 @@ type ManusClient struct {
 	nodeURL         string
 	networkID       string
 	enablePlanetary bool
+
+	entries     []LedgerEntry
+	sequence    uint64
+	subscribers []func(LedgerEntry)
+	mu          sync.RWMutex
+}
+
+// LedgerEntry is an immutable record written to the Manus ledger
+type LedgerEntry struct {
+	TxHash      string                 `json:"tx_hash"`
+	AnomalyID   string                 `json:"anomaly_id"`
+	Description string                 `json:"description"`
+	Actor       string                 `json:"actor,omitempty"`
+	Payload     map[string]interface{} `json:"payload,omitempty"`
+	RecordedAt  time.Time              `json:"recorded_at"`
 }
 
 // NewManusClient creates a new Manus Blockchain client
@@ -51,11 +69,49 @@ func (m *ManusClient) GetStatus() (*BlockchainStatus, error) {
 
 // LogAnomaly logs an anomaly to the blockchain for immutable record
 func (m *ManusClient) LogAnomaly(anomalyID, description string) (string, error) {
+	return m.LogEntry(LedgerEntry{AnomalyID: anomalyID, Description: description})
+}
+
+// LogEntry writes a ledger entry and returns its transaction hash
+func (m *ManusClient) LogEntry(entry LedgerEntry) (string, error) {
 	// This is a stub implementation
 	// In production, this would create a transaction on the Manus blockchain
-	
-	txHash := fmt.Sprintf("0x%s%d", anomalyID[:8], time.Now().Unix())
-	return txHash, nil
+
+	m.mu.Lock()
+	// The sequence keeps hashes unique for entries written in the same
+	// instant, even without an anomaly
+	m.sequence++
+	entry.RecordedAt = time.Now()
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%d/%d", m.networkID, entry.AnomalyID, entry.RecordedAt.UnixNano(), m.sequence)))
+	entry.TxHash = "0x" + hex.EncodeToString(sum[:])
+	m.entries = append(m.entries, entry)
+	subscribers := make([]func(LedgerEntry), len(m.subscribers))
+	copy(subscribers, m.subscribers)
+	m.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(entry)
+	}
+
+	return entry.TxHash, nil
+}
+
+// Entries returns every ledger entry written through this client
+func (m *ManusClient) Entries() []LedgerEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries := make([]LedgerEntry, len(m.entries))
+	copy(entries, m.entries)
+	return entries
+}
+
+// Subscribe registers fn to be called after each ledger write
+func (m *ManusClient) Subscribe(fn func(LedgerEntry)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.subscribers = append(m.subscribers, fn)
 }
 
 // VerifyCommit verifies a Git commit hash against blockchain records
@@ -66,3 +122,71 @@ func (m *ManusClient) VerifyCommit(commitHash string) (bool, error) {
 	// Simulate verification
 	return true, nil
 }
+
+// SnapshotNodes captures the ledger state of nodes so a later resync can
+// be undone, and returns the snapshot ID
+func (m *ManusClient) SnapshotNodes(nodes []string) (string, error) {
+	// This is a stub implementation
+	// In production, this would ask each node to checkpoint its ledger
+
+	if len(nodes) == 0 {
+		return "", fmt.Errorf("no nodes to snapshot")
+	}
+	return fmt.Sprintf("snap-%d-%d", len(nodes), time.Now().UnixNano()), nil
+}
+
+// RestoreNodes rolls nodes back to a snapshot taken by SnapshotNodes
+func (m *ManusClient) RestoreNodes(snapshotID string) error {
+	// This is a stub implementation
+	// In production, this would restore each node from its checkpoint
+
+	if snapshotID == "" {
+		return fmt.Errorf("snapshot ID is required")
+	}
+	return nil
+}
+
+// ResyncNodes replaces the ledger of nodes with the chain held by peer and
+// returns the block height they were synced to
+func (m *ManusClient) ResyncNodes(nodes []string, peer string) (int64, error) {
+	// This is a stub implementation
+	// In production, this would stream blocks from peer to each node
+
+	if len(nodes) == 0 || peer == "" {
+		return 0, fmt.Errorf("nodes and peer are required")
+	}
+	status, err := m.GetStatus()
+	if err != nil {
+		return 0, err
+	}
+	return status.CurrentBlock, nil
+}
+
+// RebroadcastVote re-submits a DAO vote through relay on behalf of actor,
+// for anomalyID if it is set, and returns the transaction hash of the
+// re-broadcast
+func (m *ManusClient) RebroadcastVote(anomalyID, proposalID, relay, actor string) (string, error) {
+	if proposalID == "" || relay == "" {
+		return "", fmt.Errorf("proposal ID and relay are required")
+	}
+	return m.LogEntry(LedgerEntry{
+		AnomalyID:   anomalyID,
+		Description: fmt.Sprintf("DAO vote %s re-broadcast via %s", proposalID, relay),
+		Actor:       actor,
+		Payload:     map[string]interface{}{"proposal_id": proposalID, "relay": relay},
+	})
+}
+
+// AnchorCommit records a Git commit hash on the ledger on behalf of actor,
+// for anomalyID if it is set, and returns the transaction hash
+func (m *ManusClient) AnchorCommit(anomalyID, commitHash, actor string) (string, error) {
+	if commitHash == "" {
+		return "", fmt.Errorf("commit hash is required")
+	}
+	return m.LogEntry(LedgerEntry{
+		AnomalyID:   anomalyID,
+		Description: fmt.Sprintf("Commit %s anchored", commitHash),
+		Actor:       actor,
+		Payload:     map[string]interface{}{"commit_hash": commitHash},
+	})
+}
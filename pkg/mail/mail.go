@@ -0,0 +1,221 @@
+// Package mail sends email with attachments through an SMTP relay.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultTimeout bounds a delivery when the context has no deadline
+const defaultTimeout = 30 * time.Second
+
+// Attachment is a file sent with a message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is an email. HTML, when set, is sent as an alternative to Text.
+type Message struct {
+	To          []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// SMTP sends messages through a relay, upgrading to TLS when the relay
+// offers STARTTLS and authenticating when a username is set
+type SMTP struct {
+	host     string
+	addr     string
+	from     string
+	username string
+	password string
+}
+
+// NewSMTP creates a sender for the relay at host:port sending as from,
+// which may include a display name
+func NewSMTP(host string, port int, username, password, from string) (*SMTP, error) {
+	if _, err := netmail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", from, err)
+	}
+	return &SMTP{
+		host:     host,
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		from:     from,
+		username: username,
+		password: password,
+	}, nil
+}
+
+// Send delivers m to its recipients
+func (s *SMTP) Send(ctx context.Context, m Message) error {
+	if len(m.To) == 0 {
+		return fmt.Errorf("message has no recipients")
+	}
+	body, err := m.bytes(s.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP relay: %w", err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("SMTP handshake failed: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+	if s.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	sender, _ := netmail.ParseAddress(s.from)
+	if err := c.Mail(sender.Address); err != nil {
+		return fmt.Errorf("SMTP relay rejected sender: %w", err)
+	}
+	for _, to := range m.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP relay rejected recipient %s: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP relay rejected data: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP relay rejected message: %w", err)
+	}
+	return c.Quit()
+}
+
+// bytes encodes m as a MIME message
+func (m Message) bytes(from string, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", from)
+	header("To", strings.Join(m.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", "<"+randomID()+"@"+domain(from)+">")
+	header("MIME-Version", "1.0")
+
+	mixed := multipart.NewWriter(&buf)
+	header("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mixed.Boundary()}))
+	buf.WriteString("\r\n")
+
+	// The body, with an HTML alternative if there is one
+	if m.HTML == "" {
+		if err := writePart(mixed, "text/plain; charset=utf-8", "", []byte(m.Text)); err != nil {
+			return nil, err
+		}
+	} else {
+		var alt bytes.Buffer
+		alternative := multipart.NewWriter(&alt)
+		if err := writePart(alternative, "text/plain; charset=utf-8", "", []byte(m.Text)); err != nil {
+			return nil, err
+		}
+		if err := writePart(alternative, "text/html; charset=utf-8", "", []byte(m.HTML)); err != nil {
+			return nil, err
+		}
+		alternative.Close()
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": alternative.Boundary()})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		part.Write(alt.Bytes())
+	}
+
+	for _, a := range m.Attachments {
+		disposition := mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})
+		if err := writePart(mixed, a.ContentType, disposition, a.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writePart adds a base64 encoded part, wrapped at 76 characters
+func writePart(w *multipart.Writer, contentType, disposition string, data []byte) error {
+	h := textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+	}
+	if disposition != "" {
+		h.Set("Content-Disposition", disposition)
+	}
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		fmt.Fprintf(part, "%s\r\n", encoded[:76])
+		encoded = encoded[76:]
+	}
+	_, err = fmt.Fprintf(part, "%s\r\n", encoded)
+	return err
+}
+
+func randomID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func domain(from string) string {
+	if addr, err := netmail.ParseAddress(from); err == nil {
+		if i := strings.LastIndex(addr.Address, "@"); i >= 0 {
+			return addr.Address[i+1:]
+		}
+	}
+	return "localhost"
+}
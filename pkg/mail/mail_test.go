@@ -0,0 +1,171 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	netmail "net/mail"
+	"strings"
+	"testing"
+)
+
+// fakeSMTP accepts one message per connection and hands over what it
+// received
+type fakeSMTP struct {
+	listener net.Listener
+	received chan received
+	reject   string
+}
+
+type received struct {
+	from string
+	to   []string
+	data string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	f := &fakeSMTP{listener: l, received: make(chan received, 1)}
+	go f.serve()
+	t.Cleanup(func() { l.Close() })
+	return f
+}
+
+func (f *fakeSMTP) port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeSMTP) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+
+	var msg received
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 fake")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			to := strings.Trim(line[len("RCPT TO:"):], "<>")
+			if to == f.reject {
+				reply("550 no such user")
+				continue
+			}
+			msg.to = append(msg.to, to)
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			msg.data = data.String()
+			reply("250 queued")
+			f.received <- msg
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSendWithAttachment(t *testing.T) {
+	server := newFakeSMTP(t)
+	sender, err := NewSMTP("127.0.0.1", server.port(), "", "", "Anomaly Reports <reports@example.com>")
+	if err != nil {
+		t.Fatalf("NewSMTP failed: %v", err)
+	}
+
+	err = sender.Send(context.Background(), Message{
+		To:      []string{"ops@example.com", "lead@example.com"},
+		Subject: "Weekly anomaly report — Mars",
+		Text:    "3 new anomalies",
+		HTML:    "<p>3 new anomalies</p>",
+		Attachments: []Attachment{
+			{Filename: "report.csv", ContentType: "text/csv", Data: []byte("id,type\na1,node_desync\n")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	got := <-server.received
+	if got.from != "reports@example.com" || len(got.to) != 2 {
+		t.Fatalf("Unexpected envelope: %+v", got)
+	}
+
+	msg, err := netmail.ReadMessage(strings.NewReader(got.data))
+	if err != nil {
+		t.Fatalf("Invalid message: %v", err)
+	}
+	if subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); subject != "Weekly anomaly report — Mars" {
+		t.Errorf("Unexpected subject %q", subject)
+	}
+
+	_, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	var names []string
+	for {
+		part, err := parts.NextPart()
+		if err != nil {
+			break
+		}
+		names = append(names, part.FileName())
+	}
+	if len(names) != 2 || names[0] != "" || names[1] != "report.csv" {
+		t.Errorf("Expected a body and one attachment, got %q", names)
+	}
+}
+
+func TestSendFailsForRejectedRecipient(t *testing.T) {
+	server := newFakeSMTP(t)
+	server.reject = "nobody@example.com"
+	sender, _ := NewSMTP("127.0.0.1", server.port(), "", "", "reports@example.com")
+
+	err := sender.Send(context.Background(), Message{To: []string{"nobody@example.com"}, Subject: "x"})
+	if err == nil || !strings.Contains(err.Error(), "nobody@example.com") {
+		t.Errorf("Expected the rejected recipient to fail delivery, got %v", err)
+	}
+}
+
+func TestInvalidSender(t *testing.T) {
+	if _, err := NewSMTP("localhost", 25, "", "", "not an address"); err == nil {
+		t.Error("Expected an invalid sender to be rejected")
+	}
+}
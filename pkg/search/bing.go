@@ -1,6 +1,7 @@
 package search
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -18,21 +19,46 @@ type BingSearchClient struct {
 	client   *http.Client
 }
 
+// ClientOption configures optional BingSearchClient settings
+type ClientOption func(*BingSearchClient)
+
+// WithTimeout bounds each upstream request
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(b *BingSearchClient) {
+		b.client.Timeout = timeout
+	}
+}
+
+// WithTransport replaces the HTTP transport, e.g. with a replay.Transport
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(b *BingSearchClient) {
+		b.client.Transport = transport
+	}
+}
+
 // NewBingSearchClient creates a new Bing Search client
-func NewBingSearchClient(apiKey, endpoint string) *BingSearchClient {
-	return &BingSearchClient{
+func NewBingSearchClient(apiKey, endpoint string, opts ...ClientOption) *BingSearchClient {
+	b := &BingSearchClient{
 		apiKey:   apiKey,
 		endpoint: endpoint,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
 }
 
 // Search performs a search query using Bing Search API
-func (b *BingSearchClient) Search(query string, count int) (*models.SearchResponse, error) {
+func (b *BingSearchClient) Search(ctx context.Context, sr SearchRequest) (*models.SearchResponse, error) {
+	if err := sr.Validate(); err != nil {
+		return nil, err
+	}
+
 	if b.apiKey == "" {
-		return b.mockSearch(query, count), nil
+		return mockSearch(sr), nil
 	}
 
 	u, err := url.Parse(b.endpoint)
@@ -41,11 +67,26 @@ func (b *BingSearchClient) Search(query string, count int) (*models.SearchRespon
 	}
 
 	q := u.Query()
-	q.Set("q", query)
-	q.Set("count", fmt.Sprintf("%d", count))
+	q.Set("q", sr.EffectiveQuery())
+	q.Set("count", fmt.Sprintf("%d", sr.Count))
+	if sr.Offset > 0 {
+		q.Set("offset", fmt.Sprintf("%d", sr.Offset))
+	}
+	if sr.Market != "" {
+		q.Set("mkt", sr.Market)
+	}
+	if sr.Language != "" {
+		q.Set("setLang", sr.Language)
+	}
+	if sr.Freshness != FreshnessAny {
+		q.Set("freshness", string(sr.Freshness))
+	}
+	if sr.SafeSearch != SafeSearchDefault {
+		q.Set("safeSearch", string(sr.SafeSearch))
+	}
 	u.RawQuery = q.Encode()
 
-	req, err := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -75,8 +116,9 @@ func (b *BingSearchClient) Search(query string, count int) (*models.SearchRespon
 
 	// Convert Bing response to our model
 	searchResp := &models.SearchResponse{
-		Query:        query,
-		TotalResults: len(bingResp.WebPages.Value),
+		Query:        sr.Query,
+		TotalResults: bingResp.WebPages.TotalEstimatedMatches,
+		Offset:       sr.Offset,
 		Results:      make([]models.SearchResult, 0, len(bingResp.WebPages.Value)),
 		Source:       "Bing Search API",
 	}
@@ -92,8 +134,25 @@ func (b *BingSearchClient) Search(query string, count int) (*models.SearchRespon
 	return searchResp, nil
 }
 
+// MockSearchClient serves the canned results used when Bing is not
+// configured; it also acts as the last-resort fallback for guarded providers
+type MockSearchClient struct{}
+
+// Name identifies the mock provider
+func (MockSearchClient) Name() string {
+	return "mock"
+}
+
+// Search returns the canned results
+func (MockSearchClient) Search(ctx context.Context, sr SearchRequest) (*models.SearchResponse, error) {
+	if err := sr.Validate(); err != nil {
+		return nil, err
+	}
+	return mockSearch(sr), nil
+}
+
 // mockSearch returns mock search results when API key is not configured
-func (b *BingSearchClient) mockSearch(query string, count int) *models.SearchResponse {
+func mockSearch(sr SearchRequest) *models.SearchResponse {
 	mockResults := []models.SearchResult{
 		{
 			Title:   "Manus Blockchain - Interplanetary Distributed Ledger",
@@ -122,21 +181,43 @@ func (b *BingSearchClient) mockSearch(query string, count int) *models.SearchRes
 		},
 	}
 
-	// Limit results to requested count
-	if count > 0 && count < len(mockResults) {
-		mockResults = mockResults[:count]
+	// Apply site filters, then page through the remaining results
+	filtered := mockResults[:0]
+	for _, result := range mockResults {
+		if sr.MatchesSite(result.URL) {
+			filtered = append(filtered, result)
+		}
 	}
+	total := len(filtered)
+	filtered = paginate(filtered, sr.Offset, sr.Count)
 
 	return &models.SearchResponse{
-		Query:        query,
-		TotalResults: len(mockResults),
-		Results:      mockResults,
+		Query:        sr.Query,
+		TotalResults: total,
+		Offset:       sr.Offset,
+		Results:      filtered,
 		Source:       "Mock Search (API key not configured)",
 	}
 }
 
 // SearchAnomalyContext searches for context about a specific anomaly
-func (b *BingSearchClient) SearchAnomalyContext(anomalyType models.AnomalyType) (*models.SearchResponse, error) {
-	query := fmt.Sprintf("MANUS Blockchain %s anomaly resolution", anomalyType)
-	return b.Search(query, 5)
+func (b *BingSearchClient) SearchAnomalyContext(ctx context.Context, anomalyType models.AnomalyType) (*models.SearchResponse, error) {
+	return b.Search(ctx, SearchRequest{Query: AnomalyContextQuery(anomalyType), Count: 5})
+}
+
+// paginate returns the page of results starting at offset
+func paginate(results []models.SearchResult, offset, count int) []models.SearchResult {
+	if offset >= len(results) {
+		return []models.SearchResult{}
+	}
+	results = results[offset:]
+	if count > 0 && count < len(results) {
+		results = results[:count]
+	}
+	return results
+}
+
+// AnomalyContextQuery returns the generic context query for an anomaly type
+func AnomalyContextQuery(anomalyType models.AnomalyType) string {
+	return fmt.Sprintf("MANUS Blockchain %s anomaly resolution", anomalyType)
 }
@@ -0,0 +1,166 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/search/replay"
+)
+
+func TestBingSearchMapsRequestOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		expected := map[string]string{
+			"q":          "ledger divergence (site:manus.blockchain OR site:github.com)",
+			"count":      "5",
+			"offset":     "10",
+			"mkt":        "en-US",
+			"setLang":    "en",
+			"freshness":  "Week",
+			"safeSearch": "Strict",
+		}
+		for key, value := range expected {
+			if q.Get(key) != value {
+				t.Errorf("Expected %s=%q, got %q", key, value, q.Get(key))
+			}
+		}
+		if r.Header.Get("Ocp-Apim-Subscription-Key") != "test-key" {
+			t.Error("Expected subscription key header")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"webPages":{"totalEstimatedMatches":1234,"value":[{"name":"Manus","url":"https://manus.blockchain/docs","snippet":"ledger"}]}}`))
+	}))
+	defer server.Close()
+
+	client := NewBingSearchClient("test-key", server.URL)
+	resp, err := client.Search(context.Background(), SearchRequest{
+		Query:      "ledger divergence",
+		Count:      5,
+		Offset:     10,
+		Market:     "en-US",
+		Language:   "en",
+		Freshness:  FreshnessWeek,
+		SafeSearch: SafeSearchStrict,
+		Sites:      []string{"manus.blockchain", "github.com"},
+	})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if resp.TotalResults != 1234 {
+		t.Errorf("Expected totalEstimatedMatches 1234, got %d", resp.TotalResults)
+	}
+	if resp.Offset != 10 || len(resp.Results) != 1 {
+		t.Errorf("Unexpected page: offset %d, %d results", resp.Offset, len(resp.Results))
+	}
+}
+
+func TestBingSearchHonoursCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := NewBingSearchClient("test-key", "http://127.0.0.1:1")
+	if _, err := client.Search(ctx, SearchRequest{Query: "ledger"}); err == nil {
+		t.Error("Expected error for cancelled context")
+	}
+}
+
+func TestSearchRequestValidate(t *testing.T) {
+	valid := SearchRequest{Query: "ledger", Freshness: "2026-01-01..2026-02-01"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected valid request, got %v", err)
+	}
+	if valid.Count != DefaultCount {
+		t.Errorf("Expected default count %d, got %d", DefaultCount, valid.Count)
+	}
+
+	invalid := []SearchRequest{
+		{Query: ""},
+		{Query: "ledger", Count: MaxCount + 1},
+		{Query: "ledger", Offset: -1},
+		{Query: "ledger", Freshness: "Year"},
+		{Query: "ledger", Freshness: "2026-02-01..2026-01-01"},
+		{Query: "ledger", SafeSearch: "Maybe"},
+		{Query: "ledger", Sites: []string{"bad site"}},
+	}
+	for _, req := range invalid {
+		if err := req.Validate(); err == nil {
+			t.Errorf("Expected validation error for %+v", req)
+		}
+	}
+}
+
+func TestMockSearchPaginates(t *testing.T) {
+	resp, err := MockSearchClient{}.Search(context.Background(), SearchRequest{Query: "manus", Count: 2, Offset: 4})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if resp.TotalResults != 5 || len(resp.Results) != 1 {
+		t.Errorf("Expected last page of 1 out of 5, got %d of %d", len(resp.Results), resp.TotalResults)
+	}
+}
+
+func newReplayClient(t *testing.T) *BingSearchClient {
+	t.Helper()
+	transport, err := replay.NewTransport(replay.ModeReplay, "testdata/cassettes/bing.json", nil)
+	if err != nil {
+		t.Fatalf("Failed to open cassette: %v", err)
+	}
+	return NewBingSearchClient("replay-key", "https://api.bing.microsoft.com/v7.0/search", WithTransport(transport))
+}
+
+func TestBingReplaySearchAnomalyContext(t *testing.T) {
+	client := newReplayClient(t)
+
+	resp, err := client.SearchAnomalyContext(context.Background(), models.AnomalyTypeLedgerDivergence)
+	if err != nil {
+		t.Fatalf("SearchAnomalyContext failed: %v", err)
+	}
+	if resp.TotalResults != 48200 || len(resp.Results) != 2 {
+		t.Errorf("Expected 2 of 48200 results, got %d of %d", len(resp.Results), resp.TotalResults)
+	}
+	if resp.Results[0].URL != "https://manus.blockchain/docs/ledger-divergence" {
+		t.Errorf("Unexpected first result: %s", resp.Results[0].URL)
+	}
+}
+
+func TestBingReplayPaginatedQuery(t *testing.T) {
+	client := newReplayClient(t)
+
+	resp, err := client.Search(context.Background(), SearchRequest{
+		Query:     "MANUS Blockchain ledger divergence",
+		Count:     1,
+		Offset:    1,
+		Freshness: FreshnessWeek,
+	})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if resp.Offset != 1 || len(resp.Results) != 1 {
+		t.Errorf("Expected second page with one result, got offset %d and %d results", resp.Offset, len(resp.Results))
+	}
+}
+
+func TestBingReplayUpstreamError(t *testing.T) {
+	client := newReplayClient(t)
+
+	_, err := client.Search(context.Background(), SearchRequest{Query: "rate limited query", Count: 5})
+	if err == nil || !strings.Contains(err.Error(), "status 429") {
+		t.Errorf("Expected recorded 429 to surface, got %v", err)
+	}
+}
+
+func TestBingReplayReportsUnrecordedQuery(t *testing.T) {
+	client := newReplayClient(t)
+
+	_, err := client.Search(context.Background(), SearchRequest{Query: "never recorded", Count: 5})
+	if !errors.Is(err, replay.ErrNoInteraction) {
+		t.Errorf("Expected ErrNoInteraction, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package search
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// CircuitBreaker fails fast after a run of consecutive upstream failures
+// and lets a single trial request through once the cooldown has passed
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	trial     bool
+	trips     int
+	now       func() time.Time
+	mu        sync.Mutex
+}
+
+// NewCircuitBreaker opens after threshold consecutive failures and stays
+// open for cooldown
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether a request may be sent upstream
+func (c *CircuitBreaker) Allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case BreakerOpen:
+		if c.now().Sub(c.openedAt) < c.cooldown {
+			return false
+		}
+		c.state = BreakerHalfOpen
+		c.trial = true
+		return true
+	case BreakerHalfOpen:
+		if c.trial {
+			return false
+		}
+		c.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Record reports the outcome of a request allowed by Allow
+func (c *CircuitBreaker) Record(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.trial = false
+	if err == nil {
+		c.state = BreakerClosed
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	if c.state == BreakerHalfOpen || c.failures >= c.threshold {
+		c.state = BreakerOpen
+		c.openedAt = c.now()
+		c.trips++
+	}
+}
+
+// Cancel returns a slot granted by Allow without recording an outcome
+func (c *CircuitBreaker) Cancel() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.trial = false
+}
+
+// State returns the current breaker state
+func (c *CircuitBreaker) State() BreakerState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state
+}
+
+// Trips returns how many times the breaker has opened
+func (c *CircuitBreaker) Trips() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.trips
+}
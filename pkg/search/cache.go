@@ -0,0 +1,92 @@
+package search
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// ResponseCache is a size-bounded LRU of search responses with a TTL.
+// Expired entries are kept until evicted so they can be served as a
+// stale fallback when the upstream is unavailable.
+type ResponseCache struct {
+	ttl     time.Duration
+	maxSize int
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+type cacheEntry struct {
+	key      string
+	response *models.SearchResponse
+	expires  time.Time
+}
+
+// NewResponseCache creates a cache holding at most maxSize responses
+func NewResponseCache(ttl time.Duration, maxSize int) *ResponseCache {
+	return &ResponseCache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// Get returns the cached response for key and whether it is still fresh
+func (c *ResponseCache) Get(key string) (resp *models.SearchResponse, fresh bool, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, false
+	}
+	c.order.MoveToFront(elem)
+
+	entry := elem.Value.(*cacheEntry)
+	return entry.response, c.now().Before(entry.expires), true
+}
+
+// Put stores resp under key, evicting the least recently used entry when
+// the cache is full
+func (c *ResponseCache) Put(key string, resp *models.SearchResponse) {
+	if c.maxSize <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.response = resp
+		entry.expires = c.now().Add(c.ttl)
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{
+		key:      key,
+		response: resp,
+		expires:  c.now().Add(c.ttl),
+	})
+
+	for c.order.Len() > c.maxSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Len returns the number of cached responses
+func (c *ResponseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
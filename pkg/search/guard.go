@@ -0,0 +1,141 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// Errors returned when a guarded provider cannot reach its upstream and
+// has nothing to fall back to
+var (
+	ErrRateLimited = errors.New("search rate limit exceeded")
+	ErrCircuitOpen = errors.New("search circuit breaker is open")
+)
+
+// GuardStats reports cache, limiter and breaker counters
+type GuardStats struct {
+	Hits         int          `json:"cache_hits"`
+	Misses       int          `json:"cache_misses"`
+	StaleServed  int          `json:"stale_served"`
+	MockServed   int          `json:"fallback_served"`
+	RateLimited  int          `json:"rate_limited"`
+	BreakerOpen  int          `json:"breaker_rejections"`
+	Errors       int          `json:"upstream_errors"`
+	BreakerTrips int          `json:"breaker_trips"`
+	BreakerState BreakerState `json:"breaker_state"`
+	CacheSize    int          `json:"cache_size"`
+}
+
+// GuardedProvider wraps an upstream provider with a response cache, a
+// token-bucket rate limiter and a circuit breaker. When the upstream is
+// unavailable it serves stale cache entries, then the fallback provider.
+type GuardedProvider struct {
+	upstream Provider
+	fallback Provider
+	cache    *ResponseCache
+	limiter  *TokenBucket
+	breaker  *CircuitBreaker
+
+	stats GuardStats
+	mu    sync.Mutex
+}
+
+// NewGuardedProvider wraps upstream; fallback may be nil
+func NewGuardedProvider(upstream, fallback Provider, cache *ResponseCache, limiter *TokenBucket, breaker *CircuitBreaker) *GuardedProvider {
+	return &GuardedProvider{
+		upstream: upstream,
+		fallback: fallback,
+		cache:    cache,
+		limiter:  limiter,
+		breaker:  breaker,
+	}
+}
+
+// Name reports the upstream name so the guard is transparent to callers
+func (g *GuardedProvider) Name() string {
+	return g.upstream.Name()
+}
+
+// Search serves req from cache or the upstream
+func (g *GuardedProvider) Search(ctx context.Context, req SearchRequest) (*models.SearchResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	key := req.CacheKey()
+
+	cached, fresh, ok := g.cache.Get(key)
+	if ok && fresh {
+		g.count(func(s *GuardStats) { s.Hits++ })
+		return cached, nil
+	}
+	g.count(func(s *GuardStats) { s.Misses++ })
+
+	if !g.breaker.Allow() {
+		g.count(func(s *GuardStats) { s.BreakerOpen++ })
+		return g.fallbackFor(ctx, req, cached, ErrCircuitOpen)
+	}
+
+	if !g.limiter.Allow() {
+		g.breaker.Cancel()
+		g.count(func(s *GuardStats) { s.RateLimited++ })
+		return g.fallbackFor(ctx, req, cached, ErrRateLimited)
+	}
+
+	resp, err := g.upstream.Search(ctx, req)
+	if ctx.Err() != nil {
+		// The caller gave up; that says nothing about upstream health
+		g.breaker.Cancel()
+		return nil, ctx.Err()
+	}
+	g.breaker.Record(err)
+	if err != nil {
+		g.count(func(s *GuardStats) { s.Errors++ })
+		return g.fallbackFor(ctx, req, cached, err)
+	}
+
+	g.cache.Put(key, resp)
+	return resp, nil
+}
+
+// Stats returns a snapshot of the guard counters
+func (g *GuardedProvider) Stats() GuardStats {
+	g.mu.Lock()
+	stats := g.stats
+	g.mu.Unlock()
+
+	stats.BreakerTrips = g.breaker.Trips()
+	stats.BreakerState = g.breaker.State()
+	stats.CacheSize = g.cache.Len()
+	return stats
+}
+
+func (g *GuardedProvider) fallbackFor(ctx context.Context, req SearchRequest, stale *models.SearchResponse, cause error) (*models.SearchResponse, error) {
+	if stale != nil {
+		g.count(func(s *GuardStats) { s.StaleServed++ })
+		resp := *stale
+		resp.Source = fmt.Sprintf("%s (cached, %v)", stale.Source, cause)
+		return &resp, nil
+	}
+
+	if g.fallback != nil {
+		resp, err := g.fallback.Search(ctx, req)
+		if err == nil {
+			g.count(func(s *GuardStats) { s.MockServed++ })
+			resp.Source = fmt.Sprintf("%s (fallback, %v)", resp.Source, cause)
+			return resp, nil
+		}
+	}
+
+	return nil, cause
+}
+
+func (g *GuardedProvider) count(fn func(*GuardStats)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	fn(&g.stats)
+}
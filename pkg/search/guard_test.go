@@ -0,0 +1,193 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+type stubProvider struct {
+	err   error
+	calls int
+}
+
+func (s *stubProvider) Name() string { return "stub" }
+
+func (s *stubProvider) Search(ctx context.Context, req SearchRequest) (*models.SearchResponse, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.SearchResponse{Query: req.Query, Source: "stub"}, nil
+}
+
+func TestCacheKeyNormalisesQuery(t *testing.T) {
+	a := SearchRequest{Query: "  Ledger   DIVERGENCE ", Count: 5, Sites: []string{"Manus.Blockchain"}}
+	b := SearchRequest{Query: "ledger divergence", Count: 5, Sites: []string{"manus.blockchain"}}
+	if a.CacheKey() != b.CacheKey() {
+		t.Error("Expected whitespace and case to be normalised")
+	}
+
+	b.Offset = 10
+	if a.CacheKey() == b.CacheKey() {
+		t.Error("Expected offset to be part of the key")
+	}
+}
+
+func TestResponseCacheLRUAndTTL(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	cache := NewResponseCache(time.Minute, 2)
+	cache.now = clock.now
+
+	cache.Put("a", &models.SearchResponse{Query: "a"})
+	cache.Put("b", &models.SearchResponse{Query: "b"})
+	cache.Get("a")
+	cache.Put("c", &models.SearchResponse{Query: "c"})
+
+	if _, _, ok := cache.Get("b"); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	if _, fresh, ok := cache.Get("a"); !ok || !fresh {
+		t.Error("Expected recently used entry to be fresh")
+	}
+
+	clock.advance(2 * time.Minute)
+	if _, fresh, ok := cache.Get("a"); !ok || fresh {
+		t.Error("Expected expired entry to be kept as stale")
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	bucket := NewTokenBucket(1, 2)
+	bucket.now = clock.now
+	bucket.last = clock.t
+
+	if !bucket.Allow() || !bucket.Allow() {
+		t.Fatal("Expected burst of 2 to be allowed")
+	}
+	if bucket.Allow() {
+		t.Fatal("Expected third request to be limited")
+	}
+
+	clock.advance(time.Second)
+	if !bucket.Allow() {
+		t.Error("Expected a token after refill")
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	breaker := NewCircuitBreaker(2, time.Minute)
+	breaker.now = clock.now
+
+	failure := errors.New("boom")
+	breaker.Record(failure)
+	breaker.Record(failure)
+
+	if breaker.State() != BreakerOpen || breaker.Allow() {
+		t.Fatal("Expected breaker to open after threshold failures")
+	}
+
+	clock.advance(time.Minute)
+	if !breaker.Allow() {
+		t.Fatal("Expected a trial request after cooldown")
+	}
+	if breaker.Allow() {
+		t.Fatal("Expected only one trial request while half-open")
+	}
+
+	breaker.Record(nil)
+	if breaker.State() != BreakerClosed {
+		t.Errorf("Expected breaker to close after a successful trial, got %s", breaker.State())
+	}
+	if breaker.Trips() != 1 {
+		t.Errorf("Expected 1 trip, got %d", breaker.Trips())
+	}
+}
+
+func TestGuardedProviderFallbacks(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Now()}
+	upstream := &stubProvider{}
+	cache := NewResponseCache(time.Minute, 10)
+	cache.now = clock.now
+	breaker := NewCircuitBreaker(1, time.Hour)
+	guard := NewGuardedProvider(upstream, MockSearchClient{}, cache, NewTokenBucket(100, 100), breaker)
+
+	if _, err := guard.Search(ctx, SearchRequest{Query: "ledger", Count: 5}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if _, err := guard.Search(ctx, SearchRequest{Query: "LEDGER", Count: 5}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if upstream.calls != 1 {
+		t.Fatalf("Expected cached second call, got %d upstream calls", upstream.calls)
+	}
+
+	// Expired entry is served stale once the upstream fails
+	clock.advance(2 * time.Minute)
+	upstream.err = errors.New("upstream timeout")
+	resp, err := guard.Search(ctx, SearchRequest{Query: "ledger", Count: 5})
+	if err != nil || !strings.Contains(resp.Source, "cached") {
+		t.Fatalf("Expected stale cached response, got %v, %v", resp, err)
+	}
+
+	// Breaker is now open; an uncached query falls back to mock results
+	resp, err = guard.Search(ctx, SearchRequest{Query: "dao votes", Count: 5})
+	if err != nil || !strings.Contains(resp.Source, "fallback") {
+		t.Fatalf("Expected mock fallback, got %v, %v", resp, err)
+	}
+	if upstream.calls != 2 {
+		t.Errorf("Expected open breaker to skip upstream, got %d calls", upstream.calls)
+	}
+
+	stats := guard.Stats()
+	if stats.Hits != 1 || stats.StaleServed != 1 || stats.MockServed != 1 || stats.BreakerOpen != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if stats.BreakerState != BreakerOpen || stats.BreakerTrips != 1 {
+		t.Errorf("Expected open breaker with 1 trip, got %+v", stats)
+	}
+}
+
+func TestGuardedProviderRateLimitWithoutFallback(t *testing.T) {
+	ctx := context.Background()
+	guard := NewGuardedProvider(&stubProvider{}, nil, NewResponseCache(time.Minute, 10),
+		NewTokenBucket(0, 1), NewCircuitBreaker(5, time.Minute))
+
+	if _, err := guard.Search(ctx, SearchRequest{Query: "first", Count: 5}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if _, err := guard.Search(ctx, SearchRequest{Query: "second", Count: 5}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestGuardedProviderIgnoresCallerCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	breaker := NewCircuitBreaker(1, time.Minute)
+	guard := NewGuardedProvider(&stubProvider{err: context.Canceled}, nil, NewResponseCache(time.Minute, 10),
+		NewTokenBucket(100, 100), breaker)
+
+	if _, err := guard.Search(ctx, SearchRequest{Query: "ledger"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if breaker.State() != BreakerClosed {
+		t.Error("Expected cancelled requests not to trip the breaker")
+	}
+}
@@ -0,0 +1,248 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// BM25 tuning parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Indexed field names
+const (
+	FieldDescription = "description"
+	FieldResolution  = "resolution"
+	FieldMetadata    = "metadata"
+)
+
+// DefaultBoosts weights resolutions above descriptions so that past fixes
+// rank first when a similar anomaly reappears
+var DefaultBoosts = map[string]float64{
+	FieldDescription: 1.0,
+	FieldResolution:  1.5,
+	FieldMetadata:    0.5,
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "in": true, "is": true,
+	"it": true, "of": true, "on": true, "or": true, "the": true, "to": true,
+	"was": true, "with": true,
+}
+
+// Document is a unit of text stored in the index
+type Document struct {
+	ID        string
+	Kind      string
+	Title     string
+	URL       string
+	Fields    map[string]string
+	Timestamp time.Time
+}
+
+// Hit is a scored match returned by Index.Query
+type Hit struct {
+	Document Document
+	Score    float64
+}
+
+type posting struct {
+	docID string
+	freq  int
+}
+
+type fieldIndex struct {
+	postings map[string][]posting
+	lengths  map[string]int
+	// terms lists each document's distinct terms so removing it only
+	// touches its own postings
+	terms       map[string][]string
+	totalLength int
+}
+
+// Index is an in-memory inverted index ranked with BM25 per field
+type Index struct {
+	boosts map[string]float64
+	docs   map[string]Document
+	fields map[string]*fieldIndex
+	mu     sync.RWMutex
+}
+
+// NewIndex creates an empty index; nil boosts selects DefaultBoosts
+func NewIndex(boosts map[string]float64) *Index {
+	if boosts == nil {
+		boosts = DefaultBoosts
+	}
+	return &Index{
+		boosts: boosts,
+		docs:   make(map[string]Document),
+		fields: make(map[string]*fieldIndex),
+	}
+}
+
+// Add indexes doc, replacing any previous document with the same ID
+func (idx *Index) Add(doc Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(doc.ID)
+	idx.docs[doc.ID] = doc
+
+	for field, text := range doc.Fields {
+		terms := Tokenize(text)
+		if len(terms) == 0 {
+			continue
+		}
+
+		fi, ok := idx.fields[field]
+		if !ok {
+			fi = &fieldIndex{
+				postings: make(map[string][]posting),
+				lengths:  make(map[string]int),
+				terms:    make(map[string][]string),
+			}
+			idx.fields[field] = fi
+		}
+
+		freqs := make(map[string]int)
+		for _, term := range terms {
+			freqs[term]++
+		}
+		distinct := make([]string, 0, len(freqs))
+		for term, freq := range freqs {
+			fi.postings[term] = append(fi.postings[term], posting{docID: doc.ID, freq: freq})
+			distinct = append(distinct, term)
+		}
+		fi.terms[doc.ID] = distinct
+		fi.lengths[doc.ID] = len(terms)
+		fi.totalLength += len(terms)
+	}
+}
+
+// Remove deletes the document with the given ID
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+}
+
+func (idx *Index) remove(id string) {
+	if _, ok := idx.docs[id]; !ok {
+		return
+	}
+	delete(idx.docs, id)
+
+	for _, fi := range idx.fields {
+		length, ok := fi.lengths[id]
+		if !ok {
+			continue
+		}
+		fi.totalLength -= length
+		delete(fi.lengths, id)
+
+		for _, term := range fi.terms[id] {
+			postings := fi.postings[term]
+			kept := postings[:0]
+			for _, p := range postings {
+				if p.docID != id {
+					kept = append(kept, p)
+				}
+			}
+			if len(kept) == 0 {
+				delete(fi.postings, term)
+			} else {
+				fi.postings[term] = kept
+			}
+		}
+		delete(fi.terms, id)
+	}
+}
+
+// Len returns the number of indexed documents
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.docs)
+}
+
+// Query returns up to limit documents ranked by boosted BM25 score.
+// A limit of zero or less returns every match.
+func (idx *Index) Query(query string, limit int) []Hit {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := make(map[string]float64)
+	n := float64(len(idx.docs))
+
+	for field, fi := range idx.fields {
+		boost, ok := idx.boosts[field]
+		if !ok {
+			boost = 1.0
+		}
+		if boost == 0 || len(fi.lengths) == 0 {
+			continue
+		}
+		avgLength := float64(fi.totalLength) / float64(len(fi.lengths))
+
+		for _, term := range terms {
+			postings := fi.postings[term]
+			if len(postings) == 0 {
+				continue
+			}
+			df := float64(len(postings))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+			for _, p := range postings {
+				tf := float64(p.freq)
+				norm := 1 - bm25B + bm25B*float64(fi.lengths[p.docID])/avgLength
+				scores[p.docID] += boost * idf * (tf * (bm25K1 + 1)) / (tf + bm25K1*norm)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{Document: idx.docs[id], Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Document.ID < hits[j].Document.ID
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// Tokenize lowercases text, splits it on non-alphanumeric runes and drops
+// stop words
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := fields[:0]
+	for _, f := range fields {
+		if !stopWords[f] {
+			terms = append(terms, f)
+		}
+	}
+	return terms
+}
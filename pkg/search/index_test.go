@@ -0,0 +1,136 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blockchain"
+)
+
+func TestTokenize(t *testing.T) {
+	terms := Tokenize("The Ledger-hashes diverge on Mars_node_1!")
+	expected := []string{"ledger", "hashes", "diverge", "mars", "node", "1"}
+
+	if len(terms) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, terms)
+	}
+	for i := range expected {
+		if terms[i] != expected[i] {
+			t.Errorf("Expected term %q at %d, got %q", expected[i], i, terms[i])
+		}
+	}
+}
+
+func TestIndexQueryRanksResolutionMatches(t *testing.T) {
+	idx := NewIndex(nil)
+	idx.Add(Document{ID: "a", Fields: map[string]string{
+		FieldDescription: "Ledger hashes diverge across planetary nodes",
+	}})
+	idx.Add(Document{ID: "b", Fields: map[string]string{
+		FieldDescription: "Ledger hashes diverge on Mars",
+		FieldResolution:  "Resynced ledger from Earth peer",
+	}})
+	idx.Add(Document{ID: "c", Fields: map[string]string{
+		FieldDescription: "DAO votes fail to propagate",
+	}})
+
+	hits := idx.Query("ledger resync", 10)
+	if len(hits) != 2 {
+		t.Fatalf("Expected 2 hits, got %d", len(hits))
+	}
+	if hits[0].Document.ID != "b" {
+		t.Errorf("Expected resolved document first, got %s", hits[0].Document.ID)
+	}
+}
+
+func TestIndexIncrementalUpdate(t *testing.T) {
+	idx := NewIndex(nil)
+	idx.Add(Document{ID: "a", Fields: map[string]string{FieldDescription: "node desync"}})
+	idx.Add(Document{ID: "a", Fields: map[string]string{FieldDescription: "commit anomaly"}})
+
+	if idx.Len() != 1 {
+		t.Fatalf("Expected 1 document, got %d", idx.Len())
+	}
+	if hits := idx.Query("desync", 10); len(hits) != 0 {
+		t.Errorf("Expected stale terms to be dropped, got %d hits", len(hits))
+	}
+	if hits := idx.Query("commit", 10); len(hits) != 1 {
+		t.Errorf("Expected 1 hit for updated terms, got %d", len(hits))
+	}
+
+	idx.Add(Document{ID: "b", Fields: map[string]string{FieldDescription: "commit lag"}})
+	idx.Remove("a")
+	if hits := idx.Query("commit", 10); len(hits) != 1 || hits[0].Document.ID != "b" {
+		t.Errorf("Expected only b after removal, got %v", hits)
+	}
+	fi := idx.fields[FieldDescription]
+	if len(fi.postings) != 2 || len(fi.terms) != 1 {
+		t.Errorf("Expected a's postings and terms to be dropped, got %d postings, %d documents", len(fi.postings), len(fi.terms))
+	}
+}
+
+func TestLocalSearchClient(t *testing.T) {
+	local := NewLocalSearchClient(NewIndex(nil))
+	local.IndexAnomaly(models.Anomaly{
+		ID:          "1234",
+		Type:        models.AnomalyTypeDAOVoteFailure,
+		Description: "DAO votes fail to propagate",
+		Resolution:  "Re-broadcast vote from Moon relay",
+		Metadata:    map[string]interface{}{"proposal_id": "PROP-2026-001"},
+	})
+	local.IndexLedgerEntry(blockchain.LedgerEntry{
+		TxHash:      "0xabc",
+		AnomalyID:   "1234",
+		Description: "Re-broadcast vote from Moon relay",
+	})
+
+	ctx := context.Background()
+	resp, err := local.Search(ctx, SearchRequest{Query: "PROP-2026-001", Count: 5})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(resp.Results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(resp.Results))
+	}
+
+	id, ok := AnomalyIDFromURL(resp.Results[0].URL)
+	if !ok || id != "1234" {
+		t.Errorf("Expected anomaly URL for 1234, got %s", resp.Results[0].URL)
+	}
+
+	resp, _ = local.Search(ctx, SearchRequest{Query: "moon relay", Count: 5})
+	if resp.TotalResults != 2 {
+		t.Errorf("Expected anomaly and ledger entry to match, got %d", resp.TotalResults)
+	}
+
+	resp, _ = local.Search(ctx, SearchRequest{Query: "moon relay", Sites: []string{KindLedgerEntry}})
+	if resp.TotalResults != 1 || resp.Results[0].URL != "ledger://0xabc" {
+		t.Errorf("Expected site filter to keep only the ledger entry, got %+v", resp.Results)
+	}
+
+	resp, _ = local.Search(ctx, SearchRequest{Query: "moon relay", Count: 1, Offset: 1})
+	if resp.TotalResults != 2 || len(resp.Results) != 1 {
+		t.Errorf("Expected second page of one result out of 2, got %d of %d", len(resp.Results), resp.TotalResults)
+	}
+}
+
+func TestLedgerEntriesWrittenTogetherAreAllIndexed(t *testing.T) {
+	local := NewLocalSearchClient(NewIndex(nil))
+	ledger := blockchain.NewManusClient("http://localhost:9545", "1", false)
+	ledger.Subscribe(local.IndexLedgerEntry)
+
+	// Same anomaly and no anomaly, all within the same second
+	ledger.LogAnomaly("1234", "relay check alpha")
+	ledger.LogAnomaly("1234", "relay check beta")
+	ledger.RebroadcastVote("1234", "PROP-1", "moon-relay", "alice")
+	ledger.RebroadcastVote("1234", "PROP-2", "moon-relay", "alice")
+
+	resp, err := local.Search(context.Background(), SearchRequest{Query: "relay", Count: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(resp.Results) != 4 {
+		t.Errorf("Expected all 4 ledger entries, got %d", len(resp.Results))
+	}
+}
@@ -0,0 +1,131 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/pkg/blockchain"
+)
+
+// Document kinds stored by the local provider
+const (
+	KindAnomaly     = "anomaly"
+	KindLedgerEntry = "ledger"
+)
+
+// LocalSearchClient answers searches from the embedded index of anomalies,
+// resolutions and ledger entries
+type LocalSearchClient struct {
+	index *Index
+}
+
+// NewLocalSearchClient creates a local search provider backed by index
+func NewLocalSearchClient(index *Index) *LocalSearchClient {
+	return &LocalSearchClient{index: index}
+}
+
+// Name identifies the local provider
+func (l *LocalSearchClient) Name() string {
+	return "local"
+}
+
+// Index exposes the underlying index
+func (l *LocalSearchClient) Index() *Index {
+	return l.index
+}
+
+// Search queries the local index. Sites match the result URL scheme, so
+// "anomaly" or "ledger" restrict results to one kind of document, and
+// freshness filters on detection or ledger time.
+func (l *LocalSearchClient) Search(ctx context.Context, sr SearchRequest) (*models.SearchResponse, error) {
+	if err := sr.Validate(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	from, to := sr.Window(time.Now())
+	var results []models.SearchResult
+	for _, hit := range l.index.Query(sr.Query, 0) {
+		doc := hit.Document
+		if !sr.MatchesSite(doc.URL) {
+			continue
+		}
+		if (!from.IsZero() && doc.Timestamp.Before(from)) || (!to.IsZero() && doc.Timestamp.After(to)) {
+			continue
+		}
+		results = append(results, models.SearchResult{
+			Title:       doc.Title,
+			URL:         doc.URL,
+			Description: doc.Fields[FieldResolution],
+			Snippet:     doc.Fields[FieldDescription],
+		})
+	}
+
+	return &models.SearchResponse{
+		Query:        sr.Query,
+		TotalResults: len(results),
+		Offset:       sr.Offset,
+		Results:      paginate(results, sr.Offset, sr.Count),
+		Source:       "Local Anomaly Index",
+	}, nil
+}
+
+// IndexAnomaly adds or refreshes an anomaly in the index
+func (l *LocalSearchClient) IndexAnomaly(a models.Anomaly) {
+	l.index.Add(Document{
+		ID:    KindAnomaly + ":" + a.ID,
+		Kind:  KindAnomaly,
+		Title: fmt.Sprintf("[%s] %s", a.Type, a.Description),
+		URL:   "anomaly://" + a.ID,
+		Fields: map[string]string{
+			FieldDescription: a.Description,
+			FieldResolution:  a.Resolution,
+			FieldMetadata:    flattenMetadata(string(a.Type), a.Source, a.Metadata),
+		},
+		Timestamp: a.DetectedAt,
+	})
+}
+
+// IndexLedgerEntry adds a ledger entry to the index
+func (l *LocalSearchClient) IndexLedgerEntry(e blockchain.LedgerEntry) {
+	l.index.Add(Document{
+		ID:    KindLedgerEntry + ":" + e.TxHash,
+		Kind:  KindLedgerEntry,
+		Title: fmt.Sprintf("Ledger entry %s", e.TxHash),
+		URL:   "ledger://" + e.TxHash,
+		Fields: map[string]string{
+			FieldResolution: e.Description,
+			FieldMetadata:   flattenMetadata(e.AnomalyID, "", e.Payload),
+		},
+		Timestamp: e.RecordedAt,
+	})
+}
+
+// AnomalyIDFromURL extracts the anomaly ID from a local result URL
+func AnomalyIDFromURL(u string) (string, bool) {
+	if !strings.HasPrefix(u, "anomaly://") {
+		return "", false
+	}
+	return strings.TrimPrefix(u, "anomaly://"), true
+}
+
+// flattenMetadata renders metadata as "key value" pairs in a stable order
+func flattenMetadata(prefix, source string, metadata map[string]interface{}) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := []string{prefix, source}
+	for _, k := range keys {
+		parts = append(parts, k, fmt.Sprint(metadata[k]))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,21 @@
+package search
+
+import (
+	"context"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// Provider is implemented by every backend that can answer /api/v1/search
+type Provider interface {
+	// Name identifies the provider in the "provider" query parameter
+	Name() string
+	// Search runs req and returns one page of results. Implementations
+	// must honour cancellation of ctx.
+	Search(ctx context.Context, req SearchRequest) (*models.SearchResponse, error)
+}
+
+// Name identifies the Bing provider
+func (b *BingSearchClient) Name() string {
+	return "bing"
+}
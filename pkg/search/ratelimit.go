@@ -0,0 +1,47 @@
+package search
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket is a token-bucket rate limiter refilled at a steady rate
+type TokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+// NewTokenBucket allows ratePerSecond requests on average with bursts of
+// up to burst requests
+func NewTokenBucket(ratePerSecond float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:   ratePerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// Allow reports whether a request may proceed and consumes a token if so
+func (t *TokenBucket) Allow() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.tokens += now.Sub(t.last).Seconds() * t.rate
+	if t.tokens > t.burst {
+		t.tokens = t.burst
+	}
+	t.last = now
+
+	if t.tokens < 1 {
+		return false
+	}
+	t.tokens--
+	return true
+}
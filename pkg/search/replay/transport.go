@@ -0,0 +1,307 @@
+// Package replay provides an http.RoundTripper that records upstream
+// responses to cassette files and replays them offline, so search and
+// enrichment tests run deterministically without a Bing key or network.
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether the transport records or replays
+type Mode string
+
+const (
+	ModeRecord Mode = "record"
+	ModeReplay Mode = "replay"
+)
+
+// ErrNoInteraction is wrapped by replay errors for unrecorded requests
+var ErrNoInteraction = errors.New("replay: no recorded interaction")
+
+// sensitiveParams are dropped from recorded URLs and request keys
+var sensitiveParams = map[string]bool{
+	"key":              true,
+	"api_key":          true,
+	"subscription-key": true,
+}
+
+// Request identifies a recorded request. Headers are never stored so
+// subscription keys cannot leak into cassettes.
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+// Response is a recorded upstream response. JSON bodies are stored inline
+// for readability; anything else is stored as a string.
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	JSON    json.RawMessage   `json:"json,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// Interaction pairs a request with its recorded response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is the on-disk collection of interactions
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Transport records or replays HTTP interactions against a cassette file
+type Transport struct {
+	mode     Mode
+	path     string
+	next     http.RoundTripper
+	cassette Cassette
+	index    map[string]int
+	mu       sync.Mutex
+}
+
+// NewTransport opens the cassette at path. Replay mode requires the file
+// to exist; record mode appends to it, creating it if needed. next is
+// used for real requests in record mode and defaults to
+// http.DefaultTransport.
+func NewTransport(mode Mode, path string, next http.RoundTripper) (*Transport, error) {
+	if mode != ModeRecord && mode != ModeReplay {
+		return nil, fmt.Errorf("replay: unknown mode %q: use record or replay", mode)
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	t := &Transport{
+		mode:  mode,
+		path:  path,
+		next:  next,
+		index: make(map[string]int),
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &t.cassette); err != nil {
+			return nil, fmt.Errorf("replay: invalid cassette %s: %w", path, err)
+		}
+	case errors.Is(err, os.ErrNotExist) && mode == ModeRecord:
+	default:
+		return nil, fmt.Errorf("replay: cannot open cassette: %w", err)
+	}
+
+	for i, interaction := range t.cassette.Interactions {
+		t.index[key(interaction.Request.Method, interaction.Request.URL)] = i
+	}
+	return t, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	recordedURL := canonicalURL(req.URL)
+	k := key(req.Method, recordedURL)
+
+	if t.mode == ModeReplay {
+		t.mu.Lock()
+		i, ok := t.index[k]
+		var interaction Interaction
+		if ok {
+			interaction = t.cassette.Interactions[i]
+		}
+		t.mu.Unlock()
+
+		if !ok {
+			return nil, t.mismatch(req.Method, recordedURL)
+		}
+		return interaction.Response.toHTTP(req), nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("replay: failed to read response for recording: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := t.record(Interaction{
+		Request:  Request{Method: req.Method, URL: recordedURL},
+		Response: fromHTTP(resp, body),
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Interactions returns the number of interactions in the cassette
+func (t *Transport) Interactions() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.cassette.Interactions)
+}
+
+// record stores an interaction, replacing any earlier recording of the
+// same request, and rewrites the cassette file
+func (t *Transport) record(interaction Interaction) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	k := key(interaction.Request.Method, interaction.Request.URL)
+	if i, ok := t.index[k]; ok {
+		t.cassette.Interactions[i] = interaction
+	} else {
+		t.index[k] = len(t.cassette.Interactions)
+		t.cassette.Interactions = append(t.cassette.Interactions, interaction)
+	}
+
+	data, err := json.MarshalIndent(t.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("replay: failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return fmt.Errorf("replay: failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(t.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("replay: failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// mismatch builds an error naming the unmatched request and, for the
+// closest recording with the same method and path, the query parameters
+// that differ
+func (t *Transport) mismatch(method, rawURL string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.cassette.Interactions) == 0 {
+		return fmt.Errorf("%w: %s %s; cassette %s is empty", ErrNoInteraction, method, rawURL, t.path)
+	}
+
+	want, _ := url.Parse(rawURL)
+	best, bestDiff := "", []string(nil)
+	for _, interaction := range t.cassette.Interactions {
+		got, err := url.Parse(interaction.Request.URL)
+		if err != nil || interaction.Request.Method != method || got.Path != want.Path || got.Host != want.Host {
+			continue
+		}
+		diff := diffQuery(want.Query(), got.Query())
+		if best == "" || len(diff) < len(bestDiff) {
+			best, bestDiff = interaction.Request.URL, diff
+		}
+	}
+
+	if best == "" {
+		return fmt.Errorf("%w: %s %s in cassette %s; no recording shares its method and path",
+			ErrNoInteraction, method, rawURL, t.path)
+	}
+	return fmt.Errorf("%w: %s %s in cassette %s; closest recording %s differs in %s",
+		ErrNoInteraction, method, rawURL, t.path, best, strings.Join(bestDiff, ", "))
+}
+
+func (r Response) toHTTP(req *http.Request) *http.Response {
+	body := []byte(r.Body)
+	if len(r.JSON) > 0 {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, r.JSON); err == nil {
+			body = compact.Bytes()
+		} else {
+			body = r.JSON
+		}
+	}
+
+	header := make(http.Header)
+	for k, v := range r.Headers {
+		header.Set(k, v)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func fromHTTP(resp *http.Response, body []byte) Response {
+	recorded := Response{
+		Status:  resp.StatusCode,
+		Headers: map[string]string{},
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		recorded.Headers["Content-Type"] = ct
+	}
+
+	var compact bytes.Buffer
+	if json.Valid(body) && json.Compact(&compact, body) == nil {
+		recorded.JSON = compact.Bytes()
+	} else {
+		recorded.Body = string(body)
+	}
+	return recorded
+}
+
+// canonicalURL sorts query parameters and drops credentials
+func canonicalURL(u *url.URL) string {
+	c := *u
+	q := c.Query()
+	for name := range q {
+		if sensitiveParams[strings.ToLower(name)] {
+			q.Del(name)
+		}
+	}
+	c.RawQuery = q.Encode()
+	c.Fragment = ""
+	c.User = nil
+	return c.String()
+}
+
+func key(method, rawURL string) string {
+	return method + " " + rawURL
+}
+
+func diffQuery(want, got url.Values) []string {
+	names := make(map[string]bool)
+	for name := range want {
+		names[name] = true
+	}
+	for name := range got {
+		names[name] = true
+	}
+
+	var diff []string
+	for name := range names {
+		if strings.Join(want[name], ",") != strings.Join(got[name], ",") {
+			diff = append(diff, fmt.Sprintf("%s (want %q, recorded %q)",
+				name, strings.Join(want[name], ","), strings.Join(got[name], ",")))
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
@@ -0,0 +1,103 @@
+package replay
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"webPages": {"value": []}}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassettes", "search.json")
+	recorder, err := NewTransport(ModeRecord, path, nil)
+	if err != nil {
+		t.Fatalf("NewTransport failed: %v", err)
+	}
+
+	client := &http.Client{Transport: recorder}
+	req, _ := http.NewRequest("GET", server.URL+"/search?q=ledger&count=5&subscription-key=secret", nil)
+	req.Header.Set("Ocp-Apim-Subscription-Key", "secret")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Recording request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "webPages") {
+		t.Fatalf("Expected upstream body to be passed through, got %s", body)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Cassette not written: %v", err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Error("Cassette must not contain credentials")
+	}
+
+	player, err := NewTransport(ModeReplay, path, nil)
+	if err != nil {
+		t.Fatalf("NewTransport failed: %v", err)
+	}
+	client = &http.Client{Transport: player}
+
+	// Parameter order does not matter when matching
+	resp, err = client.Get(server.URL + "/search?count=5&q=ledger")
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	replayed, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected replayed response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if string(replayed) != `{"webPages":{"value":[]}}` {
+		t.Errorf("Unexpected replayed body: %s", replayed)
+	}
+	if calls != 1 {
+		t.Errorf("Expected replay not to reach the server, got %d calls", calls)
+	}
+}
+
+func TestReplayMismatchNamesDifferingParams(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.json")
+	os.WriteFile(path, []byte(`{"interactions": [{
+		"request": {"method": "GET", "url": "https://api.example.com/search?count=5&q=ledger"},
+		"response": {"status": 200, "body": "ok"}
+	}]}`), 0o644)
+
+	player, err := NewTransport(ModeReplay, path, nil)
+	if err != nil {
+		t.Fatalf("NewTransport failed: %v", err)
+	}
+
+	_, err = (&http.Client{Transport: player}).Get("https://api.example.com/search?count=10&q=ledger")
+	if !errors.Is(err, ErrNoInteraction) {
+		t.Fatalf("Expected ErrNoInteraction, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `count (want "10", recorded "5")`) {
+		t.Errorf("Expected mismatch to name the differing parameter, got %v", err)
+	}
+}
+
+func TestReplayRequiresCassette(t *testing.T) {
+	if _, err := NewTransport(ModeReplay, filepath.Join(t.TempDir(), "missing.json"), nil); err == nil {
+		t.Error("Expected error for missing cassette in replay mode")
+	}
+	if _, err := NewTransport("sometimes", "", nil); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
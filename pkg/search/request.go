@@ -0,0 +1,187 @@
+package search
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Result count limits accepted by Bing
+const (
+	DefaultCount = 10
+	MaxCount     = 50
+)
+
+// Freshness restricts results by age
+type Freshness string
+
+const (
+	FreshnessAny   Freshness = ""
+	FreshnessDay   Freshness = "Day"
+	FreshnessWeek  Freshness = "Week"
+	FreshnessMonth Freshness = "Month"
+)
+
+// SafeSearch controls adult content filtering
+type SafeSearch string
+
+const (
+	SafeSearchDefault  SafeSearch = ""
+	SafeSearchOff      SafeSearch = "Off"
+	SafeSearchModerate SafeSearch = "Moderate"
+	SafeSearchStrict   SafeSearch = "Strict"
+)
+
+var dateRangePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}\.\.\d{4}-\d{2}-\d{2}$`)
+
+// SearchRequest carries a query and the options every provider honours.
+// Market, Language and SafeSearch only affect web providers.
+type SearchRequest struct {
+	Query      string
+	Count      int
+	Offset     int
+	Market     string
+	Language   string
+	Freshness  Freshness
+	SafeSearch SafeSearch
+	Sites      []string
+}
+
+// Validate fills defaults and rejects unsupported option values
+func (r *SearchRequest) Validate() error {
+	if strings.TrimSpace(r.Query) == "" {
+		return fmt.Errorf("query is required")
+	}
+	if r.Count == 0 {
+		r.Count = DefaultCount
+	}
+	if r.Count < 0 || r.Count > MaxCount {
+		return fmt.Errorf("count must be between 1 and %d", MaxCount)
+	}
+	if r.Offset < 0 {
+		return fmt.Errorf("offset must not be negative")
+	}
+
+	switch r.Freshness {
+	case FreshnessAny, FreshnessDay, FreshnessWeek, FreshnessMonth:
+	default:
+		if _, _, err := r.dateRange(); err != nil {
+			return err
+		}
+	}
+
+	switch r.SafeSearch {
+	case SafeSearchDefault, SafeSearchOff, SafeSearchModerate, SafeSearchStrict:
+	default:
+		return fmt.Errorf("invalid safeSearch %q: use Off, Moderate or Strict", r.SafeSearch)
+	}
+
+	for _, site := range r.Sites {
+		if site == "" || strings.ContainsAny(site, " /") {
+			return fmt.Errorf("invalid site filter %q", site)
+		}
+	}
+	return nil
+}
+
+// EffectiveQuery returns the query with site filters appended in Bing
+// operator syntax
+func (r SearchRequest) EffectiveQuery() string {
+	if len(r.Sites) == 0 {
+		return r.Query
+	}
+
+	filters := make([]string, len(r.Sites))
+	for i, site := range r.Sites {
+		filters[i] = "site:" + site
+	}
+	if len(filters) == 1 {
+		return r.Query + " " + filters[0]
+	}
+	return fmt.Sprintf("%s (%s)", r.Query, strings.Join(filters, " OR "))
+}
+
+// CacheKey normalises the request into a cache key
+func (r SearchRequest) CacheKey() string {
+	sites := make([]string, len(r.Sites))
+	for i, site := range r.Sites {
+		sites[i] = strings.ToLower(site)
+	}
+	return strings.Join([]string{
+		strings.Join(strings.Fields(strings.ToLower(r.Query)), " "),
+		fmt.Sprint(r.Count),
+		fmt.Sprint(r.Offset),
+		strings.ToLower(r.Market),
+		strings.ToLower(r.Language),
+		string(r.Freshness),
+		string(r.SafeSearch),
+		strings.Join(sites, ","),
+	}, "|")
+}
+
+// Window returns the time range admitted by Freshness relative to now;
+// zero values leave that end unbounded
+func (r SearchRequest) Window(now time.Time) (from, to time.Time) {
+	switch r.Freshness {
+	case FreshnessDay:
+		return now.Add(-24 * time.Hour), time.Time{}
+	case FreshnessWeek:
+		return now.Add(-7 * 24 * time.Hour), time.Time{}
+	case FreshnessMonth:
+		return now.AddDate(0, -1, 0), time.Time{}
+	case FreshnessAny:
+		return time.Time{}, time.Time{}
+	}
+	from, to, _ = r.dateRange()
+	return from, to
+}
+
+// MatchesSite reports whether rawURL passes the site filters. Hosts match
+// a site exactly or as a subdomain; for non-web URLs such as anomaly://
+// the scheme is matched instead.
+func (r SearchRequest) MatchesSite(rawURL string) bool {
+	if len(r.Sites) == 0 {
+		return true
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, site := range r.Sites {
+		site = strings.ToLower(site)
+		if u.Scheme != "http" && u.Scheme != "https" {
+			if u.Scheme == site {
+				return true
+			}
+			continue
+		}
+		if host == site || strings.HasSuffix(host, "."+site) {
+			return true
+		}
+	}
+	return false
+}
+
+// dateRange parses a Bing "YYYY-MM-DD..YYYY-MM-DD" freshness range
+func (r SearchRequest) dateRange() (from, to time.Time, err error) {
+	value := string(r.Freshness)
+	if !dateRangePattern.MatchString(value) {
+		return from, to, fmt.Errorf("invalid freshness %q: use Day, Week, Month or YYYY-MM-DD..YYYY-MM-DD", value)
+	}
+
+	parts := strings.SplitN(value, "..", 2)
+	if from, err = time.Parse("2006-01-02", parts[0]); err != nil {
+		return from, to, fmt.Errorf("invalid freshness start: %w", err)
+	}
+	if to, err = time.Parse("2006-01-02", parts[1]); err != nil {
+		return from, to, fmt.Errorf("invalid freshness end: %w", err)
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("freshness range ends before it starts")
+	}
+	return from, to.Add(24*time.Hour - time.Nanosecond), nil
+}
@@ -0,0 +1,163 @@
+package timeseries
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Point is one observation of a metric
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// result is a point's score against its baseline
+type result struct {
+	Expected float64
+	Spread   float64
+	Score    float64
+	// EWMA is the smoothed value for ewma metrics
+	EWMA float64
+	// Slot is the seasonal slot the point fell in
+	Slot int
+}
+
+// series holds the rolling state of one metric and label set
+type series struct {
+	cfg      MetricConfig
+	points   []Point
+	slots    map[int][]Point
+	ewma     float64
+	started  bool
+	alerting bool
+	last     time.Time
+}
+
+func newSeries(cfg MetricConfig) *series {
+	s := &series{cfg: cfg}
+	if cfg.Algorithm == AlgorithmSeasonal {
+		s.slots = make(map[int][]Point)
+	}
+	return s
+}
+
+// observe scores p against the baseline before adding it. It returns the
+// baseline used and false while there are too few points to judge.
+func (s *series) observe(p Point) (result, []Point, bool) {
+	cfg := s.cfg
+	s.last = p.Time
+
+	var res result
+	baseline := s.points
+	if cfg.Algorithm == AlgorithmSeasonal {
+		res.Slot = s.slot(p.Time)
+		baseline = s.slots[res.Slot]
+	}
+	baseline = append([]Point(nil), baseline...)
+	ready := len(baseline) >= cfg.MinPoints
+
+	if ready {
+		values := valuesOf(baseline)
+		switch cfg.Algorithm {
+		case AlgorithmZScore, AlgorithmSeasonal:
+			res.Expected, res.Spread = meanStdDev(values)
+			res.Score = (p.Value - res.Expected) / math.Max(res.Spread, cfg.MinSpread)
+		case AlgorithmMAD:
+			res.Expected = median(values)
+			deviations := make([]float64, len(values))
+			for i, v := range values {
+				deviations[i] = math.Abs(v - res.Expected)
+			}
+			res.Spread = median(deviations)
+			// 0.6745 scales the MAD to a standard deviation for normal data
+			res.Score = 0.6745 * (p.Value - res.Expected) / math.Max(res.Spread, cfg.MinSpread)
+		case AlgorithmEWMA:
+			mean, stdDev := meanStdDev(values)
+			res.EWMA = cfg.Alpha*p.Value + (1-cfg.Alpha)*s.ewma
+			res.Expected = mean
+			// Steady-state standard deviation of the EWMA statistic
+			res.Spread = stdDev * math.Sqrt(cfg.Alpha/(2-cfg.Alpha))
+			res.Score = (res.EWMA - mean) / math.Max(res.Spread, cfg.MinSpread)
+		}
+	}
+
+	s.add(p, res.Slot)
+	return res, baseline, ready
+}
+
+func (s *series) add(p Point, slot int) {
+	if s.started {
+		s.ewma = s.cfg.Alpha*p.Value + (1-s.cfg.Alpha)*s.ewma
+	} else {
+		s.ewma = p.Value
+		s.started = true
+	}
+
+	if s.cfg.Algorithm == AlgorithmSeasonal {
+		s.slots[slot] = trim(append(s.slots[slot], p), s.cfg.Window)
+		return
+	}
+	s.points = trim(append(s.points, p), s.cfg.Window)
+}
+
+// slot maps t to its position within the seasonal period
+func (s *series) slot(t time.Time) int {
+	period := int64(s.cfg.Period)
+	offset := t.UnixNano() % period
+	if offset < 0 {
+		offset += period
+	}
+	return int(offset / (period / int64(s.cfg.Slots)))
+}
+
+// exceeds reports whether score breaches the threshold in the configured
+// direction
+func (c MetricConfig) exceeds(score float64) bool {
+	switch c.Direction {
+	case DirectionAbove:
+		return score >= c.Sensitivity
+	case DirectionBelow:
+		return score <= -c.Sensitivity
+	}
+	return math.Abs(score) >= c.Sensitivity
+}
+
+func trim(points []Point, window int) []Point {
+	if len(points) > window {
+		points = append([]Point(nil), points[len(points)-window:]...)
+	}
+	return points
+}
+
+func valuesOf(points []Point) []float64 {
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.Value
+	}
+	return values
+}
+
+func meanStdDev(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)))
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,161 @@
+// Package timeseries detects anomalies in numeric metrics such as node
+// latency, block interval and vote propagation time using rolling z-scores,
+// EWMA control charts, MAD-based robust outliers and seasonal baselines.
+package timeseries
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// Algorithm selects how a metric's baseline and score are computed
+type Algorithm string
+
+const (
+	// AlgorithmZScore scores a value by standard deviations from the mean
+	// of the rolling window
+	AlgorithmZScore Algorithm = "zscore"
+	// AlgorithmEWMA flags an exponentially weighted moving average that
+	// leaves its control limits, catching small sustained shifts
+	AlgorithmEWMA Algorithm = "ewma"
+	// AlgorithmMAD scores a value by the modified z-score against the
+	// window's median and median absolute deviation, ignoring outliers in
+	// the baseline
+	AlgorithmMAD Algorithm = "mad"
+	// AlgorithmSeasonal scores a value against past values in the same
+	// slot of the period, e.g. the same hour of the day
+	AlgorithmSeasonal Algorithm = "seasonal"
+)
+
+// Direction limits which deviations are anomalous
+type Direction string
+
+const (
+	DirectionBoth  Direction = "both"
+	DirectionAbove Direction = "above"
+	DirectionBelow Direction = "below"
+)
+
+// MetricConfig configures detection for one metric
+type MetricConfig struct {
+	Name      string    `json:"name"`
+	Algorithm Algorithm `json:"algorithm"`
+	// Window is how many recent points form the baseline (per slot for
+	// seasonal metrics)
+	Window int `json:"window"`
+	// Sensitivity is the score threshold: standard deviations for zscore,
+	// ewma and seasonal, modified z-score for mad. Lower is more sensitive.
+	Sensitivity float64   `json:"sensitivity"`
+	Direction   Direction `json:"direction,omitempty"`
+	// MinPoints is how many baseline points are needed before scoring
+	MinPoints int `json:"min_points,omitempty"`
+	// Alpha is the EWMA smoothing factor in (0, 1]
+	Alpha float64 `json:"alpha,omitempty"`
+	// Period and Slots define the seasonal cycle, e.g. 24h in 24 slots
+	Period time.Duration `json:"period,omitempty"`
+	Slots  int           `json:"slots,omitempty"`
+	// MinSpread floors the baseline spread so a flat series does not turn
+	// every small change into an outlier
+	MinSpread float64 `json:"min_spread,omitempty"`
+
+	Type models.AnomalyType `json:"type"`
+	// Severity overrides the severity derived from the score
+	Severity models.AnomalySeverity `json:"severity,omitempty"`
+	Unit     string                 `json:"unit,omitempty"`
+}
+
+// DefaultMetrics covers the metrics reported by Manus nodes
+var DefaultMetrics = []MetricConfig{
+	{
+		Name:        "node_latency_ms",
+		Algorithm:   AlgorithmSeasonal,
+		Window:      14,
+		Sensitivity: 3,
+		Direction:   DirectionAbove,
+		Period:      24 * time.Hour,
+		Slots:       24,
+		MinSpread:   5,
+		Type:        models.AnomalyTypeNodeDesynchronization,
+		Unit:        "ms",
+	},
+	{
+		Name:        "block_interval_s",
+		Algorithm:   AlgorithmEWMA,
+		Window:      60,
+		Sensitivity: 3,
+		Alpha:       0.2,
+		MinSpread:   0.1,
+		Type:        models.AnomalyTypeLedgerDivergence,
+		Unit:        "s",
+	},
+	{
+		Name:        "vote_propagation_ms",
+		Algorithm:   AlgorithmMAD,
+		Window:      50,
+		Sensitivity: 3.5,
+		Direction:   DirectionAbove,
+		MinSpread:   10,
+		Type:        models.AnomalyTypeDAOVoteFailure,
+		Unit:        "ms",
+	},
+}
+
+// withDefaults validates c and fills in unset fields
+func (c MetricConfig) withDefaults() (MetricConfig, error) {
+	if c.Name == "" {
+		return c, fmt.Errorf("metric name is required")
+	}
+	switch c.Algorithm {
+	case AlgorithmZScore, AlgorithmEWMA, AlgorithmMAD, AlgorithmSeasonal:
+	case "":
+		c.Algorithm = AlgorithmZScore
+	default:
+		return c, fmt.Errorf("metric %s: unknown algorithm %q", c.Name, c.Algorithm)
+	}
+	switch c.Direction {
+	case DirectionBoth, DirectionAbove, DirectionBelow:
+	case "":
+		c.Direction = DirectionBoth
+	default:
+		return c, fmt.Errorf("metric %s: unknown direction %q", c.Name, c.Direction)
+	}
+
+	if c.Window <= 0 {
+		c.Window = 30
+	}
+	if c.Sensitivity <= 0 {
+		c.Sensitivity = 3
+		if c.Algorithm == AlgorithmMAD {
+			c.Sensitivity = 3.5
+		}
+	}
+	if c.MinPoints <= 0 {
+		c.MinPoints = max(3, c.Window/2)
+	}
+	if c.MinPoints > c.Window {
+		return c, fmt.Errorf("metric %s: min_points %d exceeds window %d", c.Name, c.MinPoints, c.Window)
+	}
+	if c.Alpha == 0 {
+		c.Alpha = 0.3
+	}
+	if c.Alpha < 0 || c.Alpha > 1 {
+		return c, fmt.Errorf("metric %s: alpha must be in (0, 1]", c.Name)
+	}
+	if c.Algorithm == AlgorithmSeasonal {
+		if c.Period <= 0 {
+			c.Period = 24 * time.Hour
+		}
+		if c.Slots <= 0 {
+			c.Slots = 24
+		}
+	}
+	if c.MinSpread <= 0 {
+		c.MinSpread = 1e-9
+	}
+	if c.Type == "" {
+		c.Type = models.AnomalyTypeUnknown
+	}
+	return c, nil
+}
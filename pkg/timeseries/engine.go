@@ -0,0 +1,242 @@
+package timeseries
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+// Source is recorded on anomalies the engine detects
+const Source = "Time-Series Engine"
+
+var (
+	// ErrUnknownMetric is returned for samples of an unconfigured metric
+	ErrUnknownMetric = errors.New("unknown metric")
+	// ErrOutOfOrder is returned for a sample older than its series' latest
+	ErrOutOfOrder = errors.New("sample is older than the latest point")
+)
+
+// Sample is a metric observation, optionally labelled with e.g. the node
+// or route it was measured on. Each label set is tracked as its own series.
+type Sample struct {
+	Metric string            `json:"metric"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Time   time.Time         `json:"time"`
+	Value  float64           `json:"value"`
+}
+
+// Engine scores incoming samples against per-series baselines
+type Engine struct {
+	metrics   map[string]MetricConfig
+	series    map[string]*series
+	maxSeries int
+	mu        sync.Mutex
+}
+
+// NewEngine creates an engine for the given metrics, tracking at most the
+// default store's number of series
+func NewEngine(metrics ...MetricConfig) (*Engine, error) {
+	e := &Engine{
+		metrics:   make(map[string]MetricConfig),
+		series:    make(map[string]*series),
+		maxSeries: DefaultStoreConfig().MaxSeries,
+	}
+	for _, m := range metrics {
+		cfg, err := m.withDefaults()
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := e.metrics[cfg.Name]; exists {
+			return nil, fmt.Errorf("metric %s configured twice", cfg.Name)
+		}
+		e.metrics[cfg.Name] = cfg
+	}
+	return e, nil
+}
+
+// SetMaxSeries caps the distinct label sets scored; samples for new series
+// beyond the cap are rejected with ErrTooManySeries. Zero or less removes
+// the cap.
+func (e *Engine) SetMaxSeries(n int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.maxSeries = n
+}
+
+// Metrics returns the effective configuration of every metric
+func (e *Engine) Metrics() []MetricConfig {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	metrics := make([]MetricConfig, 0, len(e.metrics))
+	for _, cfg := range e.metrics {
+		metrics = append(metrics, cfg)
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+	return metrics
+}
+
+// Has reports whether metric is configured for detection
+func (e *Engine) Has(metric string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.metrics[metric]
+	return ok
+}
+
+// Ingest scores samples in order and returns an anomaly for each series
+// that breaches its threshold. A series is reported once per excursion and
+// again only after it has returned to normal. Samples that cannot be
+// scored are skipped and their errors joined.
+func (e *Engine) Ingest(samples ...Sample) ([]*models.Anomaly, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var anomalies []*models.Anomaly
+	var errs []error
+	for _, sample := range samples {
+		cfg, ok := e.metrics[sample.Metric]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrUnknownMetric, sample.Metric))
+			continue
+		}
+		if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+			errs = append(errs, fmt.Errorf("%s: value must be finite", sample.Metric))
+			continue
+		}
+		if sample.Time.IsZero() {
+			sample.Time = time.Now()
+		}
+
+		key := SeriesKey(sample.Metric, sample.Labels)
+		s, ok := e.series[key]
+		if !ok {
+			if e.maxSeries > 0 && len(e.series) >= e.maxSeries {
+				errs = append(errs, fmt.Errorf("%w: %s", ErrTooManySeries, key))
+				continue
+			}
+			s = newSeries(cfg)
+			e.series[key] = s
+		}
+		if sample.Time.Before(s.last) {
+			errs = append(errs, fmt.Errorf("%w: %s at %s", ErrOutOfOrder, key, sample.Time.Format(time.RFC3339)))
+			continue
+		}
+
+		point := Point{Time: sample.Time, Value: sample.Value}
+		res, baseline, ready := s.observe(point)
+		if !ready || !cfg.exceeds(res.Score) {
+			s.alerting = false
+			continue
+		}
+		if s.alerting {
+			continue
+		}
+		s.alerting = true
+		anomalies = append(anomalies, newAnomaly(cfg, sample, res, append(baseline, point)))
+	}
+	return anomalies, errors.Join(errs...)
+}
+
+func newAnomaly(cfg MetricConfig, sample Sample, res result, window []Point) *models.Anomaly {
+	severity := cfg.Severity
+	if severity == "" {
+		severity = severityFor(math.Abs(res.Score) / cfg.Sensitivity)
+	}
+
+	direction := "above"
+	if res.Score < 0 {
+		direction = "below"
+	}
+	series := SeriesKey(sample.Metric, sample.Labels)
+
+	metadata := map[string]interface{}{
+		"metric":    sample.Metric,
+		"algorithm": string(cfg.Algorithm),
+		"value":     sample.Value,
+		"expected":  round(res.Expected),
+		"spread":    round(res.Spread),
+		"score":     round(res.Score),
+		"threshold": cfg.Sensitivity,
+		"window":    window,
+	}
+	if len(sample.Labels) > 0 {
+		metadata["labels"] = sample.Labels
+		// Flatten labels so playbooks and runbooks can reference them
+		for k, v := range sample.Labels {
+			if _, taken := metadata[k]; !taken {
+				metadata[k] = v
+			}
+		}
+	}
+	if cfg.Unit != "" {
+		metadata["unit"] = cfg.Unit
+	}
+	switch cfg.Algorithm {
+	case AlgorithmEWMA:
+		metadata["ewma"] = round(res.EWMA)
+	case AlgorithmSeasonal:
+		metadata["slot"] = res.Slot
+	}
+
+	return &models.Anomaly{
+		Type: cfg.Type,
+		Description: fmt.Sprintf("%s %g%s is %.1f %s %s expected %g (%s)",
+			series, sample.Value, cfg.Unit, math.Abs(res.Score), scoreUnit(cfg.Algorithm), direction,
+			round(res.Expected), cfg.Algorithm),
+		Severity:   severity,
+		Status:     models.StatusDetected,
+		DetectedAt: sample.Time,
+		Metadata:   metadata,
+		Source:     Source,
+	}
+}
+
+// severityFor grades how far past the threshold a score is
+func severityFor(ratio float64) models.AnomalySeverity {
+	switch {
+	case ratio >= 3:
+		return models.SeverityCritical
+	case ratio >= 2:
+		return models.SeverityHigh
+	case ratio >= 1.5:
+		return models.SeverityMedium
+	}
+	return models.SeverityLow
+}
+
+func scoreUnit(algorithm Algorithm) string {
+	if algorithm == AlgorithmMAD {
+		return "robust σ"
+	}
+	return "σ"
+}
+
+// SeriesKey names the series of a metric and labels as metric{k=v,...}
+func SeriesKey(metric string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return metric
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + labels[k]
+	}
+	return metric + "{" + strings.Join(pairs, ",") + "}"
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,255 @@
+package timeseries
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexandrepedrosaai/Manus-Copilot-Github-Anomalis-Coopetition-Integration/internal/models"
+)
+
+var start = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// noisy returns n samples alternating around base so the baseline has a
+// small, known spread
+func noisy(metric string, base float64, n int, step time.Duration, labels map[string]string) []Sample {
+	samples := make([]Sample, n)
+	for i := range samples {
+		jitter := float64(i%5) - 2
+		samples[i] = Sample{Metric: metric, Labels: labels, Time: start.Add(time.Duration(i) * step), Value: base + jitter}
+	}
+	return samples
+}
+
+func newTestEngine(t *testing.T, metrics ...MetricConfig) *Engine {
+	t.Helper()
+	engine, err := NewEngine(metrics...)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	return engine
+}
+
+func TestZScoreFlagsSpikeOncePerExcursion(t *testing.T) {
+	engine := newTestEngine(t, MetricConfig{
+		Name:      "node_latency_ms",
+		Algorithm: AlgorithmZScore,
+		Window:    20,
+		Type:      models.AnomalyTypeNodeDesynchronization,
+		Unit:      "ms",
+	})
+	labels := map[string]string{"route": "Earth-Mars"}
+
+	history := noisy("node_latency_ms", 300, 20, time.Minute, labels)
+	if found, err := engine.Ingest(history...); err != nil || len(found) != 0 {
+		t.Fatalf("Expected quiet baseline, got %d anomalies, err %v", len(found), err)
+	}
+
+	at := start.Add(20 * time.Minute)
+	found, err := engine.Ingest(
+		Sample{Metric: "node_latency_ms", Labels: labels, Time: at, Value: 450},
+		Sample{Metric: "node_latency_ms", Labels: labels, Time: at.Add(time.Minute), Value: 460},
+	)
+	if err != nil {
+		t.Fatalf("Ingest failed: %v", err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("Expected one anomaly for the excursion, got %d", len(found))
+	}
+
+	a := found[0]
+	if a.Type != models.AnomalyTypeNodeDesynchronization || a.Source != Source || !a.DetectedAt.Equal(at) {
+		t.Errorf("Unexpected anomaly: %+v", a)
+	}
+	if a.Severity != models.SeverityCritical {
+		t.Errorf("Expected far outlier to be critical, got %s", a.Severity)
+	}
+	if a.Metadata["route"] != "Earth-Mars" || a.Metadata["expected"] != 300.0 || a.Metadata["value"] != 450.0 {
+		t.Errorf("Unexpected metadata: %v", a.Metadata)
+	}
+	window := a.Metadata["window"].([]Point)
+	if len(window) != 21 || window[len(window)-1].Value != 450 {
+		t.Errorf("Expected baseline plus the outlier as evidence, got %d points", len(window))
+	}
+	if a.Description != "node_latency_ms{route=Earth-Mars} 450ms is 106.1 σ above expected 300 (zscore)" {
+		t.Errorf("Unexpected description: %s", a.Description)
+	}
+}
+
+func TestRecoveryRearmsSeries(t *testing.T) {
+	engine := newTestEngine(t, MetricConfig{Name: "m", Window: 10, MinPoints: 5})
+	samples := noisy("m", 100, 10, time.Second, nil)
+	at := start.Add(10 * time.Second)
+	samples = append(samples,
+		Sample{Metric: "m", Time: at, Value: 200},
+		Sample{Metric: "m", Time: at.Add(time.Second), Value: 100},
+		Sample{Metric: "m", Time: at.Add(2 * time.Second), Value: 300},
+	)
+
+	found, _ := engine.Ingest(samples...)
+	if len(found) != 2 {
+		t.Errorf("Expected a second anomaly after recovery, got %d", len(found))
+	}
+}
+
+func TestDirection(t *testing.T) {
+	engine := newTestEngine(t, MetricConfig{Name: "m", Window: 10, Direction: DirectionAbove})
+	samples := append(noisy("m", 100, 10, time.Second, nil),
+		Sample{Metric: "m", Time: start.Add(time.Minute), Value: 10})
+
+	if found, _ := engine.Ingest(samples...); len(found) != 0 {
+		t.Error("Expected drop to be ignored for an above-only metric")
+	}
+}
+
+func TestMADIgnoresOutliersInBaseline(t *testing.T) {
+	metric := MetricConfig{Name: "vote_propagation_ms", Window: 20}
+
+	// A couple of earlier outliers inflate the standard deviation enough
+	// to hide a new one from the z-score, but not from the median
+	samples := noisy("vote_propagation_ms", 100, 20, time.Second, nil)
+	samples[3].Value = 900
+	samples[11].Value = 950
+	spike := Sample{Metric: "vote_propagation_ms", Time: start.Add(time.Minute), Value: 400}
+
+	zscore := newTestEngine(t, metric)
+	zscore.Ingest(samples...)
+	if found, _ := zscore.Ingest(spike); len(found) != 0 {
+		t.Fatal("Expected the z-score baseline to be masked by earlier outliers")
+	}
+
+	metric.Algorithm = AlgorithmMAD
+	mad := newTestEngine(t, metric)
+	mad.Ingest(samples...)
+	found, _ := mad.Ingest(spike)
+	if len(found) != 1 {
+		t.Fatal("Expected MAD to flag the spike")
+	}
+	if found[0].Metadata["expected"] != 100.0 || found[0].Metadata["algorithm"] != "mad" {
+		t.Errorf("Expected median baseline, got %v", found[0].Metadata)
+	}
+}
+
+func TestEWMACatchesSustainedShift(t *testing.T) {
+	metric := MetricConfig{Name: "block_interval_s", Window: 60, Sensitivity: 3, Alpha: 0.2}
+	samples := noisy("block_interval_s", 12, 60, 12*time.Second, nil)
+
+	// A shift of about two standard deviations never trips a per-point
+	// z-score but accumulates in the EWMA
+	var shifted []Sample
+	for i := 0; i < 15; i++ {
+		shifted = append(shifted, Sample{
+			Metric: "block_interval_s",
+			Time:   start.Add(time.Hour + time.Duration(i)*12*time.Second),
+			Value:  14.5 + float64(i%3)*0.1,
+		})
+	}
+
+	zscore := newTestEngine(t, metric)
+	zscore.Ingest(samples...)
+	if found, _ := zscore.Ingest(shifted...); len(found) != 0 {
+		t.Fatal("Expected z-score to miss the small shift")
+	}
+
+	metric.Algorithm = AlgorithmEWMA
+	ewma := newTestEngine(t, metric)
+	ewma.Ingest(samples...)
+	found, _ := ewma.Ingest(shifted...)
+	if len(found) != 1 {
+		t.Fatalf("Expected EWMA to flag the shift once, got %d", len(found))
+	}
+	if _, ok := found[0].Metadata["ewma"]; !ok {
+		t.Error("Expected ewma value in metadata")
+	}
+}
+
+func TestSeasonalBaseline(t *testing.T) {
+	engine := newTestEngine(t, MetricConfig{
+		Name:      "node_latency_ms",
+		Algorithm: AlgorithmSeasonal,
+		Window:    7,
+		MinPoints: 5,
+		Period:    24 * time.Hour,
+		Slots:     24,
+		MinSpread: 5,
+	})
+
+	// Latency peaks at 500ms at 18:00 every day and idles at 100ms otherwise
+	var samples []Sample
+	for day := 0; day < 7; day++ {
+		for hour := 0; hour < 24; hour++ {
+			value := 100.0
+			if hour == 18 {
+				value = 500
+			}
+			value += float64(day%3) * 2
+			samples = append(samples, Sample{
+				Metric: "node_latency_ms",
+				Time:   start.Add(time.Duration(day*24+hour) * time.Hour),
+				Value:  value,
+			})
+		}
+	}
+	if found, _ := engine.Ingest(samples...); len(found) != 0 {
+		t.Fatalf("Expected the daily peak to be learned, got %d anomalies", len(found))
+	}
+
+	day8 := start.Add(7 * 24 * time.Hour)
+	found, _ := engine.Ingest(
+		Sample{Metric: "node_latency_ms", Time: day8.Add(18 * time.Hour), Value: 505},
+		Sample{Metric: "node_latency_ms", Time: day8.Add(19 * time.Hour), Value: 500},
+	)
+	if len(found) != 1 || found[0].Metadata["slot"] != 19 {
+		t.Fatalf("Expected only the off-peak 500ms to be flagged, got %+v", found)
+	}
+}
+
+func TestIngestErrors(t *testing.T) {
+	engine := newTestEngine(t, MetricConfig{Name: "m"})
+
+	_, err := engine.Ingest(Sample{Metric: "missing", Value: 1})
+	if !errors.Is(err, ErrUnknownMetric) {
+		t.Errorf("Expected ErrUnknownMetric, got %v", err)
+	}
+
+	engine.Ingest(Sample{Metric: "m", Time: start.Add(time.Minute), Value: 1})
+	_, err = engine.Ingest(
+		Sample{Metric: "m", Time: start, Value: 1},
+		Sample{Metric: "m", Time: start.Add(2 * time.Minute), Value: math.NaN()},
+	)
+	if !errors.Is(err, ErrOutOfOrder) {
+		t.Errorf("Expected ErrOutOfOrder, got %v", err)
+	}
+
+	engine.SetMaxSeries(1)
+	_, err = engine.Ingest(
+		Sample{Metric: "m", Labels: map[string]string{"node": "a"}, Time: start, Value: 1},
+		Sample{Metric: "m", Time: start.Add(3 * time.Minute), Value: 1},
+	)
+	if !errors.Is(err, ErrTooManySeries) || strings.Contains(err.Error(), ErrOutOfOrder.Error()) {
+		t.Errorf("Expected only the new series to be rejected, got %v", err)
+	}
+	if len(engine.series) != 1 {
+		t.Errorf("Expected 1 series, got %d", len(engine.series))
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	for _, cfg := range []MetricConfig{
+		{},
+		{Name: "m", Algorithm: "fft"},
+		{Name: "m", Direction: "sideways"},
+		{Name: "m", Window: 5, MinPoints: 10},
+		{Name: "m", Alpha: 1.5},
+	} {
+		if _, err := NewEngine(cfg); err == nil {
+			t.Errorf("Expected %+v to be rejected", cfg)
+		}
+	}
+
+	if _, err := NewEngine(DefaultMetrics...); err != nil {
+		t.Errorf("Expected default metrics to be valid: %v", err)
+	}
+}
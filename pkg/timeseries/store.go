@@ -0,0 +1,374 @@
+package timeseries
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrTooManySeries is returned when a new series would exceed the store's
+// series limit
+var ErrTooManySeries = errors.New("series limit reached")
+
+// StoreConfig bounds the memory a Store uses
+type StoreConfig struct {
+	// Capacity is how many raw points are kept per series
+	Capacity int
+	// MaxSeries caps distinct metric and label combinations
+	MaxSeries int
+	// Resolution is the width of downsampled buckets; zero disables them
+	Resolution time.Duration
+	// Rollups is how many downsampled buckets are kept per series
+	Rollups int
+}
+
+// DefaultStoreConfig keeps a day of per-minute samples raw and a week of
+// one-minute rollups
+func DefaultStoreConfig() StoreConfig {
+	return StoreConfig{
+		Capacity:   1440,
+		MaxSeries:  10000,
+		Resolution: time.Minute,
+		Rollups:    7 * 24 * 60,
+	}
+}
+
+// Rollup summarises the points of one series within a bucket
+type Rollup struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+	Sum   float64   `json:"sum"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+}
+
+// Mean is the average value in the bucket
+func (r Rollup) Mean() float64 {
+	return r.Sum / float64(r.Count)
+}
+
+func (r *Rollup) add(v float64) {
+	if r.Count == 0 {
+		r.Min, r.Max = v, v
+	}
+	r.Count++
+	r.Sum += v
+	r.Min = math.Min(r.Min, v)
+	r.Max = math.Max(r.Max, v)
+}
+
+// SeriesInfo describes a stored series
+type SeriesInfo struct {
+	Series  string            `json:"series"`
+	Metric  string            `json:"metric"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Points  int               `json:"points"`
+	Rollups int               `json:"rollups"`
+	First   time.Time         `json:"first,omitempty"`
+	Last    time.Time         `json:"last,omitempty"`
+}
+
+// QueryResult is a series over a time range. Older parts of the range that
+// are no longer held raw are filled from rollup means.
+type QueryResult struct {
+	Series      string            `json:"series"`
+	Metric      string            `json:"metric"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+	Points      []Point           `json:"points"`
+	Downsampled int               `json:"downsampled"`
+}
+
+// ring is a fixed-size buffer that overwrites its oldest element
+type ring[T any] struct {
+	buf   []T
+	start int
+	n     int
+}
+
+func newRing[T any](size int) *ring[T] {
+	return &ring[T]{buf: make([]T, size)}
+}
+
+func (r *ring[T]) push(v T) {
+	if len(r.buf) == 0 {
+		return
+	}
+	if r.n < len(r.buf) {
+		r.buf[(r.start+r.n)%len(r.buf)] = v
+		r.n++
+		return
+	}
+	r.buf[r.start] = v
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+func (r *ring[T]) at(i int) *T {
+	return &r.buf[(r.start+i)%len(r.buf)]
+}
+
+func (r *ring[T]) last() *T {
+	if r.n == 0 {
+		return nil
+	}
+	return r.at(r.n - 1)
+}
+
+type storedSeries struct {
+	metric  string
+	labels  map[string]string
+	points  *ring[Point]
+	rollups *ring[Rollup]
+}
+
+// Store holds recent samples per series in bounded ring buffers, with
+// optional downsampled rollups that outlive the raw points and can be
+// persisted to disk
+type Store struct {
+	cfg    StoreConfig
+	series map[string]*storedSeries
+	mu     sync.RWMutex
+}
+
+// NewStore creates an empty store
+func NewStore(cfg StoreConfig) *Store {
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = DefaultStoreConfig().Capacity
+	}
+	if cfg.Resolution <= 0 {
+		cfg.Rollups = 0
+	}
+	return &Store{
+		cfg:    cfg,
+		series: make(map[string]*storedSeries),
+	}
+}
+
+// Append stores samples, skipping non-finite values and samples older than
+// their series' latest point, and returns how many were stored
+func (s *Store) Append(samples ...Sample) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := 0
+	var errs []error
+	for _, sample := range samples {
+		if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+			continue
+		}
+		if sample.Time.IsZero() {
+			sample.Time = time.Now()
+		}
+
+		key := SeriesKey(sample.Metric, sample.Labels)
+		series, err := s.seriesFor(key, sample.Metric, sample.Labels)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if last := series.points.last(); last != nil && sample.Time.Before(last.Time) {
+			errs = append(errs, fmt.Errorf("%w: %s at %s", ErrOutOfOrder, key, sample.Time.Format(time.RFC3339)))
+			continue
+		}
+
+		series.points.push(Point{Time: sample.Time, Value: sample.Value})
+		if s.cfg.Rollups > 0 {
+			bucket := sample.Time.Truncate(s.cfg.Resolution)
+			if r := series.rollups.last(); r != nil && r.Start.Equal(bucket) {
+				r.add(sample.Value)
+			} else if r == nil || bucket.After(r.Start) {
+				rollup := Rollup{Start: bucket}
+				rollup.add(sample.Value)
+				series.rollups.push(rollup)
+			}
+		}
+		stored++
+	}
+	return stored, errors.Join(errs...)
+}
+
+// seriesFor returns the series for key, creating it if the limit allows;
+// callers hold s.mu
+func (s *Store) seriesFor(key, metric string, labels map[string]string) (*storedSeries, error) {
+	if series, ok := s.series[key]; ok {
+		return series, nil
+	}
+	if s.cfg.MaxSeries > 0 && len(s.series) >= s.cfg.MaxSeries {
+		return nil, fmt.Errorf("%w: %s", ErrTooManySeries, key)
+	}
+
+	copied := make(map[string]string, len(labels))
+	for k, v := range labels {
+		copied[k] = v
+	}
+	series := &storedSeries{
+		metric:  metric,
+		labels:  copied,
+		points:  newRing[Point](s.cfg.Capacity),
+		rollups: newRing[Rollup](s.cfg.Rollups),
+	}
+	s.series[key] = series
+	return series, nil
+}
+
+// Series lists stored series, optionally for one metric
+func (s *Store) Series(metric string) []SeriesInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var infos []SeriesInfo
+	for key, series := range s.series {
+		if metric != "" && series.metric != metric {
+			continue
+		}
+		info := SeriesInfo{
+			Series:  key,
+			Metric:  series.metric,
+			Labels:  series.labels,
+			Points:  series.points.n,
+			Rollups: series.rollups.n,
+		}
+		if series.rollups.n > 0 {
+			info.First = series.rollups.at(0).Start
+		}
+		if series.points.n > 0 {
+			if first := series.points.at(0).Time; info.First.IsZero() || first.Before(info.First) {
+				info.First = first
+			}
+			info.Last = series.points.last().Time
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Series < infos[j].Series })
+	return infos
+}
+
+// Query returns the points of one series between from and to inclusive
+func (s *Store) Query(metric string, labels map[string]string, from, to time.Time) (*QueryResult, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key := SeriesKey(metric, labels)
+	series, ok := s.series[key]
+	if !ok {
+		return nil, false
+	}
+
+	result := &QueryResult{
+		Series: key,
+		Metric: series.metric,
+		Labels: series.labels,
+		From:   from,
+		To:     to,
+		Points: []Point{},
+	}
+
+	// Rollups cover whatever is older than the oldest raw point
+	var rawStart time.Time
+	if series.points.n > 0 {
+		rawStart = series.points.at(0).Time
+	}
+	for i := 0; i < series.rollups.n; i++ {
+		r := series.rollups.at(i)
+		if !rawStart.IsZero() && !r.Start.Before(rawStart.Truncate(s.cfg.Resolution)) {
+			break
+		}
+		if r.Start.Before(from) || r.Start.After(to) {
+			continue
+		}
+		result.Points = append(result.Points, Point{Time: r.Start, Value: r.Mean()})
+		result.Downsampled++
+	}
+
+	for i := 0; i < series.points.n; i++ {
+		p := series.points.at(i)
+		if p.Time.Before(from) || p.Time.After(to) {
+			continue
+		}
+		result.Points = append(result.Points, *p)
+	}
+	return result, true
+}
+
+type persistedSeries struct {
+	Metric  string            `json:"metric"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Rollups []Rollup          `json:"rollups"`
+}
+
+// Save writes every series' rollups to path as JSON. Raw points are not
+// persisted.
+func (s *Store) Save(path string) error {
+	s.mu.RLock()
+	persisted := make([]persistedSeries, 0, len(s.series))
+	for _, series := range s.series {
+		if series.rollups.n == 0 {
+			continue
+		}
+		p := persistedSeries{Metric: series.metric, Labels: series.labels}
+		for i := 0; i < series.rollups.n; i++ {
+			p.Rollups = append(p.Rollups, *series.rollups.at(i))
+		}
+		persisted = append(persisted, p)
+	}
+	s.mu.RUnlock()
+
+	data, err := json.Marshal(map[string]interface{}{"series": persisted})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+
+	// Write then rename so a crash never leaves a truncated file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+// Load restores rollups saved by Save. A missing file is not an error.
+func (s *Store) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	var file struct {
+		Series []persistedSeries `json:"series"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse metrics %s: %w", path, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range file.Series {
+		series, err := s.seriesFor(SeriesKey(p.Metric, p.Labels), p.Metric, p.Labels)
+		if err != nil {
+			return err
+		}
+		for _, r := range p.Rollups {
+			if last := series.rollups.last(); last == nil || r.Start.After(last.Start) {
+				series.rollups.push(r)
+			}
+		}
+	}
+	return nil
+}
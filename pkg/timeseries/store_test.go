@@ -0,0 +1,138 @@
+package timeseries
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreRingBufferAndRollups(t *testing.T) {
+	store := NewStore(StoreConfig{Capacity: 10, Resolution: time.Minute, Rollups: 100})
+	labels := map[string]string{"node": "moon-node-2"}
+
+	// 30 samples, 20s apart: 10 minutes of data, the last 10 held raw
+	for i := 0; i < 30; i++ {
+		n, err := store.Append(Sample{
+			Metric: "node_latency_ms",
+			Labels: labels,
+			Time:   start.Add(time.Duration(i) * 20 * time.Second),
+			Value:  float64(i),
+		})
+		if n != 1 || err != nil {
+			t.Fatalf("Append failed: %d %v", n, err)
+		}
+	}
+
+	info := store.Series("node_latency_ms")
+	if len(info) != 1 || info[0].Points != 10 || info[0].Rollups != 10 || !info[0].First.Equal(start) {
+		t.Fatalf("Unexpected series info: %+v", info)
+	}
+
+	result, ok := store.Query("node_latency_ms", labels, start, start.Add(time.Hour))
+	if !ok {
+		t.Fatal("Expected series to be found")
+	}
+	// Minutes 0-5 come from rollups; raw points start at 6:40
+	if result.Downsampled != 6 || len(result.Points) != 16 {
+		t.Fatalf("Expected 6 rollups and 10 raw points, got %d of %d", result.Downsampled, len(result.Points))
+	}
+	if first := result.Points[0]; first.Value != 1 || !first.Time.Equal(start) {
+		t.Errorf("Expected first minute to average 0,1,2, got %+v", first)
+	}
+	if last := result.Points[len(result.Points)-1]; last.Value != 29 {
+		t.Errorf("Expected last raw point, got %+v", last)
+	}
+
+	narrow, _ := store.Query("node_latency_ms", labels, start.Add(9*time.Minute), start.Add(time.Hour))
+	if narrow.Downsampled != 0 || len(narrow.Points) != 3 {
+		t.Errorf("Expected only the raw points in range, got %+v", narrow)
+	}
+
+	if _, ok := store.Query("node_latency_ms", nil, start, start.Add(time.Hour)); ok {
+		t.Error("Expected unlabelled series to be distinct")
+	}
+}
+
+func TestStoreRejectsBadSamples(t *testing.T) {
+	store := NewStore(StoreConfig{Capacity: 5, MaxSeries: 1})
+	store.Append(Sample{Metric: "m", Time: start.Add(time.Minute), Value: 1})
+
+	n, err := store.Append(
+		Sample{Metric: "m", Time: start, Value: 2},
+		Sample{Metric: "m", Time: start.Add(2 * time.Minute), Value: math.NaN()},
+		Sample{Metric: "other", Time: start, Value: 3},
+	)
+	if n != 0 || !errors.Is(err, ErrOutOfOrder) || !errors.Is(err, ErrTooManySeries) {
+		t.Errorf("Expected out of order and series limit errors, got %d %v", n, err)
+	}
+}
+
+func TestStorePersistsRollups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics", "rollups.json")
+	cfg := StoreConfig{Capacity: 5, Resolution: time.Minute, Rollups: 10}
+
+	store := NewStore(cfg)
+	for i := 0; i < 6; i++ {
+		store.Append(Sample{Metric: "block_interval_s", Time: start.Add(time.Duration(i) * 30 * time.Second), Value: 12})
+	}
+	if err := store.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	restored := NewStore(cfg)
+	if err := restored.Load(path); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	result, ok := restored.Query("block_interval_s", nil, start, start.Add(time.Hour))
+	if !ok || result.Downsampled != 3 || result.Points[0].Value != 12 {
+		t.Errorf("Expected 3 restored rollups, got %+v", result)
+	}
+
+	if err := NewStore(cfg).Load(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("Expected missing file to be ignored, got %v", err)
+	}
+}
+
+func TestParseText(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	text := `# HELP node_latency_ms Round trip latency to the peer
+# TYPE node_latency_ms gauge
+node_latency_ms{route="Earth-Mars",node="mars-node-1"} 452.5 1772366400000
+node_latency_ms{route="Earth-Moon", note="say \"hi\"\n"} 1.3e3
+block_interval_s 12
+vote_propagation_ms{} NaN
+`
+	samples, err := ParseText(strings.NewReader(text), now)
+	if err != nil {
+		t.Fatalf("ParseText failed: %v", err)
+	}
+	if len(samples) != 3 {
+		t.Fatalf("Expected 3 samples, got %d", len(samples))
+	}
+
+	first := samples[0]
+	if first.Labels["route"] != "Earth-Mars" || first.Value != 452.5 || !first.Time.Equal(time.UnixMilli(1772366400000)) {
+		t.Errorf("Unexpected first sample: %+v", first)
+	}
+	if samples[1].Labels["note"] != "say \"hi\"\n" || samples[1].Value != 1300 || !samples[1].Time.Equal(now) {
+		t.Errorf("Unexpected escaped sample: %+v", samples[1])
+	}
+	if samples[2].Metric != "block_interval_s" || samples[2].Labels != nil {
+		t.Errorf("Unexpected unlabelled sample: %+v", samples[2])
+	}
+
+	for _, bad := range []string{
+		"node_latency_ms",
+		`node_latency_ms{route="Earth} 1`,
+		`node_latency_ms{route=Earth} 1`,
+		"node_latency_ms abc",
+		"9lives 1",
+	} {
+		if _, err := ParseText(strings.NewReader(bad), now); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
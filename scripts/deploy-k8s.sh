@@ -48,6 +48,10 @@ fi
 echo -e "${YELLOW}🔐 Applying secrets...${NC}"
 kubectl apply -f ${DEPLOYMENT_DIR}/secrets.yaml -n ${NAMESPACE}
 
+# Apply database used for leader election
+echo -e "${YELLOW}🐘 Deploying Postgres...${NC}"
+kubectl apply -f ${DEPLOYMENT_DIR}/postgres.yaml -n ${NAMESPACE}
+
 # Apply deployment
 echo -e "${YELLOW}🚢 Deploying application...${NC}"
 kubectl apply -f ${DEPLOYMENT_DIR}/deployment.yaml -n ${NAMESPACE}